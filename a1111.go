@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- AUTOMATIC1111 BACKEND ---
+
+// Renders shots through the SD WebUI API (AUTOMATIC1111, Forge), with AnimateDiff when set.
+
+type a1111Backend struct {
+	a      *App
+	server ComfyServer
+}
+
+const (
+	a1111Steps         = 25
+	a1111Denoise       = 0.6
+	a1111MaxSize       = 1024 // Long edge for img2img at the source image's aspect
+	animateDiffFPS     = 8.0  // AnimateDiff's native rate when none is configured
+	a1111RenderTimeout = 60 * time.Minute
+)
+
+func (b a1111Backend) ping(server string) error {
+	return b.a.comfyJSONAt(server, "GET", "/sdapi/v1/progress?skip_current_image=true", nil, nil)
+}
+
+func (b a1111Backend) freeMemory(server string) error {
+	return b.a.comfyJSONAt(server, "POST", "/sdapi/v1/unload-checkpoint", nil, nil)
+}
+
+func (b a1111Backend) generate(server string, shot Shot, workflowName string, outPath string) error {
+	a := b.a
+	duration := shot.Duration
+	if duration <= 0 {
+		duration = 4
+	}
+	fps := a.configuredFPS(shot, workflowName)
+	if fps <= 0 {
+		fps = defaultRenderFPS
+		if b.server.Motion != "" {
+			fps = animateDiffFPS
+		}
+	}
+
+	payload := map[string]interface{}{
+		"prompt":                               shot.Prompt,
+		"seed":                                 shot.Seed,
+		"steps":                                a1111Steps,
+		"send_images":                          true,
+		"save_images":                          false,
+		"override_settings_restore_afterwards": true,
+	}
+	endpoint := "/sdapi/v1/txt2img"
+	if shot.SourceImage != "" {
+		image, err := os.ReadFile(shot.SourceImage)
+		if err != nil {
+			return fmt.Errorf("source image is missing")
+		}
+		endpoint = "/sdapi/v1/img2img"
+		payload["init_images"] = []string{base64.StdEncoding.EncodeToString(image)}
+		payload["denoising_strength"] = a1111Denoise
+		if w, h, err := probeVideoSize(shot.SourceImage); err == nil {
+			scale := math.Min(1, float64(a1111MaxSize)/float64(max(w, h)))
+			payload["width"] = int(float64(w)*scale) / 8 * 8
+			payload["height"] = int(float64(h)*scale) / 8 * 8
+		}
+	}
+	if checkpoint := shot.Models["checkpoint"]; checkpoint != "" {
+		payload["override_settings"] = map[string]interface{}{"sd_model_checkpoint": checkpoint}
+	}
+	for k, v := range shot.Params {
+		payload[k] = v
+	}
+	if shot.Quality == QualityDraft {
+		draftA1111(payload, a.getConfig().DraftProfile.withDefaults())
+	}
+	if b.server.Motion != "" {
+		payload["alwayson_scripts"] = map[string]interface{}{
+			"animatediff": map[string]interface{}{"args": []map[string]interface{}{{
+				"enable":       true,
+				"model":        b.server.Motion,
+				"video_length": int(math.Round(duration * fps)),
+				"fps":          fps,
+				"format":       []string{"PNG"},
+			}}},
+		}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		b.watch(server, shot.ID, done)
+	}()
+	images, err := b.render(server, endpoint, payload)
+	close(done)
+	wg.Wait()
+	if shotRenderCanceled(shot.ID) {
+		return fmt.Errorf("render canceled")
+	}
+	if err != nil {
+		return err
+	}
+
+	if b.server.Motion != "" && len(images) > 1 {
+		return encodeA1111Frames(images, fps, outPath)
+	}
+	return holdA1111Image(images[0], duration, fps, outPath)
+}
+
+// render posts the payload and returns the decoded images
+func (b a1111Backend) render(server string, endpoint string, payload map[string]interface{}) ([][]byte, error) {
+	data, _ := json.Marshal(payload)
+	req, err := b.a.comfyRequest("POST", server, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := (&http.Client{Timeout: a1111RenderTimeout}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SD WebUI: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Detail interface{} `json:"detail"`
+			Error  string      `json:"error"`
+			Errors string      `json:"errors"`
+		}
+		json.Unmarshal(body, &apiErr)
+		message := strings.TrimSpace(fmt.Sprint(apiErr.Errors, " ", apiErr.Error))
+		if message == "" && apiErr.Detail != nil {
+			message = fmt.Sprint(apiErr.Detail)
+		}
+		return nil, fmt.Errorf("SD WebUI API Error (%d): %s", resp.StatusCode, message)
+	}
+
+	var result struct {
+		Images []string `json:"images"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("unexpected reply from SD WebUI: %v", err)
+	}
+	images := [][]byte{}
+	for _, encoded := range result.Images {
+		// Some extensions prefix a data URL header
+		if i := strings.Index(encoded, ","); i >= 0 && strings.HasPrefix(encoded, "data:") {
+			encoded = encoded[i+1:]
+		}
+		if image, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(image) > 0 {
+			images = append(images, image)
+		}
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("SD WebUI returned no image")
+	}
+	return images, nil
+}
+
+// watch reports the server's progress until done, interrupting the job when
+// the shot's render is canceled
+func (b a1111Backend) watch(server string, shotId string, done chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		if shotRenderCanceled(shotId) {
+			b.a.comfyJSONAt(server, "POST", "/sdapi/v1/interrupt", nil, nil)
+			return
+		}
+		var progress struct {
+			Progress float64 `json:"progress"`
+		}
+		if b.a.comfyJSONAt(server, "GET", "/sdapi/v1/progress?skip_current_image=true", nil, &progress) == nil && progress.Progress > 0 {
+			percentage := int(progress.Progress * 100)
+			if b.a.ctx != nil {
+				runtime.EventsEmit(b.a.ctx, "comfy:progress", percentage)
+			}
+			b.a.reportRenderProgress(shotId, percentage)
+		}
+	}
+}
+
+// draftA1111 scales a payload down like the draft profile does workflows
+func draftA1111(payload map[string]interface{}, p DraftProfile) {
+	if steps, ok := numberValue(payload["steps"]); ok {
+		payload["steps"] = max(1, int(math.Round(steps*p.StepsScale)))
+	}
+	for _, key := range []string{"width", "height"} {
+		if size, ok := numberValue(payload[key]); ok {
+			payload[key] = max(64, int(size*p.ResolutionScale)/8*8)
+		}
+	}
+}
+
+// encodeA1111Frames encodes AnimateDiff frames as outPath
+func encodeA1111Frames(frames [][]byte, fps float64, outPath string) error {
+	dir, err := os.MkdirTemp(filepath.Dir(outPath), "frames-")
+	if err != nil {
+		return fmt.Errorf("failed to save result: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for i, frame := range frames {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("frame_%05d.png", i+1)), frame, 0644); err != nil {
+			return fmt.Errorf("failed to save result: %v", err)
+		}
+	}
+	cmd := exec.Command("ffmpeg", "-y",
+		"-framerate", fmt.Sprintf("%g", fps),
+		"-i", mediaPath(filepath.Join(dir, "frame_%05d.png")),
+		"-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2,format=yuv420p",
+		"-c:v", "libx264", "-preset", "fast", "-crf", "16",
+		"-movflags", "+faststart",
+		outPath)
+	if out, err := combinedOutputTracked(cmd); err != nil {
+		return fmt.Errorf("assembling %d frames failed: %v: %s", len(frames), err, string(out))
+	}
+	return nil
+}
+
+// holdA1111Image turns a still into a clip of duration seconds
+func holdA1111Image(image []byte, duration float64, fps float64, outPath string) error {
+	still := strings.TrimSuffix(outPath, filepath.Ext(outPath)) + "_still.png"
+	if err := os.WriteFile(still, image, 0644); err != nil {
+		return fmt.Errorf("failed to save result: %v", err)
+	}
+	defer os.Remove(still)
+	cmd := exec.Command("ffmpeg", "-y",
+		"-loop", "1", "-framerate", fmt.Sprintf("%g", fps),
+		"-i", mediaPath(still),
+		"-t", fmt.Sprintf("%f", duration),
+		"-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2,format=yuv420p",
+		"-c:v", "libx264", "-preset", "fast", "-crf", "16", "-tune", "stillimage",
+		"-movflags", "+faststart",
+		outPath)
+	if out, err := combinedOutputTracked(cmd); err != nil {
+		return fmt.Errorf("encoding the image failed: %v: %s", err, string(out))
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// --- ADJUSTMENT LAYERS ---
+
+// Effects on "adjustment" track items apply to everything visible below them.
+
+const TrackTypeAdjustment = "adjustment"
+
+type AdjustmentEffect struct {
+	Type string `json:"type"` // lut, grade, vignette, text
+
+	LUT string `json:"lut"` // .cube/.3dl file
+
+	Brightness float64 `json:"brightness"` // -1..1, 0 = unchanged
+	Contrast   float64 `json:"contrast"`   // 0 = unchanged (1.0)
+	Saturation float64 `json:"saturation"` // 0 = unchanged (1.0)
+	Gamma      float64 `json:"gamma"`      // 0 = unchanged (1.0)
+
+	Strength float64 `json:"strength"` // vignette 0..1
+
+	Text     string `json:"text"`
+	Position string `json:"position"` // top, center, bottom (default)
+	FontSize int    `json:"fontSize"` // 0 = h/20
+}
+
+// isAdjustmentTrack reports whether the track at index is an adjustment layer
+func isAdjustmentTrack(timeline TimelineData, idx int) bool {
+	return idx < len(timeline.TrackSettings) && timeline.TrackSettings[idx].Type == TrackTypeAdjustment
+}
+
+// parseAdjustmentEffects reads an adjustment item's effect list
+func parseAdjustmentEffects(rawItem map[string]interface{}) []AdjustmentEffect {
+	var effects []AdjustmentEffect
+	if raw, ok := rawItem["effects"]; ok {
+		remarshal(raw, &effects)
+	}
+	return effects
+}
+
+// filterPath quotes a file path for use inside a filter option
+func filterPath(p string) string {
+	p = filepath.ToSlash(p)
+	p = strings.ReplaceAll(p, `'`, `'\''`)
+	return "'" + p + "'"
+}
+
+// adjustmentFilter converts effects into a simple ffmpeg filter chain
+func adjustmentFilter(effects []AdjustmentEffect) string {
+	orOne := func(v float64) float64 {
+		if v == 0 {
+			return 1
+		}
+		return v
+	}
+
+	filters := []string{}
+	for _, e := range effects {
+		switch e.Type {
+		case "lut":
+			if e.LUT != "" {
+				filters = append(filters, "lut3d=file="+filterPath(e.LUT))
+			}
+		case "grade":
+			filters = append(filters, fmt.Sprintf("eq=brightness=%.3f:contrast=%.3f:saturation=%.3f:gamma=%.3f",
+				e.Brightness, orOne(e.Contrast), orOne(e.Saturation), orOne(e.Gamma)))
+		case "vignette":
+			strength := math.Min(math.Max(e.Strength, 0), 1)
+			if strength == 0 {
+				strength = 0.5
+			}
+			filters = append(filters, fmt.Sprintf("vignette=angle=%.4f", strength*math.Pi/2))
+		case "text":
+			if strings.TrimSpace(e.Text) == "" {
+				continue
+			}
+			size := "h/20"
+			if e.FontSize > 0 {
+				size = fmt.Sprint(e.FontSize)
+			}
+			y := "h-text_h-h/12"
+			switch e.Position {
+			case "top":
+				y = "h/12"
+			case "center":
+				y = "(h-text_h)/2"
+			}
+			filters = append(filters, fmt.Sprintf("drawtext=text='%s':fontcolor=white:fontsize=%s:x=(w-text_w)/2:y=%s:borderw=2:bordercolor=black@0.5",
+				drawtextSafe(e.Text), size, y))
+		}
+	}
+	return strings.Join(filters, ",")
+}
+
+// adjustmentsAt returns the combined filter of all adjustment items covering
+// time t on tracks above layer (lower indexes), nearest layer first
+func adjustmentsAt(timeline TimelineData, layer int, t float64) string {
+	chain := []string{}
+	for tIdx := min(layer, len(timeline.Tracks)) - 1; tIdx >= 0; tIdx-- {
+		if !isAdjustmentTrack(timeline, tIdx) || !timeline.TrackSettings[tIdx].Visible {
+			continue
+		}
+		for _, raw := range timeline.Tracks[tIdx] {
+			item := parseTimelineItem(raw)
+			if t >= item.StartTime && t < item.StartTime+item.Duration {
+				if f := adjustmentFilter(parseAdjustmentEffects(raw)); f != "" {
+					chain = append(chain, f)
+				}
+			}
+		}
+	}
+	return strings.Join(chain, ",")
+}
+
+// joinFilters chains two optional filter chains
+func joinFilters(a string, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+	return a + "," + b
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --- ADVANCED EXPORT ARGUMENTS ---
+
+// AdvancedExportArgs lets niche delivery specs pass extra ffmpeg output options
+// without code changes. EncodeArgs go to the encode pass (e.g. -x264-params,
+// -tune, -g); MuxArgs go to the final mux (e.g. -movflags, -metadata).
+// For audio-only formats encoding happens in the mux, so both apply there.
+type AdvancedExportArgs struct {
+	EncodeArgs string `json:"encodeArgs"`
+	MuxArgs    string `json:"muxArgs"`
+}
+
+// allowedExportArgs are the output options advanced arguments may set, with
+// the number of values each takes. Stream specifiers (-b:v, -metadata:s:a:0)
+// are matched on the name before the colon. Anything else is rejected: inputs,
+// maps, filters and codecs belong to the engine, and a stray value would be
+// taken by ffmpeg as one more output file.
+var allowedExportArgs = map[string]int{
+	// Rate control and encoder tuning
+	"preset": 1, "tune": 1, "profile": 1, "level": 1, "crf": 1, "qp": 1, "q": 1, "qscale": 1,
+	"b": 1, "maxrate": 1, "minrate": 1, "bufsize": 1, "rc": 1, "cq": 1,
+	"g": 1, "keyint_min": 1, "bf": 1, "refs": 1, "sc_threshold": 1, "force_key_frames": 1,
+	"x264-params": 1, "x264opts": 1, "x265-params": 1, "svtav1-params": 1,
+	"cpu-used": 1, "row-mt": 1, "tile-columns": 1, "deadline": 1, "lag-in-frames": 1,
+	"vendor": 1, "bits_per_mb": 1, "quant_mat": 1, "threads": 1, "strict": 1,
+	// Picture and sound
+	"pix_fmt": 1, "r": 1, "fps_mode": 1, "aspect": 1, "field_order": 1,
+	"color_primaries": 1, "color_trc": 1, "colorspace": 1, "color_range": 1,
+	"ar": 1, "ac": 1, "aq": 1,
+	// Container and metadata
+	"movflags": 1, "metadata": 1, "timecode": 1, "brand": 1, "write_tmcd": 1, "tag": 1,
+	"disposition": 1, "frag_duration": 1, "use_editlist": 1,
+	// Length
+	"t": 1, "to": 1, "shortest": 0,
+}
+
+// ValidateExportArgs checks an advanced argument string and returns "" if it is
+// acceptable, otherwise a description of the problem.
+func (a *App) ValidateExportArgs(args string) string {
+	if _, err := parseExportArgs(args); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// parseExportArgs splits a shell-style argument string (supporting single and
+// double quotes) and checks it against allowedExportArgs.
+func parseExportArgs(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inToken := false
+	var quote rune
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inToken {
+				args = append(args, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in advanced arguments")
+	}
+	if inToken {
+		args = append(args, current.String())
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return nil, fmt.Errorf("unexpected argument %q: only options with their values are allowed", arg)
+		}
+		name, _, _ := strings.Cut(arg[1:], ":")
+		values, ok := allowedExportArgs[name]
+		if !ok {
+			return nil, fmt.Errorf("option %s is not allowed in advanced arguments", arg)
+		}
+		if i+values >= len(args) {
+			return nil, fmt.Errorf("option %s needs a value", arg)
+		}
+		i += values
+	}
+	return args, nil
+}
+
+// withExtraArgs inserts extra options before the output path (the last argument)
+func withExtraArgs(args []string, extra []string) []string {
+	if len(extra) == 0 || len(args) == 0 {
+		return args
+	}
+	out := make([]string, 0, len(args)+len(extra))
+	out = append(out, args[:len(args)-1]...)
+	out = append(out, extra...)
+	return append(out, args[len(args)-1])
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseExportArgs(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "-crf 18 -preset slow", want: []string{"-crf", "18", "-preset", "slow"}},
+		{in: `-metadata title="My Film" -movflags +faststart`, want: []string{"-metadata", "title=My Film", "-movflags", "+faststart"}},
+		{in: "-metadata:s:a:0 language=eng", want: []string{"-metadata:s:a:0", "language=eng"}},
+		{in: "-b:v 8M -maxrate:v 10M", want: []string{"-b:v", "8M", "-maxrate:v", "10M"}},
+		{in: "-x264-params 'keyint=48:min-keyint=48'", want: []string{"-x264-params", "keyint=48:min-keyint=48"}},
+		{in: "-g -1", want: []string{"-g", "-1"}}, // Values may look like options
+		{in: "-shortest -t 10", want: []string{"-shortest", "-t", "10"}},
+
+		// Engine-owned options, in every spelling
+		{in: "-c:v libx265", wantErr: true},
+		{in: "-c:v:0 libx265", wantErr: true},
+		{in: "-codec copy", wantErr: true},
+		{in: "-vcodec h264", wantErr: true},
+		{in: "-filter:v scale=640:-1", wantErr: true},
+		{in: "-filter scale=640:-1", wantErr: true},
+		{in: "-vf scale=640:-1", wantErr: true},
+		{in: "-map 0:v", wantErr: true},
+		{in: "-i other.mp4", wantErr: true},
+		{in: "-y", wantErr: true},
+
+		// Stray values would be extra output files
+		{in: "/tmp/evil.mp4", wantErr: true},
+		{in: "-crf 18 /tmp/evil.mp4", wantErr: true},
+		{in: "-crf 18 19", wantErr: true},
+
+		// Malformed
+		{in: "-crf", wantErr: true},
+		{in: `-metadata "title=open`, wantErr: true},
+		{in: "- 1", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseExportArgs(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseExportArgs(%q) = %q, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseExportArgs(%q) error: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseExportArgs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
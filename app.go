@@ -7,11 +7,11 @@ import (
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"math"
-	"mime/multipart"
 	"net/http"
 	"os"
 	"os/exec"
@@ -21,10 +21,10 @@ import (
 	"strings"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/google/uuid"       // <--- NEW
-	"github.com/gorilla/websocket" // <--- NEW
+	"github.com/google/uuid" // <--- NEW
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
@@ -34,6 +34,9 @@ type App struct {
 	comfyURL string
 	clientID string // <--- NEW: For WebSocket connection
 	nodeMappings map[string]map[string]string // Class -> Input -> Type
+	mappingsMu   sync.RWMutex                 // Guards nodeMappings, which imports change during renders
+	config   Config
+	configMu sync.Mutex
 }
 
 // NewApp creates a new App application struct
@@ -57,11 +60,35 @@ func (a *App) startup(ctx context.Context) {
 	// ---------------------------------------------------------
 	// CRITICAL FIX: START THE ENGINE HERE
 	// ---------------------------------------------------------
-	go StartStreamServer()
+	ensureFFmpegPath()
+	a.loadConfig()
+	applyLanguage(a.getConfig().Language)
+	a.cleanupOnStartup()
+	a.restoreBookmarks()
+	StartStreamServer(a) // Transport is settled before the frontend asks for it
 	// ---------------------------------------------------------
 
-	a.loadConfig()
 	a.loadNodeMappings()
+
+	go a.registerHotkeys()
+	go a.startOSC()
+	go a.runBackupScheduler()
+	go a.runBackgroundRenderer()
+	go a.runRenderQueue()
+	go a.runStatsMonitor()
+	go a.runRunPodMonitor()
+	go a.recoverRenders()
+	go a.refreshPreviewLoops()
+}
+
+// shutdown is called when the app is closing
+func (a *App) shutdown(ctx context.Context) {
+	if a.getConfig().Backup.OnClose {
+		a.backupChangedProjects()
+	}
+	a.StopLiveOutput()
+	stopVoiceRecording()
+	a.cleanupOnShutdown()
 }
 
 // Ping is a fast, safe handshake that lets the frontend verify the Wails bridge
@@ -75,6 +102,20 @@ func (a *App) Ping() bool {
 // UpdateTimeline receives a list of file paths, generates a playlist,
 // renders a gapless MP4 preview, and tells the frontend where to stream it from.
 func (a *App) UpdateTimeline(clips []string) string {
+	return a.renderTimelinePreview(clips, 0, "")
+}
+
+// UpdateProjectTimeline is UpdateTimeline with the project's frame rate
+// conform policy applied, so mixed-fps clips preview exactly as they export.
+func (a *App) UpdateProjectTimeline(projectId string, clips []string) string {
+	p, err := a.GetProject(projectId)
+	if err != nil {
+		return "error: project not found"
+	}
+	return a.renderTimelinePreview(clips, p.FrameRate, p.ConformPolicy)
+}
+
+func (a *App) renderTimelinePreview(clips []string, projectFPS float64, policy string) string {
 	if server == nil {
 		return "error: server_not_ready"
 	}
@@ -83,29 +124,39 @@ func (a *App) UpdateTimeline(clips []string) string {
 	_, err := server.GeneratePlaylist(clips)
 	if err != nil {
 		fmt.Println("Error generating playlist:", err)
+		recordEngineError("preview", err.Error())
 		return "error: " + err.Error()
 	}
 
-	// 2. Render a gapless MP4 preview (fast concat because clips match)
-	_, err = server.RenderPreviewMP4()
+	// 2. Render a gapless MP4 preview (fast concat when clips match,
+	// re-encoded through the conform filter when their frame rates differ)
+	filter := ""
+	if fps, needed := resolveConform(projectFPS, clips, a.getVideoFrameRate); needed && fps > 0 {
+		filter = conformFilter(fps, policy)
+	}
+	_, err = server.RenderPreviewMP4(filter)
 	if err != nil {
 		fmt.Println("Error rendering preview:", err)
+		recordEngineError("preview", err.Error())
 		return "error: " + err.Error()
 	}
 
 	// 3. Return the preview URL with a timestamp to force reload
-	return fmt.Sprintf("http://localhost:3456/preview.mp4?t=%d", time.Now().UnixMilli())
+	return engineURL(fmt.Sprintf("/preview.mp4?t=%d", time.Now().UnixMilli()))
 }
 
 // --- MODELS ---
 
 type Project struct {
-	ID         string `json:"id"`
-	Name       string `json:"name"`
-	Type       string `json:"type"`
-	Thumbnail  string `json:"thumbnail"`
-	UpdatedAt  string `json:"updatedAt"`
-	SceneCount int    `json:"sceneCount"`
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Type          string  `json:"type"`
+	Thumbnail     string  `json:"thumbnail"`
+	UpdatedAt     string  `json:"updatedAt"`
+	SceneCount    int     `json:"sceneCount"`
+	FrameRate     float64 `json:"frameRate"`     // Timeline fps, 0 = follow the first clip
+	ConformPolicy string  `json:"conformPolicy"` // drop (default), blend, interpolate
+	PreviewLoop   string  `json:"previewLoop"`   // Animated thumbnail, empty until built
 }
 
 type Scene struct {
@@ -122,34 +173,100 @@ type Shot struct {
 	SceneID        string  `json:"sceneId"`
 	Name           string  `json:"name"`
 	SourceImage    string  `json:"sourceImage"`    // Path to input image
+	SourceImageEnd string  `json:"sourceImageEnd,omitempty"` // Last frame for first/last-frame workflows
+	ControlImages  map[string]string `json:"controlImages,omitempty"` // depth, pose, reference, control -> path; see controlimages.go
 	AudioPath      string  `json:"audioPath"`      // Path to audio file
 	AudioStart     float64 `json:"audioStart"`     // Start trim time
 	AudioDuration  float64 `json:"audioDuration"`  // Duration to keep
 	Prompt         string  `json:"prompt"`         // AI Prompt
 	MotionStrength int     `json:"motionStrength"` // 1-127
-	Seed           int64   `json:"seed"`
+	Seed           int64   `json:"seed"`           // Seed of the latest render
+	SeedMode       string  `json:"seedMode,omitempty"` // "" (locked), random, increment; see seedmode.go
 	Duration       float64 `json:"duration"`    // Seconds
 	Status         string  `json:"status"`      // DRAFT, RENDERING, DONE
 	OutputVideo    string  `json:"outputVideo"` // Path to generated MP4
+	Thumbnail      string  `json:"thumbnail"`   // Middle-frame JPEG of OutputVideo
+	Stale          bool    `json:"stale"`       // Output predates a prompt/parameter change
 	Waveform       []float64 `json:"waveform"`
+
+	// Inputs of the last render, see shotFingerprint
+	RenderWorkflow    string            `json:"renderWorkflow,omitempty"`
+	RenderFingerprint map[string]string `json:"renderFingerprint,omitempty"`
+
+	// What the shot renders with when RenderShot gets no workflow; Params
+	// are input overrides, see workflowparams.go, and Models replace the
+	// workflow's checkpoint, LoRA, VAE or UNet
+	Workflow string                 `json:"workflow,omitempty"`
+	Params   map[string]interface{} `json:"params,omitempty"`
+	Models   map[string]string      `json:"models,omitempty"` // Kind -> file, see comfymodels.go
+	FPS      float64                `json:"fps,omitempty"`    // Overrides the workflow's rate, see shotfps.go
+
+	// Shot whose last frame this one starts from, see chains.go
+	ChainFrom string `json:"chainFrom,omitempty"`
+	// Profile of the current output: draft or final, see renderprofile.go
+	Quality string `json:"quality,omitempty"`
+	// Render the upscaled OutputVideo was made from, see upscale.go
+	RawVideo string `json:"rawVideo,omitempty"`
+	// GPU to render on ("cuda:1"), overriding the server's; see gpuselect.go
+	Device string `json:"device,omitempty"`
 }
 
 type Config struct {
-	ComfyURL string `json:"comfyUrl"`
+	ComfyURL  string           `json:"comfyUrl"`
+	Backup    BackupSettings   `json:"backup"`
+	Engine    EngineSettings   `json:"engine"`
+	Hotkeys   HotkeySettings   `json:"hotkeys"`
+	Remote    RemoteSettings   `json:"remote"`
+	Language  string           `json:"language"` // "" = follow the system locale
+	Guides    GuideSettings    `json:"guides"`
+	ImagePrep ImagePrepOptions `json:"imagePrep"`
+
+	RenderWorkers int                 `json:"renderWorkers"` // Parallel queued renders on the primary server
+	RenderRetries int                 `json:"renderRetries"` // Resubmits of prompts lost to a ComfyUI restart; 0 = default, -1 = off
+	ComfyServers  []ComfyServer       `json:"comfyServers"`  // Render pool, see comfyservers.go
+	ComfyAuth     ComfyAuthSettings   `json:"comfyAuth"`     // Proxy auth headers, see comfyauth.go
+	DraftProfile  DraftProfile        `json:"draftProfile"`  // Scale factors of draft renders
+	Upscale       UpscaleSettings     `json:"upscale"`       // Pass after final renders, see upscale.go
+	ComfyUpload   ComfyUploadSettings `json:"comfyUpload"`   // Input subfolders, see comfyupload.go
+	FreeMemory    string              `json:"freeMemory"`    // "", render or idle, see freememory.go
+	ComfyDevice   string              `json:"comfyDevice"`   // GPU of the primary server, see gpuselect.go
+	LLM           LLMSettings         `json:"llm"`           // Prompt assistant endpoint, see llm.go
+	Caption       CaptionSettings     `json:"caption"`       // How DescribeImage works, see caption.go
+	Whisper       WhisperSettings     `json:"whisper"`       // Transcription backend, see whisper.go
+	Music         MusicSettings       `json:"music"`         // Music generation backend, see music.go
+	RunPod        RunPodSettings      `json:"runPod"`        // On-demand ComfyUI pod, see runpod.go
 }
 
 type TrackSetting struct {
 	Locked  bool   `json:"locked"`
 	Visible bool   `json:"visible"`
 	Name    string `json:"name"`
-	Type    string `json:"type"`
+	Type    string `json:"type"` // video, audio or adjustment
 }
 
 type ExportOptions struct {
-	Format       string `json:"format"`       // mp4, mov, mkv, mp3, wav
-	IncludeVideo bool   `json:"includeVideo"`
-	IncludeAudio bool   `json:"includeAudio"`
-	Quality      string `json:"quality"`
+	Format       string             `json:"format"` // mp4, mov, mxf, mkv, webm, mp3, wav, opus, flac
+	IncludeVideo bool               `json:"includeVideo"`
+	IncludeAudio bool               `json:"includeAudio"`
+	Quality      string             `json:"quality"`
+	VideoCodec   string             `json:"videoCodec"`   // mov: prores (default), dnxhr; webm: vp9 (default), av1, svtav1
+	VideoProfile string             `json:"videoProfile"` // dnxhr only: lb, sq, hq, hqx (defaults from quality)
+	Encoder      string             `json:"encoder"`      // "" = software, nvenc, qsv, amf, videotoolbox; see hwencode.go
+	Width        int                `json:"width"`        // Target frame size, 0 = keep each clip's; see exportsize.go
+	Height       int                `json:"height"`       // Set with Width
+	FPS          float64            `json:"fps"`          // Target rate, 0 = project rate
+	Fit          string             `json:"fit"`          // letterbox (default) or crop
+	Watermark    string             `json:"watermark"`    // Optional text burned into the video (review copies)
+	Slate        string             `json:"slate"`        // Optional GenerateSlate clip prepended to the export
+	Advanced     AdvancedExportArgs `json:"advanced"`     // Extra raw ffmpeg output options
+
+	silent           bool          // Internal: suppress export:* events for background renders
+	timeline         *TimelineData // Internal: export this instead of the scene's saved timeline
+	run              *exportRun    // Internal: run of the master or project export this is part of
+	unjournaled      bool          // Internal: not resumable, for throwaway copies like review cuts
+	Transparent      bool          `json:"transparent"`      // Encode with alpha: webm/vp9 (yuva420p) or mov/ProRes 4444 only
+	AudioBitrate     int           `json:"audioBitrate"`     // kbps for mp3/opus/webm, 0 = codec default
+	CompressionLevel int           `json:"compressionLevel"` // flac only: 1-12, 0 = ffmpeg default (5)
 }
 
 type TimelineData struct {
@@ -161,14 +278,16 @@ type Workflow struct {
 	ID       string `json:"id"`
 	Name     string `json:"name"`
 	HasAudio bool   `json:"hasAudio"` // Flag for UI
+
+	Meta WorkflowMeta `json:"meta"` // Tags, category, favorite, see workflowmeta.go
 }
 
 // --- HELPER FUNCTIONS ---
 
-// getAppDir returns the path to "Documents/MotionStudio"
+// getAppDir returns the path to "Documents/MotionStudio" (XDG data dir on Linux)
 func (a *App) getAppDir() string {
-	homeDir, _ := os.UserHomeDir()
-	return filepath.Join(homeDir, "Documents", "MotionStudio")
+	appDataDirOnce.Do(func() { appDataDir = resolveAppDir() })
+	return appDataDir
 }
 
 // getWorkflowsDir returns the path to "Documents/MotionStudio/workflows"
@@ -189,12 +308,35 @@ func (a *App) loadConfig() {
 	data, err := os.ReadFile(path)
 	if err == nil {
 		var config Config
-		if err := json.Unmarshal(data, &config); err == nil && config.ComfyURL != "" {
-			a.comfyURL = config.ComfyURL
+		if err := json.Unmarshal(data, &config); err == nil {
+			a.configMu.Lock()
+			a.config = config
+			a.configMu.Unlock()
+			if config.ComfyURL != "" {
+				a.comfyURL = config.ComfyURL
+			}
 		}
 	}
 }
 
+// getConfig returns a copy of the current settings
+func (a *App) getConfig() Config {
+	a.configMu.Lock()
+	defer a.configMu.Unlock()
+	return a.config
+}
+
+// updateConfig applies a change to the settings and persists config.json
+func (a *App) updateConfig(change func(c *Config)) {
+	a.configMu.Lock()
+	defer a.configMu.Unlock()
+	change(&a.config)
+
+	path := filepath.Join(a.getAppDir(), "config.json")
+	data, _ := json.MarshalIndent(a.config, "", "  ")
+	os.WriteFile(path, data, 0644)
+}
+
 func (a *App) loadNodeMappings() {
 	path := filepath.Join(a.getAppDir(), "node_mappings.json")
 	data, err := os.ReadFile(path)
@@ -212,8 +354,16 @@ func (a *App) loadNodeMappings() {
 		"EmptyLatentVideo":         {"frame_count": "MAX_FRAMES"},
 		"MultiTalkWav2VecEmbeds":   {"num_frames": "MAX_FRAMES"},
 		"WanImageToVideo":          {"length": "WAN_LENGTH"},
+		"VHS_VideoCombine":         {"frame_rate": "FRAME_RATE"},
+		"CreateVideo":              {"fps": "FRAME_RATE"},
+		"VHS_LoadVideo":            {"video": "VIDEO"},
+		"LoadVideo":                {"file": "VIDEO"},
+		"RIFE VFI":                 {"multiplier": "MULTIPLIER"},
+		"FILM VFI":                 {"multiplier": "MULTIPLIER"},
 	}
 
+	a.mappingsMu.Lock()
+	defer a.mappingsMu.Unlock()
 	if err == nil {
 		json.Unmarshal(data, &a.nodeMappings)
 	}
@@ -228,10 +378,20 @@ func (a *App) loadNodeMappings() {
 
 func (a *App) saveNodeMappings() {
 	path := filepath.Join(a.getAppDir(), "node_mappings.json")
+	a.mappingsMu.RLock()
 	data, _ := json.MarshalIndent(a.nodeMappings, "", "  ")
+	a.mappingsMu.RUnlock()
 	os.WriteFile(path, data, 0644)
 }
 
+// nodeRules returns the mapping rules of a node class (nil when unmapped).
+// Rules are replaced, never changed in place, so they can be read unlocked.
+func (a *App) nodeRules(classType string) map[string]string {
+	a.mappingsMu.RLock()
+	defer a.mappingsMu.RUnlock()
+	return a.nodeMappings[classType]
+}
+
 func (a *App) analyzeWorkflowForMappings(workflowData []byte) {
 	var workflow map[string]interface{}
 	if err := json.Unmarshal(workflowData, &workflow); err != nil {
@@ -239,6 +399,7 @@ func (a *App) analyzeWorkflowForMappings(workflowData []byte) {
 	}
 
 	updated := false
+	a.mappingsMu.Lock()
 	for _, node := range workflow {
 		if nodeMap, ok := node.(map[string]interface{}); ok {
 			if classType, ok := nodeMap["class_type"].(string); ok {
@@ -265,6 +426,10 @@ func (a *App) analyzeWorkflowForMappings(workflowData []byte) {
 						// --- NEW RULE: CATCH "LENGTH" ---
 						} else if lowerKey == "length" { 
 							newRules[key] = "WAN_LENGTH" 
+						} else if lowerKey == "frame_rate" || lowerKey == "fps" {
+							newRules[key] = "FRAME_RATE"
+						} else if (strings.Contains(strings.ToLower(classType), "video") && lowerKey == "video") {
+							newRules[key] = "VIDEO"
 						}
 					}
 
@@ -276,6 +441,7 @@ func (a *App) analyzeWorkflowForMappings(workflowData []byte) {
 			}
 		}
 	}
+	a.mappingsMu.Unlock()
 
 	if updated {
 		a.saveNodeMappings()
@@ -334,12 +500,20 @@ func (a *App) GetProject(id string) (Project, error) {
 		}
 	}
 	p.SceneCount = count
+
+	// Animated thumbnail built in the background
+	p.PreviewLoop = ""
+	loopPath := filepath.Join(a.getAppDir(), id, previewLoopFile)
+	if _, statErr := os.Stat(loopPath); statErr == nil {
+		p.PreviewLoop = loopPath
+	}
 	return p, err
 }
 
 func (a *App) UpdateProject(p Project) {
 	p.UpdatedAt = time.Now().Format("2006-01-02 15:04")
 	a.saveProjectFile(p)
+	a.recordHistory(p.ID, "Update project settings")
 }
 
 func (a *App) DeleteProject(id string) {
@@ -376,6 +550,7 @@ func (a *App) CreateScene(projectId string, name string) Scene {
 
 	data, _ := json.MarshalIndent(s, "", "  ")
 	os.WriteFile(filepath.Join(sceneDir, "scene.json"), data, 0644)
+	a.recordHistory(projectId, fmt.Sprintf("Create scene %q", name))
 	return s
 }
 
@@ -394,7 +569,10 @@ func (a *App) GetScenes(projectId string) []Scene {
 				shots := a.GetShots(projectId, s.ID)
 				s.ShotCount = len(shots)
 				if len(shots) > 0 {
-					s.Thumbnail = shots[0].SourceImage
+					s.Thumbnail = shots[0].Thumbnail
+					if s.Thumbnail == "" {
+						s.Thumbnail = shots[0].SourceImage
+					}
 				}
 				scenes = append(scenes, s)
 			}
@@ -407,17 +585,26 @@ func (a *App) DeleteScene(projectId string, sceneId string) {
 	if projectId == "" || sceneId == "" {
 		return
 	}
+	label := a.sceneLabel(projectId, sceneId)
 	sceneDir := filepath.Join(a.getAppDir(), projectId, "scenes", sceneId)
 	os.RemoveAll(sceneDir)
+	a.recordHistory(projectId, "Delete scene "+label)
 }
 
 // --- SHOT FUNCTIONS ---
 
 // SaveShots writes the list of shots to shots.json inside the scene folder
 func (a *App) SaveShots(projectId string, sceneId string, shots []Shot) {
+	a.recordShotEdits(projectId, sceneId, shots)
+	a.writeShots(projectId, sceneId, shots)
+}
+
+// writeShots persists shots without touching the prompt history
+func (a *App) writeShots(projectId string, sceneId string, shots []Shot) {
 	path := filepath.Join(a.getAppDir(), projectId, "scenes", sceneId, "shots.json")
 	data, _ := json.MarshalIndent(shots, "", "  ")
 	os.WriteFile(path, data, 0644)
+	a.recordHistory(projectId, "Update shots in scene "+a.sceneLabel(projectId, sceneId))
 }
 
 // GetShots reads the list from disk
@@ -442,7 +629,11 @@ func (a *App) DeleteShot(projectId string, sceneId string, shotId string) {
 			if s.OutputVideo != "" {
 				os.Remove(s.OutputVideo)
 			}
+			a.deleteShotVersions(projectId, sceneId, shotId)
 		} else {
+			if s.ChainFrom == shotId {
+				s.ChainFrom = "" // Keeps its source image, but nothing to follow anymore
+			}
 			newShots = append(newShots, s)
 		}
 	}
@@ -466,6 +657,8 @@ func (a *App) SaveTimeline(projectId string, sceneId string, timeline TimelineDa
 	path := filepath.Join(a.getAppDir(), projectId, "scenes", sceneId, "timeline.json")
 	data, _ := json.MarshalIndent(timeline, "", "  ")
 	os.WriteFile(path, data, 0644)
+	a.recordHistory(projectId, "Edit timeline of scene "+a.sceneLabel(projectId, sceneId))
+	a.scheduleBackgroundRender(projectId, sceneId)
 }
 
 func (a *App) GetTimeline(projectId string, sceneId string) TimelineData {
@@ -489,14 +682,11 @@ func (a *App) SetComfyURL(url string) {
 	a.comfyURL = strings.TrimRight(url, "/")
 
 	// Save Config
-	path := filepath.Join(a.getAppDir(), "config.json")
-	config := Config{ComfyURL: a.comfyURL}
-	data, _ := json.MarshalIndent(config, "", "  ")
-	os.WriteFile(path, data, 0644)
+	a.updateConfig(func(c *Config) { c.ComfyURL = a.comfyURL })
 }
 
 func (a *App) TestComfyConnection() bool {
-	resp, err := http.Get(a.comfyURL + "/system_stats")
+	resp, err := a.comfyGet(a.comfyURL, "/system_stats")
 	if err != nil {
 		return false
 	}
@@ -509,7 +699,7 @@ func (a *App) GetWorkflows() []Workflow {
 	entries, _ := os.ReadDir(dir)
 	var workflows []Workflow
 	for _, e := range entries {
-		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+		if !e.IsDir() && isWorkflowFile(e.Name()) {
 			// Read file to detect audio nodes
 			hasAudio := false
 			content, err := os.ReadFile(filepath.Join(dir, e.Name()))
@@ -538,6 +728,7 @@ func (a *App) GetWorkflows() []Workflow {
 				ID:       name,
 				Name:     name,
 				HasAudio: hasAudio, // Set the flag
+				Meta:     a.loadWorkflowMeta(name),
 			})
 		}
 	}
@@ -545,7 +736,7 @@ func (a *App) GetWorkflows() []Workflow {
 	if len(workflows) == 0 {
 		defaultPath := filepath.Join(dir, "default.json")
 		a.createDefaultWorkflow(defaultPath)
-		workflows = append(workflows, Workflow{ID: "default", Name: "default", HasAudio: false})
+		workflows = append(workflows, Workflow{ID: "default", Name: "default", HasAudio: false, Meta: a.loadWorkflowMeta("default")})
 	}
 	return workflows
 }
@@ -554,7 +745,7 @@ func (a *App) GetWorkflows() []Workflow {
 // and copies it to the workflows directory with the given name.
 func (a *App) ImportWorkflow(name string) string {
 	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Select ComfyUI Workflow (API Format)",
+		Title: "Select ComfyUI Workflow",
 		Filters: []runtime.FileFilter{
 			{DisplayName: "JSON Files", Pattern: "*.json"},
 		},
@@ -568,6 +759,21 @@ func (a *App) ImportWorkflow(name string) string {
 		return "Error reading file"
 	}
 
+	if _, err := a.saveImportedWorkflow(name, data); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Success"
+}
+
+// saveImportedWorkflow converts and stores an imported workflow under a
+// sanitized name, which it returns
+func (a *App) saveImportedWorkflow(name string, data []byte) (string, error) {
+	// Editor ("Save") graphs are converted, anything else must be API format
+	data, err := a.prepareWorkflowImport(data)
+	if err != nil {
+		return "", err
+	}
+
 	// Analyze and update mappings
 	a.analyzeWorkflowForMappings(data)
 
@@ -585,10 +791,10 @@ func (a *App) ImportWorkflow(name string) string {
 	dest := filepath.Join(a.getWorkflowsDir(), safeName+".json")
 	err = os.WriteFile(dest, data, 0644)
 	if err != nil {
-		return "Error saving workflow"
+		return "", fmt.Errorf("saving workflow failed")
 	}
 
-	return "Success"
+	return safeName, nil
 }
 
 func (a *App) RenameWorkflow(oldName, newName string) string {
@@ -615,6 +821,7 @@ func (a *App) RenameWorkflow(oldName, newName string) string {
 	if err != nil {
 		return "Error renaming file"
 	}
+	a.moveWorkflowMeta(oldName, safeName)
 	return "Success"
 }
 
@@ -628,6 +835,7 @@ func (a *App) DeleteWorkflow(name string) string {
 	if err != nil {
 		return "Error deleting file"
 	}
+	a.moveWorkflowMeta(name, "")
 	return "Success"
 }
 
@@ -643,8 +851,42 @@ func (a *App) SelectAndSaveWorkflow() string {
 
 // --- COMFYUI INTEGRATION ---
 
-// RenderShot orchestrates the ComfyUI generation
+// RenderShot orchestrates the ComfyUI generation. Without a workflowName the
+// shot's own workflow (or the default) is used.
 func (a *App) RenderShot(projectId string, sceneId string, shotId string, workflowName string) (Shot, error) {
+	shot, err := a.renderShotOn(a.comfyURL, projectId, sceneId, shotId, workflowName, 0, 0, QualityFinal)
+	a.freeAfterRender(a.comfyURL)
+	return shot, err
+}
+
+// renderShotOn renders a shot on a specific ComfyUI server (see comfyservers.go),
+// resubmitting it when a ComfyUI restart loses the prompt (see renderretry.go).
+// A retry (attempt > 0) renders with seed, the seed of the lost prompt.
+func (a *App) renderShotOn(server string, projectId string, sceneId string, shotId string, workflowName string, attempt int, seed int64, profile string) (Shot, error) {
+	atomic.AddInt32(&activeRenders, 1)
+	defer atomic.AddInt32(&activeRenders, -1)
+
+	shot, err := a.renderShot(server, projectId, sceneId, shotId, workflowName, attempt, seed, nil, profile)
+	for errors.Is(err, errPromptLost) && attempt < a.renderRetries() {
+		attempt++
+		a.emitRenderRetry(shotId, attempt)
+		shot, err = a.renderShot(server, projectId, sceneId, shotId, workflowName, attempt, shot.Seed, nil, profile)
+	}
+	if err != nil {
+		recordEngineError("render", err.Error())
+	} else {
+		shot = a.autoUpscale(a.workflowServer(server), projectId, sceneId, shot)
+		a.recordShotTake(projectId, sceneId, shot)
+		a.schedulePreviewLoop(projectId)
+		a.touchWorkflow(shot.RenderWorkflow)
+	}
+	return shot, err
+}
+
+// renderShot submits one render and waits for it. A take (see variations.go)
+// renders with a given seed and is only recorded as a version. profile is
+// draft or final (see renderprofile.go). Retries pass the seed they resubmit.
+func (a *App) renderShot(server string, projectId string, sceneId string, shotId string, workflowName string, attempt int, seed int64, take *renderTake, profile string) (Shot, error) {
 	// 1. Get Shot
 	shots := a.GetShots(projectId, sceneId)
 	var shot *Shot
@@ -658,9 +900,309 @@ func (a *App) RenderShot(projectId string, sceneId string, shotId string, workfl
 		return Shot{}, fmt.Errorf("shot not found")
 	}
 
-	if shot.SourceImage == "" {
-		return *shot, fmt.Errorf("source image is missing")
+	// Servers running another engine render through its backend (backends.go)
+	backend := a.serverBackend(server)
+
+	// ---------------------------------------------------------
+	// 2.5 CONNECT WEBSOCKET (REAL-TIME PROGRESS)
+	// ---------------------------------------------------------
+	// Shared per server (comfyws.go); opening it now lets it connect while
+	// the prompt is prepared. Without it we still finish by polling.
+	if backend == nil {
+		a.comfySocketFor(server)
+	}
+
+	// An explicit workflow becomes the shot's own once it renders with it
+	workflowName = shotWorkflow(*shot, workflowName)
+	shot.Workflow = workflowName
+	if backend == nil {
+		if err := a.checkSourceImage(*shot, workflowName); err != nil {
+			return *shot, err
+		}
+	}
+	shot.Quality = profile
+	if shot.Quality == "" {
+		shot.Quality = QualityFinal
+	}
+	if take != nil {
+		shot.Seed = take.Seed
+	} else {
+		shot.Seed = renderSeed(*shot, attempt, seed)
+	}
+	if backend != nil {
+		return a.renderWithBackend(backend, server, projectId, sceneId, shot, workflowName, take)
+	}
+
+	// 1.5 - 5.5 Upload media and inject it into the workflow
+	prepared, err := a.buildShotWorkflow(shot, workflowName, false, func(path string) (string, error) {
+		return a.uploadAsset(server, path)
+	})
+	if err != nil {
+		return *shot, err
+	}
+	workflow := prepared.Workflow
+	a.applyDevice(server, *shot, workflow, prepared)
+
+	// 6. Queue Prompt with Client ID
+	promptID, err := a.queuePrompt(server, workflow)
+	if err != nil {
+		return *shot, err
+	}
+	rememberPrompt(promptID, server, projectId, sceneId, *shot)
+
+	// Until it finishes, the prompt is on disk so a restart can pick it up
+	inflight := inflightRender{
+		PromptID:  promptID,
+		Server:    server,
+		ProjectID: projectId,
+		SceneID:   sceneId,
+		ShotID:    shotId,
+		Workflow:  workflowName,
+		Attempt:   attempt,
+		Seed:      shot.Seed,
+		Submitted: time.Now().Format(time.RFC3339),
+	}
+	if take != nil {
+		inflight.Variation = take.Set
+	}
+	inflight.Profile = shot.Quality
+	a.trackInflight(inflight)
+	defer a.untrackInflight(promptID)
+
+	if err := a.waitForPrompt(server, shotId, promptID); err != nil {
+		return *shot, err
+	}
+	return a.collectShotOutput(server, projectId, sceneId, shot, promptID, workflowName, take)
+}
+
+// queuePrompt submits a workflow to a server and returns the prompt id
+func (a *App) queuePrompt(server string, workflow map[string]interface{}) (string, error) {
+	promptReq := map[string]interface{}{
+		"prompt":    workflow,
+		"client_id": a.clientID,
+	}
+	promptBytes, _ := json.Marshal(promptReq)
+	queueReq, err := a.comfyRequest("POST", server, "/prompt", bytes.NewBuffer(promptBytes))
+	if err != nil {
+		return "", err
+	}
+	queueReq.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(queueReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to ComfyUI: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("ComfyUI API Error (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var promptResp map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&promptResp)
+	promptID, _ := promptResp["prompt_id"].(string)
+	if promptID == "" {
+		return "", fmt.Errorf("ComfyUI did not return a prompt id")
+	}
+	return promptID, nil
+}
+
+// waitForPrompt follows a submitted prompt until it shows up in the server's
+// history, forwarding progress and previews from the websocket
+func (a *App) waitForPrompt(server string, shotId string, promptID string) error {
+	// 7. LISTEN FOR WEBSOCKET PROGRESS (ROBUST MODE)
+	socket := a.comfySocketFor(server)
+	doneChan := make(chan bool)
+	defer clearLivePreview(shotId)
+	events := socket.subscribe(promptID)
+	defer socket.unsubscribe(promptID)
+
+	go func() {
+		defer close(doneChan)
+		var lastPreview time.Time
+		for ev := range events {
+			if ev.Type == "preview" && time.Since(lastPreview) >= previewInterval {
+				lastPreview = time.Now()
+				a.emitLivePreview(shotId, promptID, ev)
+			}
+
+			if ev.Type == "progress" {
+				val, _ := ev.Data["value"].(float64)
+				max, _ := ev.Data["max"].(float64)
+				if max > 0 {
+					percentage := int((val / max) * 100)
+					runtime.EventsEmit(a.ctx, "comfy:progress", percentage)
+					a.reportRenderProgress(shotId, percentage)
+				}
+			}
+
+			if ev.Type == "executing" {
+				node := ev.Data["node"]
+				if node != nil {
+					runtime.EventsEmit(a.ctx, "comfy:status", fmt.Sprintf("Processing Node %v", node))
+				}
+			}
+
+			if ev.finished() {
+				return
+			}
+		}
+	}()
+
+	// 7.5 INTELLIGENT WAITING LOOP
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	// Report the prompt's place in the server queue (see queueposition.go)
+	queue := a.trackQueue(server, shotId, promptID)
+	queue.poll()
+	finished := false
+	defer func() { queue.finish(finished) }()
+
+	timeout := time.After(60 * time.Minute) // 60 Minute Timeout for Local/Wan2.1
+
+	for tick := 1; ; tick++ {
+		select {
+		case <-doneChan:
+			// WebSocket finished, but we still check history to be sure.
+			doneChan = nil // Closed; don't spin on it until the next tick
+		case <-timeout:
+			return fmt.Errorf("timeout: generation took longer than 60 minutes")
+		case <-ticker.C:
+			if shotRenderCanceled(shotId) {
+				return fmt.Errorf("render canceled")
+			}
+			// Check History directly
+			if resp, err := a.comfyGet(server, "/history/"+promptID); err == nil {
+				var h map[string]interface{}
+				json.NewDecoder(resp.Body).Decode(&h)
+				resp.Body.Close()
+				
+				if _, ok := h[promptID]; ok {
+					finished = true
+					return nil
+				}
+			}
+			if tick%queuePollTicks == 0 {
+				queue.poll()
+			}
+			// A restarted ComfyUI forgets its queue; notice instead of timing out
+			if tick%promptLostCheckTicks == 0 && a.promptLost(server, promptID) {
+				return errPromptLost
+			}
+		}
+	}
+}
+
+// collectShotOutput downloads the output of a finished prompt and makes it
+// the shot's new version
+func (a *App) collectShotOutput(server string, projectId string, sceneId string, shot *Shot, promptID string, workflowName string, take *renderTake) (Shot, error) {
+	shotId := shot.ID
+	var files []comfyOutput
+
+	// 8. Poll History (Error-Aware Mode)
+	for i := 0; i < 5; i++ {
+		histResp, err := a.comfyGet(server, "/history/"+promptID)
+		if err == nil {
+			var histMap map[string]interface{}
+			dec := json.NewDecoder(histResp.Body)
+			dec.UseNumber() // Seeds exceed float64 precision
+			dec.Decode(&histMap)
+			histResp.Body.Close()
+
+			if data, ok := histMap[promptID].(map[string]interface{}); ok {
+				// Record the seed that actually ran
+				if seed, ok := a.executedSeed(data); ok {
+					shot.Seed = seed
+				}
+				
+				// A. CHECK FOR CRASHES
+				if status, ok := data["status"].(map[string]interface{}); ok {
+					if statusStr, ok := status["status_str"].(string); ok && statusStr == "error" {
+						if messages, ok := status["messages"].([]interface{}); ok && len(messages) > 0 {
+							if errPair, ok := messages[0].([]interface{}); ok && len(errPair) >= 2 {
+                                if errDetails, ok := errPair[1].(map[string]interface{}); ok {
+                                    if msg, ok := errDetails["exception_message"].(string); ok {
+                                        return *shot, fmt.Errorf("ComfyUI Crashed: %s", msg)
+                                    }
+                                }
+							}
+						}
+						return *shot, fmt.Errorf("ComfyUI reported a fatal error during generation")
+					}
+				}
+
+				// B. COLLECT OUTPUT FILES (see comfyoutputs.go)
+				if outputs, ok := data["outputs"].(map[string]interface{}); ok {
+					files = comfyOutputs(outputs)
+				}
+			}
+		}
+
+		if len(files) > 0 {
+			break
+		}
+		time.Sleep(1 * time.Second)
+	}
+
+	plan, err := planShotOutput(files)
+	if err != nil {
+		return *shot, err
+	}
+
+	// 9. Download Result
+	// Every render is kept as a new version (see versions.go)
+	outPath := a.nextVersionPath(projectId, sceneId, shotId)
+	if err := a.fetchShotOutput(server, plan, outPath, a.renderFPS(*shot, workflowName)); err != nil {
+		os.Remove(outPath)
+		return *shot, err
+	}
+	return a.finishShotOutput(server, projectId, sceneId, shot, promptID, outPath, workflowName, take)
+}
+
+// finishShotOutput checks a downloaded render and records it as a version of
+// the shot; jobID is the prompt (or backend job) that made it
+func (a *App) finishShotOutput(server string, projectId string, sceneId string, shot *Shot, jobID string, outPath string, workflowName string, take *renderTake) (Shot, error) {
+	// Catch truncated or broken outputs now rather than at delivery
+	if check := verifyMedia(outPath, MediaExpectation{Video: true, FullDecode: true}); !check.OK {
+		os.Remove(outPath) // Frees the reserved version number
+		return *shot, fmt.Errorf("rendered output is corrupt: %s", check.summary())
 	}
+	a.interpolateOutput(server, shot.ID, workflowName, outPath, a.renderFPS(*shot, workflowName))
+
+	// A variation is only recorded; PromoteVariation makes it the output
+	if take != nil {
+		shot.OutputVideo = outPath
+		shot.RenderWorkflow = workflowName
+		shot.RenderFingerprint = a.shotFingerprint(*shot, workflowName)
+		shot.Duration = a.outputDuration(outPath, a.renderFPS(*shot, workflowName))
+		a.addShotVersion(projectId, sceneId, *shot, jobID, workflowName, take.Set)
+		return *shot, nil
+	}
+
+	previousOutput := shot.OutputVideo
+	shot.OutputVideo = outPath
+	shot.RawVideo = ""
+	shot.Status = "DONE"
+	shot.Stale = false
+	shot.RenderWorkflow = workflowName
+	shot.RenderFingerprint = a.shotFingerprint(*shot, workflowName)
+	shot.Duration = a.outputDuration(outPath, a.renderFPS(*shot, workflowName))
+	if err := a.generateShotThumbnail(projectId, sceneId, shot); err != nil {
+		fmt.Println("Thumbnail:", err)
+	}
+	a.saveShotResult(projectId, sceneId, *shot)
+	a.addShotVersion(projectId, sceneId, *shot, jobID, workflowName, "")
+	a.repointTimelineMedia(projectId, sceneId, previousOutput, outPath)
+
+	return *shot, nil
+}
+
+// buildShotWorkflow prepares the prompt for a shot: trims its audio, hands
+// the media to upload, and injects everything into the workflow. A dry run
+// (see PreviewInjectedWorkflow) skips the trim.
+func (a *App) buildShotWorkflow(shot *Shot, workflowName string, dryRun bool, upload func(path string) (string, error)) (*WorkflowPreview, error) {
+	result := &WorkflowPreview{Injections: []WorkflowInjection{}, Warnings: []string{}}
 
 	// ---------------------------------------------------------
 	// 1.5 HANDLE AUDIO TRIMMING & DURATION CALC
@@ -673,14 +1215,15 @@ func (a *App) RenderShot(projectId string, sceneId string, shotId string, workfl
 		finalDuration = a.getVideoDuration(shot.AudioPath)
 	}
 
-	// Apply Trim if needed
-	if shot.AudioPath != "" && shot.AudioDuration > 0 {
+	// Apply Trim if needed (a dry run injects the untrimmed file's name)
+	if shot.AudioPath != "" && shot.AudioDuration > 0 && !dryRun {
 		tempName := fmt.Sprintf("trim_%s_%d%s", shot.ID, time.Now().Unix(), filepath.Ext(shot.AudioPath))
-		tempPath := filepath.Join(os.TempDir(), tempName)
+		tempPath := trackTemp(filepath.Join(os.TempDir(), tempName))
+		defer releaseTemp(tempPath)
 
 		cmd := exec.Command("ffmpeg",
 			"-y",
-			"-i", shot.AudioPath,
+			"-i", mediaPath(shot.AudioPath),
 			"-ss", fmt.Sprintf("%f", shot.AudioStart),
 			"-t", fmt.Sprintf("%f", shot.AudioDuration),
 			"-c", "copy",
@@ -695,60 +1238,61 @@ func (a *App) RenderShot(projectId string, sceneId string, shotId string, workfl
 		}
 	}
 
-	// Calculate Max Frames for Audio-based workflows (standard 25fps)
+	// Calculate Max Frames for Audio-based workflows (at the render rate)
 	if finalDuration <= 0 { finalDuration = 1.0 }
-	maxFrames := int(finalDuration * 25)
+	maxFrames := int(finalDuration * a.renderFPS(*shot, workflowName))
 	
 	// ---------------------------------------------------------
 	// 2. UPLOAD ASSETS TO COMFYUI
 	// ---------------------------------------------------------
 	
-	// A. Upload Image
-	comfyImageName, err := a.uploadImageToComfy(shot.SourceImage)
-	if err != nil {
-		return *shot, fmt.Errorf("image upload failed: %v", err)
+	// A. Upload Image (text-to-video workflows have none, see textvideo.go)
+	comfyImageName := ""
+	if shot.SourceImage != "" {
+		uploadedName, err := upload(shot.SourceImage)
+		if err != nil {
+			return nil, fmt.Errorf("image upload failed: %v", err)
+		}
+		comfyImageName = uploadedName
+	}
+
+	// A2. Upload End Image (first/last-frame workflows, see keyframes.go)
+	comfyEndName := ""
+	if shot.SourceImageEnd != "" {
+		var err error
+		if comfyEndName, err = upload(shot.SourceImageEnd); err != nil {
+			return nil, fmt.Errorf("end image upload failed: %v", err)
+		}
+	}
+
+	// A3. Upload Control Images (depth, pose, reference, ...)
+	comfyControlNames := map[string]string{}
+	for _, kind := range controlKindList(shot.ControlImages) {
+		name, err := upload(shot.ControlImages[kind])
+		if err != nil {
+			return nil, fmt.Errorf("%s image upload failed: %v", kind, err)
+		}
+		comfyControlNames[controlRole(kind)] = name
 	}
 
 	// B. Upload Audio (If exists)
 	comfyAudioName := ""
 	if localAudioPath != "" {
-		uploadedName, err := a.uploadImageToComfy(localAudioPath)
+		uploadedName, err := upload(localAudioPath)
 		if err != nil {
-			return *shot, fmt.Errorf("audio upload failed: %v", err)
+			return nil, fmt.Errorf("audio upload failed: %v", err)
 		}
 		comfyAudioName = uploadedName
 		fmt.Printf("Audio uploaded to ComfyUI as: %s\n", comfyAudioName)
 	}
 
-	// ---------------------------------------------------------
-	// 2.5 CONNECT WEBSOCKET (REAL-TIME PROGRESS)
-	// ---------------------------------------------------------
-	wsScheme := "ws"
-	if strings.HasPrefix(a.comfyURL, "https") {
-		wsScheme = "wss"
-	}
-	wsURL := strings.Replace(a.comfyURL, "http://", "", 1)
-	wsURL = strings.Replace(wsURL, "https://", "", 1)
-	wsURL = fmt.Sprintf("%s://%s/ws?clientId=%s", wsScheme, wsURL, a.clientID)
-
-	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
-	if err != nil {
-		fmt.Println("WS Connection Failed, falling back to polling:", err)
-		// Don't error out, just continue without progress bars
-	} else {
-		defer conn.Close()
-	}
-
 	// 3. Ensure Workflow Template Exists
-	if workflowName == "" {
-		workflowName = "default"
-	}
 	workflowPath := filepath.Join(a.getWorkflowsDir(), workflowName+".json")
 	if _, err := os.Stat(workflowPath); os.IsNotExist(err) {
 		if workflowName == "default" {
 			a.createDefaultWorkflow(workflowPath)
 		} else {
-			return *shot, fmt.Errorf("workflow %s not found", workflowName)
+			return nil, fmt.Errorf("workflow %s not found", workflowName)
 		}
 	}
 
@@ -761,31 +1305,45 @@ func (a *App) RenderShot(projectId string, sceneId string, shotId string, workfl
 	// 5. INJECT VALUES (UPDATED WITH FORCE FIX)
 	// =========================================================
 	imageInjected := false
+	endInjected := false
+	endLoaders := a.endImageLoaders(workflow)
+	controlLoaders := controlImageLoaders(workflow)
+	controlInjected := map[string]bool{}
 	
 	// --- A. Calculate Wan2 Frame Count ---
-	// Formula: 16 fps * duration + 1
-	// 5s = 81 frames, 10s = 161 frames
+	// Formula: render fps * duration + 1
+	// At 16 fps: 5s = 81 frames, 10s = 161 frames
 	wanDuration := shot.Duration
 	if wanDuration <= 0 { wanDuration = 5 } // Default to 5s if unset
-	wanFrames := int(wanDuration*16) + 1
+	wanFrames := int(wanDuration*a.renderFPS(*shot, workflowName)) + 1
 
 	fmt.Printf("DEBUG: Generating Wan2 with %d seconds (%d frames)\n", int(wanDuration), wanFrames)
 	
 	// Prepare Injection Values
 	injectValues := map[string]interface{}{
 		"IMAGE":      comfyImageName,
+		"IMAGE_END":  comfyEndName,
 		"PROMPT":     shot.Prompt,
 		"SEED":       shot.Seed,
 		"MOTION":     shot.MotionStrength,
 		"WAN_LENGTH": wanFrames, // <--- Value for mapped "length" inputs
 	}
 	
+	if comfyEndName == "" {
+		injectValues["IMAGE_END"] = comfyImageName // Better a still end than a missing file
+	}
+	for role, name := range comfyControlNames {
+		injectValues[role] = name
+	}
 	if comfyAudioName != "" {
 		injectValues["AUDIO"] = comfyAudioName
 		injectValues["MAX_FRAMES"] = maxFrames
 	}
+	if fps := a.configuredFPS(*shot, workflowName); fps > 0 {
+		injectValues["FRAME_RATE"] = fps // Only when set, so workflows keep their own rate
+	}
 
-	for _, node := range workflow {
+	for nodeId, node := range workflow {
 		nodeMap, ok := node.(map[string]interface{})
 		if !ok { continue }
 
@@ -793,14 +1351,21 @@ func (a *App) RenderShot(projectId string, sceneId string, shotId string, workfl
 		inputs, _ := nodeMap["inputs"].(map[string]interface{})
 		
 		// --- B. Standard Mapping Injection ---
-		if rules, known := a.nodeMappings[classType]; known {
+		if rules := a.nodeRules(classType); rules != nil {
 			for inputKey, valueType := range rules {
 				if _, inputExists := inputs[inputKey]; inputExists {
 					if _, isLink := inputs[inputKey].([]interface{}); isLink { continue }
+					if valueType == "IMAGE" && controlLoaders[nodeId] != "" {
+						// Titled for a control image; without one the workflow's file stays
+						valueType = controlRole(controlLoaders[nodeId])
+					} else if valueType == "IMAGE" && endLoaders[nodeId] { valueType = "IMAGE_END" }
 
 					if val, hasVal := injectValues[valueType]; hasVal {
 						inputs[inputKey] = val
+						result.inject(nodeId, classType, inputKey, valueType, val)
 						if valueType == "IMAGE" { imageInjected = true }
+						if valueType == "IMAGE_END" { endInjected = true }
+						controlInjected[valueType] = true
 					}
 				}
 			}
@@ -812,6 +1377,7 @@ func (a *App) RenderShot(projectId string, sceneId string, shotId string, workfl
 		if classType == "WanImageToVideo" {
 			// Force the length input if it exists in the node
 			inputs["length"] = wanFrames
+			result.inject(nodeId, classType, "length", "WAN_LENGTH", wanFrames)
 			fmt.Printf("DEBUG: Forced WanImageToVideo length to %d\n", wanFrames)
 		}
 
@@ -821,203 +1387,57 @@ func (a *App) RenderShot(projectId string, sceneId string, shotId string, workfl
 				lowerTitle := strings.ToLower(title)
 				if strings.Contains(lowerTitle, "max frames") || strings.Contains(lowerTitle, "frame count") {
 					if val, hasVal := injectValues["MAX_FRAMES"]; hasVal {
-						if _, ok := inputs["value"]; ok { inputs["value"] = val }
+						if _, ok := inputs["value"]; ok {
+							inputs["value"] = val
+							result.inject(nodeId, classType, "value", "MAX_FRAMES", val)
+						}
 					}
 				}
 			}
 		}
 	}
 
-	if !imageInjected {
+	if !imageInjected && shot.SourceImage != "" {
 		fmt.Println("WARNING: No 'LoadImage' node found.")
+		result.warn("no LoadImage node found; the source image isn't used")
 	}
-
-	// 6. Queue Prompt with Client ID
-	promptReq := map[string]interface{}{
-		"prompt":    workflow,
-		"client_id": a.clientID,
-	}
-	promptBytes, _ := json.Marshal(promptReq)
-	resp, err := http.Post(a.comfyURL+"/prompt", "application/json", bytes.NewBuffer(promptBytes))
-	if err != nil {
-		return *shot, fmt.Errorf("failed to connect to ComfyUI: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		return *shot, fmt.Errorf("ComfyUI API Error (%d): %s", resp.StatusCode, string(body))
+	if endInjected && shot.SourceImageEnd == "" {
+		result.warn("workflow takes an end image but the shot has none; the source image is used")
+	} else if !endInjected && shot.SourceImageEnd != "" {
+		result.warn("workflow has no end image loader; the end image isn't used")
 	}
-
-	var promptResp map[string]interface{}
-	json.NewDecoder(resp.Body).Decode(&promptResp)
-	promptID := promptResp["prompt_id"].(string)
-
-	// 7. LISTEN FOR WEBSOCKET PROGRESS (ROBUST MODE)
-	outputFilename := ""
-	outputSubfolder := ""
-	outputType := ""
-
-	doneChan := make(chan bool)
-
-	if conn != nil {
-		go func() {
-			defer close(doneChan)
-			for {
-				_, message, err := conn.ReadMessage()
-				if err != nil {
-					return
-				}
-
-				var msg map[string]interface{}
-				json.Unmarshal(message, &msg)
-				msgType, _ := msg["type"].(string)
-				data, _ := msg["data"].(map[string]interface{})
-
-				if msgType == "progress" {
-					val := data["value"].(float64)
-					max := data["max"].(float64)
-					percentage := int((val / max) * 100)
-					runtime.EventsEmit(a.ctx, "comfy:progress", percentage)
-				}
-				
-				if msgType == "executing" {
-					node := data["node"]
-					if node != nil {
-						runtime.EventsEmit(a.ctx, "comfy:status", fmt.Sprintf("Processing Node %v", node))
-					}
-				}
-
-				if msgType == "execution_success" {
-					sid, _ := data["prompt_id"].(string)
-					if sid == promptID {
-						return
-					}
-				}
-			}
-		}()
-	}
-
-	// 7.5 INTELLIGENT WAITING LOOP
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-
-	timeout := time.After(60 * time.Minute) // 60 Minute Timeout for Local/Wan2.1
-
-loop:
-	for {
-		select {
-		case <-doneChan:
-			// WebSocket finished, but we still check history to be sure.
-		case <-timeout:
-			return *shot, fmt.Errorf("timeout: generation took longer than 60 minutes")
-		case <-ticker.C:
-			// Check History directly
-			if resp, err := http.Get(a.comfyURL + "/history/" + promptID); err == nil {
-				var h map[string]interface{}
-				json.NewDecoder(resp.Body).Decode(&h)
-				resp.Body.Close()
-				
-				if _, ok := h[promptID]; ok {
-					break loop
-				}
-			}
+	for _, kind := range controlKindList(shot.ControlImages) {
+		if !controlInjected[controlRole(kind)] {
+			result.warn(fmt.Sprintf("no LoadImage node titled for a %s image; it isn't used", kind))
 		}
 	}
 
-	// 8. Poll History (Error-Aware Mode)
-	for i := 0; i < 5; i++ {
-		histResp, err := http.Get(a.comfyURL + "/history/" + promptID)
-		if err == nil {
-			var histMap map[string]interface{}
-			json.NewDecoder(histResp.Body).Decode(&histMap)
-			histResp.Body.Close()
-
-			if data, ok := histMap[promptID].(map[string]interface{}); ok {
-				
-				// A. CHECK FOR CRASHES
-				if status, ok := data["status"].(map[string]interface{}); ok {
-					if statusStr, ok := status["status_str"].(string); ok && statusStr == "error" {
-						if messages, ok := status["messages"].([]interface{}); ok && len(messages) > 0 {
-							if errPair, ok := messages[0].([]interface{}); ok && len(errPair) >= 2 {
-                                if errDetails, ok := errPair[1].(map[string]interface{}); ok {
-                                    if msg, ok := errDetails["exception_message"].(string); ok {
-                                        return *shot, fmt.Errorf("ComfyUI Crashed: %s", msg)
-                                    }
-                                }
-							}
-						}
-						return *shot, fmt.Errorf("ComfyUI reported a fatal error during generation")
-					}
-				}
-
-				// B. SEARCH FOR OUTPUT FILE
-				if outputs, ok := data["outputs"].(map[string]interface{}); ok {
-					for _, outNode := range outputs {
-						outNodeMap, ok := outNode.(map[string]interface{})
-						if !ok { continue }
-
-						for _, categoryValue := range outNodeMap {
-							if items, ok := categoryValue.([]interface{}); ok && len(items) > 0 {
-								if item, ok := items[0].(map[string]interface{}); ok {
-									if fn, ok := item["filename"].(string); ok {
-										outputFilename = fn
-										if s, ok := item["subfolder"].(string); ok { outputSubfolder = s }
-										if t, ok := item["type"].(string); ok { outputType = t }
-										break
-									}
-								}
-							}
-						}
-						if outputFilename != "" { break }
-					}
-				}
-			}
+	// 5.5 Per-shot parameter overrides (steps, cfg, size, ...)
+	if problems := a.applyParamOverrides(workflow, shot.Params); len(problems) > 0 {
+		fmt.Println("WARNING: Ignored parameter overrides:", strings.Join(problems, "; "))
+		for _, p := range problems {
+			result.warn("parameter override ignored: " + p)
 		}
-
-		if outputFilename != "" {
-			break
-		}
-		time.Sleep(1 * time.Second)
-	}
-
-	if outputFilename == "" {
-		return *shot, fmt.Errorf("job finished but no output file was found (check ComfyUI console)")
 	}
 
-	// 9. Download Result
-	outPath := filepath.Join(a.getAppDir(), projectId, "scenes", sceneId, shotId+".mp4")
-	query := fmt.Sprintf("filename=%s&subfolder=%s&type=%s", outputFilename, outputSubfolder, outputType)
-	vidResp, err := http.Get(fmt.Sprintf("%s/view?%s", a.comfyURL, query))
-	
-	if err == nil {
-		defer vidResp.Body.Close()
-		if vidResp.StatusCode != 200 {
-			return *shot, fmt.Errorf("download failed (Status %d)", vidResp.StatusCode)
-		}
-
-		outFile, _ := os.Create(outPath)
-		io.Copy(outFile, vidResp.Body)
-		outFile.Close()
+	// 5.6 Per-shot models (checkpoint, LoRA, VAE, UNet)
+	applyShotModels(workflow, shot.Models, result)
 
-		shot.OutputVideo = outPath
-		shot.Status = "DONE"
-		shot.Duration = a.getVideoDuration(outPath)
-		a.SaveShots(projectId, sceneId, shots)
-	} else {
-		return *shot, fmt.Errorf("failed to download result: %v", err)
-	}
+	// 5.7 Draft profile scales resolution, steps and frames down
+	a.applyRenderProfile(workflow, shot.Quality, result)
 
-	return *shot, nil
+	result.Workflow = workflow
+	return result, nil
 }
 
+
 func (a *App) getVideoDuration(path string) float64 {
 	// Use ffprobe to get exact duration in seconds
 	cmd := exec.Command("ffprobe",
 		"-v", "error",
 		"-show_entries", "format=duration",
 		"-of", "default=noprint_wrappers=1:nokey=1",
-		path)
+		mediaPath(path))
 
 	// Start the command and capture output
 	out, err := cmd.Output()
@@ -1039,42 +1459,6 @@ func (a *App) getVideoDuration(path string) float64 {
 	return duration
 }
 
-func (a *App) uploadImageToComfy(path string) (string, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	part, _ := writer.CreateFormFile("image", filepath.Base(path))
-	io.Copy(part, file)
-	writer.Close()
-
-	req, _ := http.NewRequest("POST", a.comfyURL+"/upload/image", body)
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("comfyui returned status %d", resp.StatusCode)
-	}
-
-	var res map[string]interface{}
-	json.NewDecoder(resp.Body).Decode(&res)
-
-	// Comfy returns name, possibly modified if duplicate
-	if name, ok := res["name"].(string); ok {
-		return name, nil
-	}
-	return filepath.Base(path), nil
-}
 
 func (a *App) createDefaultWorkflow(path string) {
 	// A minimal valid SVD workflow JSON structure
@@ -1126,7 +1510,8 @@ func (a *App) SelectImage() string {
 	return selection
 }
 
-// ImportImage opens a dialog, copies the file to the project assets, and returns the new path
+// ImportImage opens a dialog, copies the file to the project assets, and returns the new path.
+// The saved image prep transforms (rotate/crop/resize) are applied on the way.
 func (a *App) ImportImage(projectId string) string {
 	// 1. Open the File Dialog to let user pick a file
 	srcPath := a.SelectImage()
@@ -1134,30 +1519,8 @@ func (a *App) ImportImage(projectId string) string {
 		return ""
 	}
 
-	// 2. Define the destination folder: Documents/MotionStudio/<ProjectID>/assets
-	assetsDir := filepath.Join(a.getAppDir(), projectId, "assets")
-	// Ensure the folder exists
-	os.MkdirAll(assetsDir, 0755)
-
-	// 3. Create a unique filename (timestamp + original extension)
-	// This prevents overwriting if you import "image.png" twice
-	ext := filepath.Ext(srcPath)
-	newFilename := fmt.Sprintf("%d%s", time.Now().UnixNano(), ext)
-	destPath := filepath.Join(assetsDir, newFilename)
-
-	// 4. Copy the file
-	input, err := os.ReadFile(srcPath)
-	if err != nil {
-		return ""
-	}
-
-	err = os.WriteFile(destPath, input, 0644)
-	if err != nil {
-		return ""
-	}
-
-	// 5. Return the NEW safe path inside the project
-	return destPath
+	// 2. Copy (and prepare) into Documents/MotionStudio/<ProjectID>/assets
+	return a.importImageFile(projectId, srcPath, a.GetImagePrepSettings())
 }
 
 // ImportAudio opens a dialog, copies the file to the project assets, and returns the new path
@@ -1202,6 +1565,8 @@ func (a *App) SelectAudio() string {
 	if err != nil {
 		return ""
 	}
+	// Audio is referenced in place, so keep access to it across launches
+	a.rememberAccess(selection)
 	return selection
 }
 
@@ -1214,15 +1579,7 @@ func (a *App) ReadImageBase64(path string) string {
 		return ""
 	}
 
-	mimeType := "image/jpeg"
-	ext := strings.ToLower(filepath.Ext(path))
-	if ext == ".png" {
-		mimeType = "image/png"
-	} else if ext == ".webp" {
-		mimeType = "image/webp"
-	} else if ext == ".mp4" {
-		mimeType = "video/mp4"
-	}
+	mimeType := mediaContentType(path)
 
 	base64Str := base64.StdEncoding.EncodeToString(bytes)
 	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64Str)
@@ -1238,7 +1595,7 @@ func (a *App) ExtractAudioPeaks(filePath string, samplesPerSec int) ([]float64,
 	// -ar 4000: low sample rate (sufficient for visual waveform)
 	// -f s16le: output raw 16-bit little-endian PCM
 	// -: output to stdout
-	cmd := exec.Command("ffmpeg", "-i", filePath, "-vn", "-ac", "1", "-ar", "4000", "-f", "s16le", "-")
+	cmd := exec.Command("ffmpeg", "-i", mediaPath(filePath), "-vn", "-ac", "1", "-ar", "4000", "-f", "s16le", "-")
 	
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -1324,7 +1681,7 @@ func (a *App) ExtractLastFrame(inputPath string) string {
 	}
 
 	// 2. If input is video, run FFmpeg
-	cmd := exec.Command("ffmpeg", "-sseof", "-0.25", "-i", inputPath, "-update", "1", "-q:v", "1", "-vframes", "1", outputPath, "-y")
+	cmd := exec.Command("ffmpeg", "-sseof", "-0.25", "-i", mediaPath(inputPath), "-update", "1", "-q:v", "1", "-vframes", "1", outputPath, "-y")
 
 	err := cmd.Run()
 	if err != nil {
@@ -1335,6 +1692,21 @@ func (a *App) ExtractLastFrame(inputPath string) string {
 	return outputPath
 }
 
+// extractFrameAt grabs a single frame at t seconds into outputPath (png/jpg by extension)
+func (a *App) extractFrameAt(inputPath string, t float64, outputPath string) error {
+	cmd := exec.Command("ffmpeg", "-y", "-ss", fmt.Sprintf("%f", t), "-i", mediaPath(inputPath), "-frames:v", "1", "-q:v", "2", outputPath)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, string(out))
+	}
+	return nil
+}
+
+// extractMiddleFrame grabs the frame halfway through a video, which is more
+// representative of an AI shot than the first or last frame
+func (a *App) extractMiddleFrame(inputPath string, outputPath string) error {
+	return a.extractFrameAt(inputPath, a.getVideoDuration(inputPath)/2, outputPath)
+}
+
 // --- EXPORT ENGINE ---
 
 type RenderSegment struct {
@@ -1344,6 +1716,8 @@ type RenderSegment struct {
 	Duration   float64
 	IsImage    bool
 	AudioSource string
+	Filter     string // Custom clip video filter, applied in a pre-render
+	Reversed   bool   // Source range plays backwards (see resolveReversedSegments)
 }
 
 func (a *App) ExportVideo(projectId string, sceneId string, options ExportOptions) string {
@@ -1352,168 +1726,162 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 	filterPattern := "*" + ext
 	
 	outPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
-		Title:           "Export " + strings.ToUpper(options.Format),
+		Title:           tr("export.dialogTitle", strings.ToUpper(options.Format)),
 		DefaultFilename: "export" + ext,
 		Filters: []runtime.FileFilter{
-			{DisplayName: strings.ToUpper(options.Format) + " File", Pattern: filterPattern},
+			{DisplayName: tr("export.fileFilter", strings.ToUpper(options.Format)), Pattern: filterPattern},
 		},
 	})
 	if err != nil || outPath == "" {
 		return "Cancelled"
 	}
+	rememberExportDir(filepath.Dir(outPath))
 
-	// Emit initial progress
-	runtime.EventsEmit(a.ctx, "export:progress", 0)
+	result := a.exportTimeline(projectId, sceneId, options, outPath)
+	if result != "Success" && result != "Cancelled" {
+		recordEngineError("export", result)
+	}
+	return result
+}
 
-	// 2. Load Timeline
-	timeline := a.GetTimeline(projectId, sceneId)
-	if len(timeline.Tracks) == 0 {
-		return "Empty timeline"
+// exportTimeline renders a scene timeline to outPath. It is the engine behind
+// ExportVideo and is reused by exporters that pick their own destination.
+func (a *App) exportTimeline(projectId string, sceneId string, options ExportOptions, outPath string) (result string) {
+	// Validate advanced overrides before doing any work
+	encodeArgs, err := parseExportArgs(options.Advanced.EncodeArgs)
+	if err != nil {
+		return tr("export.error.advancedArgs", err.Error())
+	}
+	muxArgs, err := parseExportArgs(options.Advanced.MuxArgs)
+	if err != nil {
+		return tr("export.error.advancedArgs", err.Error())
+	}
+	encoder, err := a.exportEncoderFor(options)
+	if err != nil {
+		return tr("export.error.encoder", err.Error())
+	}
+	if err := validateTransparent(options); err != nil {
+		return tr("export.error.encoder", err.Error())
+	}
+	if err := validateExportSize(options); err != nil {
+		return tr("export.error.size", err.Error())
 	}
 
-	tempDir := os.TempDir()
-	videoOutput := ""
-	audioOutput := ""
-	
-	// 0. Prepare Black Frame for Gaps
-	blackPath := filepath.Join(tempDir, "black.png")
-	if _, err := os.Stat(blackPath); os.IsNotExist(err) {
-		data, _ := base64.StdEncoding.DecodeString("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNk+A8AAQUBAScY42YAAAAASUVORK5CYII=")
-		os.WriteFile(blackPath, data, 0644)
+	// Background callers (previews, analysis) run the engine without UI events
+	emit := func(event string, data interface{}) {
+		if !options.silent {
+			runtime.EventsEmit(a.ctx, event, data)
+		}
+	}
+	label := func(l string) string {
+		if options.silent {
+			return ""
+		}
+		return l
 	}
 
-	// 0.5 Prepare Silence for Audio Gaps (1 hour buffer)
-	silencePath := filepath.Join(tempDir, "silence.wav")
-	if _, err := os.Stat(silencePath); os.IsNotExist(err) {
-		exec.Command("ffmpeg", "-y", "-f", "lavfi", "-i", "anullsrc=r=48000:cl=stereo", "-t", "3600", "-c:a", "pcm_s16le", silencePath).Run()
+	a.beginForeground()
+	defer a.endForeground()
+	if !options.silent {
+		atomic.AddInt32(&activeExports, 1)
+		defer atomic.AddInt32(&activeExports, -1)
 	}
 
-	// Helper to parse map to struct-like
-	type Item struct {
-		ID          string
-		StartTime   float64
-		Duration    float64
-		TrimStart   float64
-		OutputVideo string
-		AudioPath   string
-		SourceImage string
-		PairID		string
+	// Emit initial progress
+	emit("export:progress", 0)
+
+	// 2. Load Timeline (or the assembled one handed in by ExportProject)
+	var timeline TimelineData
+	if options.timeline != nil {
+		timeline = *options.timeline
+	} else {
+		timeline = a.GetTimeline(projectId, sceneId)
+	}
+	if len(timeline.Tracks) == 0 {
+		return tr("export.error.emptyTimeline")
+	}
+	if !options.silent && options.timeline == nil {
+		// Sync problems are reported up front; the export still goes ahead
+		if issues := a.ValidateSceneDurations(projectId, sceneId); len(issues) > 0 {
+			emit("export:warnings", issues)
+		}
 	}
 
-	// --- PASS 1: ANALYZE TIMELINE (VISUALS) ---
-	runtime.EventsEmit(a.ctx, "export:status", "Analyzing Timeline...")
-
-	// Map to track which PairIDs are currently visible on screen
-	// If a video is covered up, its PairID won't be in this map.
-	visiblePairIDs := make(map[string]bool)
-
-	// 1. Collect all time points
-	timePoints := []float64{0.0}
-
-	var tracks [][]Item
-	for _, rawTrack := range timeline.Tracks {
-		var track []Item
-		for _, rawItem := range rawTrack {
-			item := Item{}
-			if v, ok := rawItem["startTime"].(float64); ok { item.StartTime = v }
-			if v, ok := rawItem["duration"].(float64); ok { item.Duration = v }
-			if v, ok := rawItem["trimStart"].(float64); ok { item.TrimStart = v }
-			if v, ok := rawItem["outputVideo"].(string); ok { item.OutputVideo = v }
-			if v, ok := rawItem["audioPath"].(string); ok { item.AudioPath = v }
-			if v, ok := rawItem["sourceImage"].(string); ok { item.SourceImage = v }
-			if v, ok := rawItem["pairId"].(string); ok { item.PairID = v } // <--- Parse PairID
-
-			track = append(track, item)
-			timePoints = append(timePoints, item.StartTime)
-			timePoints = append(timePoints, item.StartTime+item.Duration)
-		}
-		tracks = append(tracks, track)
-	}
-
-	// 2. Sort and Unique
-	sort.Float64s(timePoints)
-	uniquePoints := []float64{}
-	if len(timePoints) > 0 {
-		uniquePoints = append(uniquePoints, timePoints[0])
-		for i := 1; i < len(timePoints); i++ {
-			if timePoints[i] > timePoints[i-1]+0.001 {
-				uniquePoints = append(uniquePoints, timePoints[i])
+	// Identical unfinished exports resume from their last finished pass
+	job := a.openExportJournal(projectId, sceneId, options, timeline, outPath)
+	finished := false
+	muxing := false
+	// Part of a larger export, the run and its events belong to the caller
+	run := options.run
+	if run == nil {
+		run = beginExportRun(job)
+		defer endExportRun(run)
+		emit("export:started", run.ID)
+	}
+	defer func() {
+		// A canceled export is dropped with its intermediates, not resumed
+		canceled := run.isCanceled() && !finished
+		if canceled {
+			result = "Cancelled"
+			if muxing {
+				os.Remove(outPath)
 			}
 		}
+		a.releaseExportJournal(job, finished || canceled)
+		if canceled && options.run == nil {
+			emit("export:cancelled", run.ID)
+		}
+	}()
+	if job != nil && len(job.Passes) > 0 {
+		emit("export:status", tr("export.resuming"))
 	}
 
-	var segments []RenderSegment
-
-	// 3. Iterate Time Slices
-	for i := 0; i < len(uniquePoints)-1; i++ {
-		start := uniquePoints[i]
-		end := uniquePoints[i+1]
-		mid := (start + end) / 2
-		dur := end - start
+	if options.Slate != "" {
+		timeline = withSlate(timeline, options.Slate, a.getVideoDuration(options.Slate))
+	}
 
-		var activeItem *Item
+	tempDir := os.TempDir()
+	temps := &tempSet{}
+	defer temps.release()
+	videoOutput, videoDone := job.done(passVideo)
+	audioOutput, audioDone := job.done(passAudio)
+	
+	blackPath, silencePath := a.prepareGapMedia()
 
-		// 4. Find Top-Most Visible Video
-		for tIdx, track := range tracks {
-			if tIdx < len(timeline.TrackSettings) {
-				ts := timeline.TrackSettings[tIdx]
-				if !ts.Visible { continue }
-				isAudio := ts.Type == "audio" || strings.HasPrefix(ts.Name, "A")
-				if isAudio { continue }
-			}
+	// --- PASS 1: ANALYZE TIMELINE (VISUALS) ---
+	emit("export:status", tr("export.analyzing"))
+	segments, visiblePairIDs := analyzeVisualSegments(timeline, blackPath, silencePath)
+	if err := a.resolveReversedSegments(run, segments); err != nil {
+		return tr("export.error.reverse", err.Error())
+	}
 
-			foundClip := false
-			for _, item := range track {
-				if mid >= item.StartTime && mid < item.StartTime+item.Duration {
-					itemCopy := item
-					activeItem = &itemCopy
-					foundClip = true
-					break
-				}
+	// --- PASS 2: RENDER VIDEO ---
+	if !videoDone && options.IncludeVideo && (options.Format == "mp4" || options.Format == "mov" || options.Format == "mxf" || options.Format == "mkv" || options.Format == "webm") {
+		// Segments with a custom clip filter are rendered to intermediates first,
+		// then concatenated like any other source
+		a.recordSegmentKeys(job, segments)
+		for i := range segments {
+			if segments[i].Filter == "" {
+				continue
 			}
-			if foundClip { break }
-		}
-
-		if activeItem != nil {
-			// Register this clip as "Visible"
-			if activeItem.PairID != "" {
-				visiblePairIDs[activeItem.PairID] = true
+			emit("export:status", tr("export.clipFilter", i+1, len(segments)))
+			filtered, err := a.renderFilteredSegment(run, segments[i])
+			if err != nil {
+				return tr("export.error.clipFilter", err.Error())
 			}
-
-			offset := start - activeItem.StartTime + activeItem.TrimStart
-			source := activeItem.OutputVideo
-			if source == "" { source = activeItem.SourceImage }
-
-			// ECHO FIX: Force AudioSource to Silence.
-			// We will rely entirely on Pass 3 (Audio Tracks) to render the audio.
-			// This prevents the "Video File" and "Audio File" from playing at the same time.
-			segments = append(segments, RenderSegment{
-				SourcePath:  source,
-				InPoint:     offset,
-				OutPoint:    offset + dur,
-				Duration:    dur,
-				IsImage:     strings.HasSuffix(source, ".png") || strings.HasSuffix(source, ".jpg"),
-				AudioSource: silencePath, // <--- Key Change
-			})
-		} else {
-			segments = append(segments, RenderSegment{
-				SourcePath:  blackPath,
-				AudioSource: silencePath,
-				Duration:    dur,
-				IsImage:     true,
+			segments[i] = RenderSegment{
+				SourcePath:  filtered,
 				InPoint:     0,
-				OutPoint:    dur,
-			})
+				OutPoint:    segments[i].Duration,
+				Duration:    segments[i].Duration,
+				AudioSource: segments[i].AudioSource,
+			}
 		}
-	}
 
-	// --- PASS 2: RENDER VIDEO ---
-	if options.IncludeVideo && (options.Format == "mp4" || options.Format == "mov" || options.Format == "mkv") {
 		var concat strings.Builder
 		concat.WriteString("ffconcat version 1.0\n")
 		for _, seg := range segments {
-			safePath := strings.ReplaceAll(filepath.ToSlash(seg.SourcePath), "'", "'\\''")
-			concat.WriteString(fmt.Sprintf("file '%s'\n", safePath))
+			concat.WriteString(concatEntry(seg.SourcePath))
 			if !seg.IsImage {
 				concat.WriteString(fmt.Sprintf("inpoint %f\n", seg.InPoint))
 				concat.WriteString(fmt.Sprintf("outpoint %f\n", seg.OutPoint))
@@ -1523,12 +1891,48 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 			}
 		}
 
-		listPath := filepath.Join(tempDir, fmt.Sprintf("export_list_%d.txt", time.Now().Unix()))
+		listPath := temps.add(filepath.Join(tempDir, fmt.Sprintf("export_list_%d.txt", time.Now().Unix())))
 		os.WriteFile(listPath, []byte(concat.String()), 0644)
 
-		videoOutput = filepath.Join(tempDir, fmt.Sprintf("temp_video_%d.%s", time.Now().Unix(), options.Format))
+		videoOutput = a.workFile(job, temps, fmt.Sprintf("temp_video_%d.%s", time.Now().Unix(), options.Format))
 		args := []string{"-y", "-f", "concat", "-safe", "0", "-i", listPath}
 
+		var videoFilters []string
+
+		// --- OUTPUT SIZE ---
+		// Mixed-size clips are scaled to one frame size (before the conform)
+		if filter := exportSizeFilter(options); filter != "" {
+			videoFilters = append(videoFilters, filter)
+		}
+
+		// --- FRAME RATE CONFORM ---
+		// Mixed-fps sources are retimed with the project policy (same as preview),
+		// to the export's own rate if it sets one
+		if project, err := a.GetProject(projectId); err == nil || options.FPS > 0 {
+			rate := project.FrameRate
+			if options.FPS > 0 {
+				rate = options.FPS
+			}
+			var sources []string
+			for _, seg := range segments {
+				if !seg.IsImage {
+					sources = append(sources, seg.SourcePath)
+				}
+			}
+			if fps, needed := resolveConform(rate, sources, a.getVideoFrameRate); needed && fps > 0 {
+				videoFilters = append(videoFilters, conformFilter(fps, project.ConformPolicy))
+			}
+		}
+
+		// --- WATERMARK ---
+		if options.Watermark != "" {
+			videoFilters = append(videoFilters, watermarkFilter(options.Watermark))
+		}
+
+		if len(videoFilters) > 0 {
+			args = append(args, "-vf", strings.Join(videoFilters, ","))
+		}
+
 		// --- QUALITY LOGIC ---
 		// H.264 (MP4/MKV): Lower CRF = Higher Quality.
 		// ProRes (MOV): Higher Profile = Higher Quality.
@@ -1547,14 +1951,30 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 			proresProfile = "2"
 		}
 
-		if options.Format == "mov" {
+		if encoder != EncoderSoftware {
+			// --- HARDWARE LOGIC (GPU H.264 / ProRes) ---
+			args = append(args, hardwareVideoArgs(encoder, options)...)
+			args = append(args, "-an", videoOutput)
+		} else if options.Format == "mxf" || (options.Format == "mov" && options.VideoCodec == "dnxhr") {
+			// --- DNxHR LOGIC (Avid) ---
+			args = append(args, dnxhrVideoArgs(options)...)
+			args = append(args, "-an", videoOutput)
+		} else if options.Format == "mov" {
 			// --- PRORES LOGIC ---
+			pixFmt := "yuv422p10le"
+			if options.Transparent {
+				proresProfile, pixFmt = "4", "yuva444p10le" // 4444 carries alpha
+			}
 			args = append(args,
 				"-c:v", "prores_ks",
 				"-profile:v", proresProfile,
 				"-vendor", "apl0",
-				"-pix_fmt", "yuv422p10le",
+				"-pix_fmt", pixFmt,
 				"-an", videoOutput)
+		} else if options.Format == "webm" {
+			// --- VP9 / AV1 LOGIC (WEBM) ---
+			args = append(args, webmVideoArgs(options)...)
+			args = append(args, "-an", videoOutput)
 		} else {
 			// --- H.264 LOGIC (MP4 / MKV) ---
 			args = append(args,
@@ -1563,34 +1983,39 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 				"-crf", crf, // Uses the dynamic CRF calculated above
 				"-an", videoOutput)
 		}
+		args = withExtraArgs(args, encodeArgs)
 
-		if err := a.runFFmpegWithProgress(args, "Video"); err != nil {
-			return "Video Render Error: " + err.Error()
+		if err := a.runFFmpegWithProgress(run, args, label(tr("export.stage.video"))); err != nil {
+			return tr("export.error.video", err.Error())
 		}
+		a.finishPass(job, passVideo, videoOutput)
 	}
 
 // --- PASS 3: RENDER AUDIO ---
-	if options.IncludeAudio {
-		runtime.EventsEmit(a.ctx, "export:status", "Rendering Audio...")
+	if !audioDone && options.IncludeAudio {
+		emit("export:status", tr("export.renderingAudio"))
 
 		// 3a. Render "Main" Audio (from Video Tracks) using Concat
 		// This ensures audio follows video visibility (V2 mutes V1)
 		var audioConcat strings.Builder
 		audioConcat.WriteString("ffconcat version 1.0\n")
 		for _, seg := range segments {
-			safePath := strings.ReplaceAll(filepath.ToSlash(seg.AudioSource), "'", "'\\''")
-			audioConcat.WriteString(fmt.Sprintf("file '%s'\n", safePath))
+			audioConcat.WriteString(concatEntry(seg.AudioSource))
 			audioConcat.WriteString(fmt.Sprintf("inpoint %f\n", seg.InPoint))
 			audioConcat.WriteString(fmt.Sprintf("outpoint %f\n", seg.OutPoint))
 		}
 
-		audioListPath := filepath.Join(tempDir, fmt.Sprintf("export_audio_list_%d.txt", time.Now().Unix()))
+		audioListPath := temps.add(filepath.Join(tempDir, fmt.Sprintf("export_audio_list_%d.txt", time.Now().Unix())))
 		os.WriteFile(audioListPath, []byte(audioConcat.String()), 0644)
 
-		mainAudioOutput := filepath.Join(tempDir, fmt.Sprintf("temp_audio_main_%d.wav", time.Now().Unix()))
 		// Render Main Audio
-		if err := a.runFFmpegWithProgress([]string{"-y", "-f", "concat", "-safe", "0", "-i", audioListPath, "-c:a", "pcm_s16le", mainAudioOutput}, "Main Audio"); err != nil {
-			return "Main Audio Error: " + err.Error()
+		mainAudioOutput, mainAudioDone := job.done(passMainAudio)
+		if !mainAudioDone {
+			mainAudioOutput = a.workFile(job, temps, fmt.Sprintf("temp_audio_main_%d.wav", time.Now().Unix()))
+			if err := a.runFFmpegWithProgress(run, []string{"-y", "-f", "concat", "-safe", "0", "-i", audioListPath, "-c:a", "pcm_s16le", mainAudioOutput}, label(tr("export.stage.mainAudio"))); err != nil {
+				return tr("export.error.mainAudio", err.Error())
+			}
+			a.finishPass(job, passMainAudio, mainAudioOutput)
 		}
 
 		type AudioOp struct {
@@ -1599,6 +2024,7 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 			Duration  float64
 			TrimStart float64 // Source offset
 			Volume    float64
+			Filter    string // Custom clip audio filter chain
 		}
 		var audioOps []AudioOp
 
@@ -1606,7 +2032,7 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 		// Instead of just looping and adding, we slice time and let higher tracks overwrite lower ones.
 
 		// 1. Gather all Audio-Only Tracks
-		var audioTracks [][]Item
+		var audioTracks [][]TimelineItem
 		audioTimePoints := []float64{0.0}
 
 		for tIdx, rawTrack := range timeline.Tracks {
@@ -1617,29 +2043,22 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 					continue
 				}
 				// We only care about AUDIO tracks here (A1, A2...)
-				isAudio := ts.Type == "audio" || strings.HasPrefix(ts.Name, "A")
+				isAudio := ts.Type == "audio" || (ts.Type != TrackTypeAdjustment && strings.HasPrefix(ts.Name, "A"))
 				if !isAudio {
 					continue
 				}
 
-				var track []Item
+				var track []TimelineItem
 				for _, rawItem := range rawTrack {
-					item := Item{}
-					if v, ok := rawItem["startTime"].(float64); ok { item.StartTime = v }
-					if v, ok := rawItem["duration"].(float64); ok { item.Duration = v }
-					if v, ok := rawItem["trimStart"].(float64); ok { item.TrimStart = v }
-					if v, ok := rawItem["outputVideo"].(string); ok { item.OutputVideo = v }
-					if v, ok := rawItem["audioPath"].(string); ok { item.AudioPath = v }
-					if v, ok := rawItem["pairId"].(string); ok { item.PairID = v }
-					// Volume default
-					item.Duration = item.Duration // hack to keep type
-					
-					// Add to our list
-					track = append(track, item)
-
-					// Collect Time Points
-					audioTimePoints = append(audioTimePoints, item.StartTime)
-					audioTimePoints = append(audioTimePoints, item.StartTime+item.Duration)
+					// Looping clips loop their audio too, one item per pass
+					for _, item := range expandLoop(parseTimelineItem(rawItem)) {
+						// Add to our list
+						track = append(track, item)
+
+						// Collect Time Points
+						audioTimePoints = append(audioTimePoints, item.StartTime)
+						audioTimePoints = append(audioTimePoints, item.StartTime+item.Duration)
+					}
 				}
 				audioTracks = append(audioTracks, track)
 			}
@@ -1664,7 +2083,7 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 			mid := (start + end) / 2
 			dur := end - start
 
-			var activeItem *Item
+			var activeItem *TimelineItem
 
 			// 4. Find the Winner for this segment
 			// We iterate ALL audio tracks (0..N).
@@ -1693,6 +2112,24 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 				offset := start - activeItem.StartTime + activeItem.TrimStart
 				src := activeItem.OutputVideo
 				if src == "" { src = activeItem.AudioPath }
+				filter := activeItem.AudioFilter
+
+				// Reversed clips: areverse short slices inline, cut long ones
+				// from the reversed proxy
+				if src != "" && activeItem.Reversed {
+					srcIn, srcOut := reversedSourceRange(*activeItem, start, dur)
+					if dur <= reverseAudioInlineSeconds {
+						offset = srcIn
+						filter = joinFilters("areverse", filter)
+					} else {
+						proxy, length, err := a.reversedProxy(run, src)
+						if err != nil {
+							return tr("export.error.reverse", err.Error())
+						}
+						src = proxy
+						offset = max(0, length-srcOut)
+					}
+				}
 				
 				if src != "" {
 					audioOps = append(audioOps, AudioOp{
@@ -1701,6 +2138,7 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 						Duration:  dur,   // Use segment duration
 						TrimStart: offset,
 						Volume:    1.0, // Default volume
+						Filter:    filter,
 					})
 				}
 			}
@@ -1716,7 +2154,7 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 
 			// Inputs 1..N are Extra Audio Clips
 			for _, op := range audioOps {
-				args = append(args, "-i", op.Source)
+				args = append(args, "-i", mediaPath(op.Source))
 			}
 
 			// Filter
@@ -1729,9 +2167,16 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 				// Use exact duration logic for cleaner cuts
 				end := op.TrimStart + op.Duration
 				
-				// Apply Trim -> Reset Timestamp -> Delay -> Volume
-				filterComplex.WriteString(fmt.Sprintf("[%d:a]atrim=start=%f:end=%f,asetpts=PTS-STARTPTS,adelay=%d|%d,volume=%f[a%d];",
-					inputIdx, op.TrimStart, end, delayMs, delayMs, op.Volume, i))
+				// Apply Trim -> Reset Timestamp -> (Custom Filter) -> Delay -> Volume
+				custom := ""
+				if op.Filter != "" {
+					if err := validateClipFilter(op.Filter, "audio"); err != nil {
+						return tr("export.error.clipFilter", err.Error())
+					}
+					custom = op.Filter + ","
+				}
+				filterComplex.WriteString(fmt.Sprintf("[%d:a]atrim=start=%f:end=%f,asetpts=PTS-STARTPTS,%sadelay=%d|%d,volume=%f[a%d];",
+					inputIdx, op.TrimStart, end, custom, delayMs, delayMs, op.Volume, i))
 			}
 
 			// Mix
@@ -1742,24 +2187,25 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 			// Normalize=0 prevents volume drop when mixing
 			filterComplex.WriteString(fmt.Sprintf("amix=inputs=%d:dropout_transition=0:normalize=0[outa]", len(audioOps)+1))
 
-			audioOutput = filepath.Join(tempDir, fmt.Sprintf("temp_audio_%d.m4a", time.Now().Unix()))
+			audioOutput = a.workFile(job, temps, fmt.Sprintf("temp_audio_%d.m4a", time.Now().Unix()))
 
 			args = append(args, "-filter_complex", filterComplex.String(), "-map", "[outa]", "-c:a", "aac", "-b:a", "192k", audioOutput)
 
-			if err := a.runFFmpegWithProgress(args, "Audio"); err != nil {
-				return "Audio Render Error: " + err.Error()
+			if err := a.runFFmpegWithProgress(run, args, label(tr("export.stage.audio"))); err != nil {
+				return tr("export.error.audio", err.Error())
 			}
 		} else {
 			// No extra audio, just convert main audio to AAC
-			audioOutput = filepath.Join(tempDir, fmt.Sprintf("temp_audio_%d.m4a", time.Now().Unix()))
-			if err := a.runFFmpegWithProgress([]string{"-y", "-i", mainAudioOutput, "-c:a", "aac", "-b:a", "192k", audioOutput}, "Audio Convert"); err != nil {
-				return "Audio Convert Error: " + err.Error()
+			audioOutput = a.workFile(job, temps, fmt.Sprintf("temp_audio_%d.m4a", time.Now().Unix()))
+			if err := a.runFFmpegWithProgress(run, []string{"-y", "-i", mainAudioOutput, "-c:a", "aac", "-b:a", "192k", audioOutput}, label(tr("export.stage.audioConvert"))); err != nil {
+				return tr("export.error.audioConvert", err.Error())
 			}
 		}
+		a.finishPass(job, passAudio, audioOutput)
 	}
 	
 	// --- MUX / FINALIZE ---
-	runtime.EventsEmit(a.ctx, "export:status", "Finalizing...")
+	emit("export:status", tr("export.finalizing"))
 
 	finalArgs := []string{"-y"}
 
@@ -1771,7 +2217,7 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 	}
 
 	if videoOutput == "" && audioOutput == "" {
-		return "Nothing to export"
+		return tr("export.error.nothing")
 	}
 
 	// 1. Handle Video Stream
@@ -1793,21 +2239,57 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 		// We must convert the temp audio (AAC) to the user's requested format.
 		if options.Format == "mp3" {
 			// Convert to MP3
-			finalArgs = append(finalArgs, "-c:a", "libmp3lame", "-b:a", "320k")
+			finalArgs = append(finalArgs, "-c:a", "libmp3lame", "-b:a", audioBitrate(options, 320))
 		} else if options.Format == "wav" {
 			// Convert to WAV (Uncompressed)
 			finalArgs = append(finalArgs, "-c:a", "pcm_s16le")
+		} else if options.Format == "opus" {
+			// Opus: transparent for speech/music at a fraction of MP3 sizes (podcast/web)
+			finalArgs = append(finalArgs, "-c:a", "libopus", "-b:a", audioBitrate(options, 128), "-vbr", "on")
+		} else if options.Format == "flac" {
+			// FLAC: lossless archival copy, level only trades encode time for size
+			finalArgs = append(finalArgs, "-c:a", "flac")
+			if options.CompressionLevel > 0 && options.CompressionLevel <= 12 {
+				finalArgs = append(finalArgs, "-compression_level", strconv.Itoa(options.CompressionLevel))
+			}
+		} else if options.Format == "mxf" {
+			// Avid expects uncompressed 48kHz PCM in MXF
+			finalArgs = append(finalArgs, "-c:a", "pcm_s24le", "-ar", "48000")
+		} else if options.Format == "webm" {
+			// WebM only accepts Vorbis/Opus, so re-encode the AAC mix
+			finalArgs = append(finalArgs, "-c:a", "libopus", "-b:a", audioBitrate(options, 160))
 		} else {
 			// For Video (MP4/MOV/MKV), keeping the AAC audio is standard and fast.
 			finalArgs = append(finalArgs, "-c:a", "copy")
 		}
 	}
 
+	// Audio-only formats are encoded in the mux, so encoder overrides apply here
+	if videoOutput == "" {
+		finalArgs = append(finalArgs, encodeArgs...)
+	}
+	finalArgs = append(finalArgs, muxArgs...)
 	finalArgs = append(finalArgs, outPath)
 
 	cmd := exec.Command("ffmpeg", finalArgs...)
-	if out, err := cmd.CombinedOutput(); err != nil {
-		return "Mux Error: " + string(out)
+	muxing = true
+	if out, err := run.combinedOutput(cmd); err != nil {
+		return tr("export.error.mux", string(out))
+	}
+
+	// --- VERIFY ---
+	// A broken file fails the export now, while the passes can still be resumed
+	if !options.silent {
+		emit("export:status", tr("export.verifying"))
+		expect := MediaExpectation{Video: videoOutput != "", Audio: audioOutput != ""}
+		// Advanced args may legitimately cut the output (-t, -to)
+		if len(encodeArgs) == 0 && len(muxArgs) == 0 {
+			expect.Duration = timelineDuration(timeline)
+		}
+		check := verifyMedia(outPath, expect)
+		if !check.OK {
+			return tr("export.error.verify", check.summary())
+		}
 	}
 
 	// Cleanup Temp Files
@@ -1817,12 +2299,139 @@ func (a *App) ExportVideo(projectId string, sceneId string, options ExportOption
 	if audioOutput != "" {
 		os.Remove(audioOutput)
 	}
+	finished = true
 
-	runtime.EventsEmit(a.ctx, "export:progress", 100)
+	emit("export:progress", 100)
 	return "Success"
 }
 
-func (a *App) runFFmpegWithProgress(args []string, label string) error {
+// watermarkFilter burns semi-transparent text across the centre of the frame
+func watermarkFilter(text string) string {
+	// Filtergraph escaping of drawtext is multi-level and fragile, so drop the
+	// characters that need it rather than escaping them
+	safe := drawtextSafe(text)
+	return fmt.Sprintf("drawtext=text='%s':fontcolor=white@0.35:fontsize=h/10:x=(w-text_w)/2:y=(h-text_h)/2:borderw=2:bordercolor=black@0.25", safe)
+}
+
+// audioBitrate formats the requested audio bitrate for ffmpeg, falling back
+// to the given default (kbps) when the option is unset or out of range.
+func audioBitrate(options ExportOptions, defaultKbps int) string {
+	kbps := options.AudioBitrate
+	if kbps <= 0 || kbps > 512 {
+		kbps = defaultKbps
+	}
+	return fmt.Sprintf("%dk", kbps)
+}
+
+// dnxhrVideoArgs returns the encoder arguments for a DNxHR export.
+// The resolution-independent DNxHR profiles are used (rather than legacy DNxHD)
+// so AI clips at arbitrary sizes don't need to be conformed to 1080p first.
+func dnxhrVideoArgs(options ExportOptions) []string {
+	profile := options.VideoProfile
+	if profile == "" {
+		switch options.Quality {
+		case "high":
+			profile = "hq"
+		case "low":
+			profile = "lb"
+		default: // medium
+			profile = "sq"
+		}
+	}
+
+	// HQX is the only 10-bit profile in this set
+	pixFmt := "yuv422p"
+	switch profile {
+	case "lb", "sq", "hq":
+	case "hqx":
+		pixFmt = "yuv422p10le"
+	default:
+		profile = "sq"
+	}
+
+	return []string{
+		"-c:v", "dnxhd",
+		"-profile:v", "dnxhr_" + profile,
+		"-pix_fmt", pixFmt,
+	}
+}
+
+// validateTransparent rejects alpha for encoders that would drop it
+func validateTransparent(options ExportOptions) error {
+	if !options.Transparent {
+		return nil
+	}
+	switch {
+	case options.Format == "webm" && (options.VideoCodec == "" || options.VideoCodec == "vp9"):
+		return nil
+	case options.Format == "mov" && options.VideoCodec != "dnxhr":
+		return nil
+	}
+	return fmt.Errorf("transparency needs WebM with VP9 or MOV with ProRes")
+}
+
+// webmVideoArgs returns the encoder arguments for a WebM export.
+// VP9 is the default since it is the only WebM codec browsers decode with alpha;
+// AV1 is offered through libaom (best quality) or SVT-AV1 (much faster).
+func webmVideoArgs(options ExportOptions) []string {
+	switch options.VideoCodec {
+	case "av1":
+		crf := "30"
+		switch options.Quality {
+		case "high":
+			crf = "24"
+		case "low":
+			crf = "38"
+		}
+		return []string{
+			"-c:v", "libaom-av1",
+			"-crf", crf,
+			"-b:v", "0",
+			"-cpu-used", "4",
+			"-row-mt", "1",
+			"-pix_fmt", "yuv420p",
+		}
+	case "svtav1":
+		crf := "35"
+		switch options.Quality {
+		case "high":
+			crf = "28"
+		case "low":
+			crf = "45"
+		}
+		return []string{
+			"-c:v", "libsvtav1",
+			"-crf", crf,
+			"-preset", "8",
+			"-pix_fmt", "yuv420p",
+		}
+	default: // vp9
+		crf := "31"
+		switch options.Quality {
+		case "high":
+			crf = "24"
+		case "low":
+			crf = "38"
+		}
+		args := []string{
+			"-c:v", "libvpx-vp9",
+			"-crf", crf,
+			"-b:v", "0", // Constant quality mode
+			"-row-mt", "1",
+			"-deadline", "good",
+		}
+		if options.Transparent {
+			// Alt-ref frames are incompatible with alpha in libvpx
+			args = append(args, "-pix_fmt", "yuva420p", "-auto-alt-ref", "0")
+		} else {
+			args = append(args, "-pix_fmt", "yuv420p")
+		}
+		return args
+	}
+}
+
+// runFFmpegWithProgress runs ffmpeg as a process of run (nil outside exports)
+func (a *App) runFFmpegWithProgress(run *exportRun, args []string, label string) error {
 	cmd := exec.Command("ffmpeg", args...)
 	
 	// Capture stderr for progress
@@ -1831,7 +2440,7 @@ func (a *App) runFFmpegWithProgress(args []string, label string) error {
 		return err
 	}
 	
-	if err := cmd.Start(); err != nil {
+	if err := run.start(cmd); err != nil {
 		return err
 	}
 
@@ -1847,19 +2456,19 @@ func (a *App) runFFmpegWithProgress(args []string, label string) error {
 		scanner.Split(bufio.ScanLines)
 		for scanner.Scan() {
 			line := scanner.Text()
-			if strings.Contains(line, "time=") {
+			if label != "" && strings.Contains(line, "time=") {
 				// Extract time
 				re := regexp.MustCompile(`time=(\d{2}):(\d{2}):(\d{2}\.\d{2})`)
 				matches := re.FindStringSubmatch(line)
 				if len(matches) == 4 {
 					// Just emit the raw string for the UI to display
-					runtime.EventsEmit(a.ctx, "export:status", fmt.Sprintf("%s: %s:%s:%s", label, matches[1], matches[2], matches[3]))
+					runtime.EventsEmit(a.ctx, "export:status", tr("export.progress", label, matches[1]+":"+matches[2]+":"+matches[3]))
 				}
 			}
 		}
 	}()
 
-	return cmd.Wait()
+	return run.wait(cmd)
 }
 
 // =========================================================================
@@ -1887,11 +2496,8 @@ func NewStreamServer() *StreamServer {
 func (s *StreamServer) GeneratePlaylist(clips []string) (string, error) {
 	var content strings.Builder
 	for _, clip := range clips {
-		// Normalize slashes for FFmpeg (Windows backslash fix)
-		normalized := filepath.ToSlash(clip)
-		// Escape single quotes for FFmpeg
-		safePath := strings.ReplaceAll(normalized, "'", "'\\''")
-		content.WriteString(fmt.Sprintf("file '%s'\n", safePath))
+		// Normalized and escaped for FFmpeg (Windows backslash, UNC and long path fix)
+		content.WriteString(concatEntry(clip))
 	}
 
 	playlistPath := filepath.Join(s.currentDir, "playlist.txt")
@@ -1899,7 +2505,10 @@ func (s *StreamServer) GeneratePlaylist(clips []string) (string, error) {
 	return playlistPath, err
 }
 
-func (s *StreamServer) RenderPreviewMP4() (string, error) {
+// RenderPreviewMP4 concatenates the playlist into preview.mp4. An empty filter
+// stream-copies (fast, requires matching clips); otherwise the video is re-encoded
+// through the given conform filter.
+func (s *StreamServer) RenderPreviewMP4(filter string) (string, error) {
 	playlistPath := filepath.Join(s.currentDir, "playlist.txt")
 	if _, err := os.Stat(playlistPath); os.IsNotExist(err) {
 		return "", fmt.Errorf("playlist not found")
@@ -1907,19 +2516,21 @@ func (s *StreamServer) RenderPreviewMP4() (string, error) {
 
 	outPath := filepath.Join(s.currentDir, "preview.mp4")
 
-	// Fast concat (no re-encode). Requires matching codecs/params across clips.
-	cmd := exec.Command("ffmpeg",
-		"-y",
-		"-f", "concat",
-		"-safe", "0",
-		"-i", playlistPath,
-		"-c", "copy",
-		"-movflags", "+faststart",
-		outPath,
-	)
+	args := []string{"-y", "-f", "concat", "-safe", "0", "-i", playlistPath}
+	if filter == "" {
+		// Fast concat (no re-encode). Requires matching codecs/params across clips.
+		args = append(args, "-c", "copy")
+	} else {
+		args = append(args,
+			"-vf", filter,
+			"-c:v", "libx264", "-preset", "ultrafast", "-crf", "20",
+			"-c:a", "aac", "-b:a", "192k")
+	}
+	args = append(args, "-movflags", "+faststart", outPath)
+	cmd := exec.Command("ffmpeg", args...)
 
 	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
+	if err := runTracked(cmd); err != nil {
 		return "", err
 	}
 	return outPath, nil
@@ -1990,10 +2601,17 @@ func (s *StreamServer) StartStreamHandler(w http.ResponseWriter, r *http.Request
 	}
 }
 
-func StartStreamServer() {
+// StartStreamServer builds the engine routes and resolves the transport, then
+// serves them in the background under the supervisor, which restarts the
+// listener if it ever fails.
+func StartStreamServer(app *App) {
 	server = NewStreamServer()
 	mux := http.NewServeMux()
 
+	// Liveness + detailed status for the frontend's "engine offline" state
+	mux.HandleFunc("/healthz", healthzHandler)
+	mux.HandleFunc("/status", app.statusHandler)
+
 	// Legacy MJPEG stream (still available)
 	mux.HandleFunc("/stream", server.StartStreamHandler)
 
@@ -2008,14 +2626,39 @@ func StartStreamServer() {
 		http.ServeFile(w, r, path)
 	})
 
+	// Stream Deck / controller commands
+	mux.HandleFunc("/control/", app.controlHandler)
+
+	// Client-facing monitor on a second display
+	mux.HandleFunc("/monitor", monitorPageHandler)
+	mux.HandleFunc("/monitor/events", monitorEventsHandler)
+
+	// Histogram / waveform / vectorscope for a single frame
+	mux.HandleFunc("/scopes", server.ScopesHandler)
+
+	// Scrub frames with safe-area guides and mattes (preview only)
+	mux.HandleFunc("/frame", server.FrameHandler)
+
 	// Serve local video files for pre-loading
 	mux.HandleFunc("/video/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		// /video/C:/Path/To/File.mp4 -> C:/Path/To/File.mp4
-		path := strings.TrimPrefix(r.URL.Path, "/video/")
-		http.ServeFile(w, r, path)
+		// /video/C:/Path/To/File.mp4 -> C:/Path/To/File.mp4 (UNC shares as //NAS/...)
+		path := videoRequestPath(strings.TrimPrefix(r.URL.Path, "/video/"))
+		serveMedia(w, r, path)
 	})
 
-	fmt.Println("🎥 Video Engine listening on http://localhost:3456/stream")
-	http.ListenAndServe(":3456", mux)
+	// Loopback only: local files must never be reachable from the LAN
+	settings := app.getConfig().Engine
+	network, addr := engineListenAddr(settings)
+	tlsConfig, err := app.engineTLSConfig(settings)
+	if err != nil {
+		fmt.Println("⚠️ Engine TLS disabled:", err)
+		recordEngineError("engine", err.Error())
+	} else if tlsConfig != nil {
+		engineAddrMu.Lock()
+		engineBase = "https://" + engineTCPAddr
+		engineAddrMu.Unlock()
+	}
+	fmt.Printf("🎥 Video Engine listening on %s %s (tls: %t)\n", network, addr, tlsConfig != nil)
+	go superviseServer(network, addr, mux, tlsConfig, app.emitEngineHealth)
 }
\ No newline at end of file
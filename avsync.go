@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/cmplx"
+	"os/exec"
+)
+
+// --- WAVEFORM SYNC ---
+
+// Lines up separately recorded audio with a clip's scratch track by cross-correlation.
+
+const (
+	syncSampleRate   = 8000
+	syncEnvelopeRate = 500 // Envelope bins per second (2 ms resolution)
+	syncMaxReference = 60.0
+	syncMaxTarget    = 15 * 60.0
+	syncMinScore     = 0.25
+)
+
+type SyncResult struct {
+	Offset     float64 `json:"offset"`     // Seconds the target moved (+ = later)
+	NewStart   float64 `json:"newStart"`   // Target's timeline start after sync
+	TrimStart  float64 `json:"trimStart"`  // Target's trim after sync
+	Confidence float64 `json:"confidence"` // Normalized correlation peak, 0-1
+	Applied    bool    `json:"applied"`
+}
+
+// decodeEnvelope decodes seconds of audio from start and returns its onset envelope
+func decodeEnvelope(path string, start float64, seconds float64) ([]float64, error) {
+	cmd := exec.Command("ffmpeg", "-v", "error",
+		"-ss", fmt.Sprintf("%f", start), "-t", fmt.Sprintf("%f", seconds),
+		"-i", mediaPath(path),
+		"-vn", "-ac", "1", "-ar", fmt.Sprint(syncSampleRate), "-f", "s16le", "-")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	const binSize = syncSampleRate / syncEnvelopeRate
+	reader := bufio.NewReader(stdout)
+	rms := []float64{}
+	sum, n := 0.0, 0
+	var sample int16
+	for {
+		if err := binary.Read(reader, binary.LittleEndian, &sample); err != nil {
+			if err != io.EOF && err != io.ErrUnexpectedEOF {
+				cmd.Wait()
+				return nil, err
+			}
+			break
+		}
+		v := float64(sample) / 32768
+		sum += v * v
+		n++
+		if n == binSize {
+			rms = append(rms, math.Sqrt(sum/binSize))
+			sum, n = 0, 0
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("could not decode audio from %s: %v", path, err)
+	}
+
+	envelope := make([]float64, len(rms))
+	mean := 0.0
+	for i := 1; i < len(rms); i++ {
+		envelope[i] = math.Max(0, rms[i]-rms[i-1])
+		mean += envelope[i]
+	}
+	// Zero-mean, so unrelated audio correlates near 0 instead of high
+	if len(envelope) > 0 {
+		mean /= float64(len(envelope))
+		for i := range envelope {
+			envelope[i] -= mean
+		}
+	}
+	return envelope, nil
+}
+
+// fft is an in-place radix-2 transform; len(x) must be a power of two
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, sign*2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				u := x[start+k]
+				v := x[start+k+size/2] * w
+				x[start+k] = u + v
+				x[start+k+size/2] = u - v
+				w *= step
+			}
+		}
+	}
+	if inverse {
+		for i := range x {
+			x[i] /= complex(float64(n), 0)
+		}
+	}
+}
+
+// bestAlignment finds where ref best fits inside target. Returns the bin
+// offset and the normalized correlation at that offset.
+func bestAlignment(ref []float64, target []float64) (int, float64) {
+	if len(ref) == 0 || len(target) < len(ref) {
+		return 0, 0
+	}
+	size := 1
+	for size < len(ref)+len(target) {
+		size <<= 1
+	}
+	a := make([]complex128, size)
+	b := make([]complex128, size)
+	for i, v := range ref {
+		a[i] = complex(v, 0)
+	}
+	for i, v := range target {
+		b[i] = complex(v, 0)
+	}
+	fft(a, false)
+	fft(b, false)
+	for i := range a {
+		a[i] = cmplx.Conj(a[i]) * b[i]
+	}
+	fft(a, true)
+
+	refEnergy := 0.0
+	for _, v := range ref {
+		refEnergy += v * v
+	}
+	// Running energy of the target window for normalization
+	prefix := make([]float64, len(target)+1)
+	for i, v := range target {
+		prefix[i+1] = prefix[i] + v*v
+	}
+
+	best, bestScore := 0, 0.0
+	for k := 0; k+len(ref) <= len(target); k++ {
+		windowEnergy := prefix[k+len(ref)] - prefix[k]
+		if windowEnergy <= 0 || refEnergy <= 0 {
+			continue
+		}
+		score := real(a[k]) / math.Sqrt(refEnergy*windowEnergy)
+		if score > bestScore {
+			best, bestScore = k, score
+		}
+	}
+	return best, bestScore
+}
+
+// findTimelineClip locates a clip by id
+func findTimelineClip(timeline TimelineData, clipId string) (map[string]interface{}, bool) {
+	for _, track := range timeline.Tracks {
+		for _, raw := range track {
+			if id, _ := raw["id"].(string); id == clipId {
+				return raw, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// clipAudioSource returns the file a clip's sound comes from
+func clipAudioSource(item TimelineItem) string {
+	if item.AudioPath != "" {
+		return item.AudioPath
+	}
+	return item.OutputVideo
+}
+
+// SyncByWaveform aligns targetClipId (e.g. a separately recorded take) to the
+// audio of referenceClipId (e.g. a rendered clip with scratch audio). With
+// apply false it only measures the offset.
+func (a *App) SyncByWaveform(projectId string, sceneId string, referenceClipId string, targetClipId string, apply bool) (SyncResult, error) {
+	var result SyncResult
+	timeline := a.GetTimeline(projectId, sceneId)
+	refRaw, ok := findTimelineClip(timeline, referenceClipId)
+	if !ok {
+		return result, fmt.Errorf("reference clip not found")
+	}
+	targetRaw, ok := findTimelineClip(timeline, targetClipId)
+	if !ok {
+		return result, fmt.Errorf("target clip not found")
+	}
+	ref, target := parseTimelineItem(refRaw), parseTimelineItem(targetRaw)
+	refSource, targetSource := clipAudioSource(ref), clipAudioSource(target)
+	if refSource == "" || targetSource == "" {
+		return result, fmt.Errorf("both clips need audio")
+	}
+
+	refEnv, err := decodeEnvelope(refSource, ref.TrimStart, math.Min(ref.Duration, syncMaxReference))
+	if err != nil {
+		return result, err
+	}
+	targetEnv, err := decodeEnvelope(targetSource, 0, syncMaxTarget)
+	if err != nil {
+		return result, err
+	}
+	if len(targetEnv) < len(refEnv) {
+		// The target is the shorter recording: search the reference instead
+		pos, score := bestAlignment(targetEnv, refEnv)
+		result.Confidence = score
+		// Target source time 0 sits pos bins into the reference span
+		result.NewStart = ref.StartTime + float64(pos)/syncEnvelopeRate + target.TrimStart
+	} else {
+		pos, score := bestAlignment(refEnv, targetEnv)
+		result.Confidence = score
+		// The reference span starts pos bins into the target source
+		result.NewStart = ref.StartTime - (float64(pos)/syncEnvelopeRate - target.TrimStart)
+	}
+	if result.Confidence < syncMinScore {
+		return result, fmt.Errorf("no reliable match (confidence %.2f)", result.Confidence)
+	}
+
+	// The whole clip shifts by Offset; one pushed before zero loses its head
+	result.Offset = result.NewStart - target.StartTime
+	result.TrimStart = target.TrimStart
+	if result.NewStart < 0 {
+		result.TrimStart -= result.NewStart
+		result.NewStart = 0
+	}
+	if !apply {
+		return result, nil
+	}
+
+	// Move the target and anything paired with it by the same amount
+	for _, track := range timeline.Tracks {
+		for _, raw := range track {
+			id, _ := raw["id"].(string)
+			pair, _ := raw["pairId"].(string)
+			if id != targetClipId && (target.PairID == "" || pair != target.PairID) {
+				continue
+			}
+			item := parseTimelineItem(raw)
+			start := item.StartTime + result.Offset
+			if start < 0 {
+				raw["trimStart"] = item.TrimStart - start
+				raw["duration"] = math.Max(0, item.Duration+start)
+				start = 0
+			}
+			raw["startTime"] = start
+		}
+	}
+	a.SaveTimeline(projectId, sceneId, timeline)
+	result.Applied = true
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// --- GENERATION BACKENDS ---
+
+// Server profiles name their engine; non-ComfyUI engines implement generationBackend.
+
+const (
+	BackendComfyUI = "comfyui"
+	BackendA1111   = "a1111" // AUTOMATIC1111 / Forge SD WebUI, see a1111.go
+	// BackendReplicate and BackendFal are hosted models, see cloud.go
+)
+
+// generationBackend is a render engine other than ComfyUI
+type generationBackend interface {
+	// ping checks that server answers
+	ping(server string) error
+	// generate renders shot on server into outPath. It reports progress
+	// with reportRenderProgress and stops when shotRenderCanceled.
+	generate(server string, shot Shot, workflowName string, outPath string) error
+	// freeMemory unloads the models the server keeps loaded
+	freeMemory(server string) error
+}
+
+// validBackend reports whether a server profile's backend is known
+func validBackend(kind string) bool {
+	switch kind {
+	case "", BackendComfyUI, BackendA1111, BackendReplicate, BackendFal:
+		return true
+	}
+	return false
+}
+
+// serverBackendKind is the backend of a configured server ("" = ComfyUI)
+func (a *App) serverBackendKind(server string) string {
+	for _, s := range a.getConfig().ComfyServers {
+		if s.URL == server && s.Backend != BackendComfyUI {
+			return s.Backend
+		}
+	}
+	return ""
+}
+
+// serverBackend returns the backend of server, nil for ComfyUI
+func (a *App) serverBackend(server string) generationBackend {
+	switch a.serverBackendKind(server) {
+	case BackendA1111:
+		return a1111Backend{a: a, server: a.findComfyServer(server)}
+	case BackendReplicate, BackendFal:
+		return cloudBackend{a: a, kind: a.serverBackendKind(server), server: a.findComfyServer(server)}
+	}
+	return nil
+}
+
+// findComfyServer returns the profile of a configured server
+func (a *App) findComfyServer(server string) ComfyServer {
+	for _, s := range a.getConfig().ComfyServers {
+		if s.URL == server {
+			return s
+		}
+	}
+	return ComfyServer{URL: server}
+}
+
+// workflowServer is the ComfyUI server workflow passes of a render on
+// server use: server itself, or the primary one for other engines
+func (a *App) workflowServer(server string) string {
+	if a.serverBackend(server) != nil {
+		return a.comfyURL
+	}
+	return server
+}
+
+// renderWithBackend renders a prepared shot on a non-ComfyUI server
+func (a *App) renderWithBackend(backend generationBackend, server string, projectId string, sceneId string, shot *Shot, workflowName string, take *renderTake) (Shot, error) {
+	jobID := fmt.Sprintf("%s-%d", a.serverBackendKind(server), time.Now().UnixNano())
+	outPath := a.nextVersionPath(projectId, sceneId, shot.ID)
+	if err := backend.generate(server, *shot, workflowName, outPath); err != nil {
+		os.Remove(outPath)
+		return *shot, err
+	}
+	return a.finishShotOutput(a.workflowServer(server), projectId, sceneId, shot, jobID, outPath, workflowName, take)
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// --- PROJECT BACKUPS ---
+
+type BackupSettings struct {
+	Enabled         bool   `json:"enabled"`
+	Dir             string `json:"dir"`             // Empty = Documents/MotionStudio/_backups
+	IntervalMinutes int    `json:"intervalMinutes"` // 0 = only on close / manual
+	Keep            int    `json:"keep"`            // Rotating copies per project (default 10)
+	OnClose         bool   `json:"onClose"`
+	IncludeMedia    bool   `json:"includeMedia"` // Also archive assets and renders (large)
+}
+
+type BackupInfo struct {
+	Name      string `json:"name"`
+	ProjectID string `json:"projectId"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// mediaExtensions are skipped unless IncludeMedia is set; everything else
+// (project/scene/shot/timeline JSON) is small and always archived.
+var mediaExtensions = map[string]bool{
+	".mp4": true, ".mov": true, ".mkv": true, ".webm": true, ".gif": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".webp": true,
+	".wav": true, ".mp3": true, ".m4a": true, ".flac": true, ".ogg": true, ".opus": true,
+}
+
+// GetBackupSettings returns the backup scheduler configuration
+func (a *App) GetBackupSettings() BackupSettings {
+	return a.getConfig().Backup
+}
+
+// SaveBackupSettings persists the backup scheduler configuration.
+// The scheduler picks up the new interval on its next tick.
+func (a *App) SaveBackupSettings(settings BackupSettings) {
+	a.updateConfig(func(c *Config) { c.Backup = settings })
+}
+
+func (a *App) getBackupDir() string {
+	dir := a.getConfig().Backup.Dir
+	if dir == "" {
+		dir = filepath.Join(a.getAppDir(), "_backups")
+	}
+	os.MkdirAll(dir, 0755)
+	return dir
+}
+
+// runBackupScheduler checks for changed projects once a minute and backs them
+// up when the configured interval has elapsed since the last run.
+func (a *App) runBackupScheduler() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	lastRun := time.Now()
+	for range ticker.C {
+		settings := a.getConfig().Backup
+		if !settings.Enabled || settings.IntervalMinutes <= 0 {
+			continue
+		}
+		if time.Since(lastRun) < time.Duration(settings.IntervalMinutes)*time.Minute {
+			continue
+		}
+		lastRun = time.Now()
+		a.backupChangedProjects()
+	}
+}
+
+// backupChangedProjects snapshots every project modified since its newest backup
+func (a *App) backupChangedProjects() {
+	if !a.getConfig().Backup.Enabled {
+		return
+	}
+	for _, p := range a.GetProjects() {
+		projectDir := filepath.Join(a.getAppDir(), p.ID)
+		changed := latestModTime(projectDir)
+
+		backups := a.GetBackups(p.ID)
+		if len(backups) > 0 {
+			if info, err := os.Stat(backups[0].Path); err == nil && !changed.After(info.ModTime()) {
+				continue
+			}
+		}
+		if _, err := a.createBackup(p.ID); err != nil {
+			fmt.Printf("Backup of project %s failed: %v\n", p.ID, err)
+		}
+	}
+}
+
+// projectGitDir is the project history repository, never backed up or restored
+const projectGitDir = ".git"
+
+// latestModTime returns the newest modification time of any file under dir
+func latestModTime(dir string) time.Time {
+	var latest time.Time
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && d.Name() == projectGitDir {
+			return fs.SkipDir // Commits aren't edits
+		}
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest
+}
+
+// BackupNow snapshots a single project immediately
+func (a *App) BackupNow(projectId string) string {
+	if _, err := a.createBackup(projectId); err != nil {
+		return tr("backup.error.create", err.Error())
+	}
+	return "Success"
+}
+
+func (a *App) createBackup(projectId string) (string, error) {
+	path, err := a.writeBackup(projectId)
+	if err == nil {
+		a.rotateBackups(projectId, a.getConfig().Backup.Keep)
+	}
+	return path, err
+}
+
+// writeBackup archives the project without rotating old backups
+func (a *App) writeBackup(projectId string) (string, error) {
+	if projectId == "" {
+		return "", fmt.Errorf("missing project id")
+	}
+	projectDir := filepath.Join(a.getAppDir(), projectId)
+	if _, err := os.Stat(filepath.Join(projectDir, "project.json")); err != nil {
+		return "", fmt.Errorf("project not found")
+	}
+
+	settings := a.getConfig().Backup
+	destDir := filepath.Join(a.getBackupDir(), projectId)
+	os.MkdirAll(destDir, 0755)
+
+	name := fmt.Sprintf("%s_%s.zip", projectId, time.Now().Format("20060102-150405"))
+	destPath := filepath.Join(destDir, name)
+	tmpPath := destPath + ".partial"
+
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return "", err
+	}
+	zw := zip.NewWriter(out)
+
+	err = filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && d.Name() == projectGitDir {
+			return fs.SkipDir // History outlives backups, see history.go
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if !settings.IncludeMedia && mediaExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		rel, _ := filepath.Rel(projectDir, path)
+		w, err := zw.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(w, f)
+		return err
+	})
+
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		return "", err
+	}
+	return destPath, nil
+}
+
+// rotateBackups deletes the oldest archives beyond the keep limit, never the
+// ones named in keepNames
+func (a *App) rotateBackups(projectId string, keep int, keepNames ...string) {
+	if keep <= 0 {
+		keep = 10
+	}
+	backups := a.GetBackups(projectId)
+	for i := keep; i < len(backups); i++ {
+		if !slices.Contains(keepNames, backups[i].Name) {
+			os.Remove(backups[i].Path)
+		}
+	}
+}
+
+// GetBackups lists a project's backups, newest first
+func (a *App) GetBackups(projectId string) []BackupInfo {
+	dir := filepath.Join(a.getBackupDir(), projectId)
+	entries, _ := os.ReadDir(dir)
+
+	backups := []BackupInfo{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".zip") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, BackupInfo{
+			Name:      e.Name(),
+			ProjectID: projectId,
+			Path:      filepath.Join(dir, e.Name()),
+			Size:      info.Size(),
+			CreatedAt: info.ModTime().Format("2006-01-02 15:04"),
+		})
+	}
+	// Names embed a sortable timestamp
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Name > backups[j].Name })
+	return backups
+}
+
+// RestoreBackup replaces the project folder with a backup. The archive is
+// extracted next to the project and swapped in, so files made after the
+// backup don't survive the restore. Media the archive doesn't hold (backups
+// without IncludeMedia) is kept. The current state is backed up first so a
+// restore can itself be undone.
+func (a *App) RestoreBackup(projectId string, backupName string) string {
+	if projectId == "" || backupName == "" || filepath.Base(backupName) != backupName {
+		return tr("backup.error.invalid")
+	}
+	src := filepath.Join(a.getBackupDir(), projectId, backupName)
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return tr("backup.error.open", err.Error())
+	}
+	defer zr.Close()
+
+	projectDir := filepath.Join(a.getAppDir(), projectId)
+	_, err = os.Stat(projectDir)
+	live := err == nil
+	if live {
+		if _, err := a.writeBackup(projectId); err != nil {
+			return tr("backup.error.safety", err.Error())
+		}
+	}
+
+	// Staged on the same disk (for the rename), outside the project list
+	restoreDir := filepath.Join(a.getAppDir(), ".restore")
+	os.MkdirAll(restoreDir, 0755)
+	staging, err := os.MkdirTemp(restoreDir, projectId+"-")
+	if err != nil {
+		return tr("backup.error.restore", err.Error())
+	}
+	defer os.RemoveAll(staging)
+	if err := extractBackup(zr, staging); err != nil {
+		return err.Error()
+	}
+	if live {
+		if err := keepMissingMedia(projectDir, staging); err != nil {
+			return tr("backup.error.restore", err.Error())
+		}
+	}
+
+	// The live history stays, so commits made after the backup aren't lost
+	liveGit := filepath.Join(projectDir, projectGitDir)
+	stagedGit := filepath.Join(staging, projectGitDir)
+	if _, err := os.Stat(liveGit); err == nil {
+		if err := os.Rename(liveGit, stagedGit); err != nil {
+			return tr("backup.error.restore", err.Error())
+		}
+	}
+
+	// Swap: the live folder is only moved aside once the restore is complete
+	old := staging + ".old"
+	if live {
+		if err := os.Rename(projectDir, old); err != nil {
+			os.Rename(stagedGit, liveGit)
+			return tr("backup.error.restore", err.Error())
+		}
+	}
+	if err := os.Rename(staging, projectDir); err != nil {
+		if live {
+			os.Rename(old, projectDir)
+		}
+		os.Rename(stagedGit, liveGit)
+		return tr("backup.error.restore", err.Error())
+	}
+	os.RemoveAll(old)
+
+	a.rotateBackups(projectId, a.getConfig().Backup.Keep, backupName)
+	return "Success"
+}
+
+// extractBackup writes the archive's files into dir
+func extractBackup(zr *zip.ReadCloser, dir string) error {
+	for _, f := range zr.File {
+		dest := filepath.Join(dir, filepath.FromSlash(f.Name))
+		// Guard against entries escaping the folder
+		if !strings.HasPrefix(dest, filepath.Clean(dir)+string(os.PathSeparator)) {
+			continue
+		}
+		// Older backups may hold a history; the live one is kept instead
+		if strings.HasPrefix(f.Name, projectGitDir+"/") {
+			continue
+		}
+		os.MkdirAll(filepath.Dir(dest), 0755)
+
+		rc, err := f.Open()
+		if err != nil {
+			return errors.New(tr("backup.error.read", err.Error()))
+		}
+		out, err := os.Create(dest)
+		if err != nil {
+			rc.Close()
+			return errors.New(tr("backup.error.restore", err.Error()))
+		}
+		_, err = io.Copy(out, rc)
+		out.Close()
+		rc.Close()
+		if err != nil {
+			return errors.New(tr("backup.error.restore", err.Error()))
+		}
+	}
+	return nil
+}
+
+// keepMissingMedia links the project's media files the restored tree lacks
+// into it (copying where links aren't supported)
+func keepMissingMedia(projectDir string, restored string) error {
+	return filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && d.Name() == projectGitDir {
+			return fs.SkipDir
+		}
+		if err != nil || d.IsDir() || !mediaExtensions[strings.ToLower(filepath.Ext(path))] {
+			return err
+		}
+		rel, _ := filepath.Rel(projectDir, path)
+		dest := filepath.Join(restored, rel)
+		if _, err := os.Stat(dest); err == nil {
+			return nil
+		}
+		os.MkdirAll(filepath.Dir(dest), 0755)
+		if os.Link(path, dest) == nil {
+			return nil
+		}
+		return copyFile(path, dest)
+	})
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- FOLDER ACCESS (macOS BOOKMARKS) ---
+
+// Security-scoped bookmarks keep picked files and folders readable across launches.
+
+type FolderAccess struct {
+	Path      string `json:"path"`
+	Bookmark  []byte `json:"bookmark"`
+	Available bool   `json:"available"` // Resolved this session
+}
+
+var (
+	bookmarksMu sync.Mutex
+	bookmarks   []FolderAccess
+)
+
+func (a *App) getBookmarksPath() string {
+	return filepath.Join(a.getAppDir(), "bookmarks.json")
+}
+
+// saveBookmarks writes the list; the caller must hold bookmarksMu
+func (a *App) saveBookmarks() {
+	data, _ := json.MarshalIndent(bookmarks, "", "  ")
+	os.WriteFile(a.getBookmarksPath(), data, 0644)
+}
+
+// restoreBookmarks resolves every saved bookmark and starts accessing it.
+// Stale bookmarks (folder moved or renamed) are recreated at the new location.
+func (a *App) restoreBookmarks() {
+	if !bookmarksSupported {
+		return
+	}
+	data, err := os.ReadFile(a.getBookmarksPath())
+	if err != nil {
+		return
+	}
+
+	bookmarksMu.Lock()
+	defer bookmarksMu.Unlock()
+
+	json.Unmarshal(data, &bookmarks)
+	changed := false
+	for i := range bookmarks {
+		path, stale, err := resolveBookmark(bookmarks[i].Bookmark)
+		if err != nil {
+			fmt.Println("Bookmark:", bookmarks[i].Path, err)
+			recordEngineError("bookmarks", fmt.Sprintf("%s: %v", bookmarks[i].Path, err))
+			bookmarks[i].Available = false
+			continue
+		}
+		bookmarks[i].Available = true
+		if stale || path != bookmarks[i].Path {
+			if fresh, err := createBookmark(path); err == nil {
+				bookmarks[i].Bookmark = fresh
+			}
+			bookmarks[i].Path = path
+			changed = true
+		}
+	}
+	if changed {
+		a.saveBookmarks()
+	}
+}
+
+// rememberAccess persists a bookmark for a user-chosen file or folder. Paths
+// inside an already bookmarked folder or the app directory are skipped.
+func (a *App) rememberAccess(path string) {
+	if !bookmarksSupported || path == "" || hasAccessUnder(a.getAppDir(), path) {
+		return
+	}
+
+	bookmarksMu.Lock()
+	defer bookmarksMu.Unlock()
+
+	for _, b := range bookmarks {
+		if hasAccessUnder(b.Path, path) {
+			return
+		}
+	}
+	data, err := createBookmark(path)
+	if err != nil {
+		fmt.Println("Bookmark:", err)
+		return
+	}
+	bookmarks = append(bookmarks, FolderAccess{Path: path, Bookmark: data, Available: true})
+	a.saveBookmarks()
+}
+
+// hasAccessUnder reports whether path is root or inside it
+func hasAccessUnder(root string, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// GetFolderAccess lists the folders and files the app keeps access to
+func (a *App) GetFolderAccess() []FolderAccess {
+	bookmarksMu.Lock()
+	defer bookmarksMu.Unlock()
+
+	list := []FolderAccess{}
+	for _, b := range bookmarks {
+		list = append(list, FolderAccess{Path: b.Path, Available: b.Available})
+	}
+	return list
+}
+
+// GrantFolderAccess lets the user pick a folder (asset library, external
+// drive...) that stays accessible across launches. Returns the folder path,
+// or "" if cancelled.
+func (a *App) GrantFolderAccess() string {
+	selection, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Grant Access to Media Folder",
+	})
+	if err != nil || selection == "" {
+		return ""
+	}
+	a.rememberAccess(selection)
+	return selection
+}
+
+// RevokeFolderAccess forgets a saved bookmark
+func (a *App) RevokeFolderAccess(path string) {
+	bookmarksMu.Lock()
+	defer bookmarksMu.Unlock()
+
+	kept := bookmarks[:0]
+	for _, b := range bookmarks {
+		if b.Path != path {
+			kept = append(kept, b)
+		}
+	}
+	bookmarks = kept
+	a.saveBookmarks()
+}
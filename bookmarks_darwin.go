@@ -0,0 +1,90 @@
+//go:build darwin && cgo
+
+package main
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Foundation
+#import <Foundation/Foundation.h>
+#include <stdlib.h>
+#include <string.h>
+
+// createBookmark returns malloc'd security-scoped bookmark bytes, or NULL
+static void* createBookmark(const char* path, int* outLen) {
+	@autoreleasepool {
+		NSURL* url = [NSURL fileURLWithPath:[NSString stringWithUTF8String:path]];
+		NSError* err = nil;
+		NSData* data = [url bookmarkDataWithOptions:NSURLBookmarkCreationWithSecurityScope
+			includingResourceValuesForKeys:nil
+			relativeToURL:nil
+			error:&err];
+		if (data == nil) {
+			return NULL;
+		}
+		void* buf = malloc(data.length);
+		memcpy(buf, data.bytes, data.length);
+		*outLen = (int)data.length;
+		return buf;
+	}
+}
+
+// resolveBookmark resolves a bookmark and starts accessing it for the rest of
+// the process lifetime. Returns a malloc'd path, or NULL.
+static char* resolveBookmark(const void* bytes, int length, int* stale) {
+	@autoreleasepool {
+		NSData* data = [NSData dataWithBytes:bytes length:length];
+		BOOL isStale = NO;
+		NSError* err = nil;
+		NSURL* url = [NSURL URLByResolvingBookmarkData:data
+			options:NSURLBookmarkResolutionWithSecurityScope
+			relativeToURL:nil
+			bookmarkDataIsStale:&isStale
+			error:&err];
+		if (url == nil) {
+			return NULL;
+		}
+		[url startAccessingSecurityScopedResource];
+		*stale = isStale ? 1 : 0;
+		return strdup(url.fileSystemRepresentation);
+	}
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+const bookmarksSupported = true
+
+func createBookmark(path string) ([]byte, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	var length C.int
+	buf := C.createBookmark(cPath, &length)
+	if buf == nil {
+		return nil, fmt.Errorf("could not create bookmark for %s", path)
+	}
+	defer C.free(buf)
+	return C.GoBytes(buf, length), nil
+}
+
+// resolveBookmark returns the bookmarked path and whether the bookmark is
+// stale (the folder moved) and should be recreated
+func resolveBookmark(data []byte) (string, bool, error) {
+	if len(data) == 0 {
+		return "", false, fmt.Errorf("empty bookmark")
+	}
+	cData := C.CBytes(data)
+	defer C.free(cData)
+
+	var stale C.int
+	cPath := C.resolveBookmark(cData, C.int(len(data)), &stale)
+	if cPath == nil {
+		return "", false, fmt.Errorf("bookmark could not be resolved")
+	}
+	defer C.free(unsafe.Pointer(cPath))
+	return C.GoString(cPath), stale != 0, nil
+}
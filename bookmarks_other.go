@@ -0,0 +1,18 @@
+//go:build !darwin || !cgo
+
+package main
+
+import "errors"
+
+// Security-scoped bookmarks only exist on macOS; elsewhere paths just work
+const bookmarksSupported = false
+
+var errBookmarksUnsupported = errors.New("security-scoped bookmarks are only available on macOS")
+
+func createBookmark(path string) ([]byte, error) {
+	return nil, errBookmarksUnsupported
+}
+
+func resolveBookmark(data []byte) (string, bool, error) {
+	return "", false, errBookmarksUnsupported
+}
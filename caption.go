@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// --- IMAGE CAPTIONING ---
+
+// Describes an image as a prompt through a caption workflow or a vision model.
+
+type CaptionSettings struct {
+	Workflow string `json:"workflow"` // ComfyUI caption workflow; "" = vision model
+	Model    string `json:"model"`    // Vision model; "" = the prompt assistant's model
+}
+
+const captionInstructions = `Describe this image as a prompt for an AI video generator: one paragraph covering the subject, setting, composition and framing, lighting, colors and style. Reply with only the prompt.`
+
+// captionMaxSize is the long edge images are scaled to for vision models
+const captionMaxSize = 1024
+
+// GetCaptionSettings returns how images are described
+func (a *App) GetCaptionSettings() CaptionSettings {
+	return a.getConfig().Caption
+}
+
+// SaveCaptionSettings sets how images are described
+func (a *App) SaveCaptionSettings(settings CaptionSettings) string {
+	settings.Workflow = strings.TrimSpace(settings.Workflow)
+	settings.Model = strings.TrimSpace(settings.Model)
+	if settings.Workflow != "" {
+		if _, err := a.loadWorkflow(settings.Workflow); err != nil {
+			return "Error: " + err.Error()
+		}
+	}
+	a.updateConfig(func(c *Config) { c.Caption = settings })
+	return "Success"
+}
+
+// DescribeImage generates a prompt from an image
+func (a *App) DescribeImage(path string) (string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("image not found")
+	}
+	settings := a.getConfig().Caption
+	var caption string
+	var err error
+	if settings.Workflow != "" {
+		caption, err = a.captionWithWorkflow(a.comfyURL, settings.Workflow, path)
+	} else {
+		caption, err = a.captionWithModel(settings.Model, path)
+	}
+	if err != nil {
+		return "", err
+	}
+	caption = strings.TrimSpace(strings.Trim(strings.TrimSpace(caption), "\""))
+	if caption == "" {
+		return "", fmt.Errorf("no description was generated")
+	}
+	return caption, nil
+}
+
+// captionWithWorkflow runs the caption workflow on path and returns its text
+func (a *App) captionWithWorkflow(server string, workflowName string, path string) (string, error) {
+	workflow, err := a.loadWorkflow(workflowName)
+	if err != nil {
+		return "", err
+	}
+	uploaded, err := a.uploadAsset(server, path)
+	if err != nil {
+		return "", fmt.Errorf("image upload failed: %v", err)
+	}
+	injected := false
+	for _, node := range workflow {
+		nodeMap, _ := node.(map[string]interface{})
+		classType, _ := nodeMap["class_type"].(string)
+		inputs, _ := nodeMap["inputs"].(map[string]interface{})
+		for input, role := range a.nodeRules(classType) {
+			value, exists := inputs[input]
+			if _, isLink := value.([]interface{}); exists && !isLink && role == "IMAGE" {
+				inputs[input] = uploaded
+				injected = true
+			}
+		}
+	}
+	if !injected {
+		return "", fmt.Errorf("workflow %q has no image loader (IMAGE input)", workflowName)
+	}
+
+	promptID, err := a.queuePrompt(server, workflow)
+	if err != nil {
+		return "", err
+	}
+	if err := a.waitForPrompt(server, "", promptID); err != nil {
+		return "", err
+	}
+	var history map[string]struct {
+		Outputs map[string]interface{} `json:"outputs"`
+	}
+	if err := a.comfyJSONAt(server, "GET", "/history/"+promptID, nil, &history); err != nil {
+		return "", err
+	}
+	texts := comfyTextOutputs(history[promptID].Outputs)
+	if len(texts) == 0 {
+		return "", fmt.Errorf("workflow %q shows no text (add a ShowText node)", workflowName)
+	}
+	return texts[0], nil
+}
+
+// comfyTextOutputs lists the text outputs of a history entry in node order
+func comfyTextOutputs(outputs map[string]interface{}) []string {
+	var texts []string
+	for _, id := range sortedNodeIDs(outputs) {
+		nodeMap, _ := outputs[id].(map[string]interface{})
+		for _, key := range []string{"text", "string", "caption", "tags"} {
+			items, _ := nodeMap[key].([]interface{})
+			for _, item := range items {
+				if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+					texts = append(texts, s)
+				}
+			}
+		}
+	}
+	return texts
+}
+
+// captionWithModel asks a vision model on the prompt assistant's endpoint
+func (a *App) captionWithModel(model string, path string) (string, error) {
+	settings := a.getConfig().LLM
+	if model != "" {
+		settings.Model = model
+	}
+	settings.Temperature = 0.2 // A description, not ideas
+	image, err := scaledJPEG(path, captionMaxSize)
+	if err != nil {
+		return "", err
+	}
+	return a.llmComplete(settings, []map[string]interface{}{
+		llmImageMessage(settings, captionInstructions, image, "image/jpeg"),
+	})
+}
+
+// scaledJPEG encodes an image as a JPEG no larger than size on either edge
+func scaledJPEG(path string, size int) ([]byte, error) {
+	var out, stderr bytes.Buffer
+	cmd := exec.Command("ffmpeg", "-v", "error", "-i", mediaPath(path),
+		"-vf", fmt.Sprintf("scale='min(%d,iw)':'min(%d,ih)':force_original_aspect_ratio=decrease", size, size),
+		"-frames:v", "1", "-pix_fmt", "yuvj420p", "-q:v", "3", "-f", "image2pipe", "-c:v", "mjpeg", "-")
+	cmd.Stdout, cmd.Stderr = &out, &stderr
+	if err := runTracked(cmd); err != nil {
+		return nil, fmt.Errorf("could not read the image: %v: %s", err, stderr.String())
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- SHOT CHAINS ---
+
+// Shots continued from the last frame of the previous one, rendered in order.
+
+// ExtendChain adds a shot right after shotId that starts on its last frame
+// and inherits its prompt and render settings
+func (a *App) ExtendChain(projectId string, sceneId string, shotId string) (Shot, error) {
+	// Extract outside the lock; ffmpeg takes a moment
+	frame := ""
+	for _, s := range a.GetShots(projectId, sceneId) {
+		if s.ID == shotId && s.OutputVideo != "" {
+			frame = a.ExtractLastFrame(s.OutputVideo) // "" if it fails; RenderChain retries
+		}
+	}
+
+	shotsMu.Lock()
+	defer shotsMu.Unlock()
+
+	shots := a.GetShots(projectId, sceneId)
+	index := -1
+	for i := range shots {
+		if shots[i].ID == shotId {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return Shot{}, fmt.Errorf("shot not found")
+	}
+	prev := shots[index]
+
+	next := a.CreateShot(sceneId)
+	next.Name = prev.Name + " (cont.)"
+	next.Prompt = prev.Prompt
+	next.MotionStrength = prev.MotionStrength
+	next.Seed = prev.Seed
+	next.SeedMode = prev.SeedMode
+	next.Duration = prev.Duration
+	next.Workflow = prev.Workflow
+	next.Params = prev.Params
+	next.Models = prev.Models
+	next.FPS = prev.FPS
+	next.Device = prev.Device
+	next.ChainFrom = prev.ID
+	next.SourceImage = frame
+
+	shots = append(shots[:index+1], append([]Shot{next}, shots[index+1:]...)...)
+	a.SaveShots(projectId, sceneId, shots)
+	return next, nil
+}
+
+// chainFrom lists shotId and every shot chained after it, predecessors first
+func chainFrom(shots []Shot, shotId string) []Shot {
+	var chain []Shot
+	seen := map[string]bool{}
+	var walk func(id string)
+	walk = func(id string) {
+		for _, s := range shots {
+			if s.ID == id && !seen[id] {
+				seen[id] = true
+				chain = append(chain, s)
+			}
+		}
+		for _, s := range shots {
+			if s.ChainFrom == id && !seen[s.ID] {
+				walk(s.ID)
+			}
+		}
+	}
+	walk(shotId)
+	return chain
+}
+
+// GetChain returns the whole chain a shot belongs to, from its first shot
+func (a *App) GetChain(projectId string, sceneId string, shotId string) []Shot {
+	shots := a.GetShots(projectId, sceneId)
+	byID := map[string]Shot{}
+	for _, s := range shots {
+		byID[s.ID] = s
+	}
+	root := shotId
+	for seen := map[string]bool{}; !seen[root]; {
+		seen[root] = true
+		if prev, ok := byID[byID[root].ChainFrom]; ok {
+			root = prev.ID
+		}
+	}
+	chain := chainFrom(shots, root)
+	if chain == nil {
+		return []Shot{}
+	}
+	return chain
+}
+
+// RenderChain renders shotId and the shots chained after it, one at a time,
+// and returns an ID for the "render:chain" events
+func (a *App) RenderChain(projectId string, sceneId string, shotId string, workflowName string) (string, error) {
+	chain := chainFrom(a.GetShots(projectId, sceneId), shotId)
+	if len(chain) == 0 {
+		return "", fmt.Errorf("shot not found")
+	}
+	checked := map[string]bool{}
+	for _, s := range chain {
+		if workflow := shotWorkflow(s, workflowName); !checked[workflow] {
+			checked[workflow] = true
+			if err := a.checkWorkflowBeforeQueue(workflow); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	chainId := uuid.New().String()
+	go a.runChain(chainId, projectId, sceneId, chain, workflowName)
+	return chainId, nil
+}
+
+func (a *App) runChain(chainId string, projectId string, sceneId string, chain []Shot, workflowName string) {
+	emit := func(index int, shotId string, status string, message string) {
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "render:chain", map[string]interface{}{
+				"chain": chainId, "shotId": shotId, "index": index, "total": len(chain),
+				"status": status, "error": message,
+			})
+		}
+	}
+
+	for i, step := range chain {
+		shot, err := a.refreshChainSource(projectId, sceneId, step.ID)
+		if err == nil {
+			err = a.checkSourceImage(shot, shotWorkflow(shot, workflowName))
+		}
+		if err != nil {
+			emit(i, step.ID, JobFailed, err.Error())
+			return
+		}
+
+		jobId := a.queueRender(projectId, sceneId, shot, workflowName, chainId, 0, QualityFinal)
+		emit(i, shot.ID, JobQueued, "")
+		if status, message := waitForJob(jobId); status != JobDone {
+			emit(i, shot.ID, status, message)
+			return
+		}
+		emit(i, shot.ID, JobDone, "")
+	}
+}
+
+// refreshChainSource points a chained shot at the last frame of its
+// predecessor's current output
+func (a *App) refreshChainSource(projectId string, sceneId string, shotId string) (Shot, error) {
+	var shot, prev *Shot
+	shots := a.GetShots(projectId, sceneId)
+	for i := range shots {
+		if shots[i].ID == shotId {
+			shot = &shots[i]
+		}
+	}
+	if shot == nil {
+		return Shot{}, fmt.Errorf("shot not found")
+	}
+	for i := range shots {
+		if shot.ChainFrom != "" && shots[i].ID == shot.ChainFrom {
+			prev = &shots[i]
+		}
+	}
+	if prev == nil || prev.OutputVideo == "" {
+		return *shot, nil
+	}
+	frame := a.ExtractLastFrame(prev.OutputVideo)
+	if frame == "" {
+		return *shot, fmt.Errorf("could not extract the last frame of %s", prev.Name)
+	}
+	if frame != shot.SourceImage {
+		shot.SourceImage = frame
+		a.updateShot(projectId, sceneId, shotId, func(s *Shot) { s.SourceImage = frame })
+	}
+	return *shot, nil
+}
+
+// waitForJob blocks until a render job ends and returns its status and error
+func waitForJob(jobId string) (string, string) {
+	for {
+		renderQueueMu.Lock()
+		status, message := "", "render job disappeared from the queue"
+		for _, job := range renderJobs {
+			if job.ID == jobId {
+				status, message = job.Status, job.Error
+			}
+		}
+		renderQueueMu.Unlock()
+		if status != JobQueued && status != JobRunning {
+			if status == "" {
+				status = JobFailed
+			}
+			return status, message
+		}
+		time.Sleep(time.Second)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- CLICK TRACK ---
+
+// Metronome WAVs for timing shots to a tempo.
+
+const (
+	clickSampleRate = 48000
+	maxClickSeconds = 30 * 60
+)
+
+type ClickTrack struct {
+	Path      string    `json:"path"`
+	Duration  float64   `json:"duration"`
+	BPM       float64   `json:"bpm"`
+	Beats     []float64 `json:"beats"`     // Every click, in seconds
+	Downbeats []float64 `json:"downbeats"` // First beat of every bar
+}
+
+// parseTimeSignature reads "4/4", "3/4", "6/8"... (beats per bar, beat unit)
+func parseTimeSignature(sig string) (int, int, error) {
+	num, den, ok := strings.Cut(strings.TrimSpace(sig), "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid time signature %q", sig)
+	}
+	beats, err1 := strconv.Atoi(strings.TrimSpace(num))
+	unit, err2 := strconv.Atoi(strings.TrimSpace(den))
+	if err1 != nil || err2 != nil || beats < 1 || beats > 32 || (unit != 2 && unit != 4 && unit != 8 && unit != 16) {
+		return 0, 0, fmt.Errorf("invalid time signature %q", sig)
+	}
+	return beats, unit, nil
+}
+
+// writeClick mixes a short decaying sine burst into samples at t seconds
+func writeClick(samples []float64, t float64, freq float64, gain float64) {
+	const length = 0.03
+	start := int(t * clickSampleRate)
+	for i := 0; i < int(length*clickSampleRate) && start+i < len(samples); i++ {
+		x := float64(i) / clickSampleRate
+		samples[start+i] += gain * math.Sin(2*math.Pi*freq*x) * math.Exp(-x/0.006)
+	}
+}
+
+// encodeWAV encodes mono float samples (-1..1) as 16-bit PCM
+func encodeWAV(samples []float64, rate int) []byte {
+	var buf bytes.Buffer
+	dataSize := uint32(len(samples) * 2)
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, []uint32{16})
+	binary.Write(&buf, binary.LittleEndian, []uint16{1, 1})
+	binary.Write(&buf, binary.LittleEndian, []uint32{uint32(rate), uint32(rate * 2)})
+	binary.Write(&buf, binary.LittleEndian, []uint16{2, 16})
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, dataSize)
+	data := make([]byte, dataSize)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(math.Max(-1, math.Min(1, s))*32767)))
+	}
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+// GenerateClickTrack renders bars of clicks at bpm (beats of the signature's
+// unit per minute) with an accented downbeat, e.g. (projectId, 120, 8, "4/4")
+func (a *App) GenerateClickTrack(projectId string, bpm float64, bars int, timeSignature string) (ClickTrack, error) {
+	track := ClickTrack{BPM: bpm, Beats: []float64{}, Downbeats: []float64{}}
+	if bpm < 20 || bpm > 400 {
+		return track, fmt.Errorf("tempo must be between 20 and 400 BPM")
+	}
+	if bars < 1 || bars > 1000 {
+		return track, fmt.Errorf("bars must be between 1 and 1000")
+	}
+	beatsPerBar, _, err := parseTimeSignature(timeSignature)
+	if err != nil {
+		return track, err
+	}
+
+	interval := 60 / bpm
+	track.Duration = interval * float64(beatsPerBar*bars)
+	if track.Duration > maxClickSeconds {
+		return track, fmt.Errorf("click track would be longer than %d minutes", maxClickSeconds/60)
+	}
+	samples := make([]float64, int(math.Ceil(track.Duration*clickSampleRate)))
+	for beat := 0; beat < beatsPerBar*bars; beat++ {
+		t := float64(beat) * interval
+		track.Beats = append(track.Beats, t)
+		if beat%beatsPerBar == 0 {
+			track.Downbeats = append(track.Downbeats, t)
+			writeClick(samples, t, 1600, 0.9)
+		} else {
+			writeClick(samples, t, 1000, 0.6)
+		}
+	}
+
+	assetsDir := filepath.Join(a.getAppDir(), projectId, "assets")
+	os.MkdirAll(assetsDir, 0755)
+	name := fmt.Sprintf("click_%gbpm_%s_%d.wav", bpm, strings.ReplaceAll(timeSignature, "/", "-"), time.Now().UnixNano())
+	track.Path = filepath.Join(assetsDir, name)
+	if err := os.WriteFile(track.Path, encodeWAV(samples, clickSampleRate), 0644); err != nil {
+		return track, err
+	}
+	return track, nil
+}
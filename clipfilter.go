@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// --- CUSTOM CLIP FILTERS ---
+
+// Raw ffmpeg filter chains on timeline clips ("videoFilter", "audioFilter").
+
+// ValidateClipFilter dry-runs a filter chain against a generated test source.
+// kind is "video" or "audio". Returns "" when the filter is usable, otherwise
+// ffmpeg's error message.
+func (a *App) ValidateClipFilter(filter string, kind string) string {
+	if err := validateClipFilter(filter, kind); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func validateClipFilter(filter string, kind string) error {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return nil
+	}
+	if strings.ContainsAny(filter, ";[]") {
+		return fmt.Errorf("only simple filter chains are allowed (no ';' or [labels])")
+	}
+
+	var args []string
+	if kind == "audio" {
+		args = []string{"-v", "error", "-f", "lavfi", "-i", "sine=frequency=440:duration=0.2", "-af", filter, "-f", "null", "-"}
+	} else {
+		args = []string{"-v", "error", "-f", "lavfi", "-i", "testsrc2=size=320x180:rate=25:duration=0.2", "-vf", filter, "-f", "null", "-"}
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("ffmpeg", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("invalid %s filter: %s", kind, msg)
+	}
+	return nil
+}
+
+// renderFilteredSegment runs a segment through the clip's filter chain into a
+// near-lossless intermediate for the concat pass. Results live in the segment
+// cache, so the background renderer has usually done this before export.
+func (a *App) renderFilteredSegment(run *exportRun, seg RenderSegment) (string, error) {
+	return a.renderCachedSegment(run, seg, "", "export")
+}
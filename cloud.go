@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// --- CLOUD BACKENDS (REPLICATE / FAL.AI) ---
+
+// Renders on hosted video models through Replicate or fal.ai.
+
+const (
+	BackendReplicate = "replicate"
+	BackendFal       = "fal"
+
+	cloudPollEvery = 3 * time.Second
+	cloudTimeout   = 60 * time.Minute
+)
+
+// cloudDefaultInputs are the input names most models of a provider use
+var cloudDefaultInputs = map[string]map[string]string{
+	BackendReplicate: {"PROMPT": "prompt", "IMAGE": "image", "SEED": "seed"},
+	BackendFal:       {"PROMPT": "prompt", "IMAGE": "image_url", "SEED": "seed"},
+}
+
+type cloudBackend struct {
+	a      *App
+	kind   string
+	server ComfyServer
+}
+
+// cloudJob is a submitted prediction and where to follow it
+type cloudJob struct {
+	Status   string // Provider's status, upper-cased
+	Poll     string
+	Result   string // fal: where the finished result is read
+	Cancel   string
+	Output   interface{}
+	Error    string
+	Position int // fal: place in the queue
+}
+
+func (b cloudBackend) key(server string) string {
+	return b.a.comfyCredential(CredCloudKey, server)
+}
+
+func (b cloudBackend) ping(server string) error {
+	if b.key(server) == "" {
+		return fmt.Errorf("no API key is stored for %s", b.kind)
+	}
+	if b.kind == BackendReplicate {
+		_, err := b.call("GET", server, nil)
+		return err
+	}
+	return nil // fal has no endpoint to ask; the key is checked on render
+}
+
+func (b cloudBackend) freeMemory(server string) error {
+	return nil
+}
+
+func (b cloudBackend) generate(server string, shot Shot, workflowName string, outPath string) error {
+	if b.key(server) == "" {
+		return fmt.Errorf("no API key is stored for %s", b.kind)
+	}
+	inputs, err := b.inputs(shot)
+	if err != nil {
+		return err
+	}
+
+	job, err := b.submit(server, inputs)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(cloudTimeout)
+	for !cloudFinished(job.Status) {
+		if shotRenderCanceled(shot.ID) {
+			if job.Cancel != "" {
+				method := "POST"
+				if b.kind == BackendFal {
+					method = "PUT"
+				}
+				b.call(method, job.Cancel, nil)
+			}
+			return fmt.Errorf("render canceled")
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout: %s took longer than %v", b.kind, cloudTimeout)
+		}
+		time.Sleep(cloudPollEvery)
+		if job, err = b.status(job); err != nil {
+			return err
+		}
+		b.a.emitCloudStatus(shot.ID, job)
+	}
+	if job.Status != "SUCCEEDED" && job.Status != "COMPLETED" {
+		if job.Error != "" {
+			return fmt.Errorf("%s render failed: %s", b.kind, job.Error)
+		}
+		return fmt.Errorf("%s render %s", b.kind, strings.ToLower(job.Status))
+	}
+
+	output := job.Output
+	if b.kind == BackendFal {
+		data, err := b.call("GET", job.Result, nil)
+		if err != nil {
+			return err
+		}
+		json.Unmarshal(data, &output)
+	}
+	url := findVideoURL(output)
+	if url == "" {
+		return fmt.Errorf("%s returned no video", b.kind)
+	}
+	return downloadURL(url, outPath)
+}
+
+// inputs maps the shot onto the model's input names
+func (b cloudBackend) inputs(shot Shot) (map[string]interface{}, error) {
+	names := map[string]string{}
+	for role, name := range cloudDefaultInputs[b.kind] {
+		names[role] = name
+	}
+	for role, name := range b.server.Inputs {
+		names[strings.ToUpper(role)] = name // "" drops a default
+	}
+
+	inputs := map[string]interface{}{}
+	set := func(role string, value interface{}) {
+		if name := names[role]; name != "" {
+			inputs[name] = value
+		}
+	}
+	set("PROMPT", shot.Prompt)
+	set("SEED", shot.Seed)
+	duration := shot.Duration
+	if shot.AudioPath != "" && shot.AudioDuration > 0 {
+		duration = shot.AudioDuration
+	}
+	if duration > 0 {
+		set("DURATION", duration)
+	}
+	if shot.SourceImage != "" && names["IMAGE"] != "" {
+		uri, err := dataURI(shot.SourceImage)
+		if err != nil {
+			return nil, fmt.Errorf("source image is missing")
+		}
+		set("IMAGE", uri)
+	}
+	if shot.AudioPath != "" && names["AUDIO"] != "" {
+		audio, cleanup, err := trimmedShotAudio(shot)
+		if err != nil {
+			return nil, err
+		}
+		uri, err := dataURI(audio)
+		cleanup()
+		if err != nil {
+			return nil, fmt.Errorf("audio file is missing")
+		}
+		set("AUDIO", uri)
+	}
+	for k, v := range shot.Params {
+		inputs[k] = v
+	}
+	return inputs, nil
+}
+
+// submit starts a job with the given inputs
+func (b cloudBackend) submit(server string, inputs map[string]interface{}) (cloudJob, error) {
+	if b.kind == BackendReplicate {
+		data, err := b.call("POST", server+"/predictions", map[string]interface{}{"input": inputs})
+		if err != nil {
+			return cloudJob{}, err
+		}
+		return parseReplicateJob(data)
+	}
+	data, err := b.call("POST", server, inputs)
+	if err != nil {
+		return cloudJob{}, err
+	}
+	var queued struct {
+		StatusURL   string `json:"status_url"`
+		ResponseURL string `json:"response_url"`
+		CancelURL   string `json:"cancel_url"`
+	}
+	if err := json.Unmarshal(data, &queued); err != nil || queued.StatusURL == "" {
+		return cloudJob{}, fmt.Errorf("unexpected reply from fal.ai: %s", strings.TrimSpace(string(data)))
+	}
+	return cloudJob{Status: "IN_QUEUE", Poll: queued.StatusURL, Result: queued.ResponseURL, Cancel: queued.CancelURL}, nil
+}
+
+// status reads a job's current state
+func (b cloudBackend) status(job cloudJob) (cloudJob, error) {
+	data, err := b.call("GET", job.Poll, nil)
+	if err != nil {
+		return job, err
+	}
+	if b.kind == BackendReplicate {
+		return parseReplicateJob(data)
+	}
+	var status struct {
+		Status        string `json:"status"`
+		QueuePosition int    `json:"queue_position"`
+		Error         string `json:"error"`
+	}
+	json.Unmarshal(data, &status)
+	job.Status = strings.ToUpper(status.Status)
+	job.Position = status.QueuePosition
+	job.Error = status.Error
+	if job.Status == "COMPLETED" && status.Error != "" {
+		job.Status = "FAILED"
+	}
+	return job, nil
+}
+
+func parseReplicateJob(data []byte) (cloudJob, error) {
+	var prediction struct {
+		Status string      `json:"status"`
+		Output interface{} `json:"output"`
+		Error  interface{} `json:"error"`
+		URLs   struct {
+			Get    string `json:"get"`
+			Cancel string `json:"cancel"`
+		} `json:"urls"`
+	}
+	if err := json.Unmarshal(data, &prediction); err != nil || prediction.URLs.Get == "" {
+		return cloudJob{}, fmt.Errorf("unexpected reply from Replicate: %s", strings.TrimSpace(string(data)))
+	}
+	job := cloudJob{
+		Status: strings.ToUpper(prediction.Status),
+		Poll:   prediction.URLs.Get,
+		Cancel: prediction.URLs.Cancel,
+		Output: prediction.Output,
+	}
+	if prediction.Error != nil {
+		job.Error = fmt.Sprint(prediction.Error)
+	}
+	return job, nil
+}
+
+func cloudFinished(status string) bool {
+	switch status {
+	case "SUCCEEDED", "COMPLETED", "FAILED", "CANCELED", "CANCELLED", "ERROR":
+		return true
+	}
+	return false
+}
+
+// call sends a request with the provider's auth and returns the body
+func (b cloudBackend) call(method string, url string, body interface{}) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	key := b.key(b.server.URL)
+	if b.kind == BackendFal {
+		req.Header.Set("Authorization", "Key "+key)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := (&http.Client{Timeout: 2 * time.Minute}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s unreachable: %v", b.kind, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s API Error (%d): %s", b.kind, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// emitCloudStatus moves the job's progress once the provider runs it
+func (a *App) emitCloudStatus(shotId string, job cloudJob) {
+	switch job.Status {
+	case "IN_PROGRESS", "PROCESSING":
+		a.reportRenderProgress(shotId, 50) // Providers don't report steps
+	}
+}
+
+// findVideoURL looks for a video URL in a model's output: a URL, a list of
+// them, or an object with one (video.url and the like)
+func findVideoURL(output interface{}) string {
+	switch v := output.(type) {
+	case string:
+		if strings.HasPrefix(v, "https://") || strings.HasPrefix(v, "http://") {
+			return v
+		}
+	case []interface{}:
+		for _, item := range v {
+			if url := findVideoURL(item); url != "" {
+				return url
+			}
+		}
+	case map[string]interface{}:
+		for _, field := range []string{"video", "videos", "url", "output"} {
+			if url := findVideoURL(v[field]); url != "" {
+				return url
+			}
+		}
+		for _, item := range v {
+			if url := findVideoURL(item); url != "" {
+				return url
+			}
+		}
+	}
+	return ""
+}
+
+// dataURI inlines a file for a model input
+func dataURI(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return "data:" + mediaContentType(path) + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// trimmedShotAudio cuts the shot's audio to its trim; cleanup removes the copy
+func trimmedShotAudio(shot Shot) (string, func(), error) {
+	if shot.AudioDuration <= 0 {
+		return shot.AudioPath, func() {}, nil
+	}
+	path := trackTemp(filepath.Join(os.TempDir(), fmt.Sprintf("trim_%s_%d.wav", shot.ID, time.Now().UnixNano())))
+	cmd := exec.Command("ffmpeg", "-y",
+		"-i", mediaPath(shot.AudioPath),
+		"-ss", fmt.Sprintf("%f", shot.AudioStart),
+		"-t", fmt.Sprintf("%f", shot.AudioDuration),
+		"-vn", path)
+	if out, err := combinedOutputTracked(cmd); err != nil {
+		releaseTemp(path)
+		return "", nil, fmt.Errorf("audio trim failed: %v: %s", err, string(out))
+	}
+	return path, func() { releaseTemp(path) }, nil
+}
+
+// downloadURL saves a finished cloud render to dest
+func downloadURL(url string, dest string) error {
+	resp, err := (&http.Client{Timeout: 30 * time.Minute}).Get(url)
+	if err != nil {
+		return fmt.Errorf("download failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return downloadStatusError{resp.StatusCode}
+	}
+	partial := dest + ".partial"
+	defer os.Remove(partial)
+	out, err := os.Create(partial)
+	if err != nil {
+		return fmt.Errorf("failed to save result: %v", err)
+	}
+	written, err := io.Copy(out, resp.Body)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("download failed: %v", err)
+	}
+	if resp.ContentLength >= 0 && written != resp.ContentLength {
+		return fmt.Errorf("download incomplete: got %d of %d bytes", written, resp.ContentLength)
+	}
+	return os.Rename(partial, dest)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// --- COLOR MATCHING ---
+
+// Grades a shot towards a reference shot's per-channel mean and spread.
+
+type ChannelStats struct {
+	Mean float64 `json:"mean"`
+	Std  float64 `json:"std"`
+}
+
+type ColorMatch struct {
+	Reference [3]ChannelStats `json:"reference"` // R, G, B
+	Target    [3]ChannelStats `json:"target"`
+	Filter    string          `json:"filter"`
+	Output    string          `json:"output"` // Graded file when applied
+	Applied   bool            `json:"applied"`
+}
+
+const (
+	colorSampleW = 64
+	colorSampleH = 36
+)
+
+// sampleColorStats reads a few downscaled frames and returns RGB mean/std (0-255)
+func sampleColorStats(path string) ([3]ChannelStats, error) {
+	var stats [3]ChannelStats
+	cmd := exec.Command("ffmpeg", "-v", "error", "-i", mediaPath(path),
+		"-vf", fmt.Sprintf("fps=2,scale=%d:%d", colorSampleW, colorSampleH),
+		"-frames:v", "12", "-f", "rawvideo", "-pix_fmt", "rgb24", "-")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return stats, err
+	}
+	if err := cmd.Start(); err != nil {
+		return stats, err
+	}
+	data, _ := io.ReadAll(stdout)
+	if err := cmd.Wait(); err != nil {
+		return stats, fmt.Errorf("could not sample %s: %v", path, err)
+	}
+	pixels := len(data) / 3
+	if pixels == 0 {
+		return stats, fmt.Errorf("no frames in %s", path)
+	}
+
+	var sum, sumSq [3]float64
+	for i := 0; i+2 < len(data); i += 3 {
+		for c := 0; c < 3; c++ {
+			v := float64(data[i+c])
+			sum[c] += v
+			sumSq[c] += v * v
+		}
+	}
+	for c := 0; c < 3; c++ {
+		mean := sum[c] / float64(pixels)
+		stats[c] = ChannelStats{Mean: mean, Std: math.Sqrt(math.Max(sumSq[c]/float64(pixels)-mean*mean, 0))}
+	}
+	return stats, nil
+}
+
+// colorTransferFilter builds the lutrgb grade that moves target towards reference
+func colorTransferFilter(reference [3]ChannelStats, target [3]ChannelStats) string {
+	names := [3]string{"r", "g", "b"}
+	filter := "lutrgb="
+	for c := 0; c < 3; c++ {
+		gain := 1.0
+		if target[c].Std > 1 {
+			// Clamped so a near-flat frame can't blow the contrast up
+			gain = math.Min(math.Max(reference[c].Std/target[c].Std, 0.5), 2)
+		}
+		if c > 0 {
+			filter += ":"
+		}
+		filter += fmt.Sprintf("%s='clip((val-%.2f)*%.4f+%.2f,0,255)'", names[c], target[c].Mean, gain, reference[c].Mean)
+	}
+	return filter
+}
+
+// MatchColor analyzes both shots and, with apply, grades the target shot's
+// output to match the reference. Timeline clips using the old output are
+// pointed at the graded file.
+func (a *App) MatchColor(projectId string, sceneId string, referenceShotId string, targetShotId string, apply bool) (ColorMatch, error) {
+	var match ColorMatch
+	shots := a.GetShots(projectId, sceneId)
+	var reference, target *Shot
+	for i := range shots {
+		switch shots[i].ID {
+		case referenceShotId:
+			reference = &shots[i]
+		case targetShotId:
+			target = &shots[i]
+		}
+	}
+	if reference == nil || target == nil {
+		return match, fmt.Errorf("shot not found")
+	}
+	if reference.OutputVideo == "" || target.OutputVideo == "" {
+		return match, fmt.Errorf("both shots need to be rendered")
+	}
+
+	var err error
+	if match.Reference, err = sampleColorStats(reference.OutputVideo); err != nil {
+		return match, err
+	}
+	if match.Target, err = sampleColorStats(target.OutputVideo); err != nil {
+		return match, err
+	}
+	match.Filter = colorTransferFilter(match.Reference, match.Target)
+	if !apply {
+		return match, nil
+	}
+
+	a.beginForeground()
+	defer a.endForeground()
+	oldOutput := target.OutputVideo
+	match.Output = filepath.Join(a.getAppDir(), projectId, "scenes", sceneId, fmt.Sprintf("%s_matched_%d.mp4", target.ID, time.Now().Unix()))
+	cmd := exec.Command("ffmpeg", "-y", "-i", mediaPath(oldOutput),
+		"-vf", match.Filter+",format=yuv420p",
+		"-c:v", "libx264", "-preset", "fast", "-crf", "16",
+		"-c:a", "copy", "-movflags", "+faststart",
+		match.Output)
+	if out, err := combinedOutputTracked(cmd); err != nil {
+		os.Remove(match.Output)
+		return match, fmt.Errorf("%v: %s", err, string(out))
+	}
+
+	target.OutputVideo = match.Output
+	if err := a.generateShotThumbnail(projectId, sceneId, target); err != nil {
+		fmt.Println("Thumbnail:", err)
+	}
+	a.SaveShots(projectId, sceneId, shots)
+	a.repointTimelineMedia(projectId, sceneId, oldOutput, match.Output)
+	match.Applied = true
+	return match, nil
+}
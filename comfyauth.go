@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// --- COMFYUI AUTHENTICATION ---
+
+// Basic auth and API key headers for every request to a ComfyUI server.
+
+type ComfyAuthSettings struct {
+	TokenHeader string            `json:"tokenHeader"` // "" or "Authorization" = "Bearer <token>", else the raw token (e.g. X-API-Key)
+	Headers     map[string]string `json:"headers"`     // Extra non-secret headers sent as-is
+}
+
+// GetComfyAuthSettings returns how credentials are sent to ComfyUI
+func (a *App) GetComfyAuthSettings() ComfyAuthSettings {
+	return a.getConfig().ComfyAuth
+}
+
+// SaveComfyAuthSettings persists the header settings; secrets go through SetCredential
+func (a *App) SaveComfyAuthSettings(settings ComfyAuthSettings) string {
+	settings.TokenHeader = strings.TrimSpace(settings.TokenHeader)
+	for name := range settings.Headers {
+		if strings.EqualFold(name, "Authorization") {
+			return "Error: store Authorization values as a credential, not a plain header"
+		}
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			return fmt.Sprintf("Error: invalid header name %q", name)
+		}
+	}
+	a.updateConfig(func(c *Config) { c.ComfyAuth = settings })
+	return "Success"
+}
+
+// comfyCredential returns the server's own secret for key, or the shared one
+func (a *App) comfyCredential(key string, server string) string {
+	if v := a.getCredential(key + "@" + server); v != "" {
+		return v
+	}
+	return a.getCredential(key)
+}
+
+// comfyHeaders builds the auth headers for requests to server
+func (a *App) comfyHeaders(server string) http.Header {
+	settings := a.getConfig().ComfyAuth
+	header := http.Header{}
+	for k, v := range settings.Headers {
+		header.Set(k, v)
+	}
+
+	if basic := a.comfyCredential(CredComfyBasic, server); basic != "" {
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(basic)))
+	}
+	if token := a.comfyCredential(CredComfyToken, server); token != "" {
+		name := strings.TrimSpace(settings.TokenHeader)
+		if name == "" || strings.EqualFold(name, "Authorization") {
+			// Basic auth already owns Authorization; the proxy gets that one
+			if header.Get("Authorization") == "" {
+				header.Set("Authorization", "Bearer "+token)
+			}
+		} else {
+			header.Set(name, token)
+		}
+	}
+	return header
+}
+
+// comfyRequest creates a request to server with its auth headers attached
+func (a *App) comfyRequest(method string, server string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, server+path, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range a.comfyHeaders(server) {
+		req.Header[k] = v
+	}
+	return req, nil
+}
+
+// comfyGet fetches path from server with the default client (no timeout, for
+// downloads and history polling)
+func (a *App) comfyGet(server string, path string) (*http.Response, error) {
+	req, err := a.comfyRequest("GET", server, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return http.DefaultClient.Do(req)
+}
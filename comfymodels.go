@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// --- MODEL BROWSER ---
+
+// Lists the server's models and writes a shot's picks into its loader nodes.
+
+type ComfyModels struct {
+	Checkpoints []string `json:"checkpoints"`
+	Loras       []string `json:"loras"`
+	Vaes        []string `json:"vaes"`
+	Unets       []string `json:"unets"` // Diffusion models loaded on their own (Wan, Flux)
+}
+
+// modelInputs maps loader input names to the model kind they select
+var modelInputs = map[string]string{
+	"ckpt_name": "checkpoint",
+	"lora_name": "lora",
+	"vae_name":  "vae",
+	"unet_name": "unet",
+}
+
+// serverModels collects the options of every loader input by model kind
+func (a *App) serverModels(server string) (map[string][]string, error) {
+	info, err := a.fetchObjectInfo(server)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]map[string]bool{}
+	models := map[string][]string{}
+	for _, def := range info {
+		_, required := orderedInputs(def.Input.Required)
+		_, optional := orderedInputs(def.Input.Optional)
+		for _, section := range []map[string]json.RawMessage{required, optional} {
+			for input, spec := range section {
+				kind, ok := modelInputs[input]
+				if !ok {
+					continue
+				}
+				if seen[kind] == nil {
+					seen[kind] = map[string]bool{}
+				}
+				for _, o := range comboOptions(spec) {
+					name, _ := o.(string)
+					if name != "" && name != "None" && !seen[kind][name] {
+						seen[kind][name] = true
+						models[kind] = append(models[kind], name)
+					}
+				}
+			}
+		}
+	}
+	for _, list := range models {
+		sort.Slice(list, func(i, j int) bool { return strings.ToLower(list[i]) < strings.ToLower(list[j]) })
+	}
+	return models, nil
+}
+
+// GetComfyModels lists the checkpoints, LoRAs, VAEs and UNets on the primary server
+func (a *App) GetComfyModels() (ComfyModels, error) {
+	models, err := a.serverModels(a.comfyURL)
+	if err != nil {
+		return ComfyModels{Checkpoints: []string{}, Loras: []string{}, Vaes: []string{}, Unets: []string{}},
+			fmt.Errorf("could not read models from ComfyUI: %v", err)
+	}
+	list := func(kind string) []string {
+		if models[kind] == nil {
+			return []string{}
+		}
+		return models[kind]
+	}
+	return ComfyModels{Checkpoints: list("checkpoint"), Loras: list("lora"), Vaes: list("vae"), Unets: list("unet")}, nil
+}
+
+// SetShotModels stores the models a shot renders with; a kind left out (or
+// set to "") keeps whatever the workflow loads. Names are checked against the
+// server when it can be reached.
+func (a *App) SetShotModels(projectId string, sceneId string, shotId string, models map[string]string) error {
+	clean := map[string]string{}
+	for kind, name := range models {
+		if !isModelKind(kind) {
+			return fmt.Errorf("unknown model kind %q", kind)
+		}
+		if name = strings.TrimSpace(name); name != "" {
+			clean[kind] = name
+		}
+	}
+	if available, err := a.serverModels(a.comfyURL); err == nil {
+		for kind, name := range clean {
+			match := ""
+			for _, m := range available[kind] {
+				if strings.EqualFold(m, name) {
+					match = m // ComfyUI wants the exact file name
+					break
+				}
+			}
+			if match == "" {
+				return fmt.Errorf("%s %q is not available on the server", kind, name)
+			}
+			clean[kind] = match
+		}
+	}
+
+	found := false
+	for _, s := range a.GetShots(projectId, sceneId) {
+		if s.ID == shotId {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("shot not found")
+	}
+	if len(clean) == 0 {
+		clean = nil
+	}
+	a.updateShot(projectId, sceneId, shotId, func(s *Shot) { s.Models = clean })
+	return nil
+}
+
+func isModelKind(kind string) bool {
+	for _, k := range modelInputs {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// applyShotModels writes a shot's models into the loader nodes of a workflow
+func applyShotModels(workflow map[string]interface{}, models map[string]string, result *WorkflowPreview) {
+	used := map[string]bool{}
+	for nodeId, node := range workflow {
+		nodeMap, _ := node.(map[string]interface{})
+		classType, _ := nodeMap["class_type"].(string)
+		inputs, _ := nodeMap["inputs"].(map[string]interface{})
+		for input, value := range inputs {
+			name := models[modelInputs[input]]
+			if _, isLink := value.([]interface{}); isLink || name == "" {
+				continue
+			}
+			inputs[input] = name
+			used[modelInputs[input]] = true
+			result.inject(nodeId, classType, input, "MODEL", name)
+		}
+	}
+	for kind, name := range models {
+		if !used[kind] {
+			result.warn(fmt.Sprintf("no %s loader in the workflow; %s isn't used", kind, name))
+		}
+	}
+}
+
+// modelsHash is a stable digest of a shot's models for the fingerprint
+func modelsHash(models map[string]string) string {
+	if len(models) == 0 {
+		return ""
+	}
+	kinds := make([]string, 0, len(models))
+	for kind := range models {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		parts = append(parts, kind+"="+models[kind])
+	}
+	return hashOf("%s", strings.Join(parts, "|"))
+}
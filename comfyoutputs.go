@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// --- COMFYUI OUTPUTS ---
+
+// Builds a shot's output from every file a prompt produced.
+
+// comfyOutput is one file in a prompt's history outputs
+type comfyOutput struct {
+	Node      string
+	Category  string // images, gifs, videos, audio, ...
+	Animated  bool   // Set by animated WEBP/PNG savers
+	Filename  string
+	Subfolder string
+	Type      string // output or temp
+}
+
+// kind tells videos, images and audio apart
+func (o comfyOutput) kind() string {
+	if o.Category == "gifs" || o.Category == "videos" || o.Category == "video" || o.Animated {
+		return "video"
+	}
+	t := mediaTypes[strings.ToLower(filepath.Ext(o.Filename))]
+	switch {
+	case strings.HasPrefix(t, "video/"):
+		return "video"
+	case strings.HasPrefix(t, "audio/"):
+		return "audio"
+	case strings.HasPrefix(t, "image/"):
+		return "image"
+	}
+	if o.Category == "audio" {
+		return "audio"
+	}
+	return ""
+}
+
+// comfyOutputs lists every file of a history entry's outputs, saved files
+// before previews and otherwise in node order
+func comfyOutputs(outputs map[string]interface{}) []comfyOutput {
+	var files []comfyOutput
+	for _, id := range sortedNodeIDs(outputs) {
+		nodeMap, _ := outputs[id].(map[string]interface{})
+		animated := false
+		if flags, ok := nodeMap["animated"].([]interface{}); ok && len(flags) > 0 {
+			animated, _ = flags[0].(bool)
+		}
+		categories := make([]string, 0, len(nodeMap))
+		for category := range nodeMap {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			items, _ := nodeMap[category].([]interface{})
+			for _, raw := range items {
+				item, _ := raw.(map[string]interface{})
+				name, _ := item["filename"].(string)
+				if name == "" {
+					continue
+				}
+				o := comfyOutput{Node: id, Category: category, Animated: animated, Filename: name}
+				o.Subfolder, _ = item["subfolder"].(string)
+				o.Type, _ = item["type"].(string)
+				files = append(files, o)
+			}
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Type == "output" && files[j].Type != "output" })
+	return files
+}
+
+// sortedNodeIDs orders the node IDs of a prompt or its outputs numerically
+func sortedNodeIDs(nodes map[string]interface{}) []string {
+	ids := make([]string, 0, len(nodes))
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, errA := strconv.Atoi(ids[i])
+		b, errB := strconv.Atoi(ids[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// shotOutputPlan is what gets downloaded to make a shot's output
+type shotOutputPlan struct {
+	Video  *comfyOutput
+	Frames []comfyOutput // Image sequence, when there is no video
+	Audio  *comfyOutput
+}
+
+func planShotOutput(files []comfyOutput) (shotOutputPlan, error) {
+	var plan shotOutputPlan
+	frames := map[string][]comfyOutput{}
+	var order []string
+	for i := range files {
+		switch files[i].kind() {
+		case "video":
+			if plan.Video == nil {
+				plan.Video = &files[i]
+			}
+		case "audio":
+			if plan.Audio == nil {
+				plan.Audio = &files[i]
+			}
+		case "image":
+			key := files[i].Node + "/" + files[i].Type
+			if _, seen := frames[key]; !seen {
+				order = append(order, key)
+			}
+			frames[key] = append(frames[key], files[i])
+		}
+	}
+	if plan.Video != nil {
+		return plan, nil
+	}
+	for _, key := range order {
+		if len(frames[key]) > len(plan.Frames) {
+			plan.Frames = frames[key]
+		}
+	}
+	switch {
+	case len(plan.Frames) > 1:
+		return plan, nil
+	case len(plan.Frames) == 1:
+		return plan, fmt.Errorf("the workflow produced a single image, not a video or frame sequence")
+	case plan.Audio != nil:
+		return plan, fmt.Errorf("the workflow produced only audio")
+	}
+	return plan, fmt.Errorf("job finished but no output file was found (check ComfyUI console)")
+}
+
+// downloadComfyFile saves one output file of server to dest (see download.go)
+func (a *App) downloadComfyFile(server string, o comfyOutput, dest string) error {
+	query := url.Values{"filename": {o.Filename}, "subfolder": {o.Subfolder}, "type": {o.Type}}
+	return a.downloadFromComfy(server, "/view?"+query.Encode(), dest)
+}
+
+// fetchShotOutput downloads a plan's files and builds the video at outPath;
+// fps times image sequences
+func (a *App) fetchShotOutput(server string, plan shotOutputPlan, outPath string, fps float64) error {
+	base := strings.TrimSuffix(outPath, filepath.Ext(outPath))
+	if plan.Video != nil {
+		if err := a.downloadComfyFile(server, *plan.Video, outPath); err != nil {
+			return err
+		}
+	} else {
+		if err := a.assembleFrames(server, plan.Frames, outPath, fps); err != nil {
+			return err
+		}
+	}
+	if plan.Audio == nil {
+		return nil
+	}
+	if _, hasAudio := probeStreamTypes(outPath); hasAudio {
+		return nil
+	}
+
+	audioPath := base + "_audio" + filepath.Ext(plan.Audio.Filename)
+	defer os.Remove(audioPath)
+	if err := a.downloadComfyFile(server, *plan.Audio, audioPath); err != nil {
+		return fmt.Errorf("audio output: %v", err)
+	}
+	muxed := base + "_mux.mp4"
+	cmd := exec.Command("ffmpeg", "-y", "-i", mediaPath(outPath), "-i", mediaPath(audioPath),
+		"-map", "0:v:0", "-map", "1:a:0",
+		"-c:v", "copy", "-c:a", "aac", "-b:a", "192k",
+		"-shortest", "-movflags", "+faststart",
+		muxed)
+	if out, err := combinedOutputTracked(cmd); err != nil {
+		os.Remove(muxed)
+		return fmt.Errorf("adding the audio output failed: %v: %s", err, string(out))
+	}
+	return os.Rename(muxed, outPath)
+}
+
+// assembleFrames downloads an image sequence and encodes it as outPath
+func (a *App) assembleFrames(server string, frames []comfyOutput, outPath string, fps float64) error {
+	if fps <= 0 {
+		fps = defaultRenderFPS
+	}
+	dir, err := os.MkdirTemp(filepath.Dir(outPath), "frames-")
+	if err != nil {
+		return fmt.Errorf("failed to save result: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ext := strings.ToLower(filepath.Ext(frames[0].Filename))
+	for i, frame := range frames {
+		if err := a.downloadComfyFile(server, frame, filepath.Join(dir, fmt.Sprintf("frame_%05d%s", i+1, ext))); err != nil {
+			return fmt.Errorf("frame %d: %v", i+1, err)
+		}
+	}
+	cmd := exec.Command("ffmpeg", "-y",
+		"-framerate", fmt.Sprintf("%g", fps),
+		"-i", mediaPath(filepath.Join(dir, "frame_%05d"+ext)),
+		"-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2,format=yuv420p",
+		"-c:v", "libx264", "-preset", "fast", "-crf", "16",
+		"-movflags", "+faststart",
+		outPath)
+	if out, err := combinedOutputTracked(cmd); err != nil {
+		return fmt.Errorf("assembling %d frames failed: %v: %s", len(frames), err, string(out))
+	}
+	return nil
+}
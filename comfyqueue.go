@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// --- SHARED SERVER QUEUE ---
+
+// Shows a ComfyUI server's whole queue and marks the prompts we submitted.
+
+type ServerJob struct {
+	PromptID  string `json:"promptId"`
+	Number    int    `json:"number"`   // Server queue number (lower runs first)
+	State     string `json:"state"`    // running, pending, success, error
+	Position  int    `json:"position"` // 1-based place among pending jobs, 0 otherwise
+	Mine      bool   `json:"mine"`
+	Label     string `json:"label"`
+	ProjectID string `json:"projectId,omitempty"`
+	SceneID   string `json:"sceneId,omitempty"`
+	ShotID    string `json:"shotId,omitempty"`
+	Nodes     int    `json:"nodes"`
+	Time      string `json:"time,omitempty"` // Queued (pending/running) or finished (history)
+}
+
+type ServerQueue struct {
+	Running    []ServerJob `json:"running"`
+	Pending    []ServerJob `json:"pending"`
+	Recent     []ServerJob `json:"recent"`
+	MyPosition int         `json:"myPosition"` // Position of our first pending job, 0 if none
+	Ahead      int         `json:"ahead"`      // Jobs from other clients ahead of it
+}
+
+type submittedPrompt struct {
+	Server    string // ComfyUI base URL the prompt was sent to
+	ProjectID string
+	SceneID   string
+	ShotID    string
+	ShotName  string
+}
+
+var (
+	promptsMu    sync.Mutex
+	prompts      = map[string]submittedPrompt{}
+	latestPrompt = map[string]string{} // Shot ID -> last prompt id
+)
+
+// rememberPrompt maps a submitted prompt id back to its shot and server
+func rememberPrompt(promptID string, server string, projectId string, sceneId string, shot Shot) {
+	promptsMu.Lock()
+	defer promptsMu.Unlock()
+	prompts[promptID] = submittedPrompt{Server: server, ProjectID: projectId, SceneID: sceneId, ShotID: shot.ID, ShotName: shot.Name}
+	latestPrompt[shot.ID] = promptID
+}
+
+// promptServer returns the server a prompt was submitted to (the primary if unknown)
+func (a *App) promptServer(promptID string) string {
+	promptsMu.Lock()
+	defer promptsMu.Unlock()
+	if sub, ok := prompts[promptID]; ok && sub.Server != "" {
+		return sub.Server
+	}
+	return a.comfyURL
+}
+
+// comfyJSONAt calls the ComfyUI API of server, sending body (if any) and
+// decoding into out (if any)
+func (a *App) comfyJSONAt(server string, method string, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+	req, err := a.comfyRequest(method, server, path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("ComfyUI API Error (%d): %s", resp.StatusCode, string(msg))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// parseQueueItem reads ComfyUI's [number, prompt_id, prompt, extra_data, outputs] tuple
+func (a *App) parseQueueItem(item []interface{}, state string) ServerJob {
+	job := ServerJob{State: state}
+	if len(item) > 0 {
+		n, _ := item[0].(float64)
+		job.Number = int(n)
+	}
+	if len(item) > 1 {
+		job.PromptID, _ = item[1].(string)
+	}
+	if len(item) > 2 {
+		nodes, _ := item[2].(map[string]interface{})
+		job.Nodes = len(nodes)
+	}
+	if len(item) > 3 {
+		extra, _ := item[3].(map[string]interface{})
+		clientID, _ := extra["client_id"].(string)
+		job.Mine = clientID != "" && clientID == a.clientID
+		if created, ok := extra["create_time"].(float64); ok {
+			job.Time = time.UnixMilli(int64(created)).Format(time.RFC3339)
+		}
+	}
+
+	promptsMu.Lock()
+	sub, known := prompts[job.PromptID]
+	promptsMu.Unlock()
+	switch {
+	case known:
+		job.Mine = true
+		job.ProjectID, job.SceneID, job.ShotID = sub.ProjectID, sub.SceneID, sub.ShotID
+		job.Label = sub.ShotName
+	case job.Mine:
+		job.Label = "Motion Studio render"
+	default:
+		job.Label = "Another client"
+	}
+	return job
+}
+
+// GetServerQueue lists running, pending and the last historyLimit finished
+// prompts on the ComfyUI server
+func (a *App) GetServerQueue(historyLimit int) (ServerQueue, error) {
+	return a.getServerQueueAt(a.comfyURL, historyLimit)
+}
+
+func (a *App) getServerQueueAt(server string, historyLimit int) (ServerQueue, error) {
+	queue := ServerQueue{Running: []ServerJob{}, Pending: []ServerJob{}, Recent: []ServerJob{}}
+
+	var raw struct {
+		Running [][]interface{} `json:"queue_running"`
+		Pending [][]interface{} `json:"queue_pending"`
+	}
+	if err := a.comfyJSONAt(server, "GET", "/queue", nil, &raw); err != nil {
+		return queue, err
+	}
+	for _, item := range raw.Running {
+		queue.Running = append(queue.Running, a.parseQueueItem(item, "running"))
+	}
+	for _, item := range raw.Pending {
+		queue.Pending = append(queue.Pending, a.parseQueueItem(item, "pending"))
+	}
+	sort.Slice(queue.Pending, func(i, j int) bool { return queue.Pending[i].Number < queue.Pending[j].Number })
+	for i := range queue.Pending {
+		queue.Pending[i].Position = i + 1
+		if queue.Pending[i].Mine && queue.MyPosition == 0 {
+			queue.MyPosition = i + 1
+		}
+	}
+	if queue.MyPosition > 0 {
+		for _, job := range queue.Running {
+			if !job.Mine {
+				queue.Ahead++
+			}
+		}
+		for _, job := range queue.Pending[:queue.MyPosition-1] {
+			if !job.Mine {
+				queue.Ahead++
+			}
+		}
+	}
+
+	if historyLimit <= 0 {
+		historyLimit = 20
+	}
+	var history map[string]struct {
+		Prompt []interface{} `json:"prompt"`
+		Status struct {
+			StatusStr string          `json:"status_str"`
+			Messages  [][]interface{} `json:"messages"`
+		} `json:"status"`
+	}
+	if err := a.comfyJSONAt(server, "GET", fmt.Sprintf("/history?max_items=%d", historyLimit), nil, &history); err == nil {
+		for _, entry := range history {
+			state := entry.Status.StatusStr
+			if state == "" {
+				state = "success"
+			}
+			job := a.parseQueueItem(entry.Prompt, state)
+			// The last status message carries the finish timestamp (ms)
+			if n := len(entry.Status.Messages); n > 0 && len(entry.Status.Messages[n-1]) > 1 {
+				if data, ok := entry.Status.Messages[n-1][1].(map[string]interface{}); ok {
+					if ts, ok := data["timestamp"].(float64); ok {
+						job.Time = time.UnixMilli(int64(ts)).Format(time.RFC3339)
+					}
+				}
+			}
+			queue.Recent = append(queue.Recent, job)
+		}
+		sort.Slice(queue.Recent, func(i, j int) bool { return queue.Recent[i].Number > queue.Recent[j].Number })
+	}
+	return queue, nil
+}
+
+// CancelServerJob removes one of our prompts from the server queue, or
+// interrupts it if it is already running
+func (a *App) CancelServerJob(promptId string) string {
+	server := a.promptServer(promptId)
+	queue, err := a.getServerQueueAt(server, 1)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	for _, job := range queue.Running {
+		if job.PromptID != promptId {
+			continue
+		}
+		if !job.Mine {
+			return "Error: this job belongs to another client"
+		}
+		if err := a.comfyJSONAt(server, "POST", "/interrupt", map[string]string{"prompt_id": promptId}, nil); err != nil {
+			return "Error: " + err.Error()
+		}
+		return "Success"
+	}
+	for _, job := range queue.Pending {
+		if job.PromptID != promptId {
+			continue
+		}
+		if !job.Mine {
+			return "Error: this job belongs to another client"
+		}
+		if err := a.comfyJSONAt(server, "POST", "/queue", map[string][]string{"delete": {promptId}}, nil); err != nil {
+			return "Error: " + err.Error()
+		}
+		return "Success"
+	}
+	return "Job is no longer queued"
+}
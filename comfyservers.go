@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// --- COMFYUI SERVER POOL ---
+
+// Spreads render jobs over several ComfyUI servers.
+
+type ComfyServer struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Enabled bool   `json:"enabled"`
+	Slots   int    `json:"slots"`   // Jobs sent at once; 0 = 1
+	Device  string `json:"device"`  // GPU renders use ("cuda:1"), see gpuselect.go
+	Backend string `json:"backend"` // "" or comfyui, a1111, replicate, fal; see backends.go
+	Motion  string `json:"motion"`  // AnimateDiff motion module of an a1111 server; "" = stills
+
+	Inputs map[string]string `json:"inputs,omitempty"` // Cloud model input names by role, see cloud.go
+}
+
+type ComfyServerStatus struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Online  bool   `json:"online"`
+	Socket  bool   `json:"socket"`  // Progress websocket is connected (see comfyws.go)
+	Running int    `json:"running"` // Queue jobs this app is running there
+	Error   string `json:"error,omitempty"`
+}
+
+// renderSlot is a server the dispatcher can assign jobs to
+type renderSlot struct {
+	Name     string
+	URL      string
+	Capacity int
+}
+
+// GetComfyServers returns the configured render servers
+func (a *App) GetComfyServers() []ComfyServer {
+	servers := a.getConfig().ComfyServers
+	if servers == nil {
+		servers = []ComfyServer{}
+	}
+	return servers
+}
+
+// SaveComfyServers replaces the render server list
+func (a *App) SaveComfyServers(servers []ComfyServer) string {
+	seen := map[string]bool{}
+	for i := range servers {
+		s := &servers[i]
+		s.URL = strings.TrimRight(strings.TrimSpace(s.URL), "/")
+		u, err := url.Parse(s.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Sprintf("Invalid server URL: %q", s.URL)
+		}
+		if seen[s.URL] {
+			return fmt.Sprintf("Duplicate server: %s", s.URL)
+		}
+		seen[s.URL] = true
+		if s.Name == "" {
+			s.Name = u.Host
+		}
+		s.Slots = max(s.Slots, 1)
+		if !validBackend(s.Backend) {
+			return fmt.Sprintf("Unknown backend %q for %s", s.Backend, s.URL)
+		}
+	}
+	a.updateConfig(func(c *Config) { c.ComfyServers = servers })
+	wakeRenderQueue()
+	return "Success"
+}
+
+// renderSlots lists where queued jobs may run: every enabled server, or the
+// primary ComfyURL with the configured worker count when none are set up
+func (a *App) renderSlots() []renderSlot {
+	var slots []renderSlot
+	for _, s := range a.getConfig().ComfyServers {
+		if s.Enabled {
+			slots = append(slots, renderSlot{Name: s.Name, URL: s.URL, Capacity: max(s.Slots, 1)})
+		}
+	}
+	if len(slots) == 0 {
+		slots = append(slots, renderSlot{Name: "Primary", URL: a.comfyURL, Capacity: a.renderWorkers()})
+	}
+	return slots
+}
+
+// CheckComfyServers pings every enabled server (in parallel) and reports how
+// many of our jobs run on each
+func (a *App) CheckComfyServers() []ComfyServerStatus {
+	slots := a.renderSlots()
+
+	running := map[string]int{}
+	renderQueueMu.Lock()
+	for _, job := range renderJobs {
+		if job.Status == JobRunning {
+			running[job.Server]++
+		}
+	}
+	renderQueueMu.Unlock()
+
+	status := make([]ComfyServerStatus, len(slots))
+	var wg sync.WaitGroup
+	for i, slot := range slots {
+		status[i] = ComfyServerStatus{Name: slot.Name, URL: slot.URL, Running: running[slot.URL], Socket: comfySocketConnected(slot.URL)}
+		wg.Add(1)
+		go func(st *ComfyServerStatus) {
+			defer wg.Done()
+			check := func(server string) error { return a.comfyJSONAt(server, "GET", "/system_stats", nil, nil) }
+			if backend := a.serverBackend(st.URL); backend != nil {
+				check = backend.ping
+			}
+			if err := check(st.URL); err != nil {
+				st.Error = err.Error()
+				return
+			}
+			st.Online = true
+		}(&status[i])
+	}
+	wg.Wait()
+	return status
+}
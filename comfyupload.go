@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// --- COMFYUI UPLOADS ---
+
+// Uploads assets to ComfyUI with their MIME type and subfolder.
+
+type ComfyUploadSettings struct {
+	Subfolder      string `json:"subfolder"`      // Images and video; "" = input root
+	AudioSubfolder string `json:"audioSubfolder"` // Audio; "" = same as Subfolder
+	Overwrite      bool   `json:"overwrite"`      // Replace same-named files
+}
+
+// comfyUpload says where one file goes
+type comfyUpload struct {
+	Kind      string // image, audio or video
+	Subfolder string
+	Overwrite bool
+}
+
+// GetComfyUploadSettings returns where assets are uploaded on ComfyUI
+func (a *App) GetComfyUploadSettings() ComfyUploadSettings {
+	return a.getConfig().ComfyUpload
+}
+
+// SaveComfyUploadSettings stores where assets are uploaded on ComfyUI
+func (a *App) SaveComfyUploadSettings(settings ComfyUploadSettings) string {
+	for _, dir := range []*string{&settings.Subfolder, &settings.AudioSubfolder} {
+		clean, err := cleanSubfolder(*dir)
+		if err != nil {
+			return "Error: " + err.Error()
+		}
+		*dir = clean
+	}
+	a.updateConfig(func(c *Config) { c.ComfyUpload = settings })
+	return "Success"
+}
+
+// cleanSubfolder normalizes a subfolder of ComfyUI's input folder
+func cleanSubfolder(dir string) (string, error) {
+	dir = strings.Trim(strings.ReplaceAll(strings.TrimSpace(dir), "\\", "/"), "/")
+	if dir == "" {
+		return "", nil
+	}
+	dir = path.Clean(dir)
+	if dir == ".." || strings.HasPrefix(dir, "../") || strings.Contains(dir, ":") {
+		return "", fmt.Errorf("subfolder %q must stay inside ComfyUI's input folder", dir)
+	}
+	return dir, nil
+}
+
+// uploadKind tells images, audio and video apart by MIME type
+func uploadKind(file string) string {
+	switch t := mediaContentType(file); {
+	case strings.HasPrefix(t, "audio/"):
+		return "audio"
+	case strings.HasPrefix(t, "video/"):
+		return "video"
+	}
+	return "image"
+}
+
+// uploadAsset uploads a local file to ComfyUI's input folder per the upload
+// settings and returns the name to inject
+func (a *App) uploadAsset(server string, file string) (string, error) {
+	settings := a.getConfig().ComfyUpload
+	target := comfyUpload{Kind: uploadKind(file), Subfolder: settings.Subfolder, Overwrite: settings.Overwrite}
+	if target.Kind == "audio" && settings.AudioSubfolder != "" {
+		target.Subfolder = settings.AudioSubfolder
+	}
+	return a.uploadToComfy(server, file, target)
+}
+
+// uploadToComfy posts a file to /upload/image (ComfyUI's upload endpoint for
+// every input kind) and returns its subfolder-qualified name
+func (a *App) uploadToComfy(server string, file string, target comfyUpload) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="image"; filename=%q`, filepath.Base(file)))
+	header.Set("Content-Type", mediaContentType(file))
+	part, _ := writer.CreatePart(header)
+	if _, err := io.Copy(part, f); err != nil {
+		return "", err
+	}
+	writer.WriteField("type", "input")
+	writer.WriteField("subfolder", target.Subfolder)
+	if target.Overwrite {
+		writer.WriteField("overwrite", "true")
+	}
+	writer.Close()
+
+	req, err := a.comfyRequest("POST", server, "/upload/image", body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("comfyui returned status %d uploading %s", resp.StatusCode, target.Kind)
+	}
+
+	var res struct {
+		Name      string `json:"name"`
+		Subfolder string `json:"subfolder"`
+	}
+	json.NewDecoder(resp.Body).Decode(&res)
+
+	// Comfy returns the name, possibly modified if it was a duplicate
+	name, subfolder := res.Name, res.Subfolder
+	if name == "" {
+		name, subfolder = filepath.Base(file), target.Subfolder
+	}
+	if subfolder != "" {
+		return subfolder + "/" + name, nil
+	}
+	return name, nil
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- COMFYUI WEBSOCKET MANAGER ---
+
+// One long-lived websocket per ComfyUI server, routed to renders by prompt_id.
+
+const (
+	wsPingPeriod  = 20 * time.Second
+	wsPongWait    = 45 * time.Second
+	wsMaxBackoff  = 30 * time.Second
+	wsIdleTimeout = 5 * time.Minute
+	// wsFinishedKeep is how long a finish is remembered for late subscribers
+	wsFinishedKeep = time.Minute
+)
+
+// comfyEvent is one message from ComfyUI for a prompt
+type comfyEvent struct {
+	Type string
+	Data map[string]interface{}
+}
+
+// finished reports whether the prompt is over (successfully or not)
+func (e comfyEvent) finished() bool {
+	switch e.Type {
+	case "execution_success", "execution_error", "execution_interrupted":
+		return true
+	case "executing":
+		return e.Data["node"] == nil // ComfyUI's "done" before execution_success existed
+	}
+	return false
+}
+
+type comfySocket struct {
+	server string
+
+	mu        sync.Mutex
+	running   bool
+	connected bool
+	current   string // Prompt the server is executing (for messages without prompt_id)
+	subs      map[string]chan comfyEvent
+	finished  map[string]comfyEvent // Finishes nobody was subscribed for yet
+	finishAt  map[string]time.Time
+	lastUsed  time.Time
+}
+
+var (
+	comfySocketsMu sync.Mutex
+	comfySockets   = map[string]*comfySocket{}
+)
+
+// comfySocketFor returns the server's socket, starting it if needed
+func (a *App) comfySocketFor(server string) *comfySocket {
+	comfySocketsMu.Lock()
+	s, ok := comfySockets[server]
+	if !ok {
+		s = &comfySocket{
+			server:   server,
+			subs:     map[string]chan comfyEvent{},
+			finished: map[string]comfyEvent{},
+			finishAt: map[string]time.Time{},
+		}
+		comfySockets[server] = s
+	}
+	comfySocketsMu.Unlock()
+
+	s.mu.Lock()
+	s.lastUsed = time.Now()
+	start := !s.running
+	s.running = true
+	s.mu.Unlock()
+	if start {
+		go a.runComfySocket(s)
+	}
+	return s
+}
+
+// subscribe returns the events of a prompt until unsubscribe. A finish that
+// arrived before subscribing is delivered right away.
+func (s *comfySocket) subscribe(promptID string) <-chan comfyEvent {
+	ch := make(chan comfyEvent, 64)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastUsed = time.Now()
+	if ev, ok := s.finished[promptID]; ok {
+		ch <- ev
+		delete(s.finished, promptID)
+		delete(s.finishAt, promptID)
+	}
+	s.subs[promptID] = ch
+	return ch
+}
+
+// unsubscribe stops delivery and closes the prompt's channel
+func (s *comfySocket) unsubscribe(promptID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ch, ok := s.subs[promptID]; ok {
+		close(ch)
+		delete(s.subs, promptID)
+	}
+	s.lastUsed = time.Now()
+}
+
+func (s *comfySocket) isConnected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connected
+}
+
+// idle reports whether nobody has needed the socket for a while
+func (s *comfySocket) idle() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.idleLocked()
+}
+
+func (s *comfySocket) idleLocked() bool {
+	return len(s.subs) == 0 && time.Since(s.lastUsed) > wsIdleTimeout
+}
+
+// stopIfIdle marks the socket stopped when idle, so the next user restarts it
+func (s *comfySocket) stopIfIdle() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.idleLocked() {
+		s.running = false
+		return true
+	}
+	return false
+}
+
+// socketURL turns http(s)://host into ws(s)://host/ws?clientId=...
+func (a *App) socketURL(server string) string {
+	if rest, ok := strings.CutPrefix(server, "https://"); ok {
+		return fmt.Sprintf("wss://%s/ws?clientId=%s", rest, a.clientID)
+	}
+	return fmt.Sprintf("ws://%s/ws?clientId=%s", strings.TrimPrefix(server, "http://"), a.clientID)
+}
+
+// runComfySocket keeps the connection up until it has been idle for a while
+func (a *App) runComfySocket(s *comfySocket) {
+	backoff := time.Second
+	for {
+		if s.stopIfIdle() {
+			return
+		}
+
+		conn, _, err := websocket.DefaultDialer.Dial(a.socketURL(s.server), a.comfyHeaders(s.server))
+		if err != nil {
+			fmt.Printf("ComfyUI socket %s: %v (retrying in %v)\n", s.server, err, backoff)
+			time.Sleep(backoff)
+			backoff = min(backoff*2, wsMaxBackoff)
+			continue
+		}
+		backoff = time.Second
+		a.setSocketConnected(s, true)
+		a.readComfySocket(s, conn)
+		a.setSocketConnected(s, false)
+	}
+}
+
+func (a *App) setSocketConnected(s *comfySocket, connected bool) {
+	s.mu.Lock()
+	s.connected = connected
+	s.mu.Unlock()
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "comfy:socket", map[string]interface{}{"server": s.server, "connected": connected})
+	}
+}
+
+// readComfySocket pumps messages until the connection fails or goes idle
+func (a *App) readComfySocket(s *comfySocket, conn *websocket.Conn) {
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(wsPingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if s.idle() {
+					conn.Close() // Unblocks ReadMessage; runComfySocket then exits
+					return
+				}
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		kind, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		if kind == websocket.BinaryMessage {
+			if ev, ok := parsePreviewFrame(message); ok {
+				s.route(ev)
+			}
+			continue
+		}
+		var msg struct {
+			Type string                 `json:"type"`
+			Data map[string]interface{} `json:"data"`
+		}
+		if json.Unmarshal(message, &msg) != nil || msg.Data == nil {
+			continue
+		}
+		s.route(comfyEvent{Type: msg.Type, Data: msg.Data})
+	}
+}
+
+// route hands an event to the render waiting on its prompt
+func (s *comfySocket) route(ev comfyEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	promptID, _ := ev.Data["prompt_id"].(string)
+	switch ev.Type {
+	case "execution_start":
+		s.current = promptID
+	case "progress":
+		if promptID == "" {
+			promptID = s.current // Older ComfyUI omits it
+		}
+	}
+	if promptID == "" && ev.Type != "preview" {
+		return // Queue status and other server-wide messages
+	}
+	if ev.Type == "preview" {
+		if promptID == "" {
+			promptID = s.current
+		}
+		if ch, ok := s.subs[promptID]; ok {
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+		return
+	}
+	if ev.finished() && s.current == promptID {
+		s.current = ""
+	}
+
+	if ch, ok := s.subs[promptID]; ok {
+		select {
+		case ch <- ev:
+		default: // A stalled reader only loses progress ticks
+		}
+		return
+	}
+	if ev.finished() {
+		s.finished[promptID] = ev
+		s.finishAt[promptID] = time.Now()
+	}
+	for id, at := range s.finishAt {
+		if time.Since(at) > wsFinishedKeep {
+			delete(s.finished, id)
+			delete(s.finishAt, id)
+		}
+	}
+}
+
+// comfySocketConnected reports whether the server's socket is currently up
+func comfySocketConnected(server string) bool {
+	comfySocketsMu.Lock()
+	s, ok := comfySockets[server]
+	comfySocketsMu.Unlock()
+	return ok && s.isConnected()
+}
+
+// --- LIVE PREVIEWS ---
+
+// Sampler previews ComfyUI sends as binary websocket frames.
+
+const (
+	wsPreviewImage         = 1
+	wsPreviewImageMetadata = 4
+)
+
+// parsePreviewFrame decodes a binary preview into a "preview" event carrying
+// the mime type and image bytes
+func parsePreviewFrame(frame []byte) (comfyEvent, bool) {
+	if len(frame) < 8 {
+		return comfyEvent{}, false
+	}
+	data := map[string]interface{}{}
+	var image []byte
+	mime := ""
+
+	switch binary.BigEndian.Uint32(frame[:4]) {
+	case wsPreviewImage:
+		switch binary.BigEndian.Uint32(frame[4:8]) {
+		case 1:
+			mime = "image/jpeg"
+		case 2:
+			mime = "image/png"
+		default:
+			return comfyEvent{}, false
+		}
+		image = frame[8:]
+	case wsPreviewImageMetadata:
+		size := int(binary.BigEndian.Uint32(frame[4:8]))
+		if size > len(frame)-8 {
+			return comfyEvent{}, false
+		}
+		var meta struct {
+			PromptID  string `json:"prompt_id"`
+			ImageType string `json:"image_type"`
+		}
+		json.Unmarshal(frame[8:8+size], &meta)
+		data["prompt_id"] = meta.PromptID
+		mime = meta.ImageType
+		if mime == "" {
+			mime = "image/jpeg"
+		}
+		image = frame[8+size:]
+	default:
+		return comfyEvent{}, false
+	}
+	if len(image) == 0 {
+		return comfyEvent{}, false
+	}
+	data["mime"] = mime
+	data["image"] = image
+	return comfyEvent{Type: "preview", Data: data}, true
+}
+
+// previewInterval throttles preview events; samplers can produce dozens a second
+const previewInterval = 250 * time.Millisecond
+
+var (
+	livePreviewsMu sync.Mutex
+	livePreviews   = map[string]string{} // Shot ID -> latest preview as a data URL
+)
+
+// emitLivePreview sends a shot's newest preview frame as "comfy:preview"
+func (a *App) emitLivePreview(shotId string, promptID string, ev comfyEvent) {
+	image, _ := ev.Data["image"].([]byte)
+	mime, _ := ev.Data["mime"].(string)
+	url := "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(image)
+
+	livePreviewsMu.Lock()
+	livePreviews[shotId] = url
+	livePreviewsMu.Unlock()
+
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "comfy:preview", map[string]interface{}{
+			"shotId":   shotId,
+			"promptId": promptID,
+			"image":    url,
+		})
+	}
+}
+
+func clearLivePreview(shotId string) {
+	livePreviewsMu.Lock()
+	delete(livePreviews, shotId)
+	livePreviewsMu.Unlock()
+}
+
+// GetLivePreview returns the latest preview of a shot that is rendering, so
+// a view opened mid-render doesn't wait for the next frame ("" if none)
+func (a *App) GetLivePreview(shotId string) string {
+	livePreviewsMu.Lock()
+	defer livePreviewsMu.Unlock()
+	return livePreviews[shotId]
+}
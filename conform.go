@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// --- FRAME RATE CONFORM ---
+
+// Conform policies decide how clips whose native frame rate differs from the
+// project rate are retimed. The same filter is used by the preview and the export
+// so what you see while editing is what you deliver.
+const (
+	ConformDrop        = "drop"        // Drop/duplicate frames (fast, can judder)
+	ConformBlend       = "blend"       // Blend neighbouring frames (smooth, soft)
+	ConformInterpolate = "interpolate" // Motion-compensated interpolation (slow, best)
+)
+
+// getVideoFrameRate returns the average frame rate of the first video stream,
+// or 0 if it can't be determined (images, audio-only files, missing ffprobe).
+func (a *App) getVideoFrameRate(path string) float64 {
+	cmd := exec.Command("ffprobe",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=avg_frame_rate",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		mediaPath(path))
+
+	out, err := cmd.Output()
+	if err != nil {
+		return 0
+	}
+	return parseFrameRate(strings.TrimSpace(string(out)))
+}
+
+// parseFrameRate converts ffprobe rationals ("30000/1001") or plain numbers to fps.
+func parseFrameRate(value string) float64 {
+	if num, den, ok := strings.Cut(value, "/"); ok {
+		n, err1 := strconv.ParseFloat(num, 64)
+		d, err2 := strconv.ParseFloat(den, 64)
+		if err1 != nil || err2 != nil || d == 0 {
+			return 0
+		}
+		return n / d
+	}
+	fps, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return fps
+}
+
+// resolveConform picks the target frame rate for a set of clips, probing each
+// with frameRate. An explicit project rate always wins, otherwise the first
+// clip's rate is used. Conforming is only needed when a clip's rate differs
+// from the target.
+func resolveConform(projectFPS float64, clips []string, frameRate func(string) float64) (float64, bool) {
+	target := projectFPS
+	mismatch := false
+	probed := make(map[string]bool)
+	for _, clip := range clips {
+		if probed[clip] {
+			continue
+		}
+		probed[clip] = true
+		fps := frameRate(clip)
+		if fps <= 0 {
+			continue
+		}
+		if target <= 0 {
+			target = fps
+			continue
+		}
+		if diff := fps - target; diff > 0.01 || diff < -0.01 {
+			mismatch = true
+		}
+	}
+	return target, mismatch
+}
+
+// conformFilter builds the ffmpeg video filter that retimes input to fps.
+func conformFilter(fps float64, policy string) string {
+	rate := strconv.FormatFloat(fps, 'f', -1, 64)
+	switch policy {
+	case ConformBlend:
+		return fmt.Sprintf("framerate=fps=%s", rate)
+	case ConformInterpolate:
+		return fmt.Sprintf("minterpolate=fps=%s:mi_mode=mci:mc_mode=aobmc:vsbmc=1", rate)
+	default:
+		return fmt.Sprintf("fps=%s", rate)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestResolveConform(t *testing.T) {
+	rates := map[string]float64{
+		"a24.mp4":   24,
+		"b24.mp4":   24,
+		"c30.mp4":   30,
+		"d2997.mp4": 30000.0 / 1001,
+		"e2997.mp4": 29.97,
+		"still.png": 0,
+	}
+	frameRate := func(clip string) float64 { return rates[clip] }
+
+	tests := []struct {
+		name       string
+		projectFPS float64
+		clips      []string
+		wantFPS    float64
+		wantNeeded bool
+	}{
+		{name: "no clips", clips: nil, wantFPS: 0},
+		{name: "no clips, project rate", projectFPS: 25, clips: nil, wantFPS: 25},
+		{name: "matching clips", clips: []string{"a24.mp4", "b24.mp4"}, wantFPS: 24},
+		{name: "first clip sets the rate", clips: []string{"c30.mp4", "a24.mp4"}, wantFPS: 30, wantNeeded: true},
+		{name: "project rate matches", projectFPS: 24, clips: []string{"a24.mp4", "b24.mp4"}, wantFPS: 24},
+		{name: "project rate differs", projectFPS: 25, clips: []string{"a24.mp4", "b24.mp4"}, wantFPS: 25, wantNeeded: true},
+		{name: "rates within tolerance", clips: []string{"d2997.mp4", "e2997.mp4"}, wantFPS: 30000.0 / 1001},
+		{name: "unknown rates are skipped", clips: []string{"still.png", "a24.mp4", "still.png"}, wantFPS: 24},
+		{name: "repeated clip", clips: []string{"a24.mp4", "a24.mp4"}, wantFPS: 24},
+	}
+	for _, tt := range tests {
+		fps, needed := resolveConform(tt.projectFPS, tt.clips, frameRate)
+		if fps != tt.wantFPS || needed != tt.wantNeeded {
+			t.Errorf("%s: resolveConform = (%v, %v), want (%v, %v)", tt.name, fps, needed, tt.wantFPS, tt.wantNeeded)
+		}
+	}
+}
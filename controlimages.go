@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// --- CONTROL IMAGES ---
+
+// Depth, pose and reference images injected into their titled loader nodes.
+
+// controlKinds maps each kind to its role and the title words that select it
+var controlKinds = map[string]struct {
+	Role  string
+	Words []string
+}{
+	"depth":     {"IMAGE_DEPTH", []string{"depth"}},
+	"pose":      {"IMAGE_POSE", []string{"pose", "openpose", "skeleton"}},
+	"reference": {"IMAGE_REFERENCE", []string{"reference", "ref", "ipadapter", "style"}},
+	"control":   {"IMAGE_CONTROL", []string{"control", "controlnet", "canny", "lineart", "edge", "edges"}},
+}
+
+// controlImageLoaders returns the control kind of every image loader titled for one
+func controlImageLoaders(workflow map[string]interface{}) map[string]string {
+	loaders := map[string]string{}
+	for nodeId, node := range workflow {
+		nodeMap, _ := node.(map[string]interface{})
+		meta, _ := nodeMap["_meta"].(map[string]interface{})
+		title, _ := meta["title"].(string)
+		words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool { return r < 'a' || r > 'z' })
+		// Fixed order so "ControlNet Depth" is a depth map, not a generic control image
+		for _, kind := range []string{"depth", "pose", "reference", "control"} {
+			matched := false
+			for _, w := range controlKinds[kind].Words {
+				if containsFold(words, w) {
+					matched = true
+					break
+				}
+			}
+			if matched {
+				loaders[nodeId] = kind
+				break
+			}
+		}
+	}
+	return loaders
+}
+
+// controlRole returns the role of a kind's image, or "" for unknown kinds
+func controlRole(kind string) string {
+	return controlKinds[kind].Role
+}
+
+// controlKindList is the kinds in a stable order, for fingerprints
+func controlKindList(images map[string]string) []string {
+	kinds := make([]string, 0, len(images))
+	for kind := range images {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// SetShotControlImage sets a shot's depth, pose, reference or control image
+// (an empty path removes it)
+func (a *App) SetShotControlImage(projectId string, sceneId string, shotId string, kind string, path string) error {
+	if controlRole(kind) == "" {
+		return fmt.Errorf("unknown control image kind %q", kind)
+	}
+	if path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("image not found: %s", path)
+		}
+	}
+	for _, s := range a.GetShots(projectId, sceneId) {
+		if s.ID != shotId {
+			continue
+		}
+		a.updateShot(projectId, sceneId, shotId, func(s *Shot) {
+			if path == "" {
+				delete(s.ControlImages, kind)
+				if len(s.ControlImages) == 0 {
+					s.ControlImages = nil
+				}
+				return
+			}
+			if s.ControlImages == nil {
+				s.ControlImages = map[string]string{}
+			}
+			s.ControlImages[kind] = path
+		})
+		return nil
+	}
+	return fmt.Errorf("shot not found")
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+)
+
+// --- CREDENTIALS (OS KEYCHAIN) ---
+
+// Secrets never touch config.json. They live in the platform keychain
+// (macOS Keychain, Windows Credential Manager/DPAPI, Secret Service on Linux)
+// under this service name, one entry per key.
+const keyringService = "MotionStudio"
+
+// Well-known credential keys shared by the integrations.
+const (
+	CredComfyToken = "comfyui.token"  // Bearer/API key header for remote ComfyUI
+	CredComfyBasic = "comfyui.basic"  // "user:password" for proxies with basic auth
+	CredLLMKey     = "llm.apiKey"     // OpenAI-compatible prompt assistant
+	CredCloudKey   = "cloud.apiKey"   // Replicate / fal.ai
+	CredRunPodKey  = "runpod.apiKey"  // RunPod provisioning
+	CredMusicKey   = "music.apiKey"   // AI music backend
+	CredWhisperKey = "whisper.apiKey" // Hosted transcription backend
+)
+
+var (
+	credMu    sync.Mutex
+	credCache = make(map[string]string) // Avoid repeated keychain prompts on macOS
+)
+
+// getCredential returns the stored secret for key, or "" if none is stored.
+func (a *App) getCredential(key string) string {
+	credMu.Lock()
+	defer credMu.Unlock()
+
+	if v, ok := credCache[key]; ok {
+		return v
+	}
+	v, err := keyring.Get(keyringService, key)
+	if err != nil {
+		if !errors.Is(err, keyring.ErrNotFound) {
+			fmt.Printf("Warning: keychain lookup for %s failed: %v\n", key, err)
+		}
+		// Remember the miss too: ComfyUI requests look up their auth
+		// credentials every time (SetCredential replaces the entry)
+		credCache[key] = ""
+		return ""
+	}
+	credCache[key] = v
+	return v
+}
+
+// SetCredential stores a secret in the OS keychain. An empty value deletes it.
+func (a *App) SetCredential(key string, value string) string {
+	if key == "" {
+		return "Invalid key"
+	}
+	if value == "" {
+		return a.DeleteCredential(key)
+	}
+
+	credMu.Lock()
+	defer credMu.Unlock()
+
+	if err := keyring.Set(keyringService, key, value); err != nil {
+		return tr("credentials.error.save", err.Error())
+	}
+	credCache[key] = value
+	return "Success"
+}
+
+// DeleteCredential removes a secret from the OS keychain.
+func (a *App) DeleteCredential(key string) string {
+	credMu.Lock()
+	defer credMu.Unlock()
+
+	delete(credCache, key)
+	if err := keyring.Delete(keyringService, key); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return tr("credentials.error.delete", err.Error())
+	}
+	return "Success"
+}
+
+// HasCredential reports whether a secret is stored, without exposing it to the UI.
+func (a *App) HasCredential(key string) bool {
+	return a.getCredential(key) != ""
+}
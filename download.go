@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- RESUMABLE DOWNLOADS ---
+
+// Downloads from ComfyUI to a .partial file, resumed with Range requests.
+
+const (
+	downloadAttempts = 4
+	// downloadStall aborts a download that received nothing for this long
+	downloadStall = 60 * time.Second
+)
+
+// downloadFromComfy saves path of server to dest, resuming after failures
+func (a *App) downloadFromComfy(server string, path string, dest string) error {
+	partial := dest + ".partial"
+	defer os.Remove(partial)
+	os.Remove(partial)
+
+	var total int64 = -1
+	var lastErr error
+	for attempt := 1; attempt <= downloadAttempts; attempt++ {
+		if attempt > 1 {
+			fmt.Printf("Download of %s failed (%v), retrying (%d/%d)\n", path, lastErr, attempt, downloadAttempts)
+			time.Sleep(time.Duration(attempt-1) * 2 * time.Second)
+		}
+		done, size, err := a.downloadAttempt(server, path, partial)
+		if size > 0 {
+			total = size
+		}
+		if err == nil && done {
+			break
+		}
+		lastErr = err
+		if attempt == downloadAttempts {
+			return fmt.Errorf("download incomplete after %d attempts: %v", downloadAttempts, lastErr)
+		}
+		var status downloadStatusError
+		if errors.As(err, &status) && status.Code != http.StatusRequestedRangeNotSatisfiable && status.Code < 500 {
+			return err // 404 and the like won't get better
+		}
+	}
+
+	if info, err := os.Stat(partial); err != nil {
+		return fmt.Errorf("failed to save result: %v", err)
+	} else if total >= 0 && info.Size() != total {
+		return fmt.Errorf("download incomplete: got %d of %d bytes", info.Size(), total)
+	}
+	if err := os.Rename(partial, dest); err != nil {
+		return fmt.Errorf("failed to save result: %v", err)
+	}
+	return nil
+}
+
+type downloadStatusError struct{ Code int }
+
+func (e downloadStatusError) Error() string {
+	return fmt.Sprintf("download failed (Status %d)", e.Code)
+}
+
+// downloadAttempt fetches the rest of path into partial. It returns whether
+// the file is complete and its total size (-1 if unknown).
+func (a *App) downloadAttempt(server string, path string, partial string) (bool, int64, error) {
+	var offset int64
+	if info, err := os.Stat(partial); err == nil {
+		offset = info.Size()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := a.comfyRequest("GET", server, path, nil)
+	if err != nil {
+		return false, -1, err
+	}
+	req = req.WithContext(ctx)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := (&http.Client{}).Do(req)
+	if err != nil {
+		return false, -1, err
+	}
+	defer resp.Body.Close()
+
+	total := int64(-1)
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		total = contentRangeTotal(resp.Header.Get("Content-Range"))
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		// No range support (or a fresh start): begin again
+		offset = 0
+		if resp.ContentLength >= 0 {
+			total = resp.ContentLength
+		}
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// Usually means we already have it all
+		if t := contentRangeTotal(resp.Header.Get("Content-Range")); t == offset {
+			return true, t, nil
+		}
+		os.Remove(partial)
+		return false, -1, downloadStatusError{resp.StatusCode}
+	default:
+		return false, -1, downloadStatusError{resp.StatusCode}
+	}
+
+	out, err := os.OpenFile(partial, flags, 0644)
+	if err != nil {
+		return false, total, fmt.Errorf("failed to save result: %v", err)
+	}
+	written, err := io.Copy(out, &stallReader{r: resp.Body, cancel: cancel})
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return false, total, err
+	}
+	if total >= 0 && offset+written != total {
+		return false, total, fmt.Errorf("got %d of %d bytes", offset+written, total)
+	}
+	return true, total, nil
+}
+
+// contentRangeTotal reads the total size from "bytes a-b/total" (or "*/total")
+func contentRangeTotal(header string) int64 {
+	i := strings.LastIndex(header, "/")
+	if i < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(strings.TrimSpace(header[i+1:]), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
+
+// stallReader cancels a download that delivers nothing for downloadStall
+type stallReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+	once   sync.Once
+	timer  *time.Timer
+}
+
+func (s *stallReader) Read(p []byte) (int, error) {
+	s.once.Do(func() { s.timer = time.AfterFunc(downloadStall, s.cancel) })
+	s.timer.Reset(downloadStall)
+	n, err := s.r.Read(p)
+	if err != nil {
+		s.timer.Stop()
+	}
+	return n, err
+}
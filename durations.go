@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// --- DURATION VALIDATION ---
+
+// Reports and fixes shots and clips whose durations don't match their sources.
+
+const (
+	IssueShotAudioMismatch = "shot-audio-mismatch" // Rendered video vs audio trim
+	IssueClipPastSource    = "clip-past-source"    // Timeline clip longer than its media
+
+	FixRetrim    = "retrim"     // Trim the shot's audio to the video length
+	FixRerender  = "rerender"   // Queue the shot to render again
+	FixTrimClip  = "trim-clip"  // Shorten the timeline clip to its media
+	FixMarkStale = "mark-stale" // Flag the shot for a later re-render
+)
+
+type DurationIssue struct {
+	Kind     string   `json:"kind"`
+	SceneID  string   `json:"sceneId"`
+	ShotID   string   `json:"shotId,omitempty"`
+	ClipID   string   `json:"clipId,omitempty"`
+	Track    int      `json:"track"`
+	Expected float64  `json:"expected"` // Seconds the edit asks for
+	Actual   float64  `json:"actual"`   // Seconds the media provides
+	Message  string   `json:"message"`
+	Fixes    []string `json:"fixes"`
+}
+
+// probeDuration returns the container duration, unlike getVideoDuration
+// reporting failures instead of falling back to a default length
+func probeDuration(path string) (float64, error) {
+	out, err := exec.Command("ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		mediaPath(path)).Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+}
+
+// durationTolerance is one frame at the project rate, never below 50 ms
+func (a *App) durationTolerance(projectId string) float64 {
+	fps := 24.0
+	if p, err := a.GetProject(projectId); err == nil && p.FrameRate > 0 {
+		fps = p.FrameRate
+	}
+	return math.Max(1/fps, 0.05)
+}
+
+// ValidateSceneDurations checks rendered shots against their audio trims and
+// timeline clips against the length of their source media
+func (a *App) ValidateSceneDurations(projectId string, sceneId string) []DurationIssue {
+	issues := []DurationIssue{}
+	tolerance := a.durationTolerance(projectId)
+
+	durations := map[string]float64{}
+	mediaDuration := func(path string) (float64, bool) {
+		if d, ok := durations[path]; ok {
+			return d, d > 0
+		}
+		d, err := probeDuration(path)
+		if err != nil {
+			d = 0
+		}
+		durations[path] = d
+		return d, d > 0
+	}
+
+	for _, shot := range a.GetShots(projectId, sceneId) {
+		if shot.OutputVideo == "" || shot.AudioPath == "" || shot.AudioDuration <= 0 {
+			continue
+		}
+		actual, ok := mediaDuration(shot.OutputVideo)
+		if !ok || math.Abs(actual-shot.AudioDuration) <= tolerance {
+			continue
+		}
+		issues = append(issues, DurationIssue{
+			Kind:     IssueShotAudioMismatch,
+			SceneID:  sceneId,
+			ShotID:   shot.ID,
+			Track:    -1,
+			Expected: shot.AudioDuration,
+			Actual:   actual,
+			Message:  fmt.Sprintf("%s: video is %.2fs but its audio trim is %.2fs", shot.Name, actual, shot.AudioDuration),
+			Fixes:    []string{FixRetrim, FixRerender, FixMarkStale},
+		})
+	}
+
+	for tIdx, track := range a.GetTimeline(projectId, sceneId).Tracks {
+		for _, raw := range track {
+			item := parseTimelineItem(raw)
+			source := item.OutputVideo
+			if source == "" {
+				source = item.AudioPath
+			}
+			if source == "" || item.Duration <= 0 {
+				continue // Stills can be held for any length
+			}
+			actual, ok := mediaDuration(source)
+			end := item.TrimStart + sourceSpan(item) // One pass of a looping clip
+			if !ok || end <= actual+tolerance {
+				continue
+			}
+			issues = append(issues, DurationIssue{
+				Kind:     IssueClipPastSource,
+				SceneID:  sceneId,
+				ClipID:   item.ID,
+				Track:    tIdx,
+				Expected: end,
+				Actual:   actual,
+				Message:  fmt.Sprintf("Clip on track %d runs to %.2fs but its media ends at %.2fs", tIdx+1, end, actual),
+				Fixes:    []string{FixTrimClip},
+			})
+		}
+	}
+	return issues
+}
+
+// FixDurationIssue applies one of issue.Fixes. workflowName is only needed
+// for "rerender". Returns "Success" or the reason the fix couldn't be applied.
+func (a *App) FixDurationIssue(projectId string, issue DurationIssue, fix string, workflowName string) string {
+	switch fix {
+	case FixRetrim, FixMarkStale:
+		shots := a.GetShots(projectId, issue.SceneID)
+		for i := range shots {
+			if shots[i].ID != issue.ShotID {
+				continue
+			}
+			if fix == FixRetrim {
+				shots[i].AudioDuration = issue.Actual
+			} else {
+				shots[i].Stale = true
+			}
+			a.SaveShots(projectId, issue.SceneID, shots)
+			return "Success"
+		}
+		return "Shot not found"
+
+	case FixRerender:
+		if workflowName == "" {
+			return "Error: a workflow is required to re-render"
+		}
+		if _, err := a.QueueRender(projectId, issue.SceneID, issue.ShotID, workflowName); err != nil {
+			return "Error: " + err.Error()
+		}
+		return "Success"
+
+	case FixTrimClip:
+		timeline := a.GetTimeline(projectId, issue.SceneID)
+		if issue.Track < 0 || issue.Track >= len(timeline.Tracks) {
+			return "Clip not found"
+		}
+		for _, raw := range timeline.Tracks[issue.Track] {
+			if id, _ := raw["id"].(string); id != issue.ClipID {
+				continue
+			}
+			trimStart, _ := raw["trimStart"].(float64)
+			if issue.Actual <= trimStart {
+				return "Error: the clip starts past the end of its media"
+			}
+			raw["duration"] = (issue.Actual - trimStart) * float64(loopPasses(parseTimelineItem(raw)))
+			a.SaveTimeline(projectId, issue.SceneID, timeline)
+			return "Success"
+		}
+		return "Clip not found"
+	}
+	return "Unknown fix"
+}
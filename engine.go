@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- ENGINE SUPERVISOR ---
+
+// Keeps the :3456 media server running and reports its health.
+
+// EngineSettings controls how the media server is exposed. It only ever listens
+// on loopback; "unix" removes the TCP port entirely and the webview reaches the
+// engine through the Wails asset server under /engine/.
+type EngineSettings struct {
+	Transport  string `json:"transport"`  // "tcp" (default) or "unix"
+	SocketPath string `json:"socketPath"` // unix only, defaults to the temp dir
+	TLS        bool   `json:"tls"`        // tcp only, off by default: serve HTTPS (see engineTLSConfig)
+	CertFile   string `json:"certFile"`   // Optional own certificate, self-signed otherwise
+	KeyFile    string `json:"keyFile"`
+}
+
+const engineTCPAddr = "127.0.0.1:3456"
+
+var (
+	// engineAddrMu guards the transport globals, set at startup and read by
+	// asset server requests
+	engineAddrMu sync.RWMutex
+
+	// engineBase prefixes every engine URL handed to the frontend
+	engineBase = "http://" + engineTCPAddr
+
+	// engineProxy forwards /engine/ requests from the asset server to the
+	// unix socket; nil in TCP mode
+	engineProxy http.Handler
+)
+
+// engineBaseURL returns the prefix of engine URLs
+func engineBaseURL() string {
+	engineAddrMu.RLock()
+	defer engineAddrMu.RUnlock()
+	return engineBase
+}
+
+// engineURL builds a URL for an engine route ("/preview.mp4?t=...")
+func engineURL(path string) string {
+	return engineBaseURL() + path
+}
+
+// GetEngineBaseURL lets the frontend build /video/ and other engine URLs
+// that work in both TCP and unix socket mode
+func (a *App) GetEngineBaseURL() string {
+	return engineBaseURL()
+}
+
+// GetEngineSettings returns the media server transport configuration
+func (a *App) GetEngineSettings() EngineSettings {
+	return a.getConfig().Engine
+}
+
+// SaveEngineSettings persists the transport configuration. The listener is
+// only rebuilt on the next launch.
+func (a *App) SaveEngineSettings(settings EngineSettings) string {
+	if settings.Transport != "" && settings.Transport != "tcp" && settings.Transport != "unix" {
+		return "Invalid transport"
+	}
+	if (settings.CertFile == "") != (settings.KeyFile == "") {
+		return "Certificate and key must be set together"
+	}
+	// Made now so it can be trusted (GetEngineCertificate) before the restart;
+	// the webview rejects engine media until it is
+	if _, err := a.engineTLSConfig(settings); err != nil {
+		return "Error: " + err.Error()
+	}
+	a.updateConfig(func(c *Config) { c.Engine = settings })
+	return "Restart required"
+}
+
+// engineListenAddr resolves the network and address to serve on, and prepares
+// the unix socket proxy when needed
+func engineListenAddr(settings EngineSettings) (string, string) {
+	if settings.Transport != "unix" {
+		return "tcp", engineTCPAddr
+	}
+
+	socketPath := settings.SocketPath
+	if socketPath == "" {
+		dir := os.Getenv("XDG_RUNTIME_DIR")
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		socketPath = filepath.Join(dir, "motion-studio-engine.sock")
+	}
+
+	target, _ := url.Parse("http://engine")
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		},
+	}
+	engineAddrMu.Lock()
+	engineProxy = http.StripPrefix("/engine", proxy)
+	engineBase = "/engine"
+	engineAddrMu.Unlock()
+	return "unix", socketPath
+}
+
+// engineProxyFor returns the unix socket proxy when an asset server request
+// targets the engine, nil otherwise
+func engineProxyFor(path string) http.Handler {
+	engineAddrMu.RLock()
+	defer engineAddrMu.RUnlock()
+	if engineProxy == nil || !strings.HasPrefix(path, "/engine/") {
+		return nil
+	}
+	return engineProxy
+}
+
+type EngineHealth struct {
+	Running   bool   `json:"running"`
+	Addr      string `json:"addr"`
+	Restarts  int    `json:"restarts"`
+	LastError string `json:"lastError"`
+	Since     string `json:"since"` // When the current state began
+}
+
+var (
+	engineMu     sync.Mutex
+	engineHealth EngineHealth
+)
+
+const (
+	engineMinBackoff = 1 * time.Second
+	engineMaxBackoff = 30 * time.Second
+)
+
+func setEngineHealth(change func(h *EngineHealth), notify func(EngineHealth)) {
+	engineMu.Lock()
+	change(&engineHealth)
+	engineHealth.Since = time.Now().Format(time.RFC3339)
+	snapshot := engineHealth
+	engineMu.Unlock()
+
+	if notify != nil {
+		notify(snapshot)
+	}
+}
+
+// superviseServer serves handler on addr forever, restarting the listener with
+// backoff whenever it fails. It only returns if serving stops without error.
+// A non-nil tlsConfig serves HTTPS.
+func superviseServer(network string, addr string, handler http.Handler, tlsConfig *tls.Config, notify func(EngineHealth)) {
+	backoff := engineMinBackoff
+	served := false // A listen that never worked isn't a restart
+	for {
+		if network == "unix" {
+			os.Remove(addr) // Stale socket from a previous crash
+		}
+		ln, err := net.Listen(network, addr)
+		if err != nil {
+			setEngineHealth(func(h *EngineHealth) {
+				h.Running = false
+				h.Addr = addr
+				h.LastError = err.Error()
+			}, notify)
+			fmt.Printf("⚠️ Video Engine failed to listen on %s: %v (retrying in %s)\n", addr, err, backoff)
+			recordEngineError("engine", err.Error())
+			time.Sleep(backoff)
+			backoff = min(backoff*2, engineMaxBackoff)
+			continue
+		}
+		if tlsConfig != nil {
+			ln = tls.NewListener(ln, tlsConfig)
+		}
+
+		restarted := served
+		served = true
+		setEngineHealth(func(h *EngineHealth) {
+			h.Running = true
+			h.Addr = ln.Addr().String()
+			if restarted {
+				h.Restarts++
+			}
+		}, notify)
+		backoff = engineMinBackoff
+		started := time.Now()
+
+		err = http.Serve(ln, handler)
+
+		setEngineHealth(func(h *EngineHealth) {
+			h.Running = false
+			if err != nil {
+				h.LastError = err.Error()
+			}
+		}, notify)
+		if err == nil {
+			return
+		}
+		fmt.Printf("⚠️ Video Engine stopped: %v\n", err)
+		recordEngineError("engine", err.Error())
+
+		// A listener that dies immediately again is treated like a listen failure
+		if time.Since(started) < engineMaxBackoff {
+			time.Sleep(backoff)
+			backoff = min(backoff*2, engineMaxBackoff)
+		}
+	}
+}
+
+// GetEngineHealth returns the media server's current state
+func (a *App) GetEngineHealth() EngineHealth {
+	engineMu.Lock()
+	defer engineMu.Unlock()
+	return engineHealth
+}
+
+// emitEngineHealth forwards supervisor state changes to the frontend
+func (a *App) emitEngineHealth(h EngineHealth) {
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "engine:health", h)
+	}
+}
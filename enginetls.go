@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// --- ENGINE TLS ---
+
+// Serves the TCP engine over HTTPS with a user or self-signed certificate.
+
+const (
+	// certValidity stays under the 825 days macOS accepts for trusted certs
+	certValidity    = 825 * 24 * time.Hour
+	certRenewBefore = 30 * 24 * time.Hour
+)
+
+func (a *App) getTLSDir() string {
+	dir := filepath.Join(a.getAppDir(), "tls")
+	os.MkdirAll(dir, 0700)
+	return dir
+}
+
+// engineCertPaths returns the certificate and key the engine should use
+func (a *App) engineCertPaths(settings EngineSettings) (string, string) {
+	if settings.CertFile != "" && settings.KeyFile != "" {
+		return settings.CertFile, settings.KeyFile
+	}
+	dir := a.getTLSDir()
+	return filepath.Join(dir, "engine.crt"), filepath.Join(dir, "engine.key")
+}
+
+// engineTLSConfig loads the engine certificate, generating the self-signed
+// one when it is missing or about to expire. Returns nil when TLS is off.
+func (a *App) engineTLSConfig(settings EngineSettings) (*tls.Config, error) {
+	if !settings.TLS || settings.Transport == "unix" {
+		return nil, nil
+	}
+	certPath, keyPath := a.engineCertPaths(settings)
+	custom := settings.CertFile != "" && settings.KeyFile != ""
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err == nil && !custom && certExpiresSoon(cert) {
+		err = fmt.Errorf("certificate expires soon")
+	}
+	if err != nil {
+		if custom {
+			return nil, fmt.Errorf("could not load %s: %v", certPath, err)
+		}
+		if err := generateSelfSignedCert(certPath, keyPath); err != nil {
+			return nil, err
+		}
+		if cert, err = tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+			return nil, err
+		}
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func certExpiresSoon(cert tls.Certificate) bool {
+	if len(cert.Certificate) == 0 {
+		return true
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return true
+	}
+	return time.Until(leaf.NotAfter) < certRenewBefore
+}
+
+// generateSelfSignedCert writes a P-256 certificate for the loopback names
+func generateSelfSignedCert(certPath string, keyPath string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "Motion Studio Engine", Organization: []string{"Motion Studio"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+}
+
+// GetEngineCertificate returns the path of the certificate the engine serves,
+// so it can be added to the system trust store
+func (a *App) GetEngineCertificate() string {
+	settings := a.getConfig().Engine
+	if !settings.TLS || settings.Transport == "unix" {
+		return ""
+	}
+	certPath, _ := a.engineCertPaths(settings)
+	if _, err := os.Stat(certPath); err != nil {
+		return ""
+	}
+	return certPath
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// --- CANCELABLE EXPORTS ---
+
+// Tracks each export's ffmpeg processes so CancelExport can stop them.
+
+type exportRun struct {
+	ID string
+
+	mu       sync.Mutex
+	procs    map[*os.Process]bool
+	canceled bool
+}
+
+var (
+	exportRunsMu sync.Mutex
+	exportRuns   = map[string]*exportRun{}
+)
+
+// beginExportRun registers a running export under its journal id
+func beginExportRun(job *ExportJournal) *exportRun {
+	run := &exportRun{ID: fmt.Sprintf("%d", time.Now().UnixNano()), procs: map[*os.Process]bool{}}
+	if job != nil {
+		run.ID = job.ID
+	}
+	exportRunsMu.Lock()
+	exportRuns[run.ID] = run
+	exportRunsMu.Unlock()
+	return run
+}
+
+func endExportRun(run *exportRun) {
+	exportRunsMu.Lock()
+	delete(exportRuns, run.ID)
+	exportRunsMu.Unlock()
+}
+
+// CancelExport stops a running export and discards its intermediates
+func (a *App) CancelExport(jobId string) string {
+	exportRunsMu.Lock()
+	run := exportRuns[jobId]
+	exportRunsMu.Unlock()
+	if run == nil {
+		return tr("export.error.jobNotFound")
+	}
+	run.mu.Lock()
+	run.canceled = true
+	for p := range run.procs {
+		p.Kill()
+	}
+	run.mu.Unlock()
+	return "Success"
+}
+
+func (r *exportRun) isCanceled() bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.canceled
+}
+
+// start is startTracked for a process of the export (r may be nil)
+func (r *exportRun) start(cmd *exec.Cmd) error {
+	if r == nil {
+		return startTracked(cmd)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.canceled {
+		return fmt.Errorf("export canceled")
+	}
+	if err := startTracked(cmd); err != nil {
+		return err
+	}
+	r.procs[cmd.Process] = true
+	return nil
+}
+
+// wait is waitTracked for a process started with start
+func (r *exportRun) wait(cmd *exec.Cmd) error {
+	err := waitTracked(cmd)
+	if r != nil {
+		r.mu.Lock()
+		delete(r.procs, cmd.Process)
+		r.mu.Unlock()
+	}
+	return err
+}
+
+// combinedOutput is combinedOutputTracked for a process of the export
+func (r *exportRun) combinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	var out safeBuffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := r.start(cmd); err != nil {
+		return nil, err
+	}
+	err := r.wait(cmd)
+	return out.Bytes(), err
+}
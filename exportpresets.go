@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// --- EXPORT PRESETS ---
+
+// Named ExportOptions, stored as the "export" presets of presets.go.
+
+type ExportPreset struct {
+	Name    string        `json:"name"`
+	Options ExportOptions `json:"options"`
+}
+
+// storedExportPreset is the file of a preset: the options plus its name
+type storedExportPreset struct {
+	Name string `json:"name,omitempty"`
+	ExportOptions
+}
+
+// exportPresetsSeeded marks a presets folder the defaults were written to
+const exportPresetsSeeded = ".defaults"
+
+var defaultExportPresets = []ExportPreset{
+	{Name: "YouTube 1080p", Options: ExportOptions{Format: "mp4", IncludeVideo: true, IncludeAudio: true, Quality: "high", Width: 1920, Height: 1080}},
+	{Name: "Instagram Reel 9:16", Options: ExportOptions{Format: "mp4", IncludeVideo: true, IncludeAudio: true, Quality: "medium", Width: 1080, Height: 1920, FPS: 30, Fit: FitCrop}},
+	{Name: "ProRes Master", Options: ExportOptions{Format: "mov", IncludeVideo: true, IncludeAudio: true, Quality: "high", VideoCodec: "prores"}},
+	{Name: "Draft Proxy", Options: ExportOptions{Format: "mp4", IncludeVideo: true, IncludeAudio: true, Quality: "low", Width: 960, Height: 540}},
+}
+
+// GetExportPresets returns every export preset by name
+func (a *App) GetExportPresets() []ExportPreset {
+	a.seedExportPresets()
+	presets := []ExportPreset{}
+	for _, name := range a.GetPresets("export") {
+		if stored, err := a.readExportPreset(name); err == nil {
+			if stored.Name == "" {
+				stored.Name = name
+			}
+			presets = append(presets, ExportPreset{Name: stored.Name, Options: stored.ExportOptions})
+		}
+	}
+	return presets
+}
+
+// GetExportPreset returns the options of a named export preset
+func (a *App) GetExportPreset(name string) (ExportOptions, error) {
+	stored, err := a.readExportPreset(name)
+	return stored.ExportOptions, err
+}
+
+func (a *App) readExportPreset(name string) (storedExportPreset, error) {
+	var stored storedExportPreset
+	file, err := presetFileName(name)
+	if err != nil {
+		return stored, err
+	}
+	raw, err := os.ReadFile(filepath.Join(a.getPresetDir("export"), file))
+	if err != nil {
+		return stored, fmt.Errorf("export preset %q not found", name)
+	}
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		return stored, fmt.Errorf("export preset %q is damaged: %v", name, err)
+	}
+	return stored, nil
+}
+
+// SaveExportPreset stores options under name, replacing a preset of that name
+func (a *App) SaveExportPreset(name string, options ExportOptions) string {
+	if options.Format == "" {
+		return "Error: the preset has no format"
+	}
+	if err := a.SavePreset("export", name, storedExportPreset{Name: name, ExportOptions: options}); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Success"
+}
+
+// RenameExportPreset renames an export preset
+func (a *App) RenameExportPreset(name string, newName string) string {
+	options, err := a.GetExportPreset(name)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	if _, err := a.GetExportPreset(newName); err == nil && !strings.EqualFold(newName, name) {
+		return "Error: a preset named " + newName + " already exists"
+	}
+	// Removed first: on case-insensitive disks a case change is the same file
+	a.DeletePreset("export", name)
+	if result := a.SaveExportPreset(newName, options); result != "Success" {
+		a.SaveExportPreset(name, options)
+		return result
+	}
+	return "Success"
+}
+
+// DeleteExportPreset removes an export preset
+func (a *App) DeleteExportPreset(name string) string {
+	if _, err := a.GetExportPreset(name); err != nil {
+		return "Error: " + err.Error()
+	}
+	a.DeletePreset("export", name)
+	return "Success"
+}
+
+// RestoreExportPresets writes the default presets again, replacing edited
+// ones of the same name
+func (a *App) RestoreExportPresets() string {
+	for _, preset := range defaultExportPresets {
+		if result := a.SaveExportPreset(preset.Name, preset.Options); result != "Success" {
+			return result
+		}
+	}
+	return "Success"
+}
+
+// seedExportPresets writes the defaults into a new presets folder
+func (a *App) seedExportPresets() {
+	marker := filepath.Join(a.getPresetDir("export"), exportPresetsSeeded)
+	if _, err := os.Stat(marker); err == nil {
+		return
+	}
+	for _, preset := range defaultExportPresets {
+		if _, err := a.GetExportPreset(preset.Name); err != nil {
+			a.SaveExportPreset(preset.Name, preset.Options)
+		}
+	}
+	os.WriteFile(marker, nil, 0644)
+}
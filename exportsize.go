@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// --- EXPORT SIZE AND FRAME RATE ---
+
+// Scales exports to a target size (letterbox or crop) and frame rate.
+
+const (
+	FitLetterbox = "letterbox" // Fit inside the frame, pad the rest (default)
+	FitCrop      = "crop"      // Fill the frame, cut what overflows
+)
+
+const maxExportSize = 8192
+
+// validateExportSize checks the size, fit and rate of options
+func validateExportSize(options ExportOptions) error {
+	if (options.Width == 0) != (options.Height == 0) {
+		return fmt.Errorf("set both width and height, or neither")
+	}
+	if options.Width < 0 || options.Height < 0 || options.Width > maxExportSize || options.Height > maxExportSize {
+		return fmt.Errorf("size must be between 2 and %d pixels", maxExportSize)
+	}
+	if options.Width%2 != 0 || options.Height%2 != 0 {
+		return fmt.Errorf("width and height must be even")
+	}
+	switch options.Fit {
+	case "", FitLetterbox, FitCrop:
+	default:
+		return fmt.Errorf("unknown fit %q", options.Fit)
+	}
+	if options.FPS < 0 || options.FPS > 240 {
+		return fmt.Errorf("frame rate must be between 1 and 240")
+	}
+	return nil
+}
+
+// exportSizeFilter scales every frame to the target size, "" without one.
+// It runs before the conform filter, which needs frames of one size.
+func exportSizeFilter(options ExportOptions) string {
+	if options.Width == 0 || options.Height == 0 {
+		return ""
+	}
+	w, h := strconv.Itoa(options.Width), strconv.Itoa(options.Height)
+	if options.Fit == FitCrop {
+		return fmt.Sprintf("scale=%s:%s:force_original_aspect_ratio=increase,crop=%s:%s,setsar=1", w, h, w, h)
+	}
+	color := "black"
+	if options.Transparent {
+		color = "black@0" // Keep the bars transparent in alpha WebM
+	}
+	return fmt.Sprintf("scale=%s:%s:force_original_aspect_ratio=decrease,pad=%s:%s:(ow-iw)/2:(oh-ih)/2:color=%s,setsar=1", w, h, w, h, color)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// --- PROMPT FIND & REPLACE ---
+
+// Find and replace across the prompts of a project's shots.
+
+type PromptReplaceOptions struct {
+	Find          string `json:"find"`
+	Replace       string `json:"replace"`
+	SceneID       string `json:"sceneId"` // Empty = every scene in the project
+	CaseSensitive bool   `json:"caseSensitive"`
+	WholeWord     bool   `json:"wholeWord"`
+	MarkStale     bool   `json:"markStale"` // Flag rendered shots for re-render
+}
+
+type PromptMatch struct {
+	SceneID   string `json:"sceneId"`
+	SceneName string `json:"sceneName"`
+	ShotID    string `json:"shotId"`
+	ShotName  string `json:"shotName"`
+	Before    string `json:"before"`
+	After     string `json:"after"`
+	Count     int    `json:"count"`
+	Rendered  bool   `json:"rendered"` // Has an output that no longer matches the prompt
+}
+
+// promptPattern compiles the search term for opts
+func promptPattern(opts PromptReplaceOptions) (*regexp.Regexp, error) {
+	if strings.TrimSpace(opts.Find) == "" {
+		return nil, fmt.Errorf("search text is empty")
+	}
+	expr := regexp.QuoteMeta(opts.Find)
+	if opts.WholeWord {
+		expr = `\b` + expr + `\b`
+	}
+	if !opts.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// promptReplaceScenes returns the scenes opts applies to
+func (a *App) promptReplaceScenes(projectId string, opts PromptReplaceOptions) []Scene {
+	if opts.SceneID != "" {
+		return []Scene{{ID: opts.SceneID, Name: strings.Trim(a.sceneLabel(projectId, opts.SceneID), `"`)}}
+	}
+	return a.GetScenes(projectId)
+}
+
+// findPromptMatches runs the replacement in memory. apply is called for every
+// scene with its shots and the matches in it.
+func (a *App) findPromptMatches(projectId string, opts PromptReplaceOptions, apply func(sceneId string, shots []Shot, matches []PromptMatch)) ([]PromptMatch, error) {
+	re, err := promptPattern(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	all := []PromptMatch{}
+	for _, scene := range a.promptReplaceScenes(projectId, opts) {
+		shots := a.GetShots(projectId, scene.ID)
+		matches := []PromptMatch{}
+		for i := range shots {
+			count := len(re.FindAllStringIndex(shots[i].Prompt, -1))
+			if count == 0 {
+				continue
+			}
+			after := re.ReplaceAllLiteralString(shots[i].Prompt, opts.Replace)
+			matches = append(matches, PromptMatch{
+				SceneID:   scene.ID,
+				SceneName: scene.Name,
+				ShotID:    shots[i].ID,
+				ShotName:  shots[i].Name,
+				Before:    shots[i].Prompt,
+				After:     after,
+				Count:     count,
+				Rendered:  shots[i].OutputVideo != "",
+			})
+			shots[i].Prompt = after
+			if opts.MarkStale && shots[i].OutputVideo != "" {
+				shots[i].Stale = true
+			}
+		}
+		if len(matches) > 0 && apply != nil {
+			apply(scene.ID, shots, matches)
+		}
+		all = append(all, matches...)
+	}
+	return all, nil
+}
+
+// PreviewPromptReplace lists the shots a replacement would change, without saving
+func (a *App) PreviewPromptReplace(projectId string, opts PromptReplaceOptions) ([]PromptMatch, error) {
+	return a.findPromptMatches(projectId, opts, nil)
+}
+
+// ReplaceInPrompts applies the replacement and returns the changed shots
+func (a *App) ReplaceInPrompts(projectId string, opts PromptReplaceOptions) ([]PromptMatch, error) {
+	return a.findPromptMatches(projectId, opts, func(sceneId string, shots []Shot, _ []PromptMatch) {
+		a.SaveShots(projectId, sceneId, shots)
+	})
+}
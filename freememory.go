@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+)
+
+// --- FREE SERVER MEMORY ---
+
+// Asks ComfyUI to unload its models after renders or on demand.
+
+const (
+	FreeMemoryOff    = ""
+	FreeMemoryRender = "render" // After every render
+	FreeMemoryIdle   = "idle"   // When no render is queued or running anymore
+)
+
+// GetFreeMemoryMode returns when server memory is freed after renders
+func (a *App) GetFreeMemoryMode() string {
+	return a.getConfig().FreeMemory
+}
+
+// SetFreeMemoryMode sets when server memory is freed ("", render or idle)
+func (a *App) SetFreeMemoryMode(mode string) string {
+	switch mode {
+	case FreeMemoryOff, FreeMemoryRender, FreeMemoryIdle:
+	default:
+		return "Error: unknown mode " + mode
+	}
+	a.updateConfig(func(c *Config) { c.FreeMemory = mode })
+	return "Success"
+}
+
+// FreeComfyMemory unloads models and frees memory on every render server
+func (a *App) FreeComfyMemory() string {
+	var failed []string
+	for _, slot := range a.renderSlots() {
+		if err := a.freeServerMemory(slot.URL); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", slot.Name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return "Error: " + fmt.Sprint(failed)
+	}
+	return "Success"
+}
+
+func (a *App) freeServerMemory(server string) error {
+	if backend := a.serverBackend(server); backend != nil {
+		return backend.freeMemory(server)
+	}
+	return a.comfyJSONAt(server, "POST", "/free", map[string]bool{"unload_models": true, "free_memory": true}, nil)
+}
+
+// freeAfterRender frees memory after a render on server per the configured mode
+func (a *App) freeAfterRender(server string) {
+	switch a.getConfig().FreeMemory {
+	case FreeMemoryRender:
+	case FreeMemoryIdle:
+		if rendersIdle() {
+			a.FreeComfyMemory() // Every server went idle by now
+		}
+		return
+	default:
+		return
+	}
+	if err := a.freeServerMemory(server); err != nil {
+		fmt.Println("Free memory:", err)
+	}
+}
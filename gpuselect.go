@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// --- GPU SELECTION ---
+
+// Writes the server's or shot's GPU into device inputs of the workflow.
+
+// deviceInputs are input names that select a device
+var deviceInputs = map[string]bool{"device": true, "gpu": true, "gpu_id": true, "device_id": true, "cuda_device": true}
+
+// deviceKey names a device the way torch does ("cuda:0")
+func deviceKey(d GPUDevice) string {
+	return fmt.Sprintf("%s:%d", d.Type, d.Index)
+}
+
+// GetServerDevices lists the compute devices of a ComfyUI server ("" = primary)
+func (a *App) GetServerDevices(server string) ([]GPUDevice, error) {
+	if server == "" {
+		server = a.comfyURL
+	}
+	stats := a.readServerStats(server)
+	if !stats.Online {
+		return []GPUDevice{}, fmt.Errorf("%s", stats.Error)
+	}
+	return stats.Devices, nil
+}
+
+// SetServerDevice sets the device renders on a server use ("" = the workflow's own)
+func (a *App) SetServerDevice(server string, device string) string {
+	device = strings.TrimSpace(device)
+	if device != "" && !validDevice(device) {
+		return "Error: device must look like cuda:0"
+	}
+	found := false
+	a.updateConfig(func(c *Config) {
+		if server == "" || server == a.comfyURL {
+			c.ComfyDevice = device
+			found = true
+		}
+		for i := range c.ComfyServers {
+			if c.ComfyServers[i].URL == server {
+				c.ComfyServers[i].Device = device
+				found = true
+			}
+		}
+	})
+	if !found {
+		return "Error: server not found"
+	}
+	return "Success"
+}
+
+// SetShotDevice overrides the device of one shot's renders ("" = the server's)
+func (a *App) SetShotDevice(projectId string, sceneId string, shotId string, device string) error {
+	device = strings.TrimSpace(device)
+	if device != "" && !validDevice(device) {
+		return fmt.Errorf("device must look like cuda:0")
+	}
+	for _, s := range a.GetShots(projectId, sceneId) {
+		if s.ID == shotId {
+			a.updateShot(projectId, sceneId, shotId, func(s *Shot) { s.Device = device })
+			return nil
+		}
+	}
+	return fmt.Errorf("shot not found")
+}
+
+func validDevice(device string) bool {
+	kind, index, ok := strings.Cut(device, ":")
+	return ok && kind != "" && index != "" && strings.Trim(index, "0123456789") == ""
+}
+
+// renderDevice is the device a shot renders on, "" for the workflow's own
+func (a *App) renderDevice(server string, shot Shot) string {
+	if shot.Device != "" {
+		return shot.Device
+	}
+	cfg := a.getConfig()
+	for _, s := range cfg.ComfyServers {
+		if s.URL == server && s.Device != "" {
+			return s.Device
+		}
+	}
+	if server == a.comfyURL {
+		return cfg.ComfyDevice
+	}
+	return ""
+}
+
+// applyDevice writes the shot's device into a prepared workflow
+func (a *App) applyDevice(server string, shot Shot, workflow map[string]interface{}, result *WorkflowPreview) {
+	device := a.renderDevice(server, shot)
+	if device == "" {
+		return
+	}
+	_, indexText, _ := strings.Cut(device, ":")
+	var index int
+	fmt.Sscan(indexText, &index)
+	info, _ := a.fetchObjectInfo(server) // Without it, go by the current values
+
+	for nodeId, node := range workflow {
+		nodeMap, _ := node.(map[string]interface{})
+		classType, _ := nodeMap["class_type"].(string)
+		inputs, _ := nodeMap["inputs"].(map[string]interface{})
+		def, known := info[classType]
+		_, required := orderedInputs(def.Input.Required)
+		_, optional := orderedInputs(def.Input.Optional)
+
+		for input, current := range inputs {
+			if !deviceInputs[input] && a.nodeRules(classType)[input] != "DEVICE" {
+				continue
+			}
+			if _, isLink := current.([]interface{}); isLink {
+				continue
+			}
+			spec, ok := required[input]
+			if !ok {
+				spec = optional[input]
+			}
+			value, ok := deviceValue(spec, known, current, device, index)
+			if !ok {
+				continue
+			}
+			inputs[input] = value
+			result.inject(nodeId, classType, input, "DEVICE", value)
+		}
+	}
+}
+
+// deviceValue is device in the form an input takes
+func deviceValue(spec json.RawMessage, known bool, current interface{}, device string, index int) (interface{}, bool) {
+	if options := comboOptions(spec); options != nil {
+		for _, o := range options {
+			if s, ok := o.(string); ok && (s == device || strings.HasPrefix(s, device+" ")) {
+				return s, true
+			}
+		}
+		return nil, false
+	}
+	kind := ""
+	if known {
+		var parts []json.RawMessage
+		if json.Unmarshal(spec, &parts) == nil && len(parts) > 0 {
+			json.Unmarshal(parts[0], &kind)
+		}
+	} else {
+		switch v := current.(type) {
+		case float64, int:
+			kind = "INT"
+		case string:
+			if validDevice(v) {
+				kind = "STRING"
+			}
+		}
+	}
+	switch kind {
+	case "INT":
+		return index, true
+	case "STRING":
+		return device, true
+	}
+	return nil, false
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- SAFE AREA GUIDES ---
+
+// Safe area guides and aspect mattes burned into preview frames.
+
+type GuideSettings struct {
+	ActionSafe   bool    `json:"actionSafe"`   // 90% rectangle
+	TitleSafe    bool    `json:"titleSafe"`    // 80% rectangle
+	Center       bool    `json:"center"`       // Center cross
+	Matte        string  `json:"matte"`        // "2.39", "1.85:1", "4:3"... empty = none
+	MatteOpacity float64 `json:"matteOpacity"` // 0-1, 0 = opaque
+}
+
+// GetGuideSettings returns the default overlay used by GetFrameURL
+func (a *App) GetGuideSettings() GuideSettings {
+	return a.getConfig().Guides
+}
+
+// SaveGuideSettings persists the overlay defaults
+func (a *App) SaveGuideSettings(g GuideSettings) string {
+	if g.Matte != "" {
+		if _, err := parseAspectRatio(g.Matte); err != nil {
+			return "Error: " + err.Error()
+		}
+	}
+	a.updateConfig(func(c *Config) { c.Guides = g })
+	return "Success"
+}
+
+// parseAspectRatio accepts "2.39", "2.39:1", "16:9" or "16/9"
+func parseAspectRatio(s string) (float64, error) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "/", ":"))
+	num, den, hasDen := strings.Cut(s, ":")
+	w, err := strconv.ParseFloat(num, 64)
+	if err != nil || w <= 0 {
+		return 0, fmt.Errorf("invalid aspect ratio %q", s)
+	}
+	if !hasDen {
+		return w, nil
+	}
+	h, err := strconv.ParseFloat(den, 64)
+	if err != nil || h <= 0 {
+		return 0, fmt.Errorf("invalid aspect ratio %q", s)
+	}
+	return w / h, nil
+}
+
+// probeVideoSize returns the width and height of the first video stream
+func probeVideoSize(path string) (int, int, error) {
+	out, err := exec.Command("ffprobe",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=width,height",
+		"-of", "csv=s=x:p=0",
+		mediaPath(path),
+	).Output()
+	if err != nil {
+		return 0, 0, err
+	}
+	var w, h int
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(out)), "%dx%d", &w, &h); err != nil || w == 0 || h == 0 {
+		return 0, 0, fmt.Errorf("no video stream in %s", path)
+	}
+	return w, h, nil
+}
+
+// guideFilter returns drawbox filters for a w x h frame (empty if no guides)
+func guideFilter(g GuideSettings, w, h int) string {
+	filters := []string{}
+	box := func(x, y, bw, bh int, color string, thickness string) {
+		if bw <= 0 || bh <= 0 {
+			return
+		}
+		filters = append(filters, fmt.Sprintf("drawbox=x=%d:y=%d:w=%d:h=%d:color=%s:t=%s", x, y, bw, bh, color, thickness))
+	}
+	line := strconv.Itoa(max(1, h/360))
+
+	if g.Matte != "" {
+		if ratio, err := parseAspectRatio(g.Matte); err == nil {
+			opacity := g.MatteOpacity
+			if opacity <= 0 || opacity > 1 {
+				opacity = 1
+			}
+			color := fmt.Sprintf("black@%.2f", opacity)
+			if float64(w)/float64(h) > ratio {
+				bar := (w - int(float64(h)*ratio+0.5)) / 2
+				box(0, 0, bar, h, color, "fill")
+				box(w-bar, 0, bar, h, color, "fill")
+			} else {
+				bar := (h - int(float64(w)/ratio+0.5)) / 2
+				box(0, 0, w, bar, color, "fill")
+				box(0, h-bar, w, bar, color, "fill")
+			}
+		}
+	}
+	if g.ActionSafe {
+		box(w*5/100, h*5/100, w*90/100, h*90/100, "white@0.6", line)
+	}
+	if g.TitleSafe {
+		box(w*10/100, h*10/100, w*80/100, h*80/100, "yellow@0.6", line)
+	}
+	if g.Center {
+		arm := h / 20
+		box(w/2-arm, h/2, arm*2, 1, "white@0.8", "fill")
+		box(w/2, h/2-arm, 1, arm*2, "white@0.8", "fill")
+	}
+	return strings.Join(filters, ",")
+}
+
+// GetFrameURL returns the engine URL for a preview frame at t with the saved
+// guides. width scales the frame down for scrubbing (0 = source size).
+func (a *App) GetFrameURL(path string, t float64, width int) string {
+	g := a.GetGuideSettings()
+	query := url.Values{}
+	query.Set("path", path)
+	query.Set("t", strconv.FormatFloat(t, 'f', 3, 64))
+	if width > 0 {
+		query.Set("w", strconv.Itoa(width))
+	}
+	guides := []string{}
+	if g.ActionSafe {
+		guides = append(guides, "action")
+	}
+	if g.TitleSafe {
+		guides = append(guides, "title")
+	}
+	if g.Center {
+		guides = append(guides, "center")
+	}
+	if len(guides) > 0 {
+		query.Set("guides", strings.Join(guides, ","))
+	}
+	if g.Matte != "" {
+		query.Set("matte", g.Matte)
+		if g.MatteOpacity > 0 {
+			query.Set("opacity", strconv.FormatFloat(g.MatteOpacity, 'f', 2, 64))
+		}
+	}
+	query.Set("v", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	return engineURL("/frame?" + query.Encode())
+}
+
+// renderGuideFrame grabs the frame at t, scaled to width if > 0, with guides
+// drawn on top, and returns it as JPEG bytes
+func renderGuideFrame(path string, t float64, width int, g GuideSettings) ([]byte, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	w, h, err := probeVideoSize(path)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := []string{}
+	if width > 0 && width < w {
+		h = (h * width / w) &^ 1
+		w = width &^ 1
+		filters = append(filters, fmt.Sprintf("scale=%d:%d", w, h))
+	}
+	if overlay := guideFilter(g, w, h); overlay != "" {
+		filters = append(filters, overlay)
+	}
+
+	args := []string{"-v", "error", "-ss", fmt.Sprintf("%f", t), "-i", mediaPath(path), "-frames:v", "1"}
+	if len(filters) > 0 {
+		args = append(args, "-vf", strings.Join(filters, ","))
+	}
+	args = append(args, "-f", "image2pipe", "-vcodec", "mjpeg", "-q:v", "3", "-")
+
+	cmd := exec.Command("ffmpeg", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, stderr.String())
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no frame at %.3fs", t)
+	}
+	return out, nil
+}
+
+// FrameHandler serves /frame?path=<file>&t=<seconds>[&w=<px>][&guides=action,title,center][&matte=2.39][&opacity=0.8]
+func (s *StreamServer) FrameHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	q := r.URL.Query()
+	path := q.Get("path")
+	if path == "" {
+		http.Error(w, "missing path", http.StatusBadRequest)
+		return
+	}
+	t, _ := strconv.ParseFloat(q.Get("t"), 64)
+	width, _ := strconv.Atoi(q.Get("w"))
+
+	g := GuideSettings{Matte: q.Get("matte")}
+	g.MatteOpacity, _ = strconv.ParseFloat(q.Get("opacity"), 64)
+	for _, name := range strings.Split(q.Get("guides"), ",") {
+		switch strings.TrimSpace(name) {
+		case "action":
+			g.ActionSafe = true
+		case "title":
+			g.TitleSafe = true
+		case "center":
+			g.Center = true
+		}
+	}
+
+	jpg, err := renderGuideFrame(path, t, width, g)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(jpg)
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- PROJECT HISTORY (GIT) ---
+
+// Opt-in git repository per project, auto-committing metadata saves.
+
+type HistoryEntry struct {
+	Commit  string `json:"commit"`
+	Message string `json:"message"`
+	Date    string `json:"date"`
+}
+
+const historyGitignore = `# Motion Studio project history: metadata only
+*.mp4
+*.mov
+*.mkv
+*.webm
+*.gif
+*.png
+*.jpg
+*.jpeg
+*.webp
+*.wav
+*.mp3
+*.m4a
+*.flac
+*.ogg
+*.opus
+assets/
+`
+
+// historyDebounce groups rapid autosaves into a single commit
+const historyDebounce = 5 * time.Second
+
+var (
+	historyMu      sync.Mutex
+	historyPending = make(map[string][]string)    // projectId -> queued messages
+	historyTimers  = make(map[string]*time.Timer) // projectId -> debounce timer
+
+	gitLocks sync.Map // projectId -> *sync.Mutex, see lockGit
+)
+
+// lockGit serializes git in a project folder: autosave commits and rollbacks
+// run concurrently and would collide on index.lock. Returns the unlock.
+func lockGit(projectId string) func() {
+	lock, _ := gitLocks.LoadOrStore(projectId, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	return lock.(*sync.Mutex).Unlock
+}
+
+// runGit runs one git command in the project folder under its lock
+func (a *App) runGit(projectId string, args ...string) (string, error) {
+	defer lockGit(projectId)()
+	return a.git(projectId, args...)
+}
+
+// git runs git inside the project folder with a fixed identity so commits
+// work on machines that never configured git. The caller holds lockGit.
+func (a *App) git(projectId string, args ...string) (string, error) {
+	base := []string{
+		"-c", "user.name=Motion Studio",
+		"-c", "user.email=history@motionstudio.local",
+		"-c", "core.autocrlf=false",
+	}
+	cmd := exec.Command("git", append(base, args...)...)
+	cmd.Dir = filepath.Join(a.getAppDir(), projectId)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+func (a *App) historyEnabled(projectId string) bool {
+	if projectId == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(a.getAppDir(), projectId, ".git"))
+	return err == nil
+}
+
+// IsProjectHistoryEnabled reports whether the project has a history repository
+func (a *App) IsProjectHistoryEnabled(projectId string) bool {
+	return a.historyEnabled(projectId)
+}
+
+// EnableProjectHistory initializes the git repository and records the current state
+func (a *App) EnableProjectHistory(projectId string) string {
+	if a.historyEnabled(projectId) {
+		return "Success"
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return tr("history.error.noGit")
+	}
+	projectDir := filepath.Join(a.getAppDir(), projectId)
+	if _, err := os.Stat(filepath.Join(projectDir, "project.json")); err != nil {
+		return tr("history.error.project")
+	}
+
+	if _, err := a.runGit(projectId, "init", "-q"); err != nil {
+		return tr("history.error.git", err.Error())
+	}
+	os.WriteFile(filepath.Join(projectDir, ".gitignore"), []byte(historyGitignore), 0644)
+
+	a.commitHistory(projectId, "Enable project history")
+	return "Success"
+}
+
+// recordHistory queues a change description; the commit happens once saves settle
+func (a *App) recordHistory(projectId string, message string) {
+	if !a.historyEnabled(projectId) {
+		return
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	pending := historyPending[projectId]
+	// Autosave repeats the same message many times; keep each once
+	for _, m := range pending {
+		if m == message {
+			message = ""
+			break
+		}
+	}
+	if message != "" {
+		historyPending[projectId] = append(pending, message)
+	}
+
+	if t, ok := historyTimers[projectId]; ok {
+		t.Stop()
+	}
+	historyTimers[projectId] = time.AfterFunc(historyDebounce, func() {
+		historyMu.Lock()
+		messages := historyPending[projectId]
+		delete(historyPending, projectId)
+		delete(historyTimers, projectId)
+		historyMu.Unlock()
+
+		if len(messages) == 0 {
+			return
+		}
+		subject := messages[0]
+		if len(messages) > 1 {
+			subject = fmt.Sprintf("%s (+%d more changes)", subject, len(messages)-1)
+		}
+		a.commitHistory(projectId, subject+"\n\n"+strings.Join(messages, "\n"))
+	})
+}
+
+// commitHistory stages everything not ignored and commits if anything changed
+func (a *App) commitHistory(projectId string, message string) {
+	defer lockGit(projectId)()
+	a.commitLocked(projectId, message)
+}
+
+// commitLocked is commitHistory for callers holding lockGit
+func (a *App) commitLocked(projectId string, message string) {
+	if _, err := a.git(projectId, "add", "-A"); err != nil {
+		fmt.Println("History:", err)
+		return
+	}
+	// Exit status 0 means nothing staged
+	if _, err := a.git(projectId, "diff", "--cached", "--quiet"); err == nil {
+		return
+	}
+	if _, err := a.git(projectId, "commit", "-q", "-m", message); err != nil {
+		fmt.Println("History:", err)
+	}
+}
+
+// sceneLabel returns the scene's display name for commit messages
+func (a *App) sceneLabel(projectId string, sceneId string) string {
+	var s Scene
+	data, err := os.ReadFile(filepath.Join(a.getAppDir(), projectId, "scenes", sceneId, "scene.json"))
+	if err == nil && json.Unmarshal(data, &s) == nil && s.Name != "" {
+		return fmt.Sprintf("%q", s.Name)
+	}
+	return sceneId
+}
+
+// GetProjectHistory lists recent commits, newest first. path optionally limits
+// the log to one file relative to the project (e.g. "scenes/<id>/shots.json").
+func (a *App) GetProjectHistory(projectId string, path string, limit int) []HistoryEntry {
+	entries := []HistoryEntry{}
+	if !a.historyEnabled(projectId) {
+		return entries
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	args := []string{"log", fmt.Sprintf("-n%d", limit), "--format=%H%x1f%s%x1f%ci"}
+	if path != "" {
+		args = append(args, "--", filepath.ToSlash(path))
+	}
+	out, err := a.runGit(projectId, args...)
+	if err != nil {
+		return entries
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		parts := strings.Split(line, "\x1f")
+		if len(parts) != 3 {
+			continue
+		}
+		entries = append(entries, HistoryEntry{Commit: parts[0], Message: parts[1], Date: parts[2]})
+	}
+	return entries
+}
+
+// GetHistoryDiff returns the unified diff introduced by a commit
+func (a *App) GetHistoryDiff(projectId string, commit string) string {
+	if !a.historyEnabled(projectId) || !isCommitHash(commit) {
+		return ""
+	}
+	out, err := a.runGit(projectId, "show", "--format=", "--patch", commit)
+	if err != nil {
+		return ""
+	}
+	return out
+}
+
+// RollbackProject restores files to their state at commit. An empty path rolls
+// back the whole project; otherwise only that file (shots.json, timeline.json...).
+// Files added after commit are removed; ignored media is left alone. The
+// rollback itself is committed, so it can be undone too.
+func (a *App) RollbackProject(projectId string, commit string, path string) string {
+	if !a.historyEnabled(projectId) {
+		return tr("history.error.disabled")
+	}
+	if !isCommitHash(commit) {
+		return tr("history.error.commit")
+	}
+
+	defer lockGit(projectId)()
+
+	// Flush any pending autosave commit first so it isn't lost
+	a.commitLocked(projectId, "Save before rollback")
+
+	target := "."
+	if path != "" {
+		target = filepath.ToSlash(path)
+	}
+	// Unlike checkout, restore also deletes tracked files the commit lacks
+	if _, err := a.git(projectId, "restore", "--source="+commit, "--staged", "--worktree", "--", target); err != nil {
+		return tr("history.error.git", err.Error())
+	}
+
+	short := commit
+	if len(short) > 8 {
+		short = short[:8]
+	}
+	msg := "Rollback to " + short
+	if path != "" {
+		msg = fmt.Sprintf("Rollback %s to %s", target, short)
+	}
+	a.commitLocked(projectId, msg)
+	return "Success"
+}
+
+func isCommitHash(s string) bool {
+	if len(s) < 4 || len(s) > 64 {
+		return false
+	}
+	for _, r := range s {
+		if !((r >= '0' && r <= '9') || (r >= 'a' && r <= 'f')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// --- GLOBAL HOTKEY ---
+
+// A system-wide shortcut that suspends and resumes heavy jobs.
+
+type HotkeySettings struct {
+	Disabled  bool   `json:"disabled"`
+	PauseJobs string `json:"pauseJobs"` // Empty = default shortcut
+}
+
+const defaultPauseHotkey = "Ctrl+Shift+F12"
+
+var (
+	hotkeyMu         sync.Mutex
+	unregisterHotkey func()
+)
+
+// parseShortcut splits "Ctrl+Shift+F12" into lowercase modifiers and an
+// uppercase key name
+func parseShortcut(shortcut string) ([]string, string, error) {
+	parts := strings.Split(shortcut, "+")
+	if len(parts) < 2 {
+		return nil, "", fmt.Errorf("shortcut %q needs at least one modifier", shortcut)
+	}
+	mods := []string{}
+	for _, p := range parts[:len(parts)-1] {
+		mod := strings.ToLower(strings.TrimSpace(p))
+		switch mod {
+		case "control":
+			mod = "ctrl"
+		case "option":
+			mod = "alt"
+		case "cmd", "command", "win", "meta":
+			mod = "super"
+		}
+		mods = append(mods, mod)
+	}
+	key := strings.ToUpper(strings.TrimSpace(parts[len(parts)-1]))
+	if key == "" {
+		return nil, "", fmt.Errorf("shortcut %q has no key", shortcut)
+	}
+	return mods, key, nil
+}
+
+// GetHotkeySettings returns the configured global shortcuts
+func (a *App) GetHotkeySettings() HotkeySettings {
+	settings := a.getConfig().Hotkeys
+	if settings.PauseJobs == "" {
+		settings.PauseJobs = defaultPauseHotkey
+	}
+	return settings
+}
+
+// SaveHotkeySettings validates, persists and re-registers the shortcuts.
+// Returns "Success" or the reason the shortcut couldn't be registered.
+func (a *App) SaveHotkeySettings(settings HotkeySettings) string {
+	if settings.PauseJobs != "" {
+		if _, _, err := parseShortcut(settings.PauseJobs); err != nil {
+			return "Error: " + err.Error()
+		}
+	}
+	a.updateConfig(func(c *Config) { c.Hotkeys = settings })
+	if err := a.registerHotkeys(); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Success"
+}
+
+// registerHotkeys (re)binds the configured shortcuts
+func (a *App) registerHotkeys() error {
+	hotkeyMu.Lock()
+	defer hotkeyMu.Unlock()
+
+	if unregisterHotkey != nil {
+		unregisterHotkey()
+		unregisterHotkey = nil
+	}
+
+	settings := a.GetHotkeySettings()
+	if settings.Disabled {
+		return nil
+	}
+	mods, key, err := parseShortcut(settings.PauseJobs)
+	if err != nil {
+		return err
+	}
+	unregister, err := registerGlobalHotkey(mods, key, func() { a.ToggleHeavyJobs() })
+	if err != nil {
+		fmt.Println("Hotkey:", err)
+		recordEngineError("hotkey", err.Error())
+		return err
+	}
+	unregisterHotkey = unregister
+	return nil
+}
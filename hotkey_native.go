@@ -0,0 +1,61 @@
+//go:build windows || darwin
+
+package main
+
+import (
+	"fmt"
+
+	"golang.design/x/hotkey"
+)
+
+var hotkeyKeys = map[string]hotkey.Key{
+	"A": hotkey.KeyA, "B": hotkey.KeyB, "C": hotkey.KeyC, "D": hotkey.KeyD, "E": hotkey.KeyE,
+	"F": hotkey.KeyF, "G": hotkey.KeyG, "H": hotkey.KeyH, "I": hotkey.KeyI, "J": hotkey.KeyJ,
+	"K": hotkey.KeyK, "L": hotkey.KeyL, "M": hotkey.KeyM, "N": hotkey.KeyN, "O": hotkey.KeyO,
+	"P": hotkey.KeyP, "Q": hotkey.KeyQ, "R": hotkey.KeyR, "S": hotkey.KeyS, "T": hotkey.KeyT,
+	"U": hotkey.KeyU, "V": hotkey.KeyV, "W": hotkey.KeyW, "X": hotkey.KeyX, "Y": hotkey.KeyY,
+	"Z": hotkey.KeyZ,
+	"0": hotkey.Key0, "1": hotkey.Key1, "2": hotkey.Key2, "3": hotkey.Key3, "4": hotkey.Key4,
+	"5": hotkey.Key5, "6": hotkey.Key6, "7": hotkey.Key7, "8": hotkey.Key8, "9": hotkey.Key9,
+	"F1": hotkey.KeyF1, "F2": hotkey.KeyF2, "F3": hotkey.KeyF3, "F4": hotkey.KeyF4,
+	"F5": hotkey.KeyF5, "F6": hotkey.KeyF6, "F7": hotkey.KeyF7, "F8": hotkey.KeyF8,
+	"F9": hotkey.KeyF9, "F10": hotkey.KeyF10, "F11": hotkey.KeyF11, "F12": hotkey.KeyF12,
+	"SPACE": hotkey.KeySpace,
+}
+
+// registerGlobalHotkey binds a system-wide shortcut and calls fn on key down
+func registerGlobalHotkey(mods []string, key string, fn func()) (func(), error) {
+	k, ok := hotkeyKeys[key]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hotkey key %q", key)
+	}
+	var modifiers []hotkey.Modifier
+	for _, m := range mods {
+		mod, ok := hotkeyModifiers[m]
+		if !ok {
+			return nil, fmt.Errorf("unsupported hotkey modifier %q", m)
+		}
+		modifiers = append(modifiers, mod)
+	}
+
+	hk := hotkey.New(modifiers, k)
+	if err := hk.Register(); err != nil {
+		return nil, fmt.Errorf("could not register %s: %v", hk, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-hk.Keydown():
+				fn()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		hk.Unregister()
+	}, nil
+}
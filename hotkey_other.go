@@ -0,0 +1,42 @@
+//go:build !windows && !darwin
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// X11 key grabs don't work under Wayland and the hotkey library aborts
+// without a display, so Linux relies on the desktop environment instead:
+// bind a custom shortcut to `pkill -USR1 motion-studio`.
+
+var (
+	toggleSignalOnce sync.Once
+	toggleSignalMu   sync.Mutex
+	toggleSignalFn   func() // Latest callback; the listener starts only once
+)
+
+func registerGlobalHotkey(mods []string, key string, fn func()) (func(), error) {
+	toggleSignalMu.Lock()
+	toggleSignalFn = fn
+	toggleSignalMu.Unlock()
+	toggleSignalOnce.Do(func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGUSR1)
+		go func() {
+			for range ch {
+				toggleSignalMu.Lock()
+				fn := toggleSignalFn
+				toggleSignalMu.Unlock()
+				if fn != nil {
+					fn()
+				}
+			}
+		}()
+	})
+	return nil, fmt.Errorf("global shortcuts are not supported on this platform; bind pkill -USR1 motion-studio")
+}
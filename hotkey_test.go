@@ -0,0 +1,43 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseShortcut(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantMods []string
+		wantKey  string
+		wantErr  bool
+	}{
+		{in: "Ctrl+Shift+F12", wantMods: []string{"ctrl", "shift"}, wantKey: "F12"},
+		{in: "ctrl+p", wantMods: []string{"ctrl"}, wantKey: "P"},
+		{in: " Control + Option + space ", wantMods: []string{"ctrl", "alt"}, wantKey: "SPACE"},
+		{in: "Cmd+1", wantMods: []string{"super"}, wantKey: "1"},
+		{in: "Command+K", wantMods: []string{"super"}, wantKey: "K"},
+		{in: "Win+K", wantMods: []string{"super"}, wantKey: "K"},
+		{in: "Meta+K", wantMods: []string{"super"}, wantKey: "K"},
+		{in: "F12", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "Ctrl+", wantErr: true},
+		{in: "Ctrl+ ", wantErr: true},
+	}
+	for _, tt := range tests {
+		mods, key, err := parseShortcut(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseShortcut(%q) = %q, %q, want error", tt.in, mods, key)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseShortcut(%q) error: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(mods, tt.wantMods) || key != tt.wantKey {
+			t.Errorf("parseShortcut(%q) = %q, %q, want %q, %q", tt.in, mods, key, tt.wantMods, tt.wantKey)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// --- HARDWARE EXPORT ENCODERS ---
+
+// GPU encoders for exports (NVENC, QuickSync, AMF, VideoToolbox), probed before use.
+
+type ExportEncoder struct {
+	ID      string   `json:"id"`      // Value of ExportOptions.Encoder
+	Name    string   `json:"name"`    // For the UI
+	Formats []string `json:"formats"` // Export formats it encodes; empty = all
+}
+
+const (
+	EncoderSoftware     = ""
+	EncoderNVENC        = "nvenc"
+	EncoderQSV          = "qsv"
+	EncoderAMF          = "amf"
+	EncoderVideoToolbox = "videotoolbox"
+)
+
+// hardwareEncoders in the order they are offered, with their ffmpeg encoders
+var hardwareEncoders = []struct {
+	ExportEncoder
+	h264   string
+	prores string
+}{
+	{ExportEncoder{ID: EncoderNVENC, Name: "NVIDIA NVENC"}, "h264_nvenc", ""},
+	{ExportEncoder{ID: EncoderQSV, Name: "Intel Quick Sync"}, "h264_qsv", ""},
+	{ExportEncoder{ID: EncoderAMF, Name: "AMD AMF"}, "h264_amf", ""},
+	{ExportEncoder{ID: EncoderVideoToolbox, Name: "Apple VideoToolbox"}, "h264_videotoolbox", "prores_videotoolbox"},
+}
+
+var (
+	exportEncodersMu sync.Mutex
+	exportEncoders   []ExportEncoder // nil until ffmpeg could be asked
+)
+
+// GetExportEncoders lists the encoders exports can use on this machine,
+// software first. A failed probe (ffmpeg missing) isn't kept, so installing
+// ffmpeg later brings the hardware encoders back.
+func (a *App) GetExportEncoders() []ExportEncoder {
+	exportEncodersMu.Lock()
+	defer exportEncodersMu.Unlock()
+	if exportEncoders != nil {
+		return exportEncoders
+	}
+
+	encoders := []ExportEncoder{{ID: EncoderSoftware, Name: "Software (CPU)"}}
+	out, err := exec.Command("ffmpeg", "-hide_banner", "-encoders").Output()
+	if err != nil {
+		return encoders
+	}
+	listed := map[string]bool{}
+	for _, line := range strings.Split(string(out), "\n") {
+		// " V....D h264_nvenc  NVIDIA NVENC H.264 encoder"
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			listed[fields[1]] = true
+		}
+	}
+	for _, hw := range hardwareEncoders {
+		encoder := hw.ExportEncoder
+		if listed[hw.h264] && encoderWorks(hw.h264) {
+			encoder.Formats = append(encoder.Formats, "mp4", "mkv")
+		}
+		if hw.prores != "" && listed[hw.prores] && encoderWorks(hw.prores) {
+			encoder.Formats = append(encoder.Formats, "mov")
+		}
+		if len(encoder.Formats) > 0 {
+			encoders = append(encoders, encoder)
+		}
+	}
+	exportEncoders = encoders
+	return exportEncoders
+}
+
+// encoderWorks encodes a few frames with an ffmpeg encoder, which fails when
+// the GPU or its driver is missing
+func encoderWorks(encoder string) bool {
+	cmd := exec.Command("ffmpeg", "-hide_banner", "-v", "error",
+		"-f", "lavfi", "-i", "color=black:s=256x256:r=25:d=0.2",
+		"-c:v", encoder, "-f", "null", "-")
+	return runTracked(cmd) == nil
+}
+
+// exportEncoderFor checks that options.Encoder is available on this machine.
+// Formats it doesn't encode (ProRes on NVENC, DNxHR, WebM) fall back to software.
+func (a *App) exportEncoderFor(options ExportOptions) (string, error) {
+	if options.Encoder == EncoderSoftware || !hardwareFormat(options) {
+		return EncoderSoftware, nil
+	}
+	known := false
+	for _, hw := range hardwareEncoders {
+		known = known || hw.ID == options.Encoder
+	}
+	if !known {
+		return "", fmt.Errorf("unknown encoder %q", options.Encoder)
+	}
+	for _, encoder := range a.GetExportEncoders() {
+		if encoder.ID == options.Encoder {
+			for _, format := range encoder.Formats {
+				if format == options.Format {
+					return encoder.ID, nil
+				}
+			}
+			return EncoderSoftware, nil
+		}
+	}
+	return "", fmt.Errorf("%s isn't available on this machine", options.Encoder)
+}
+
+// hardwareFormat reports whether options select an H.264 or ProRes export
+func hardwareFormat(options ExportOptions) bool {
+	switch options.Format {
+	case "mp4", "mkv":
+		return true
+	case "mov":
+		// prores_videotoolbox has no 4444 profile, so alpha stays on the CPU
+		return options.VideoCodec != "dnxhr" && !options.Transparent
+	}
+	return false
+}
+
+// hardwareVideoArgs returns the encoder arguments of a hardware export
+func hardwareVideoArgs(encoder string, options ExportOptions) []string {
+	quality := options.Quality
+	if quality != "high" && quality != "low" {
+		quality = "medium"
+	}
+
+	if options.Format == "mov" {
+		// Same profiles as prores_ks: HQ, standard, proxy
+		profile := map[string]string{"high": "3", "medium": "2", "low": "0"}[quality]
+		return []string{"-c:v", "prores_videotoolbox", "-profile:v", profile}
+	}
+
+	switch encoder {
+	case EncoderNVENC:
+		// Constant quality, no bitrate target
+		cq := map[string]string{"high": "19", "medium": "24", "low": "30"}[quality]
+		return []string{"-c:v", "h264_nvenc", "-preset", "p5", "-tune", "hq", "-rc", "vbr", "-cq", cq, "-b:v", "0", "-pix_fmt", "yuv420p"}
+	case EncoderQSV:
+		// ICQ mode; QuickSync wants NV12 input
+		q := map[string]string{"high": "18", "medium": "23", "low": "29"}[quality]
+		return []string{"-c:v", "h264_qsv", "-preset", "medium", "-global_quality", q, "-pix_fmt", "nv12"}
+	case EncoderAMF:
+		// AMF has no constant-quality mode, fixed QPs come closest
+		qp := map[string]string{"high": "18", "medium": "23", "low": "29"}[quality]
+		return []string{"-c:v", "h264_amf", "-quality", "quality", "-rc", "cqp",
+			"-qp_i", qp, "-qp_p", qp, "-qp_b", qp, "-pix_fmt", "yuv420p"}
+	case EncoderVideoToolbox:
+		// -q:v is 1-100, higher is better (Apple Silicon)
+		q := map[string]string{"high": "70", "medium": "55", "low": "40"}[quality]
+		return []string{"-c:v", "h264_videotoolbox", "-q:v", q, "-pix_fmt", "yuv420p"}
+	}
+	return nil
+}
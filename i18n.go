@@ -0,0 +1,195 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- LOCALIZATION ---
+
+// Translated strings from the embedded locales/*.json catalogs.
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+const fallbackLanguage = "en"
+
+type LanguageInfo struct {
+	Code string `json:"code"`
+	Name string `json:"name"` // Native name from the catalog's "language.name"
+}
+
+var (
+	catalogsOnce sync.Once
+	catalogs     map[string]map[string]string
+
+	languageMu      sync.RWMutex
+	currentLanguage = fallbackLanguage
+)
+
+func loadCatalogs() map[string]map[string]string {
+	catalogsOnce.Do(func() {
+		catalogs = map[string]map[string]string{}
+		entries, _ := localeFS.ReadDir("locales")
+		for _, e := range entries {
+			data, err := localeFS.ReadFile(path.Join("locales", e.Name()))
+			if err != nil {
+				continue
+			}
+			strs := map[string]string{}
+			if err := json.Unmarshal(data, &strs); err != nil {
+				fmt.Println("Locale error:", e.Name(), err)
+				continue
+			}
+			catalogs[strings.TrimSuffix(e.Name(), ".json")] = strs
+		}
+	})
+	return catalogs
+}
+
+// normalizeLanguage turns "de_DE.UTF-8" or "pt_br" into "de-DE" / "pt-BR"
+func normalizeLanguage(lang string) string {
+	lang, _, _ = strings.Cut(lang, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+	lang = strings.ReplaceAll(lang, "_", "-")
+	base, region, hasRegion := strings.Cut(lang, "-")
+	base = strings.ToLower(base)
+	if base == "" || base == "c" || base == "posix" {
+		return ""
+	}
+	if hasRegion {
+		return base + "-" + strings.ToUpper(region)
+	}
+	return base
+}
+
+// languageChain returns the lookup order for lang
+func languageChain(lang string) []string {
+	chain := []string{}
+	if lang != "" {
+		chain = append(chain, lang)
+		if base, _, ok := strings.Cut(lang, "-"); ok {
+			chain = append(chain, base)
+		}
+	}
+	if lang != fallbackLanguage {
+		chain = append(chain, fallbackLanguage)
+	}
+	return chain
+}
+
+// systemLanguage reads the locale from the environment (empty if unknown)
+func systemLanguage() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if lang := normalizeLanguage(os.Getenv(key)); lang != "" {
+			return lang
+		}
+	}
+	return ""
+}
+
+// applyLanguage makes lang (or the system language when empty) active for tr
+func applyLanguage(lang string) {
+	lang = normalizeLanguage(lang)
+	if lang == "" {
+		lang = systemLanguage()
+	}
+	if lang == "" {
+		lang = fallbackLanguage
+	}
+	languageMu.Lock()
+	currentLanguage = lang
+	languageMu.Unlock()
+}
+
+// tr looks up key in the active language and formats it with args
+func tr(key string, args ...interface{}) string {
+	languageMu.RLock()
+	lang := currentLanguage
+	languageMu.RUnlock()
+
+	all := loadCatalogs()
+	format := key
+	for _, code := range languageChain(lang) {
+		if s, ok := all[code][key]; ok {
+			format = s
+			break
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// GetLanguage returns the configured language ("" = follow the system)
+func (a *App) GetLanguage() string {
+	return a.getConfig().Language
+}
+
+// GetActiveLanguage returns the language strings are currently served in
+func (a *App) GetActiveLanguage() string {
+	languageMu.RLock()
+	defer languageMu.RUnlock()
+	return currentLanguage
+}
+
+// SetLanguage stores the language setting and switches the backend strings.
+// Pass "" to follow the system locale.
+func (a *App) SetLanguage(lang string) string {
+	lang = normalizeLanguage(lang)
+	if lang != "" {
+		if _, ok := loadCatalogs()[lang]; !ok {
+			if base, _, _ := strings.Cut(lang, "-"); loadCatalogs()[base] == nil {
+				return "Unsupported language"
+			}
+		}
+	}
+	a.updateConfig(func(c *Config) { c.Language = lang })
+	applyLanguage(lang)
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "language:changed", a.GetActiveLanguage())
+	}
+	return "Success"
+}
+
+// GetLanguages lists the locales that have a catalog
+func (a *App) GetLanguages() []LanguageInfo {
+	all := loadCatalogs()
+	langs := make([]LanguageInfo, 0, len(all))
+	for code, strs := range all {
+		name := strs["language.name"]
+		if name == "" {
+			name = code
+		}
+		langs = append(langs, LanguageInfo{Code: code, Name: name})
+	}
+	sort.Slice(langs, func(i, j int) bool { return langs[i].Code < langs[j].Code })
+	return langs
+}
+
+// GetStringCatalog returns the merged catalog for lang (active language when
+// empty), with fallbacks already applied, so the UI can share the strings
+func (a *App) GetStringCatalog(lang string) map[string]string {
+	lang = normalizeLanguage(lang)
+	if lang == "" {
+		lang = a.GetActiveLanguage()
+	}
+	all := loadCatalogs()
+	merged := map[string]string{}
+	chain := languageChain(lang)
+	for i := len(chain) - 1; i >= 0; i-- {
+		for k, v := range all[chain[i]] {
+			merged[k] = v
+		}
+	}
+	return merged
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- IMAGE PREPARATION ---
+
+// Straightens, crops and downsizes imported images.
+
+type ImagePrepOptions struct {
+	AutoRotate    bool `json:"autoRotate"`    // Apply the EXIF orientation
+	CropToProject bool `json:"cropToProject"` // Centre crop to the project aspect
+	MaxDimension  int  `json:"maxDimension"`  // Longest side in px, 0 = no limit
+}
+
+// GetImagePrepSettings returns the transforms ImportImage applies
+func (a *App) GetImagePrepSettings() ImagePrepOptions {
+	return a.getConfig().ImagePrep
+}
+
+// SaveImagePrepSettings stores the default import transforms
+func (a *App) SaveImagePrepSettings(opts ImagePrepOptions) string {
+	if opts.MaxDimension < 0 {
+		opts.MaxDimension = 0
+	}
+	a.updateConfig(func(c *Config) { c.ImagePrep = opts })
+	return "Success"
+}
+
+// ImportImageWithOptions is ImportImage with explicit transforms instead of
+// the saved defaults
+func (a *App) ImportImageWithOptions(projectId string, opts ImagePrepOptions) string {
+	srcPath := a.SelectImage()
+	if srcPath == "" {
+		return ""
+	}
+	return a.importImageFile(projectId, srcPath, opts)
+}
+
+// importImageFile copies srcPath into the project assets under a unique name,
+// applying opts on the way. Returns the new path, or "" on failure.
+func (a *App) importImageFile(projectId string, srcPath string, opts ImagePrepOptions) string {
+	assetsDir := filepath.Join(a.getAppDir(), projectId, "assets")
+	os.MkdirAll(assetsDir, 0755)
+
+	// Unique filename so importing "image.png" twice doesn't overwrite
+	ext := filepath.Ext(srcPath)
+	destPath := filepath.Join(assetsDir, fmt.Sprintf("%d%s", time.Now().UnixNano(), ext))
+
+	filter, err := a.imagePrepFilter(projectId, srcPath, opts)
+	if err != nil {
+		fmt.Println("Image prep skipped:", err)
+		runtime.EventsEmit(a.ctx, "import:warning", tr("imageprep.warning", err.Error()))
+	}
+	if filter == "" {
+		if err := copyFile(srcPath, destPath); err != nil {
+			return ""
+		}
+		return destPath
+	}
+
+	args := []string{"-y", "-noautorotate", "-i", mediaPath(srcPath), "-vf", filter, "-frames:v", "1"}
+	if e := strings.ToLower(ext); e == ".jpg" || e == ".jpeg" {
+		args = append(args, "-q:v", "2")
+	}
+	args = append(args, mediaPath(destPath))
+	if out, err := exec.Command("ffmpeg", args...).CombinedOutput(); err != nil {
+		fmt.Printf("Image prep failed, importing original: %v: %s\n", err, string(out))
+		runtime.EventsEmit(a.ctx, "import:warning", tr("imageprep.warning", err.Error()))
+		os.Remove(destPath)
+		if err := copyFile(srcPath, destPath); err != nil {
+			return ""
+		}
+	}
+	return destPath
+}
+
+// imagePrepFilter builds the ffmpeg filter chain for opts (empty if the image
+// can be copied as is)
+func (a *App) imagePrepFilter(projectId string, path string, opts ImagePrepOptions) (string, error) {
+	if !opts.AutoRotate && !opts.CropToProject && opts.MaxDimension <= 0 {
+		return "", nil
+	}
+	w, h, err := probeVideoSize(path)
+	if err != nil {
+		return "", err
+	}
+
+	filters := []string{}
+	if opts.AutoRotate {
+		if rotate, swap := orientationFilter(readExifOrientation(path)); rotate != "" {
+			filters = append(filters, rotate)
+			if swap {
+				w, h = h, w
+			}
+		}
+	}
+
+	if opts.CropToProject {
+		// Without its project the aspect to crop to is unknown
+		if project, err := a.GetProject(projectId); err != nil {
+			fmt.Println("Image prep: crop skipped:", err)
+		} else {
+			pw, ph := previewSize(project)
+			ratio := float64(pw) / float64(ph)
+			cw, ch := w, h
+			if float64(w)/float64(h) > ratio {
+				cw = int(float64(h)*ratio) &^ 1
+			} else {
+				ch = int(float64(w)/ratio) &^ 1
+			}
+			if cw != w || ch != h {
+				filters = append(filters, fmt.Sprintf("crop=%d:%d", cw, ch))
+				w, h = cw, ch
+			}
+		}
+	}
+
+	if opts.MaxDimension > 0 && max(w, h) > opts.MaxDimension {
+		if w >= h {
+			h = (h * opts.MaxDimension / w) &^ 1
+			w = opts.MaxDimension &^ 1
+		} else {
+			w = (w * opts.MaxDimension / h) &^ 1
+			h = opts.MaxDimension &^ 1
+		}
+		filters = append(filters, fmt.Sprintf("scale=%d:%d:flags=lanczos", w, h))
+	}
+	return strings.Join(filters, ","), nil
+}
+
+// orientationFilter maps an EXIF orientation (1-8) to ffmpeg filters and
+// reports whether width and height swap
+func orientationFilter(orientation int) (string, bool) {
+	switch orientation {
+	case 2:
+		return "hflip", false
+	case 3:
+		return "hflip,vflip", false
+	case 4:
+		return "vflip", false
+	case 5:
+		return "transpose=0", true
+	case 6:
+		return "transpose=1", true
+	case 7:
+		return "transpose=3", true
+	case 8:
+		return "transpose=2", true
+	}
+	return "", false
+}
+
+// readExifOrientation returns the EXIF orientation of a JPEG (1 if absent)
+func readExifOrientation(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 1
+	}
+	defer f.Close()
+
+	head := make([]byte, 64*1024)
+	n, _ := io.ReadFull(f, head)
+	data := head[:n]
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	// Walk the JPEG markers to APP1 "Exif"
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || size < 2 || i+2+size > len(data) {
+			return 1 // Start of scan: no EXIF before the image data
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+// tiffOrientation reads tag 0x0112 from IFD0 of a TIFF/EXIF block
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < count; e++ {
+		entry := ifd + 2 + e*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
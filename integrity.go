@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// --- MEDIA INTEGRITY ---
+
+// Checks rendered shots and exports with ffprobe and a decode pass.
+
+type MediaCheck struct {
+	Path     string   `json:"path"`
+	OK       bool     `json:"ok"`
+	Size     int64    `json:"size"`
+	Duration float64  `json:"duration"`
+	HasVideo bool     `json:"hasVideo"`
+	HasAudio bool     `json:"hasAudio"`
+	Problems []string `json:"problems"`
+}
+
+// MediaExpectation describes what a file should contain; zero values are not checked
+type MediaExpectation struct {
+	Duration   float64
+	Video      bool
+	Audio      bool
+	FullDecode bool
+}
+
+// verifyDecodeWindow is how many seconds of head and tail a quick check decodes
+const verifyDecodeWindow = 2.0
+
+// durationSlack is how far the duration may drift: container rounding and
+// audio priming add a few frames, a truncated file misses far more
+func durationSlack(expected float64) float64 {
+	return math.Max(0.5, expected*0.02)
+}
+
+func (c *MediaCheck) fail(format string, args ...interface{}) {
+	c.Problems = append(c.Problems, fmt.Sprintf(format, args...))
+}
+
+// verifyMedia checks that a file is complete and playable
+func verifyMedia(path string, expect MediaExpectation) MediaCheck {
+	check := MediaCheck{Path: path, Problems: []string{}}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		check.fail("file is missing")
+		return check
+	}
+	check.Size = info.Size()
+	if check.Size == 0 {
+		check.fail("file is empty")
+		return check
+	}
+
+	var probe struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("ffprobe",
+		"-v", "error",
+		"-show_entries", "stream=codec_type:format=duration",
+		"-of", "json",
+		mediaPath(path))
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil || json.Unmarshal(out, &probe) != nil {
+		check.fail("container is unreadable: %s", strings.TrimSpace(stderr.String()))
+		return check
+	}
+	for _, s := range probe.Streams {
+		check.HasVideo = check.HasVideo || s.CodecType == "video"
+		check.HasAudio = check.HasAudio || s.CodecType == "audio"
+	}
+	check.Duration, _ = strconv.ParseFloat(probe.Format.Duration, 64)
+
+	if expect.Video && !check.HasVideo {
+		check.fail("no video stream")
+	}
+	if expect.Audio && !check.HasAudio {
+		check.fail("no audio stream")
+	}
+	if expect.Duration > 0 && math.Abs(check.Duration-expect.Duration) > durationSlack(expect.Duration) {
+		check.fail("duration is %.2fs, expected %.2fs", check.Duration, expect.Duration)
+	}
+
+	// Decode errors are what a player would choke on
+	type window struct{ input, output []string }
+	windows := []window{{}}
+	if !expect.FullDecode && check.Duration > 2*verifyDecodeWindow {
+		windows = []window{
+			{output: []string{"-t", fmt.Sprintf("%f", verifyDecodeWindow)}},
+			{input: []string{"-sseof", fmt.Sprintf("%f", -verifyDecodeWindow)}},
+		}
+	}
+	for _, w := range windows {
+		args := append([]string{"-v", "error"}, w.input...)
+		args = append(args, "-i", mediaPath(path))
+		args = append(args, w.output...)
+		args = append(args, "-f", "null", "-")
+
+		var decodeErr bytes.Buffer
+		cmd := exec.Command("ffmpeg", args...)
+		cmd.Stderr = &decodeErr
+		err := runTracked(cmd)
+		msg := strings.TrimSpace(decodeErr.String())
+		if err != nil || msg != "" {
+			if msg == "" {
+				msg = err.Error()
+			}
+			if first, _, found := strings.Cut(msg, "\n"); found {
+				msg = first
+			}
+			check.fail("decode error: %s", msg)
+			break
+		}
+	}
+
+	check.OK = len(check.Problems) == 0
+	return check
+}
+
+// summary joins the problems for status strings and errors
+func (c MediaCheck) summary() string {
+	return strings.Join(c.Problems, "; ")
+}
+
+// VerifyMedia fully checks a media file on demand (e.g. before delivery)
+func (a *App) VerifyMedia(path string) MediaCheck {
+	a.beginForeground()
+	defer a.endForeground()
+	return verifyMedia(path, MediaExpectation{FullDecode: true})
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- FRAME INTERPOLATION ---
+
+// Interpolates render outputs to a higher frame rate through ComfyUI or ffmpeg.
+
+type InterpolateSettings struct {
+	Factor   int    `json:"factor"`   // 2 or 4; 0 = off
+	Workflow string `json:"workflow"` // ComfyUI workflow; "" = ffmpeg
+}
+
+// SetWorkflowInterpolation sets how a workflow's outputs are interpolated
+func (a *App) SetWorkflowInterpolation(name string, settings InterpolateSettings) string {
+	if settings.Factor != 0 && settings.Factor != 2 && settings.Factor != 4 {
+		return "Error: factor must be 2 or 4 (0 turns it off)"
+	}
+	if _, err := os.Stat(filepath.Join(a.getWorkflowsDir(), name+".json")); err != nil {
+		return "Workflow not found"
+	}
+	if settings.Workflow != "" {
+		if settings.Workflow == name {
+			return "Error: a workflow can't interpolate its own output"
+		}
+		if _, err := os.Stat(filepath.Join(a.getWorkflowsDir(), settings.Workflow+".json")); err != nil {
+			return "Error: interpolation workflow not found"
+		}
+	}
+	meta := a.loadWorkflowMeta(name)
+	meta.Interpolate = settings
+	if err := a.saveWorkflowMeta(name, meta); err != nil {
+		return "Error saving workflow info"
+	}
+	return "Success"
+}
+
+// interpolateOutput replaces a downloaded render with its interpolated
+// version when the workflow asks for one. fps is the rate the shot rendered
+// at, used when the file doesn't carry one.
+func (a *App) interpolateOutput(server string, shotId string, workflowName string, path string, fps float64) {
+	settings := a.loadWorkflowMeta(workflowName).Interpolate
+	if settings.Factor < 2 {
+		return
+	}
+	emit := func(status string, err error) {
+		event := map[string]interface{}{"shotId": shotId, "status": status, "factor": settings.Factor}
+		if err != nil {
+			event["error"] = err.Error()
+		}
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "render:interpolate", event)
+		}
+	}
+
+	emit("started", nil)
+	source := a.getVideoFrameRate(path)
+	if source <= 0 {
+		source = fps
+	}
+	target := source * float64(settings.Factor)
+	tmp := strings.TrimSuffix(path, filepath.Ext(path)) + "_interp.mp4"
+	var err error
+	if settings.Workflow != "" {
+		err = a.runVideoWorkflow(server, shotId, settings.Workflow, path, tmp, map[string]interface{}{
+			"MULTIPLIER": settings.Factor,
+			"FRAME_RATE": target,
+		})
+	} else {
+		err = interpolateWithFFmpeg(path, tmp, source, target)
+	}
+	if err == nil {
+		if check := verifyMedia(tmp, MediaExpectation{Video: true, FullDecode: true}); !check.OK {
+			err = fmt.Errorf("interpolated output is corrupt: %s", check.summary())
+		}
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		recordEngineError("interpolate", err.Error())
+		emit("failed", err)
+		return
+	}
+	emit("done", nil)
+}
+
+func interpolateWithFFmpeg(in string, out string, source float64, target float64) error {
+	filter := fmt.Sprintf("minterpolate=fps=%g:mi_mode=mci:mc_mode=aobmc:vsbmc=1,format=yuv420p", target)
+	cmd := exec.Command("ffmpeg", "-y", "-r", fmt.Sprintf("%g", source), "-i", mediaPath(in),
+		"-vf", filter,
+		"-c:v", "libx264", "-preset", "fast", "-crf", "16",
+		"-c:a", "copy", "-movflags", "+faststart",
+		out)
+	if output, err := combinedOutputTracked(cmd); err != nil {
+		return fmt.Errorf("%v: %s", err, string(output))
+	}
+	return nil
+}
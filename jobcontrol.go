@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- HEAVY JOB SUSPENSION ---
+
+// Suspends and resumes running ffmpeg processes of exports and previews.
+
+var (
+	heavyMu         sync.Mutex
+	heavyProcs      = map[*os.Process]bool{}
+	jobsSuspended   bool
+	pausedBySuspend bool // Queue was paused as part of the suspend, undo on resume
+)
+
+// startTracked starts cmd and registers it as suspendable heavy work
+func startTracked(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	heavyMu.Lock()
+	heavyProcs[cmd.Process] = true
+	if jobsSuspended {
+		suspendProcess(cmd.Process)
+	}
+	heavyMu.Unlock()
+	return nil
+}
+
+// waitTracked waits for a command started with startTracked
+func waitTracked(cmd *exec.Cmd) error {
+	err := cmd.Wait()
+	heavyMu.Lock()
+	delete(heavyProcs, cmd.Process)
+	heavyMu.Unlock()
+	return err
+}
+
+// runTracked is cmd.Run for heavy work
+func runTracked(cmd *exec.Cmd) error {
+	if err := startTracked(cmd); err != nil {
+		return err
+	}
+	return waitTracked(cmd)
+}
+
+// combinedOutputTracked is cmd.CombinedOutput for heavy work
+func combinedOutputTracked(cmd *exec.Cmd) ([]byte, error) {
+	var out safeBuffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := runTracked(cmd)
+	return out.Bytes(), err
+}
+
+// safeBuffer collects stdout and stderr written from separate goroutines
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+func (b *safeBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.buf)
+}
+
+func (b *safeBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf
+}
+
+// SuspendHeavyJobs freezes running exports and preview encodes and holds the
+// queue. Returns the number of processes suspended.
+func (a *App) SuspendHeavyJobs() int {
+	heavyMu.Lock()
+	if jobsSuspended {
+		heavyMu.Unlock()
+		return 0
+	}
+	jobsSuspended = true
+	count := 0
+	for p := range heavyProcs {
+		if err := suspendProcess(p); err != nil {
+			fmt.Println("Suspend:", err)
+			continue
+		}
+		count++
+	}
+	pausedBySuspend = !isQueuePaused()
+	heavyMu.Unlock()
+
+	if pausedBySuspend {
+		a.SetQueuePaused(true)
+	}
+	a.emitJobsSuspended(true)
+	return count
+}
+
+// ResumeHeavyJobs continues everything SuspendHeavyJobs froze
+func (a *App) ResumeHeavyJobs() {
+	heavyMu.Lock()
+	if !jobsSuspended {
+		heavyMu.Unlock()
+		return
+	}
+	jobsSuspended = false
+	for p := range heavyProcs {
+		if err := resumeProcess(p); err != nil {
+			fmt.Println("Resume:", err)
+		}
+	}
+	unpause := pausedBySuspend
+	pausedBySuspend = false
+	heavyMu.Unlock()
+
+	if unpause {
+		a.SetQueuePaused(false)
+	}
+	a.emitJobsSuspended(false)
+}
+
+// ToggleHeavyJobs suspends or resumes heavy work; bound to the global hotkey
+func (a *App) ToggleHeavyJobs() bool {
+	if a.AreHeavyJobsSuspended() {
+		a.ResumeHeavyJobs()
+		return false
+	}
+	a.SuspendHeavyJobs()
+	return true
+}
+
+// AreHeavyJobsSuspended reports whether heavy work is currently frozen
+func (a *App) AreHeavyJobsSuspended() bool {
+	heavyMu.Lock()
+	defer heavyMu.Unlock()
+	return jobsSuspended
+}
+
+func (a *App) emitJobsSuspended(suspended bool) {
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "jobs:suspended", suspended)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// --- RESUMABLE EXPORTS ---
+
+// Per-export journals so an interrupted export resumes from its finished passes.
+
+const (
+	passVideo     = "video"
+	passMainAudio = "mainAudio"
+	passAudio     = "audio"
+)
+
+type ExportJournal struct {
+	ID          string            `json:"id"`
+	ProjectID   string            `json:"projectId"`
+	SceneID     string            `json:"sceneId"`
+	OutputPath  string            `json:"outputPath"`
+	Options     ExportOptions     `json:"options"`
+	Timeline    TimelineData      `json:"timeline"`
+	Fingerprint string            `json:"fingerprint"`
+	Passes      map[string]string `json:"passes"`      // Finished pass -> intermediate file
+	SegmentKeys []string          `json:"segmentKeys"` // Segment cache entries the video pass used
+	Started     string            `json:"started"`
+	Updated     string            `json:"updated"`
+}
+
+var (
+	journalMu      sync.Mutex
+	runningJournal = map[string]bool{}
+)
+
+func (a *App) getExportJobsDir() string {
+	dir := filepath.Join(a.getAppDir(), "exports", "jobs")
+	os.MkdirAll(dir, 0755)
+	return dir
+}
+
+// exportFingerprint identifies an export by everything that affects its output
+func exportFingerprint(projectId string, sceneId string, options ExportOptions, timeline TimelineData, outPath string) string {
+	h := sha1.New()
+	data, _ := json.Marshal(options)
+	tl, _ := json.Marshal(timeline)
+	fmt.Fprintf(h, "%s|%s|%s|%s|%s", projectId, sceneId, outPath, data, tl)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (a *App) loadExportJournal(id string) (*ExportJournal, error) {
+	data, err := os.ReadFile(filepath.Join(a.getExportJobsDir(), filepath.Base(id), "journal.json"))
+	if err != nil {
+		return nil, err
+	}
+	var j ExportJournal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (a *App) saveExportJournal(j *ExportJournal) {
+	j.Updated = time.Now().Format(time.RFC3339)
+	data, _ := json.MarshalIndent(j, "", "  ")
+	path := filepath.Join(a.getExportJobsDir(), j.ID, "journal.json")
+	if err := os.WriteFile(path+".tmp", data, 0644); err == nil {
+		os.Rename(path+".tmp", path)
+	}
+}
+
+// openExportJournal returns the journal of an unfinished identical export, or
+// starts a new one. Silent (background) exports are not journaled.
+func (a *App) openExportJournal(projectId string, sceneId string, options ExportOptions, timeline TimelineData, outPath string) *ExportJournal {
+	if options.silent || options.unjournaled {
+		return nil
+	}
+	fingerprint := exportFingerprint(projectId, sceneId, options, timeline, outPath)
+
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	for _, j := range a.listExportJournals() {
+		if j.Fingerprint == fingerprint && !runningJournal[j.ID] {
+			runningJournal[j.ID] = true
+			return &j
+		}
+	}
+
+	j := &ExportJournal{
+		ID:          fmt.Sprintf("%d", time.Now().UnixNano()),
+		ProjectID:   projectId,
+		SceneID:     sceneId,
+		OutputPath:  outPath,
+		Options:     options,
+		Timeline:    timeline,
+		Fingerprint: fingerprint,
+		Passes:      map[string]string{},
+		Started:     time.Now().Format(time.RFC3339),
+	}
+	if err := os.MkdirAll(filepath.Join(a.getExportJobsDir(), j.ID), 0755); err != nil {
+		return nil
+	}
+	runningJournal[j.ID] = true
+	a.saveExportJournal(j)
+	return j
+}
+
+// listExportJournals reads every journal on disk, oldest first
+func (a *App) listExportJournals() []ExportJournal {
+	journals := []ExportJournal{}
+	entries, _ := os.ReadDir(a.getExportJobsDir())
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if j, err := a.loadExportJournal(e.Name()); err == nil {
+			journals = append(journals, *j)
+		}
+	}
+	sort.Slice(journals, func(i, k int) bool { return journals[i].Started < journals[k].Started })
+	return journals
+}
+
+// done returns the intermediate of a finished pass, if it is still on disk
+func (j *ExportJournal) done(pass string) (string, bool) {
+	if j == nil {
+		return "", false
+	}
+	path, ok := j.Passes[pass]
+	if !ok {
+		return "", false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// workFile places an intermediate in the job dir, or in the temp dir
+// (tracked by temps) when the export isn't journaled
+func (a *App) workFile(j *ExportJournal, temps *tempSet, name string) string {
+	if j == nil {
+		return temps.add(filepath.Join(os.TempDir(), name))
+	}
+	return filepath.Join(a.getExportJobsDir(), j.ID, name)
+}
+
+// finishPass records a pass's intermediate in the journal
+func (a *App) finishPass(j *ExportJournal, pass string, path string) {
+	if j == nil {
+		return
+	}
+	j.Passes[pass] = path
+	a.saveExportJournal(j)
+}
+
+// recordSegmentKeys notes the cache entries the video pass depends on
+func (a *App) recordSegmentKeys(j *ExportJournal, segments []RenderSegment) {
+	if j == nil {
+		return
+	}
+	j.SegmentKeys = j.SegmentKeys[:0]
+	for _, seg := range segments {
+		if seg.Filter != "" {
+			j.SegmentKeys = append(j.SegmentKeys, segmentCacheKey(seg, "export"))
+		}
+	}
+	a.saveExportJournal(j)
+}
+
+// releaseExportJournal ends this run of a job. A finished job is removed
+// with its intermediates; an unfinished one stays on disk to be resumed.
+func (a *App) releaseExportJournal(j *ExportJournal, finished bool) {
+	if j == nil {
+		return
+	}
+	journalMu.Lock()
+	delete(runningJournal, j.ID)
+	journalMu.Unlock()
+	if finished {
+		os.RemoveAll(filepath.Join(a.getExportJobsDir(), j.ID))
+	}
+}
+
+// GetResumableExports lists interrupted exports that can be resumed
+func (a *App) GetResumableExports() []ExportJournal {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	resumable := []ExportJournal{}
+	for _, j := range a.listExportJournals() {
+		if !runningJournal[j.ID] {
+			j.Timeline = TimelineData{} // Not needed by the UI and potentially large
+			resumable = append(resumable, j)
+		}
+	}
+	return resumable
+}
+
+// ResumeExport restarts an interrupted export from its last finished pass
+func (a *App) ResumeExport(jobId string) string {
+	j, err := a.loadExportJournal(jobId)
+	if err != nil {
+		return tr("export.error.jobNotFound")
+	}
+	options := j.Options
+	options.timeline = &j.Timeline
+
+	result := a.exportTimeline(j.ProjectID, j.SceneID, options, j.OutputPath)
+	if result != "Success" && result != "Cancelled" {
+		recordEngineError("export", result)
+	}
+	return result
+}
+
+// DiscardExport deletes an interrupted export and its intermediates
+func (a *App) DiscardExport(jobId string) string {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	if runningJournal[jobId] {
+		return tr("export.error.jobRunning")
+	}
+	dir := filepath.Join(a.getExportJobsDir(), filepath.Base(jobId))
+	if _, err := os.Stat(dir); err != nil {
+		return tr("export.error.jobNotFound")
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Success"
+}
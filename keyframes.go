@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --- FIRST/LAST FRAME ---
+
+// Finds the loader that takes a shot's end image in first/last-frame workflows.
+
+// endImageInputs are input names that take the last frame
+var endImageInputs = []string{"end_image", "last_image", "end_frame", "last_frame"}
+
+// endImageLoaders returns the IDs of image loaders that supply the last
+// frame. Links are followed back through resize and crop nodes (their
+// "image" input) to the loader.
+func (a *App) endImageLoaders(workflow map[string]interface{}) map[string]bool {
+	loaders := map[string]bool{}
+	for nodeId, node := range workflow {
+		nodeMap, _ := node.(map[string]interface{})
+		inputs, _ := nodeMap["inputs"].(map[string]interface{})
+		for input, value := range inputs {
+			if !containsFold(endImageInputs, input) {
+				continue
+			}
+			if loader := a.imageLoaderOf(workflow, value); loader != "" {
+				loaders[loader] = true
+			}
+		}
+		if meta, ok := nodeMap["_meta"].(map[string]interface{}); ok {
+			title, _ := meta["title"].(string)
+			words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool { return r < 'a' || r > 'z' })
+			if (containsFold(words, "end") || containsFold(words, "last")) &&
+				(containsFold(words, "image") || containsFold(words, "frame")) {
+				loaders[nodeId] = true
+			}
+		}
+	}
+	return loaders
+}
+
+// imageLoaderOf follows an image link upstream to the node that loads the file
+func (a *App) imageLoaderOf(workflow map[string]interface{}, value interface{}) string {
+	for depth := 0; depth < 10; depth++ {
+		link, isLink := value.([]interface{})
+		if !isLink || len(link) != 2 {
+			return ""
+		}
+		id := fmt.Sprint(link[0])
+		nodeMap, _ := workflow[id].(map[string]interface{})
+		classType, _ := nodeMap["class_type"].(string)
+		for _, role := range a.nodeRules(classType) {
+			if role == "IMAGE" || role == "IMAGE_END" {
+				return id
+			}
+		}
+		inputs, _ := nodeMap["inputs"].(map[string]interface{})
+		value = inputs["image"]
+	}
+	return ""
+}
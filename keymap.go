@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/menu"
+	"github.com/wailsapp/wails/v2/pkg/menu/keys"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- KEYMAP ---
+
+// Keyboard shortcut overrides and the native menu actions they drive.
+
+type KeymapAction struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Menu    string `json:"menu"` // Native menu group, empty = frontend only
+	Default string `json:"default"`
+}
+
+type KeyBinding struct {
+	KeymapAction
+	Accelerator string `json:"accelerator"` // Empty = unbound
+}
+
+type KeymapConflict struct {
+	Accelerator string   `json:"accelerator"`
+	Actions     []string `json:"actions"`
+}
+
+type KeymapResult struct {
+	OK        bool              `json:"ok"`
+	Errors    map[string]string `json:"errors"` // Action id -> problem
+	Conflicts []KeymapConflict  `json:"conflicts"`
+}
+
+var keymapActions = []KeymapAction{
+	{ID: "file.export", Label: "Export...", Menu: "File", Default: "CmdOrCtrl+E"},
+	{ID: "file.exportReview", Label: "Export Review Package...", Menu: "File", Default: "CmdOrCtrl+Shift+E"},
+	{ID: "file.backup", Label: "Back Up Project", Menu: "File", Default: "CmdOrCtrl+Shift+B"},
+	{ID: "edit.undo", Label: "Undo", Default: "CmdOrCtrl+Z"},
+	{ID: "edit.redo", Label: "Redo", Default: "CmdOrCtrl+Shift+Z"},
+	{ID: "edit.split", Label: "Split Clip at Playhead", Default: "S"},
+	{ID: "edit.delete", Label: "Delete Selection", Default: "Delete"},
+	{ID: "transport.playPause", Label: "Play / Pause", Default: "Space"},
+	{ID: "transport.reverse", Label: "Play Reverse", Default: "J"},
+	{ID: "transport.stop", Label: "Pause", Default: "K"},
+	{ID: "transport.forward", Label: "Play Forward", Default: "L"},
+	{ID: "transport.prevFrame", Label: "Previous Frame", Default: "Left"},
+	{ID: "transport.nextFrame", Label: "Next Frame", Default: "Right"},
+	{ID: "transport.start", Label: "Go to Start", Default: "Home"},
+	{ID: "transport.end", Label: "Go to End", Default: "End"},
+	{ID: "timeline.zoomIn", Label: "Zoom In", Menu: "View", Default: "CmdOrCtrl+="},
+	{ID: "timeline.zoomOut", Label: "Zoom Out", Menu: "View", Default: "CmdOrCtrl+-"},
+	{ID: "view.scopes", Label: "Video Scopes", Menu: "View", Default: "CmdOrCtrl+Shift+S"},
+	{ID: "view.monitor", Label: "Client Monitor", Menu: "View", Default: "CmdOrCtrl+Shift+M"},
+	{ID: "shot.render", Label: "Render Selected Shot", Menu: "Render", Default: "CmdOrCtrl+R"},
+	{ID: "shot.prev", Label: "Previous Shot", Menu: "Render", Default: "CmdOrCtrl+Up"},
+	{ID: "shot.next", Label: "Next Shot", Menu: "Render", Default: "CmdOrCtrl+Down"},
+	{ID: "preview.render", Label: "Render Scene Preview", Menu: "Render", Default: "CmdOrCtrl+Shift+P"},
+	{ID: "jobs.toggle", Label: "Suspend / Resume Jobs", Menu: "Render", Default: "CmdOrCtrl+Shift+J"},
+}
+
+var keymapMenus = []string{"File", "View", "Render"}
+
+func (a *App) getKeymapPath() string {
+	return filepath.Join(a.getAppDir(), "keymap.json")
+}
+
+// loadKeymapOverrides reads the user's overrides (action id -> accelerator)
+func (a *App) loadKeymapOverrides() map[string]string {
+	overrides := map[string]string{}
+	if data, err := os.ReadFile(a.getKeymapPath()); err == nil {
+		json.Unmarshal(data, &overrides)
+	}
+	return overrides
+}
+
+// GetKeymap returns every action with its effective accelerator
+func (a *App) GetKeymap() []KeyBinding {
+	overrides := a.loadKeymapOverrides()
+	bindings := make([]KeyBinding, 0, len(keymapActions))
+	for _, action := range keymapActions {
+		accel, ok := overrides[action.ID]
+		if !ok {
+			accel = action.Default
+		}
+		bindings = append(bindings, KeyBinding{KeymapAction: action, Accelerator: accel})
+	}
+	return bindings
+}
+
+// canonicalAccelerator normalizes an accelerator for comparison on this
+// platform (CmdOrCtrl and Ctrl are the same key outside macOS)
+func canonicalAccelerator(accel string) (string, error) {
+	parsed, err := keys.Parse(accel)
+	if err != nil {
+		return "", err
+	}
+	return keys.Stringify(parsed, goruntime.GOOS), nil
+}
+
+// validateKeymap checks every accelerator parses and no two actions share one
+func validateKeymap(bindings map[string]string) KeymapResult {
+	result := KeymapResult{OK: true, Errors: map[string]string{}, Conflicts: []KeymapConflict{}}
+	known := map[string]bool{}
+	for _, action := range keymapActions {
+		known[action.ID] = true
+	}
+
+	byAccel := map[string][]string{}
+	order := []string{}
+	for _, action := range keymapActions {
+		accel, ok := bindings[action.ID]
+		if !ok || accel == "" {
+			continue
+		}
+		canonical, err := canonicalAccelerator(accel)
+		if err != nil {
+			result.Errors[action.ID] = err.Error()
+			continue
+		}
+		if _, seen := byAccel[canonical]; !seen {
+			order = append(order, canonical)
+		}
+		byAccel[canonical] = append(byAccel[canonical], action.ID)
+	}
+	for id := range bindings {
+		if !known[id] {
+			result.Errors[id] = "unknown action"
+		}
+	}
+	for _, canonical := range order {
+		if ids := byAccel[canonical]; len(ids) > 1 {
+			result.Conflicts = append(result.Conflicts, KeymapConflict{Accelerator: canonical, Actions: ids})
+		}
+	}
+	result.OK = len(result.Errors) == 0 && len(result.Conflicts) == 0
+	return result
+}
+
+// UpdateKeymap validates and saves new accelerators (action id -> accelerator,
+// "" to unbind). Actions not mentioned keep their current binding. Nothing is
+// saved when there are errors or conflicts.
+func (a *App) UpdateKeymap(changes map[string]string) KeymapResult {
+	effective := map[string]string{}
+	for _, b := range a.GetKeymap() {
+		effective[b.ID] = b.Accelerator
+	}
+	for id, accel := range changes {
+		effective[id] = strings.TrimSpace(accel)
+	}
+
+	result := validateKeymap(effective)
+	if !result.OK {
+		return result
+	}
+
+	// Only store what differs from the defaults so new defaults still apply
+	overrides := map[string]string{}
+	for _, action := range keymapActions {
+		if effective[action.ID] != action.Default {
+			overrides[action.ID] = effective[action.ID]
+		}
+	}
+	data, _ := json.MarshalIndent(overrides, "", "  ")
+	os.WriteFile(a.getKeymapPath(), data, 0644)
+	a.refreshAppMenu()
+	return result
+}
+
+// ResetKeymap restores every default accelerator
+func (a *App) ResetKeymap() []KeyBinding {
+	os.Remove(a.getKeymapPath())
+	a.refreshAppMenu()
+	return a.GetKeymap()
+}
+
+// buildAppMenu creates the native menu from the keymap
+func (a *App) buildAppMenu() *menu.Menu {
+	appMenu := menu.NewMenu()
+	if goruntime.GOOS == "darwin" {
+		appMenu.Append(menu.AppMenu())
+		appMenu.Append(menu.EditMenu()) // Keeps Cmd+C/V working in text fields
+	}
+
+	bindings := a.GetKeymap()
+	for _, group := range keymapMenus {
+		sub := appMenu.AddSubmenu(group)
+		for _, b := range bindings {
+			if b.Menu != group {
+				continue
+			}
+			var accel *keys.Accelerator
+			if b.Accelerator != "" {
+				if parsed, err := keys.Parse(b.Accelerator); err == nil {
+					accel = parsed
+				}
+			}
+			id := b.ID
+			sub.AddText(b.Label, accel, func(_ *menu.CallbackData) {
+				a.emitMenuAction(id)
+			})
+		}
+	}
+	return appMenu
+}
+
+func (a *App) refreshAppMenu() {
+	if a.ctx == nil {
+		return
+	}
+	runtime.MenuSetApplicationMenu(a.ctx, a.buildAppMenu())
+	runtime.MenuUpdateApplicationMenu(a.ctx)
+}
+
+// emitMenuAction forwards a menu/accelerator activation to the frontend.
+// Suspend/resume works even when the UI is busy.
+func (a *App) emitMenuAction(id string) {
+	if id == "jobs.toggle" {
+		a.ToggleHeavyJobs()
+		return
+	}
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "menu:action", id)
+	}
+}
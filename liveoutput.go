@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"sync"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- LIVE OUTPUT (NDI / VIRTUAL CAMERA) ---
+
+// Plays the preview in real time to NDI, a v4l2 webcam or UDP.
+
+type LiveOutputTarget struct {
+	Kind  string `json:"kind"`  // ndi, v4l2, udp
+	Label string `json:"label"` // Human readable
+	Dest  string `json:"dest"`  // NDI name, device path or URL
+}
+
+type LiveOutputOptions struct {
+	Kind      string  `json:"kind"`
+	Dest      string  `json:"dest"`      // Empty = default for the kind
+	StartTime float64 `json:"startTime"` // Seconds into the preview
+	Loop      bool    `json:"loop"`
+}
+
+const defaultLiveUDP = "udp://127.0.0.1:9010?pkt_size=1316"
+
+var (
+	liveMu  sync.Mutex
+	liveCmd *exec.Cmd
+)
+
+// GetLiveOutputTargets lists the outputs this machine's ffmpeg can feed
+func (a *App) GetLiveOutputTargets() []LiveOutputTarget {
+	targets := []LiveOutputTarget{}
+
+	muxers, _ := exec.Command("ffmpeg", "-hide_banner", "-muxers").Output()
+	if strings.Contains(string(muxers), "libndi_newtek") {
+		targets = append(targets, LiveOutputTarget{Kind: "ndi", Label: "NDI: Motion Studio", Dest: "Motion Studio"})
+	}
+
+	if goruntime.GOOS == "linux" {
+		devices, _ := filepath.Glob("/sys/devices/virtual/video4linux/video*")
+		for _, dev := range devices {
+			name, _ := os.ReadFile(filepath.Join(dev, "name"))
+			path := "/dev/" + filepath.Base(dev)
+			targets = append(targets, LiveOutputTarget{
+				Kind:  "v4l2",
+				Label: fmt.Sprintf("Virtual camera: %s (%s)", strings.TrimSpace(string(name)), path),
+				Dest:  path,
+			})
+		}
+	}
+
+	targets = append(targets, LiveOutputTarget{Kind: "udp", Label: "OBS Media Source (UDP)", Dest: defaultLiveUDP})
+	return targets
+}
+
+// StartLiveOutput starts playing the current preview into the chosen output,
+// replacing any running one
+func (a *App) StartLiveOutput(options LiveOutputOptions) string {
+	if server == nil {
+		return "error: server_not_ready"
+	}
+	source := filepath.Join(server.currentDir, "preview.mp4")
+	if _, err := os.Stat(source); err != nil {
+		return "error: render a preview first"
+	}
+
+	args := []string{"-hide_banner", "-v", "error", "-re"}
+	if options.Loop {
+		args = append(args, "-stream_loop", "-1")
+	}
+	if options.StartTime > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%f", options.StartTime))
+	}
+	args = append(args, "-i", source)
+
+	switch options.Kind {
+	case "ndi":
+		dest := options.Dest
+		if dest == "" {
+			dest = "Motion Studio"
+		}
+		args = append(args, "-vf", "format=uyvy422", "-f", "libndi_newtek", dest)
+	case "v4l2":
+		if options.Dest == "" {
+			return "error: no virtual camera device"
+		}
+		// Webcam consumers expect plain yuv420p and no audio
+		args = append(args, "-an", "-vf", "format=yuv420p", "-f", "v4l2", options.Dest)
+	case "udp":
+		dest := options.Dest
+		if dest == "" {
+			dest = defaultLiveUDP
+		}
+		args = append(args,
+			"-c:v", "libx264", "-preset", "veryfast", "-tune", "zerolatency", "-g", "50",
+			"-c:a", "aac", "-b:a", "160k",
+			"-f", "mpegts", dest)
+	default:
+		return "error: unknown output " + options.Kind
+	}
+
+	a.StopLiveOutput()
+
+	cmd := exec.Command("ffmpeg", args...)
+	var stderr safeBuffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return "error: " + err.Error()
+	}
+	liveMu.Lock()
+	liveCmd = cmd
+	liveMu.Unlock()
+	runtime.EventsEmit(a.ctx, "live:status", map[string]interface{}{"running": true, "kind": options.Kind})
+
+	go func() {
+		err := cmd.Wait()
+		liveMu.Lock()
+		if liveCmd == cmd {
+			liveCmd = nil
+		}
+		liveMu.Unlock()
+
+		status := map[string]interface{}{"running": false, "kind": options.Kind}
+		if err != nil && cmd.ProcessState != nil && !cmd.ProcessState.Success() && stderr.Len() > 0 {
+			status["error"] = strings.TrimSpace(string(stderr.Bytes()))
+			recordEngineError("live", status["error"].(string))
+		}
+		runtime.EventsEmit(a.ctx, "live:status", status)
+	}()
+	return "Success"
+}
+
+// StopLiveOutput stops the running live output, if any
+func (a *App) StopLiveOutput() {
+	liveMu.Lock()
+	cmd := liveCmd
+	liveCmd = nil
+	liveMu.Unlock()
+	if cmd != nil && cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
+
+// IsLiveOutputRunning reports whether a live output is playing
+func (a *App) IsLiveOutputRunning() bool {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+	return liveCmd != nil
+}
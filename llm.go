@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// --- PROMPT ASSISTANT (LLM) ---
+
+// Expands shot prompts through Ollama or an OpenAI-compatible chat endpoint.
+
+type LLMSettings struct {
+	Provider     string  `json:"provider"` // ollama (default) or openai
+	Endpoint     string  `json:"endpoint"` // "" = the provider's default
+	Model        string  `json:"model"`
+	Temperature  float64 `json:"temperature"`  // 0 = default
+	Suggestions  int     `json:"suggestions"`  // Prompts per request; 0 = default
+	Instructions string  `json:"instructions"` // Replaces the built-in system prompt
+}
+
+const (
+	llmOllama = "ollama"
+	llmOpenAI = "openai"
+
+	defaultOllamaURL      = "http://localhost:11434"
+	defaultOpenAIURL      = "https://api.openai.com/v1"
+	defaultLLMTemperature = 0.8
+	defaultLLMSuggestions = 3
+	// Local models on a busy GPU can take a while to answer
+	llmTimeout = 3 * time.Minute
+)
+
+const promptAssistantInstructions = `You write prompts for an AI video generator. Expand the user's shot prompt into a single detailed paragraph describing the subject, action and motion, camera movement and framing, lighting, mood and style. Keep the user's intent and any names; do not add dialogue or on-screen text. Reply with only a JSON array of strings, one prompt per element.`
+
+// GetLLMSettings returns the prompt assistant's endpoint settings
+func (a *App) GetLLMSettings() LLMSettings {
+	return a.getConfig().LLM
+}
+
+// SaveLLMSettings persists the prompt assistant's endpoint settings
+func (a *App) SaveLLMSettings(settings LLMSettings) string {
+	settings.Provider = strings.ToLower(strings.TrimSpace(settings.Provider))
+	switch settings.Provider {
+	case "":
+		settings.Provider = llmOllama
+	case llmOllama, llmOpenAI:
+	default:
+		return "Error: provider must be ollama or openai"
+	}
+	settings.Endpoint = strings.TrimRight(strings.TrimSpace(settings.Endpoint), "/")
+	if settings.Endpoint != "" && !strings.HasPrefix(settings.Endpoint, "http://") && !strings.HasPrefix(settings.Endpoint, "https://") {
+		return "Error: endpoint must be an http(s) URL"
+	}
+	settings.Model = strings.TrimSpace(settings.Model)
+	if settings.Temperature < 0 || settings.Temperature > 2 {
+		return "Error: temperature must be between 0 and 2"
+	}
+	if settings.Suggestions < 0 || settings.Suggestions > 10 {
+		return "Error: suggestions must be between 1 and 10"
+	}
+	a.updateConfig(func(c *Config) { c.LLM = settings })
+	return "Success"
+}
+
+// GetLLMModels lists the models the configured endpoint offers
+func (a *App) GetLLMModels() ([]string, error) {
+	settings := a.getConfig().LLM
+	models := []string{}
+	if settings.Provider == llmOpenAI {
+		var list struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		}
+		if err := a.llmRequest(settings, "GET", "/models", nil, &list); err != nil {
+			return models, err
+		}
+		for _, m := range list.Data {
+			models = append(models, m.ID)
+		}
+		return models, nil
+	}
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := a.llmRequest(settings, "GET", "/api/tags", nil, &tags); err != nil {
+		return models, err
+	}
+	for _, m := range tags.Models {
+		models = append(models, m.Name)
+	}
+	return models, nil
+}
+
+// EnhancePrompt asks the assistant for expanded versions of a shot's prompt;
+// sceneDescription ("" for none) gives it context
+func (a *App) EnhancePrompt(projectId string, sceneId string, shotId string, sceneDescription string) ([]string, error) {
+	var prompt string
+	found := false
+	for _, s := range a.GetShots(projectId, sceneId) {
+		if s.ID == shotId {
+			prompt, found = s.Prompt, true
+			break
+		}
+	}
+	if !found {
+		return []string{}, fmt.Errorf("shot not found")
+	}
+	return a.EnhancePromptText(prompt, sceneDescription)
+}
+
+// EnhancePromptText is EnhancePrompt for a prompt that isn't saved yet
+func (a *App) EnhancePromptText(prompt string, sceneDescription string) ([]string, error) {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return []string{}, fmt.Errorf("the prompt is empty")
+	}
+	settings := a.getConfig().LLM
+	count := settings.Suggestions
+	if count == 0 {
+		count = defaultLLMSuggestions
+	}
+	instructions := settings.Instructions
+	if strings.TrimSpace(instructions) == "" {
+		instructions = promptAssistantInstructions
+	}
+
+	var request strings.Builder
+	if d := strings.TrimSpace(sceneDescription); d != "" {
+		fmt.Fprintf(&request, "Scene: %s\n\n", d)
+	}
+	fmt.Fprintf(&request, "Shot prompt: %s\n\nWrite %d different versions.", prompt, count)
+
+	reply, err := a.llmChat(settings, instructions, request.String())
+	if err != nil {
+		return []string{}, err
+	}
+	suggestions := parseSuggestions(reply)
+	if len(suggestions) == 0 {
+		return []string{}, fmt.Errorf("the assistant returned no prompts")
+	}
+	if len(suggestions) > count {
+		suggestions = suggestions[:count]
+	}
+	return suggestions, nil
+}
+
+// llmChat sends one system + user exchange and returns the reply text
+func (a *App) llmChat(settings LLMSettings, system string, user string) (string, error) {
+	return a.llmComplete(settings, []map[string]interface{}{
+		{"role": "system", "content": system},
+		{"role": "user", "content": user},
+	})
+}
+
+// llmImageMessage is a user message carrying an image, in the provider's form
+func llmImageMessage(settings LLMSettings, text string, image []byte, contentType string) map[string]interface{} {
+	encoded := base64.StdEncoding.EncodeToString(image)
+	if settings.Provider == llmOpenAI {
+		return map[string]interface{}{"role": "user", "content": []map[string]interface{}{
+			{"type": "text", "text": text},
+			{"type": "image_url", "image_url": map[string]string{"url": "data:" + contentType + ";base64," + encoded}},
+		}}
+	}
+	return map[string]interface{}{"role": "user", "content": text, "images": []string{encoded}}
+}
+
+// llmComplete sends a conversation and returns the reply text
+func (a *App) llmComplete(settings LLMSettings, messages []map[string]interface{}) (string, error) {
+	if settings.Model == "" {
+		return "", fmt.Errorf("no model is set for the prompt assistant")
+	}
+	temperature := settings.Temperature
+	if temperature == 0 {
+		temperature = defaultLLMTemperature
+	}
+
+	if settings.Provider == llmOpenAI {
+		var reply struct {
+			Choices []struct {
+				Message struct {
+					Content string `json:"content"`
+				} `json:"message"`
+			} `json:"choices"`
+		}
+		body := map[string]interface{}{"model": settings.Model, "messages": messages, "temperature": temperature}
+		if err := a.llmRequest(settings, "POST", "/chat/completions", body, &reply); err != nil {
+			return "", err
+		}
+		if len(reply.Choices) == 0 {
+			return "", fmt.Errorf("the assistant returned no reply")
+		}
+		return reply.Choices[0].Message.Content, nil
+	}
+
+	var reply struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+	body := map[string]interface{}{
+		"model":    settings.Model,
+		"messages": messages,
+		"stream":   false,
+		"options":  map[string]interface{}{"temperature": temperature},
+	}
+	if err := a.llmRequest(settings, "POST", "/api/chat", body, &reply); err != nil {
+		return "", err
+	}
+	return reply.Message.Content, nil
+}
+
+// llmRequest calls path of the configured endpoint and decodes the JSON reply
+func (a *App) llmRequest(settings LLMSettings, method string, path string, body interface{}, out interface{}) error {
+	base := settings.Endpoint
+	if base == "" {
+		base = defaultOllamaURL
+		if settings.Provider == llmOpenAI {
+			base = defaultOpenAIURL
+		}
+	}
+	var reader io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, base+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key := a.getCredential(CredLLMKey); key != "" && settings.Provider == llmOpenAI {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	resp, err := (&http.Client{Timeout: llmTimeout}).Do(req)
+	if err != nil {
+		return fmt.Errorf("prompt assistant unreachable: %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error interface{} `json:"error"` // A string (Ollama) or {message} (OpenAI)
+		}
+		json.Unmarshal(data, &apiErr)
+		switch e := apiErr.Error.(type) {
+		case string:
+			return fmt.Errorf("prompt assistant error (Status %d): %s", resp.StatusCode, e)
+		case map[string]interface{}:
+			return fmt.Errorf("prompt assistant error (Status %d): %v", resp.StatusCode, e["message"])
+		}
+		return fmt.Errorf("prompt assistant error (Status %d)", resp.StatusCode)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("unexpected reply from the prompt assistant: %v", err)
+	}
+	return nil
+}
+
+var suggestionBullet = regexp.MustCompile(`^\s*(?:\d+[.):]|[-*•])\s*`)
+
+// parseSuggestions reads the reply's JSON array, or failing that its
+// numbered or bulleted lines (models don't always follow the format)
+func parseSuggestions(reply string) []string {
+	reply = strings.TrimSpace(reply)
+	if start, end := strings.Index(reply, "["), strings.LastIndex(reply, "]"); start >= 0 && end > start {
+		var list []string
+		if json.Unmarshal([]byte(reply[start:end+1]), &list) == nil {
+			suggestions := []string{}
+			for _, s := range list {
+				if s = strings.TrimSpace(s); s != "" {
+					suggestions = append(suggestions, s)
+				}
+			}
+			if len(suggestions) > 0 {
+				return suggestions
+			}
+		}
+	}
+
+	suggestions := []string{}
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "```") || strings.HasSuffix(line, ":") {
+			continue
+		}
+		line = strings.Trim(suggestionBullet.ReplaceAllString(line, ""), "\"")
+		if line != "" {
+			suggestions = append(suggestions, line)
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,51 @@
+package main
+
+// --- LOOPING CLIPS ---
+
+// Clips that repeat or boomerang their source range.
+
+const (
+	LoopModeRepeat    = "repeat"
+	LoopModeBoomerang = "boomerang"
+
+	// maxLoopCount keeps a typo from exploding the segment list
+	maxLoopCount = 100
+)
+
+// loopPasses is how many times the clip's source range plays (1 = no loop)
+func loopPasses(item TimelineItem) int {
+	if item.LoopMode != LoopModeRepeat && item.LoopMode != LoopModeBoomerang {
+		return 1
+	}
+	return min(max(item.LoopCount, 1), maxLoopCount)
+}
+
+// sourceSpan is the length of source one pass of the clip consumes
+func sourceSpan(item TimelineItem) float64 {
+	return item.Duration / float64(loopPasses(item))
+}
+
+// expandLoop splits a looping clip into one plain clip per pass. Boomerang
+// passes alternate direction, on top of the clip's own reversed flag.
+// Clips without a loop are returned as is.
+func expandLoop(item TimelineItem) []TimelineItem {
+	passes := loopPasses(item)
+	if passes == 1 {
+		return []TimelineItem{item}
+	}
+
+	span := sourceSpan(item)
+	expanded := make([]TimelineItem, passes)
+	for i := range expanded {
+		pass := item
+		pass.StartTime = item.StartTime + float64(i)*span
+		pass.Duration = span
+		pass.LoopMode = ""
+		pass.LoopCount = 0
+		if item.LoopMode == LoopModeBoomerang && i%2 == 1 {
+			pass.Reversed = !item.Reversed
+		}
+		expanded[i] = pass
+	}
+	return expanded
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- LOUDNESS ANALYSIS ---
+
+type DeliverySpec struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Integrated  float64 `json:"integrated"`  // Target LUFS
+	Tolerance   float64 `json:"tolerance"`   // +/- LU around the target
+	MaxTruePeak float64 `json:"maxTruePeak"` // dBTP ceiling
+}
+
+type LoudnessReport struct {
+	Integrated    float64  `json:"integrated"`    // LUFS
+	LoudnessRange float64  `json:"loudnessRange"` // LU
+	TruePeak      float64  `json:"truePeak"`      // dBTP
+	MaxShortTerm  float64  `json:"maxShortTerm"`  // LUFS (3s window)
+	MaxMomentary  float64  `json:"maxMomentary"`  // LUFS (400ms window)
+	Spec          string   `json:"spec"`
+	Pass          bool     `json:"pass"`
+	Issues        []string `json:"issues"`
+}
+
+var deliverySpecs = []DeliverySpec{
+	{ID: "ebu-r128", Name: "EBU R128 (Broadcast EU)", Integrated: -23, Tolerance: 1, MaxTruePeak: -1},
+	{ID: "atsc-a85", Name: "ATSC A/85 (Broadcast US)", Integrated: -24, Tolerance: 2, MaxTruePeak: -2},
+	{ID: "youtube", Name: "YouTube", Integrated: -14, Tolerance: 1, MaxTruePeak: -1},
+	{ID: "spotify", Name: "Spotify / Streaming Music", Integrated: -14, Tolerance: 1, MaxTruePeak: -1},
+	{ID: "apple-podcasts", Name: "Apple Podcasts", Integrated: -16, Tolerance: 1, MaxTruePeak: -1},
+	{ID: "netflix", Name: "Netflix", Integrated: -27, Tolerance: 2, MaxTruePeak: -2},
+}
+
+// GetDeliverySpecs lists the loudness targets AnalyzeLoudness can check against
+func (a *App) GetDeliverySpecs() []DeliverySpec {
+	return deliverySpecs
+}
+
+// AnalyzeLoudness renders the scene's flattened audio mix and measures it with
+// ebur128, comparing the result against the selected delivery spec.
+func (a *App) AnalyzeLoudness(projectId string, sceneId string, specId string) (LoudnessReport, error) {
+	report := LoudnessReport{Spec: specId, Issues: []string{}}
+
+	mixPath := trackTemp(filepath.Join(os.TempDir(), fmt.Sprintf("loudness_%s_%d.wav", sceneId, time.Now().UnixNano())))
+	defer releaseTemp(mixPath)
+
+	result := a.exportTimeline(projectId, sceneId, ExportOptions{
+		Format:       "wav",
+		IncludeAudio: true,
+		silent:       true,
+	}, mixPath)
+	if result != "Success" {
+		return report, fmt.Errorf("could not render mix: %s", result)
+	}
+
+	if err := measureLoudness(mixPath, &report); err != nil {
+		return report, err
+	}
+
+	for _, spec := range deliverySpecs {
+		if spec.ID == specId {
+			report.Pass = true
+			if math.Abs(report.Integrated-spec.Integrated) > spec.Tolerance {
+				report.Pass = false
+				report.Issues = append(report.Issues, fmt.Sprintf(
+					"Integrated loudness %.1f LUFS is outside %.0f ±%.0f LUFS (adjust gain by %+.1f dB)",
+					report.Integrated, spec.Integrated, spec.Tolerance, spec.Integrated-report.Integrated))
+			}
+			if report.TruePeak > spec.MaxTruePeak {
+				report.Pass = false
+				report.Issues = append(report.Issues, fmt.Sprintf(
+					"True peak %.1f dBTP exceeds the %.0f dBTP ceiling", report.TruePeak, spec.MaxTruePeak))
+			}
+			break
+		}
+	}
+	return report, nil
+}
+
+var (
+	ebuFrameRe   = regexp.MustCompile(`M:\s*(-?[\d.]+|-inf)\s+S:\s*(-?[\d.]+|-inf)`)
+	ebuSummaryRe = regexp.MustCompile(`^\s*(I|LRA|Peak):\s+(-?[\d.]+|-inf)`)
+)
+
+// measureLoudness runs ebur128 with true-peak metering and parses its log
+func measureLoudness(path string, report *LoudnessReport) error {
+	cmd := exec.Command("ffmpeg", "-nostats", "-i", mediaPath(path),
+		"-af", "ebur128=peak=true:framelog=verbose", "-f", "null", "-")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ebur128 failed: %v", err)
+	}
+
+	report.MaxShortTerm = math.Inf(-1)
+	report.MaxMomentary = math.Inf(-1)
+	inSummary := false
+
+	scanner := bufio.NewScanner(&stderr)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !inSummary {
+			if m := ebuFrameRe.FindStringSubmatch(line); m != nil {
+				report.MaxMomentary = math.Max(report.MaxMomentary, parseLoudness(m[1]))
+				report.MaxShortTerm = math.Max(report.MaxShortTerm, parseLoudness(m[2]))
+				continue
+			}
+			if strings.Contains(line, "Summary:") {
+				inSummary = true
+			}
+			continue
+		}
+		if m := ebuSummaryRe.FindStringSubmatch(line); m != nil {
+			v := parseLoudness(m[2])
+			switch m[1] {
+			case "I":
+				report.Integrated = v
+			case "LRA":
+				report.LoudnessRange = v
+			case "Peak":
+				report.TruePeak = v
+			}
+		}
+	}
+
+	if !inSummary {
+		return fmt.Errorf("ebur128 produced no summary")
+	}
+	// JSON can't encode -Inf; silence is reported as the meter floor
+	for _, v := range []*float64{&report.Integrated, &report.TruePeak, &report.MaxShortTerm, &report.MaxMomentary} {
+		if math.IsInf(*v, -1) {
+			*v = -70
+		}
+	}
+	return nil
+}
+
+func parseLoudness(s string) float64 {
+	if s == "-inf" {
+		return math.Inf(-1)
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return math.Inf(-1)
+	}
+	return v
+}
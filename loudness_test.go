@@ -0,0 +1,28 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseLoudness(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{in: "-23.0", want: -23},
+		{in: "-14.2", want: -14.2},
+		{in: "0.0", want: 0},
+		{in: "1.5", want: 1.5}, // True peak above full scale
+		{in: "7", want: 7},
+		{in: "-inf", want: math.Inf(-1)},
+		{in: "", want: math.Inf(-1)},
+		{in: "nan-ish", want: math.Inf(-1)},
+		{in: "-", want: math.Inf(-1)},
+	}
+	for _, tt := range tests {
+		if got := parseLoudness(tt.in); got != tt.want {
+			t.Errorf("parseLoudness(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
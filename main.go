@@ -4,12 +4,14 @@ import (
 	"embed"
 	"net/http"
 	"net/url"
-	"path/filepath"
+	"os"
+	goruntime "runtime"
 	"strings"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
+	"github.com/wailsapp/wails/v2/pkg/options/linux"
 	"github.com/wailsapp/wails/v2/pkg/options/mac"
 	"github.com/wailsapp/wails/v2/pkg/options/windows"
 )
@@ -37,14 +39,21 @@ func FileLoaderMiddleware(next http.Handler) http.Handler {
 
 			// 4. CLEAN THE PATH FOR WINDOWS
 			// Converts "C:/Users/Name/..." -> "C:\Users\Name\..."
-			systemPath := filepath.FromSlash(decodedPath)
+			// and "//NAS/share/..." or "UNC/NAS/share/..." -> "\\NAS\share\..."
+			systemPath := videoRequestPath(decodedPath)
 
 			// 5. DEBUG LOGS (Check your terminal!)
 			println("🔍 [Middleware] Request:", rawPath)
 			println("📂 [Middleware] Serving:", systemPath)
 
-			// 6. Serve the file
-			http.ServeFile(res, req, systemPath)
+			// 6. Serve the file (typed by extension/content, not the OS MIME db)
+			serveMedia(res, req, systemPath)
+			return
+		}
+
+		// Engine routes when the media server runs on a unix socket
+		if proxy := engineProxyFor(req.URL.Path); proxy != nil {
+			proxy.ServeHTTP(res, req)
 			return
 		}
 
@@ -55,6 +64,13 @@ func FileLoaderMiddleware(next http.Handler) http.Handler {
 
 func main() {
 	app := NewApp()
+	stopTray := startTray(app)
+	defer stopTray()
+
+	// WebKitGTK's DMA-BUF renderer shows a blank window on many NVIDIA setups
+	if goruntime.GOOS == "linux" && os.Getenv("WEBKIT_DISABLE_DMABUF_RENDERER") == "" {
+		os.Setenv("WEBKIT_DISABLE_DMABUF_RENDERER", "1")
+	}
 
 	err := wails.Run(&options.App{
 		Title:  "Motion Studio",
@@ -68,7 +84,9 @@ func main() {
 		},
 
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
+		Menu:             app.buildAppMenu(),
 		OnStartup:        app.startup,
+		OnShutdown:       app.shutdown,
 		Bind: []interface{}{
 			app,
 		},
@@ -77,6 +95,12 @@ func main() {
 			WindowIsTranslucent:  false,
 			BackdropType:         windows.Mica,
 		},
+		Linux: &linux.Options{
+			ProgramName:         "motion-studio",
+			WindowIsTranslucent: false,
+			// Video previews need hardware acceleration; wails defaults to Never
+			WebviewGpuPolicy: linux.WebviewGpuPolicyOnDemand,
+		},
 		Mac: &mac.Options{
 			TitleBar: &mac.TitleBar{
 				TitlebarAppearsTransparent: true,
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- MASTER TIMELINE ---
+
+// Assembles a project's scenes into one film.
+
+type MasterTransition struct {
+	Type     string  `json:"type"`     // cut (default), fade, dissolve, fadeblack, fadewhite, wipeleft, slideleft
+	Duration float64 `json:"duration"` // Seconds, ignored for cuts
+}
+
+type MasterEntry struct {
+	ID         string           `json:"id"`
+	SceneID    string           `json:"sceneId"`
+	Transition MasterTransition `json:"transition"` // Into this entry from the previous one
+
+	// Filled in by GetMasterTimeline
+	SceneName string  `json:"sceneName"`
+	Start     float64 `json:"start"`
+	Duration  float64 `json:"duration"` // 0 until the scene has been flattened
+	Stale     bool    `json:"stale"`    // Scene changed since it was flattened
+}
+
+type MasterTimeline struct {
+	Entries  []MasterEntry `json:"entries"`
+	Duration float64       `json:"duration"`
+}
+
+var masterTransitions = map[string]bool{
+	"cut": true, "fade": true, "dissolve": true, "fadeblack": true, "fadewhite": true, "wipeleft": true, "slideleft": true,
+}
+
+func (a *App) getMasterPath(projectId string) string {
+	return filepath.Join(a.getAppDir(), projectId, "master.json")
+}
+
+// masterScenePath is the flattened render of a scene used by the master
+func (a *App) masterScenePath(projectId string, sceneId string) string {
+	return filepath.Join(a.getAppDir(), projectId, "master", sceneId+".mp4")
+}
+
+// masterSceneCurrent reports whether the flattened scene is newer than every
+// file in the scene folder
+func (a *App) masterSceneCurrent(projectId string, sceneId string) bool {
+	info, err := os.Stat(a.masterScenePath(projectId, sceneId))
+	if err != nil {
+		return false
+	}
+	return !latestModTime(filepath.Join(a.getAppDir(), projectId, "scenes", sceneId)).After(info.ModTime())
+}
+
+// transitionLength returns how much two entries overlap
+func transitionLength(t MasterTransition) float64 {
+	if t.Type == "" || t.Type == "cut" {
+		return 0
+	}
+	return t.Duration
+}
+
+// GetMasterTimeline returns the master sequence. Without a saved one, every
+// scene appears once with cuts.
+func (a *App) GetMasterTimeline(projectId string) MasterTimeline {
+	var master MasterTimeline
+	if data, err := os.ReadFile(a.getMasterPath(projectId)); err == nil {
+		json.Unmarshal(data, &master)
+	} else {
+		for _, scene := range a.GetScenes(projectId) {
+			master.Entries = append(master.Entries, MasterEntry{ID: scene.ID, SceneID: scene.ID, Transition: MasterTransition{Type: "cut"}})
+		}
+	}
+	if master.Entries == nil {
+		master.Entries = []MasterEntry{}
+	}
+
+	names := map[string]string{}
+	for _, scene := range a.GetScenes(projectId) {
+		names[scene.ID] = scene.Name
+	}
+	t := 0.0
+	for i := range master.Entries {
+		e := &master.Entries[i]
+		e.SceneName = names[e.SceneID]
+		e.Stale = !a.masterSceneCurrent(projectId, e.SceneID)
+		e.Duration = 0
+		if d, err := probeDuration(a.masterScenePath(projectId, e.SceneID)); err == nil {
+			e.Duration = d
+		}
+		if i > 0 {
+			t -= transitionLength(e.Transition)
+		}
+		e.Start = max(0, t)
+		t += e.Duration
+	}
+	master.Duration = max(0, t)
+	return master
+}
+
+// SaveMasterTimeline stores the order and transitions of the master sequence
+func (a *App) SaveMasterTimeline(projectId string, master MasterTimeline) string {
+	for i, e := range master.Entries {
+		if e.SceneID == "" {
+			return fmt.Sprintf("Entry %d has no scene", i+1)
+		}
+		if e.Transition.Type != "" && !masterTransitions[e.Transition.Type] {
+			return fmt.Sprintf("Unknown transition %q", e.Transition.Type)
+		}
+		if e.ID == "" {
+			master.Entries[i].ID = fmt.Sprintf("%s-%d", e.SceneID, time.Now().UnixNano())
+		}
+		// Only the arrangement is stored; durations are derived
+		master.Entries[i].SceneName, master.Entries[i].Start, master.Entries[i].Duration, master.Entries[i].Stale = "", 0, 0, false
+	}
+	master.Duration = 0
+	data, _ := json.MarshalIndent(master, "", "  ")
+	os.WriteFile(a.getMasterPath(projectId), data, 0644)
+	a.recordHistory(projectId, "Edit master timeline")
+	return "Success"
+}
+
+// flattenSceneForMaster renders a scene to its master intermediate unless the
+// existing one is current. run is the master export it is for, if any.
+func (a *App) flattenSceneForMaster(run *exportRun, projectId string, sceneId string) (string, error) {
+	out := a.masterScenePath(projectId, sceneId)
+	if a.masterSceneCurrent(projectId, sceneId) {
+		return out, nil
+	}
+	os.MkdirAll(filepath.Dir(out), 0755)
+	tmp := out + ".partial.mp4"
+	result := a.exportTimeline(projectId, sceneId, ExportOptions{
+		Format:       "mp4",
+		IncludeVideo: true,
+		IncludeAudio: true,
+		Quality:      "high",
+		silent:       true,
+		run:          run,
+	}, tmp)
+	if result != "Success" {
+		os.Remove(tmp)
+		return "", fmt.Errorf("%s", result)
+	}
+	return out, os.Rename(tmp, out)
+}
+
+// RenderMasterScenes flattens every stale scene of the master sequence
+func (a *App) RenderMasterScenes(projectId string) string {
+	master := a.GetMasterTimeline(projectId)
+	for i, e := range master.Entries {
+		runtime.EventsEmit(a.ctx, "export:status", tr("master.renderingScene", e.SceneName, i+1, len(master.Entries)))
+		if _, err := a.flattenSceneForMaster(nil, projectId, e.SceneID); err != nil {
+			return "Error: " + err.Error()
+		}
+	}
+	return "Success"
+}
+
+// masterEncodeArgs returns the output encoder arguments for a master export
+func masterEncodeArgs(options ExportOptions) []string {
+	switch {
+	case options.Format == "mxf" || (options.Format == "mov" && options.VideoCodec == "dnxhr"):
+		return append(dnxhrVideoArgs(options), "-c:a", "pcm_s24le", "-ar", "48000")
+	case options.Format == "mov":
+		profile := map[string]string{"high": "3", "low": "0"}[options.Quality]
+		if profile == "" {
+			profile = "2"
+		}
+		return []string{"-c:v", "prores_ks", "-profile:v", profile, "-vendor", "apl0", "-pix_fmt", "yuv422p10le", "-c:a", "pcm_s16le"}
+	case options.Format == "webm":
+		return append(webmVideoArgs(options), "-c:a", "libopus", "-b:a", audioBitrate(options, 160))
+	}
+	crf := map[string]string{"high": "18", "low": "28"}[options.Quality]
+	if crf == "" {
+		crf = "23"
+	}
+	return []string{"-c:v", "libx264", "-preset", "fast", "-crf", crf, "-pix_fmt", "yuv420p", "-c:a", "aac", "-b:a", "192k", "-movflags", "+faststart"}
+}
+
+// ExportMaster renders the master sequence to a file chosen by the user
+func (a *App) ExportMaster(projectId string, options ExportOptions) string {
+	if options.Format == "" {
+		options.Format = "mp4"
+	}
+	switch options.Format {
+	case "mp4", "mov", "mkv", "mxf", "webm":
+	default:
+		return "Unsupported format for the master: " + options.Format
+	}
+	outPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           tr("export.dialogTitle", strings.ToUpper(options.Format)),
+		DefaultFilename: "master." + options.Format,
+		Filters: []runtime.FileFilter{
+			{DisplayName: tr("export.fileFilter", strings.ToUpper(options.Format)), Pattern: "*." + options.Format},
+		},
+	})
+	if err != nil || outPath == "" {
+		return "Cancelled"
+	}
+	rememberExportDir(filepath.Dir(outPath))
+
+	result := a.exportMaster(projectId, options, outPath)
+	if result != "Success" && result != "Cancelled" {
+		recordEngineError("export", result)
+	}
+	return result
+}
+
+func (a *App) exportMaster(projectId string, options ExportOptions, outPath string) (result string) {
+	if options.Transparent {
+		// Scenes are flattened to opaque MP4s first
+		return tr("export.error.encoder", "the master can't be exported with transparency")
+	}
+	encodeArgs, err := parseExportArgs(options.Advanced.EncodeArgs)
+	if err != nil {
+		return tr("export.error.advancedArgs", err.Error())
+	}
+
+	master := a.GetMasterTimeline(projectId)
+	if len(master.Entries) == 0 {
+		return tr("export.error.emptyTimeline")
+	}
+	atomic.AddInt32(&activeExports, 1)
+	defer atomic.AddInt32(&activeExports, -1)
+	run := beginExportRun(nil)
+	defer func() {
+		endExportRun(run)
+		if run.isCanceled() {
+			os.Remove(outPath)
+			result = "Cancelled"
+			runtime.EventsEmit(a.ctx, "export:cancelled", run.ID)
+		}
+	}()
+	runtime.EventsEmit(a.ctx, "export:started", run.ID)
+	runtime.EventsEmit(a.ctx, "export:progress", 0)
+
+	// 1. Resolve every scene to its flattened render
+	inputs := []string{}
+	durations := []float64{}
+	for i, e := range master.Entries {
+		runtime.EventsEmit(a.ctx, "export:status", tr("master.renderingScene", e.SceneName, i+1, len(master.Entries)))
+		path, err := a.flattenSceneForMaster(run, projectId, e.SceneID)
+		if err != nil {
+			return tr("export.error.video", err.Error())
+		}
+		d, err := probeDuration(path)
+		if err != nil || d <= 0 {
+			return tr("export.error.video", "could not read the length of scene "+e.SceneName)
+		}
+		inputs = append(inputs, path)
+		durations = append(durations, d)
+	}
+
+	// 2. Normalize every input to the project format
+	project, _ := a.GetProject(projectId)
+	w, h := previewSize(project)
+	if sw, sh, err := probeVideoSize(inputs[0]); err == nil {
+		w, h = sw, sh
+	}
+	fps := project.FrameRate
+	if fps <= 0 {
+		fps = 24
+	}
+	var graph strings.Builder
+	args := []string{"-y"}
+	for i, in := range inputs {
+		args = append(args, "-i", mediaPath(in))
+		fmt.Fprintf(&graph, "[%d:v]scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=%g,format=yuv420p,settb=AVTB[v%d];", i, w, h, w, h, fps, i)
+		fmt.Fprintf(&graph, "[%d:a]aresample=48000,aformat=sample_fmts=fltp:channel_layouts=stereo[a%d];", i, i)
+	}
+
+	// 3. Chain the entries with cuts (concat) or transitions (xfade/acrossfade)
+	v, au := "v0", "a0"
+	length := durations[0]
+	for i := 1; i < len(inputs); i++ {
+		t := master.Entries[i].Transition
+		d := transitionLength(t)
+		d = min(d, length/2, durations[i]/2)
+		nv, na := fmt.Sprintf("vx%d", i), fmt.Sprintf("ax%d", i)
+		if d <= 0 {
+			fmt.Fprintf(&graph, "[%s][%s][v%d][a%d]concat=n=2:v=1:a=1[%s][%s];", v, au, i, i, nv, na)
+			length += durations[i]
+		} else {
+			fmt.Fprintf(&graph, "[%s][v%d]xfade=transition=%s:duration=%f:offset=%f[%s];", v, i, t.Type, d, length-d, nv)
+			fmt.Fprintf(&graph, "[%s][a%d]acrossfade=d=%f[%s];", au, i, d, na)
+			length += durations[i] - d
+		}
+		v, au = nv, na
+	}
+
+	args = append(args,
+		"-filter_complex", strings.TrimSuffix(graph.String(), ";"),
+		"-map", "["+v+"]", "-map", "["+au+"]")
+	args = append(args, masterEncodeArgs(options)...)
+	args = withExtraArgs(args, encodeArgs)
+	args = append(args, outPath)
+
+	runtime.EventsEmit(a.ctx, "export:status", tr("export.finalizing"))
+	a.beginForeground()
+	defer a.endForeground()
+	if err := a.runFFmpegWithProgress(run, args, tr("export.stage.video")); err != nil {
+		return tr("export.error.video", err.Error())
+	}
+	runtime.EventsEmit(a.ctx, "export:progress", 100)
+	return "Success"
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// --- MEDIA TYPES ---
+
+// Content types for media served to the webview.
+
+var mediaTypes = map[string]string{
+	// Video
+	".mp4":  "video/mp4",
+	".m4v":  "video/mp4",
+	".mov":  "video/quicktime",
+	".webm": "video/webm",
+	".mkv":  "video/x-matroska",
+	".avi":  "video/x-msvideo",
+	".mxf":  "application/mxf",
+	".ts":   "video/mp2t",
+	".ogv":  "video/ogg",
+	// Audio
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".flac": "audio/flac",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".opus": "audio/ogg",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	// Images
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".webp": "image/webp",
+	".gif":  "image/gif",
+	".avif": "image/avif",
+	".heic": "image/heic",
+	".bmp":  "image/bmp",
+	".tif":  "image/tiff",
+	".tiff": "image/tiff",
+	".svg":  "image/svg+xml",
+}
+
+// sniffMediaType recognizes the containers http.DetectContentType doesn't
+func sniffMediaType(head []byte) string {
+	switch {
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		switch brand := string(head[8:12]); {
+		case brand == "qt  ":
+			return "video/quicktime"
+		case brand == "avif" || brand == "avis":
+			return "image/avif"
+		case brand == "heic" || brand == "heix" || brand == "mif1":
+			return "image/heic"
+		case strings.HasPrefix(brand, "M4A"):
+			return "audio/mp4"
+		}
+		return "video/mp4"
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		if bytes.Contains(head, []byte("webm")) {
+			return "video/webm"
+		}
+		return "video/x-matroska"
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return "audio/flac"
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return "audio/ogg"
+	case len(head) >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+		return "audio/wav"
+	}
+	if t := http.DetectContentType(head); t != "application/octet-stream" {
+		return t
+	}
+	return ""
+}
+
+// mediaContentType returns the MIME type for a media file
+func mediaContentType(path string) string {
+	if t, ok := mediaTypes[strings.ToLower(filepath.Ext(path))]; ok {
+		return t
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, _ := f.Read(head)
+	if t := sniffMediaType(head[:n]); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// serveMedia serves a local file with a proper Content-Type (ranges included)
+func serveMedia(w http.ResponseWriter, r *http.Request, path string) {
+	w.Header().Set("Content-Type", mediaContentType(path))
+	http.ServeFile(w, r, path)
+}
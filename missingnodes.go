@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- MISSING CUSTOM NODES ---
+
+// Reports custom nodes a workflow needs that the server lacks.
+
+type MissingNode struct {
+	ClassType string `json:"classType"`
+	Count     int    `json:"count"`   // Nodes of this type in the workflow
+	Package   string `json:"package"` // Likely Manager package, "" if unknown
+	Source    string `json:"source"`  // Where the guess came from: workflow, manager or known
+}
+
+type MissingNodesReport struct {
+	Workflow string        `json:"workflow"` // Saved name; "" if the import failed
+	Server   string        `json:"server"`
+	Checked  bool          `json:"checked"` // False when the server couldn't be asked
+	Error    string        `json:"error,omitempty"`
+	Missing  []MissingNode `json:"missing"`
+}
+
+// knownNodePacks maps class name prefixes of popular node packs to their
+// Manager package names
+var knownNodePacks = []struct{ Prefix, Package string }{
+	{"VHS_", "ComfyUI-VideoHelperSuite"},
+	{"WanVideo", "ComfyUI-WanVideoWrapper"},
+	{"MultiTalk", "ComfyUI-WanVideoWrapper"},
+	{"HyVideo", "ComfyUI-HunyuanVideoWrapper"},
+	{"LTXV", "ComfyUI-LTXVideo"},
+	{"RIFE VFI", "ComfyUI-Frame-Interpolation"},
+	{"FILM VFI", "ComfyUI-Frame-Interpolation"},
+	{"IPAdapter", "ComfyUI_IPAdapter_plus"},
+	{"ACN_", "ComfyUI-Advanced-ControlNet"},
+	{"ADE_", "ComfyUI-AnimateDiff-Evolved"},
+	{"UnetLoaderGGUF", "ComfyUI-GGUF"},
+	{"CLIPLoaderGGUF", "ComfyUI-GGUF"},
+	{"DownloadAndLoadFlorence2", "ComfyUI-Florence2"},
+	{"Florence2", "ComfyUI-Florence2"},
+	{"DepthAnything", "comfyui_controlnet_aux"},
+	{"DWPreprocessor", "comfyui_controlnet_aux"},
+	{"OpenposePreprocessor", "comfyui_controlnet_aux"},
+	{"easy ", "ComfyUI-Easy-Use"},
+	{"LayerUtility", "ComfyUI_LayerStyle"},
+	{"CR ", "ComfyUI_Comfyroll_CustomNodes"},
+	{"Impact", "ComfyUI-Impact-Pack"},
+	{"SUPIR", "ComfyUI-SUPIR"},
+	{"ReActor", "comfyui-reactor-node"},
+}
+
+// knownNodeSuffixes are name endings some packs put on every node
+var knownNodeSuffixes = []struct{ Suffix, Package string }{
+	{"KJ", "ComfyUI-KJNodes"},
+	{"|pysssss", "ComfyUI-Custom-Scripts"},
+}
+
+// workflowNodeTypes counts the class types of an API prompt or editor graph,
+// and collects the packages an editor graph recorded for them
+func workflowNodeTypes(data []byte) (map[string]int, map[string]string) {
+	counts := map[string]int{}
+	hints := map[string]string{}
+	if isUIWorkflow(data) {
+		var graph struct {
+			Nodes []struct {
+				Type       string `json:"type"`
+				Mode       int    `json:"mode"`
+				Properties struct {
+					CnrID string `json:"cnr_id"`
+					AuxID string `json:"aux_id"` // "user/repo" for unregistered packs
+				} `json:"properties"`
+			} `json:"nodes"`
+		}
+		json.Unmarshal(data, &graph)
+		for _, n := range graph.Nodes {
+			if uiOnlyNodes[n.Type] || n.Mode == uiModeMuted || n.Type == "" {
+				continue
+			}
+			counts[n.Type]++
+			switch {
+			case n.Properties.CnrID != "" && n.Properties.CnrID != "comfy-core":
+				hints[n.Type] = n.Properties.CnrID
+			case n.Properties.AuxID != "":
+				hints[n.Type] = path.Base(n.Properties.AuxID)
+			}
+		}
+		return counts, hints
+	}
+	var prompt map[string]struct {
+		ClassType string `json:"class_type"`
+	}
+	json.Unmarshal(data, &prompt)
+	for _, n := range prompt {
+		if n.ClassType != "" {
+			counts[n.ClassType]++
+		}
+	}
+	return counts, hints
+}
+
+// managerNodeMap asks ComfyUI-Manager which package provides which node;
+// nil when the Manager isn't installed
+func (a *App) managerNodeMap(server string) map[string]string {
+	var mappings map[string][]json.RawMessage
+	if err := a.comfyJSONAt(server, "GET", "/customnode/getmappings?mode=cache", nil, &mappings); err != nil {
+		return nil
+	}
+	packages := map[string]string{}
+	for repo, entry := range mappings {
+		if len(entry) == 0 {
+			continue
+		}
+		var nodes []string
+		json.Unmarshal(entry[0], &nodes)
+		name := path.Base(strings.TrimSuffix(repo, ".git"))
+		for _, node := range nodes {
+			if _, taken := packages[node]; !taken {
+				packages[node] = name
+			}
+		}
+	}
+	return packages
+}
+
+// guessNodePackage names the package that likely provides a node type
+func guessNodePackage(classType string) string {
+	for _, k := range knownNodePacks {
+		if strings.HasPrefix(classType, k.Prefix) {
+			return k.Package
+		}
+	}
+	for _, k := range knownNodeSuffixes {
+		if strings.HasSuffix(classType, k.Suffix) {
+			return k.Package
+		}
+	}
+	return ""
+}
+
+// missingNodes checks workflow data against a server's node definitions
+func (a *App) missingNodes(server string, data []byte) MissingNodesReport {
+	report := MissingNodesReport{Server: server, Missing: []MissingNode{}}
+	info, err := a.fetchObjectInfo(server)
+	if err != nil {
+		report.Error = fmt.Sprintf("could not read node definitions from ComfyUI: %v", err)
+		return report
+	}
+	report.Checked = true
+
+	counts, hints := workflowNodeTypes(data)
+	var manager map[string]string
+	askedManager := false
+	for classType, count := range counts {
+		if _, installed := info[classType]; installed {
+			continue
+		}
+		node := MissingNode{ClassType: classType, Count: count}
+		if hint := hints[classType]; hint != "" {
+			node.Package, node.Source = hint, "workflow"
+		} else {
+			if !askedManager {
+				manager, askedManager = a.managerNodeMap(server), true
+			}
+			if pkg := manager[classType]; pkg != "" {
+				node.Package, node.Source = pkg, "manager"
+			} else if pkg := guessNodePackage(classType); pkg != "" {
+				node.Package, node.Source = pkg, "known"
+			}
+		}
+		report.Missing = append(report.Missing, node)
+	}
+	sort.Slice(report.Missing, func(i, j int) bool { return report.Missing[i].ClassType < report.Missing[j].ClassType })
+	return report
+}
+
+// CheckMissingNodes reports the custom nodes a saved workflow needs that the
+// primary server doesn't have
+func (a *App) CheckMissingNodes(name string) (MissingNodesReport, error) {
+	data, err := os.ReadFile(filepath.Join(a.getWorkflowsDir(), name+".json"))
+	if err != nil {
+		return MissingNodesReport{Missing: []MissingNode{}}, fmt.Errorf("workflow %q not found", name)
+	}
+	report := a.missingNodes(a.comfyURL, data)
+	report.Workflow = name
+	return report, nil
+}
+
+// ImportWorkflowChecked imports a workflow like ImportWorkflow and reports
+// its missing custom nodes. Editor graphs with missing nodes can't be
+// converted yet; the report then says what to install and nothing is saved.
+func (a *App) ImportWorkflowChecked(name string) (MissingNodesReport, error) {
+	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select ComfyUI Workflow",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "JSON Files", Pattern: "*.json"},
+		},
+	})
+	if err != nil || selection == "" {
+		return MissingNodesReport{Missing: []MissingNode{}}, fmt.Errorf("cancelled")
+	}
+	data, err := os.ReadFile(selection)
+	if err != nil {
+		return MissingNodesReport{Missing: []MissingNode{}}, fmt.Errorf("error reading file")
+	}
+
+	report := a.missingNodes(a.comfyURL, data)
+	saved, err := a.saveImportedWorkflow(name, data)
+	if err != nil {
+		report.Error = err.Error()
+		return report, nil
+	}
+	report.Workflow = saved
+	return report, nil
+}
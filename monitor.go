@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- CLIENT MONITOR ---
+
+// Full-screen client monitor window on the second display.
+
+type MonitorState struct {
+	Path    string  `json:"path"`    // Local media file; empty = scene preview.mp4
+	Playing bool    `json:"playing"` // Transport state
+	Time    float64 `json:"time"`    // Playhead in seconds
+	Rate    float64 `json:"rate"`    // Playback rate (1 = normal)
+	Stamp   int64   `json:"stamp"`   // Unix ms when Time was sampled
+	Version int64   `json:"version"` // Bumped when the source is re-rendered
+}
+
+var (
+	monitorMu    sync.Mutex
+	monitorState MonitorState
+	monitorSubs  = map[chan MonitorState]bool{}
+	monitorCmd   *exec.Cmd
+)
+
+// SetMonitorState mirrors the main window's transport to the client monitor
+func (a *App) SetMonitorState(state MonitorState) {
+	if state.Rate == 0 {
+		state.Rate = 1
+	}
+	state.Stamp = time.Now().UnixMilli()
+
+	monitorMu.Lock()
+	defer monitorMu.Unlock()
+	monitorState = state
+	for ch := range monitorSubs {
+		select {
+		case ch <- state:
+		default: // Slow page; it will catch up on the next update
+		}
+	}
+}
+
+// OpenPreviewMonitor opens the client monitor full-screen on the first
+// secondary display, or in a normal browser window when no Chromium-based
+// browser is installed.
+func (a *App) OpenPreviewMonitor() string {
+	if !strings.HasPrefix(engineBaseURL(), "http") {
+		return "Error: the client monitor needs the TCP engine transport"
+	}
+	url := engineURL("/monitor")
+
+	browser := findAppBrowser()
+	if browser == "" {
+		runtime.BrowserOpenURL(a.ctx, url)
+		return "Success"
+	}
+
+	// Screens carry no positions; assume secondaries sit to the right of the primary
+	offsetX := 0
+	if screens, err := runtime.ScreenGetAll(a.ctx); err == nil && len(screens) > 1 {
+		for _, s := range screens {
+			if s.IsPrimary {
+				offsetX = s.Size.Width
+			}
+		}
+	}
+
+	monitorMu.Lock()
+	defer monitorMu.Unlock()
+	if monitorCmd != nil && monitorCmd.ProcessState == nil {
+		monitorCmd.Process.Kill()
+	}
+	profile := filepath.Join(a.getAppDir(), "cache", "monitor-profile")
+	monitorCmd = exec.Command(browser,
+		"--app="+url,
+		"--user-data-dir="+profile, // Separate profile forces a new window
+		fmt.Sprintf("--window-position=%d,0", offsetX),
+		"--start-fullscreen",
+		"--autoplay-policy=no-user-gesture-required",
+		"--no-first-run",
+	)
+	if err := monitorCmd.Start(); err != nil {
+		return "Error: " + err.Error()
+	}
+	go monitorCmd.Wait()
+	return "Success"
+}
+
+// ClosePreviewMonitor closes the client monitor window
+func (a *App) ClosePreviewMonitor() {
+	monitorMu.Lock()
+	defer monitorMu.Unlock()
+	if monitorCmd != nil && monitorCmd.Process != nil {
+		monitorCmd.Process.Kill()
+		monitorCmd = nil
+	}
+}
+
+// findAppBrowser returns a Chromium-based browser that supports --app windows
+func findAppBrowser() string {
+	var candidates []string
+	switch goruntime.GOOS {
+	case "windows":
+		for _, root := range []string{os.Getenv("ProgramFiles(x86)"), os.Getenv("ProgramFiles"), os.Getenv("LOCALAPPDATA")} {
+			if root == "" {
+				continue
+			}
+			candidates = append(candidates,
+				filepath.Join(root, "Microsoft", "Edge", "Application", "msedge.exe"),
+				filepath.Join(root, "Google", "Chrome", "Application", "chrome.exe"))
+		}
+	case "darwin":
+		candidates = []string{
+			"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+			"/Applications/Microsoft Edge.app/Contents/MacOS/Microsoft Edge",
+			"/Applications/Chromium.app/Contents/MacOS/Chromium",
+		}
+	default:
+		for _, name := range []string{"google-chrome", "chromium", "chromium-browser", "microsoft-edge"} {
+			if path, err := exec.LookPath(name); err == nil {
+				return path
+			}
+		}
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil {
+			return c
+		}
+	}
+	return ""
+}
+
+// monitorEventsHandler streams MonitorState updates as server-sent events
+func monitorEventsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	ch := make(chan MonitorState, 8)
+	monitorMu.Lock()
+	monitorSubs[ch] = true
+	current := monitorState
+	monitorMu.Unlock()
+	defer func() {
+		monitorMu.Lock()
+		delete(monitorSubs, ch)
+		monitorMu.Unlock()
+	}()
+
+	send := func(s MonitorState) {
+		data, _ := json.Marshal(s)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+	}
+	send(current)
+
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case s := <-ch:
+			send(s)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// monitorPageHandler serves the full-screen playback page
+func monitorPageHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, monitorPage)
+}
+
+const monitorPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Motion Studio - Monitor</title>
+<style>
+  html, body { margin: 0; height: 100%; background: #000; overflow: hidden; cursor: none; }
+  video { width: 100%; height: 100%; object-fit: contain; }
+</style>
+</head>
+<body>
+<video id="v" playsinline></video>
+<script>
+  const v = document.getElementById("v");
+  let source = "";
+  new EventSource("/monitor/events").onmessage = (e) => {
+    const s = JSON.parse(e.data);
+    let src = "/preview.mp4?v=" + s.version;
+    if (s.path) {
+      // Same convention as the main window; the mux would collapse "//" for shares
+      let p = s.path.replace(/\\/g, "/");
+      p = p.startsWith("//") ? "UNC/" + p.slice(2) : p.replace(/^\/+/, "");
+      src = "/video/" + p.split("/").map(encodeURIComponent).join("/");
+    }
+    if (src !== source) {
+      source = src;
+      v.src = src;
+    }
+    // Compensate for the time since the main window sampled the playhead
+    const target = s.time + (s.playing ? (Date.now() - s.stamp) / 1000 * s.rate : 0);
+    if (Math.abs(v.currentTime - target) > 0.15) v.currentTime = target;
+    v.playbackRate = s.rate;
+    if (s.playing) v.play().catch(() => {}); else v.pause();
+  };
+</script>
+</body>
+</html>`
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- MOODBOARD ---
+
+// Reference images with notes for each scene.
+
+type MoodboardItem struct {
+	ID        string `json:"id"`
+	Path      string `json:"path"`
+	Note      string `json:"note"`
+	Order     int    `json:"order"`
+	Source    string `json:"source"` // Original file name
+	CreatedAt string `json:"createdAt"`
+}
+
+func (a *App) getMoodboardDir(projectId string, sceneId string) string {
+	dir := filepath.Join(a.getAppDir(), projectId, "scenes", sceneId, "moodboard")
+	os.MkdirAll(dir, 0755)
+	return dir
+}
+
+func (a *App) getMoodboardPath(projectId string, sceneId string) string {
+	return filepath.Join(a.getAppDir(), projectId, "scenes", sceneId, "moodboard.json")
+}
+
+// GetMoodboard returns a scene's reference images in board order
+func (a *App) GetMoodboard(projectId string, sceneId string) []MoodboardItem {
+	items := []MoodboardItem{}
+	data, err := os.ReadFile(a.getMoodboardPath(projectId, sceneId))
+	if err != nil {
+		return items
+	}
+	json.Unmarshal(data, &items)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Order < items[j].Order })
+	return items
+}
+
+func (a *App) saveMoodboard(projectId string, sceneId string, items []MoodboardItem, message string) {
+	for i := range items {
+		items[i].Order = i
+	}
+	data, _ := json.MarshalIndent(items, "", "  ")
+	os.WriteFile(a.getMoodboardPath(projectId, sceneId), data, 0644)
+	a.recordHistory(projectId, message+" in scene "+a.sceneLabel(projectId, sceneId))
+}
+
+// ImportMoodboardImages lets the user pick reference images and copies them
+// into the scene's board. Returns the updated board.
+func (a *App) ImportMoodboardImages(projectId string, sceneId string) []MoodboardItem {
+	selection, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Add Reference Images",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Images", Pattern: "*.png;*.jpg;*.jpeg;*.webp;*.gif"},
+		},
+	})
+	if err != nil || len(selection) == 0 {
+		return a.GetMoodboard(projectId, sceneId)
+	}
+	return a.AddMoodboardImages(projectId, sceneId, selection)
+}
+
+// AddMoodboardImages copies the given files (e.g. dropped onto the board)
+// into the scene's board
+func (a *App) AddMoodboardImages(projectId string, sceneId string, paths []string) []MoodboardItem {
+	items := a.GetMoodboard(projectId, sceneId)
+	dir := a.getMoodboardDir(projectId, sceneId)
+
+	added := 0
+	for _, src := range paths {
+		id := uuid.New().String()
+		dest := filepath.Join(dir, id+filepath.Ext(src))
+		if err := copyFile(src, dest); err != nil {
+			fmt.Println("Moodboard:", err)
+			continue
+		}
+		items = append(items, MoodboardItem{
+			ID:        id,
+			Path:      dest,
+			Source:    filepath.Base(src),
+			CreatedAt: time.Now().Format(time.RFC3339),
+		})
+		added++
+	}
+	if added > 0 {
+		a.saveMoodboard(projectId, sceneId, items, fmt.Sprintf("Add %d reference image(s)", added))
+	}
+	return items
+}
+
+// UpdateMoodboardNote sets the note on a reference image
+func (a *App) UpdateMoodboardNote(projectId string, sceneId string, itemId string, note string) {
+	items := a.GetMoodboard(projectId, sceneId)
+	for i := range items {
+		if items[i].ID == itemId {
+			items[i].Note = note
+			a.saveMoodboard(projectId, sceneId, items, "Edit reference note")
+			return
+		}
+	}
+}
+
+// ReorderMoodboard applies a new order given as item ids; unknown ids are
+// ignored and items missing from the list keep their relative order at the end
+func (a *App) ReorderMoodboard(projectId string, sceneId string, ids []string) []MoodboardItem {
+	items := a.GetMoodboard(projectId, sceneId)
+	byID := map[string]MoodboardItem{}
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	ordered := []MoodboardItem{}
+	for _, id := range ids {
+		if item, ok := byID[id]; ok {
+			ordered = append(ordered, item)
+			delete(byID, id)
+		}
+	}
+	for _, item := range items {
+		if _, ok := byID[item.ID]; ok {
+			ordered = append(ordered, item)
+		}
+	}
+	a.saveMoodboard(projectId, sceneId, ordered, "Reorder references")
+	return ordered
+}
+
+// DeleteMoodboardItem removes a reference image and its file
+func (a *App) DeleteMoodboardItem(projectId string, sceneId string, itemId string) []MoodboardItem {
+	items := a.GetMoodboard(projectId, sceneId)
+	kept := []MoodboardItem{}
+	for _, item := range items {
+		if item.ID == itemId {
+			os.Remove(item.Path)
+			continue
+		}
+		kept = append(kept, item)
+	}
+	if len(kept) != len(items) {
+		a.saveMoodboard(projectId, sceneId, kept, "Remove reference image")
+	}
+	return kept
+}
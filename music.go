@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- MUSIC GENERATION ---
+
+// Generates soundtracks through a configurable HTTP backend.
+
+type MusicSettings struct {
+	Endpoint      string                 `json:"endpoint"`      // POST URL
+	PromptField   string                 `json:"promptField"`   // "" = prompt
+	DurationField string                 `json:"durationField"` // "" = duration; seconds
+	Fields        map[string]interface{} `json:"fields"`        // Sent with every request (model, format, ...)
+}
+
+const (
+	musicMaxDuration = 600.0
+	musicPollEvery   = 3 * time.Second
+	musicTimeout     = 15 * time.Minute
+)
+
+// musicExtensions name files by the audio type a backend sent
+var musicExtensions = map[string]string{
+	"audio/mpeg": ".mp3", "audio/mp3": ".mp3",
+	"audio/wav": ".wav", "audio/x-wav": ".wav", "audio/wave": ".wav",
+	"audio/flac": ".flac", "audio/x-flac": ".flac",
+	"audio/ogg": ".ogg", "audio/mp4": ".m4a", "audio/aac": ".m4a",
+}
+
+// musicURLFields are the reply fields audio URLs are found in
+var musicURLFields = []string{"audio_url", "audioUrl", "url", "output", "audio", "result", "data"}
+
+// GetMusicSettings returns the music backend settings
+func (a *App) GetMusicSettings() MusicSettings {
+	return a.getConfig().Music
+}
+
+// SaveMusicSettings persists the music backend settings
+func (a *App) SaveMusicSettings(settings MusicSettings) string {
+	settings.Endpoint = strings.TrimSpace(settings.Endpoint)
+	if settings.Endpoint != "" && !strings.HasPrefix(settings.Endpoint, "http://") && !strings.HasPrefix(settings.Endpoint, "https://") {
+		return "Error: endpoint must be an http(s) URL"
+	}
+	settings.PromptField = strings.TrimSpace(settings.PromptField)
+	settings.DurationField = strings.TrimSpace(settings.DurationField)
+	a.updateConfig(func(c *Config) { c.Music = settings })
+	return "Success"
+}
+
+// GenerateMusic generates a soundtrack and returns its path in the project's assets
+func (a *App) GenerateMusic(projectId string, prompt string, duration float64) (string, error) {
+	settings := a.getConfig().Music
+	if settings.Endpoint == "" {
+		return "", fmt.Errorf("no music backend is set")
+	}
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return "", fmt.Errorf("the prompt is empty")
+	}
+	if duration <= 0 || duration > musicMaxDuration {
+		return "", fmt.Errorf("duration must be between 1 and %g seconds", musicMaxDuration)
+	}
+	if _, err := a.GetProject(projectId); err != nil {
+		return "", fmt.Errorf("project not found")
+	}
+
+	body := map[string]interface{}{}
+	for k, v := range settings.Fields {
+		body[k] = v
+	}
+	promptField, durationField := settings.PromptField, settings.DurationField
+	if promptField == "" {
+		promptField = "prompt"
+	}
+	if durationField == "" {
+		durationField = "duration"
+	}
+	body[promptField] = prompt
+	body[durationField] = duration
+
+	a.emitMusicStatus("generating", "")
+	path, err := a.generateMusic(projectId, settings, body)
+	if err != nil {
+		recordEngineError("music", err.Error())
+		a.emitMusicStatus("error", err.Error())
+		return "", err
+	}
+	a.emitMusicStatus("done", path)
+	return path, nil
+}
+
+func (a *App) emitMusicStatus(status string, detail string) {
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "music:status", map[string]string{"status": status, "detail": detail})
+	}
+}
+
+func (a *App) generateMusic(projectId string, settings MusicSettings, body map[string]interface{}) (string, error) {
+	data, _ := json.Marshal(body)
+	resp, err := a.musicRequest("POST", settings.Endpoint, data)
+	if err != nil {
+		return "", err
+	}
+	deadline := time.Now().Add(musicTimeout)
+	for {
+		if audio, contentType, ok := musicAudio(resp); ok {
+			return a.saveMusic(projectId, audio, contentType, "")
+		}
+		var reply map[string]interface{}
+		if err := json.Unmarshal(resp.Body, &reply); err != nil {
+			return "", fmt.Errorf("unexpected reply from the music backend (%s)", resp.ContentType)
+		}
+		if message := musicError(reply); message != "" {
+			return "", fmt.Errorf("music generation failed: %s", message)
+		}
+		if url := findAudioURL(reply); url != "" {
+			download, err := a.musicRequest("GET", url, nil)
+			if err != nil {
+				return "", err
+			}
+			return a.saveMusic(projectId, download.Body, download.ContentType, url)
+		}
+
+		// A job still running: poll it
+		poll := musicPollURL(reply)
+		if poll == "" {
+			return "", fmt.Errorf("the music backend returned no audio")
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("music generation timed out")
+		}
+		time.Sleep(musicPollEvery)
+		if resp, err = a.musicRequest("GET", poll, nil); err != nil {
+			return "", err
+		}
+	}
+}
+
+type musicResponse struct {
+	Body        []byte
+	ContentType string
+}
+
+func (a *App) musicRequest(method string, url string, body []byte) (musicResponse, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return musicResponse{}, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if key := a.getCredential(CredMusicKey); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := (&http.Client{Timeout: musicTimeout}).Do(req)
+	if err != nil {
+		return musicResponse{}, fmt.Errorf("music backend unreachable: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return musicResponse{}, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return musicResponse{}, fmt.Errorf("music backend error (Status %d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return musicResponse{Body: data, ContentType: resp.Header.Get("Content-Type")}, nil
+}
+
+// musicAudio reports whether a reply is the audio itself
+func musicAudio(resp musicResponse) ([]byte, string, bool) {
+	mediaType, _, _ := mime.ParseMediaType(resp.ContentType)
+	return resp.Body, mediaType, strings.HasPrefix(mediaType, "audio/") || mediaType == "application/octet-stream"
+}
+
+// findAudioURL looks for an http(s) URL in the usual reply fields, also
+// inside lists and nested objects
+func findAudioURL(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://") {
+			return v
+		}
+	case []interface{}:
+		for _, item := range v {
+			if url := findAudioURL(item); url != "" {
+				return url
+			}
+		}
+	case map[string]interface{}:
+		for _, field := range musicURLFields {
+			if url := findAudioURL(v[field]); url != "" {
+				return url
+			}
+		}
+	}
+	return ""
+}
+
+// musicPollURL is where a running job's status can be read
+func musicPollURL(reply map[string]interface{}) string {
+	status, _ := reply["status"].(string)
+	switch strings.ToLower(status) {
+	case "", "succeeded", "completed", "complete", "done":
+		return ""
+	}
+	if urls, ok := reply["urls"].(map[string]interface{}); ok {
+		if get, _ := urls["get"].(string); get != "" {
+			return get
+		}
+	}
+	for _, field := range []string{"status_url", "statusUrl", "poll_url", "response_url"} {
+		if url, _ := reply[field].(string); url != "" {
+			return url
+		}
+	}
+	return ""
+}
+
+// musicError is a failed job's message
+func musicError(reply map[string]interface{}) string {
+	status, _ := reply["status"].(string)
+	switch strings.ToLower(status) {
+	case "failed", "error", "canceled", "cancelled":
+		if message, ok := reply["error"].(string); ok && message != "" {
+			return message
+		}
+		return status
+	}
+	return ""
+}
+
+// saveMusic writes generated audio to the project's assets
+func (a *App) saveMusic(projectId string, audio []byte, contentType string, url string) (string, error) {
+	if len(audio) == 0 {
+		return "", fmt.Errorf("the music backend returned an empty file")
+	}
+	ext := strings.ToLower(filepath.Ext(strings.SplitN(url, "?", 2)[0]))
+	if !strings.HasPrefix(mediaTypes[ext], "audio/") {
+		ext = musicExtensions[contentType]
+	}
+	if ext == "" {
+		ext = ".mp3"
+	}
+	assetsDir := filepath.Join(a.getAppDir(), projectId, "assets")
+	os.MkdirAll(assetsDir, 0755)
+	path := filepath.Join(assetsDir, fmt.Sprintf("music_%d%s", time.Now().UnixNano(), ext))
+	if err := os.WriteFile(path, audio, 0644); err != nil {
+		return "", fmt.Errorf("failed to save the music: %v", err)
+	}
+	return path, nil
+}
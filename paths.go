@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+)
+
+// --- WINDOWS PATH HANDLING ---
+
+// Long and UNC paths prepared for ffmpeg and concat lists on Windows.
+
+// maxShortPath is where Win32 starts failing: 248 for directories, 260 for files
+const maxShortPath = 248
+
+func isWindows() bool { return goruntime.GOOS == "windows" }
+
+// isUNCPath reports whether p is a network path (\\server\share or //server/share)
+func isUNCPath(p string) bool {
+	return len(p) > 2 && (p[0] == '\\' || p[0] == '/') && (p[1] == '\\' || p[1] == '/') && p[2] != '?' && p[2] != '.'
+}
+
+// mediaPath prepares a path for ffmpeg/ffprobe. On Windows, absolute paths
+// that are too long get the extended-length prefix (\\?\C:\... or
+// \\?\UNC\server\share\...). Everything else is returned unchanged.
+func mediaPath(p string) string {
+	if !isWindows() || len(p) < maxShortPath {
+		return p
+	}
+	p = strings.ReplaceAll(p, "/", `\`)
+	if strings.HasPrefix(p, `\\?\`) || strings.HasPrefix(p, `\\.\`) {
+		return p
+	}
+	// The prefix disables normalization, so the path must already be clean
+	p = filepath.Clean(p)
+	if isUNCPath(p) {
+		return `\\?\UNC\` + p[2:]
+	}
+	if len(p) >= 3 && p[1] == ':' && p[2] == '\\' {
+		return `\\?\` + p
+	}
+	return p
+}
+
+// concatEntry formats one "file" line of an ffconcat list. Local paths use
+// forward slashes as before; UNC and extended-length paths keep backslashes,
+// which are literal inside the quotes.
+func concatEntry(p string) string {
+	entry := mediaPath(p)
+	if !isWindows() || (!isUNCPath(entry) && !strings.HasPrefix(entry, `\\?\`)) {
+		entry = filepath.ToSlash(entry)
+	}
+	return fmt.Sprintf("file '%s'\n", strings.ReplaceAll(entry, "'", "'\\''"))
+}
+
+// videoRequestPath maps the decoded part of a /video/ URL to a file path.
+// Network shares arrive as "//NAS/share/..." or, from clients that can't keep
+// a double slash in a URL path, as "UNC/NAS/share/...". On macOS and Linux
+// the leading slash of an absolute path may have been collapsed the same way.
+func videoRequestPath(decoded string) string {
+	if isWindows() {
+		if rest, ok := strings.CutPrefix(decoded, "UNC/"); ok {
+			decoded = "//" + rest
+		}
+	} else if !strings.HasPrefix(decoded, "/") {
+		decoded = "/" + decoded
+	}
+	return filepath.FromSlash(decoded)
+}
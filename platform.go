@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"sync"
+)
+
+// --- PLATFORM SUPPORT ---
+
+var (
+	appDataDirOnce sync.Once
+	appDataDir     string
+)
+
+// resolveAppDir picks the data directory: Documents/MotionStudio on Windows
+// and macOS, the XDG data directory on Linux. Existing Linux installs that
+// already use Documents/MotionStudio keep it so nothing is orphaned.
+func resolveAppDir() string {
+	homeDir, _ := os.UserHomeDir()
+	legacy := filepath.Join(homeDir, "Documents", "MotionStudio")
+	if goruntime.GOOS != "linux" {
+		return legacy
+	}
+	if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+		return legacy
+	}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" || !filepath.IsAbs(dataHome) {
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+	return filepath.Join(dataHome, "motion-studio")
+}
+
+// ffmpegSearchDirs are checked when ffmpeg isn't on PATH. GUI launches on
+// Linux (desktop files) and macOS (Finder) often get a minimal PATH.
+func ffmpegSearchDirs() []string {
+	homeDir, _ := os.UserHomeDir()
+	switch goruntime.GOOS {
+	case "windows":
+		return []string{
+			`C:\ffmpeg\bin`,
+			filepath.Join(os.Getenv("ProgramFiles"), "ffmpeg", "bin"),
+			filepath.Join(os.Getenv("LOCALAPPDATA"), "Microsoft", "WinGet", "Links"),
+			filepath.Join(homeDir, "scoop", "shims"),
+			`C:\ProgramData\chocolatey\bin`,
+		}
+	case "darwin":
+		return []string{"/opt/homebrew/bin", "/usr/local/bin", "/opt/local/bin"}
+	default:
+		return []string{
+			"/usr/bin",
+			"/usr/local/bin",
+			"/snap/bin",
+			"/var/lib/flatpak/exports/bin",
+			"/home/linuxbrew/.linuxbrew/bin",
+			filepath.Join(homeDir, ".linuxbrew", "bin"),
+			filepath.Join(homeDir, ".local", "bin"),
+			"/opt/ffmpeg/bin",
+		}
+	}
+}
+
+// ensureFFmpegPath makes ffmpeg/ffprobe resolvable by every exec.Command call
+// by appending the first directory that contains them to PATH.
+func ensureFFmpegPath() {
+	if _, err := exec.LookPath("ffmpeg"); err == nil {
+		return
+	}
+	name := "ffmpeg"
+	if goruntime.GOOS == "windows" {
+		name = "ffmpeg.exe"
+	}
+	for _, dir := range ffmpegSearchDirs() {
+		if dir == "" {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+			os.Setenv("PATH", os.Getenv("PATH")+string(os.PathListSeparator)+dir)
+			fmt.Println("🎬 Found ffmpeg in", dir)
+			return
+		}
+	}
+	fmt.Println("⚠️ ffmpeg not found on PATH or in common locations")
+}
+
+// audioCaptureInput returns the ffmpeg input arguments for the default
+// microphone. On Linux, PipeWire and PulseAudio both serve the pulse API;
+// plain ALSA is the fallback.
+func audioCaptureInput() []string {
+	switch goruntime.GOOS {
+	case "windows":
+		return []string{"-f", "dshow", "-i", "audio=" + defaultDshowAudioDevice()}
+	case "darwin":
+		return []string{"-f", "avfoundation", "-i", ":0"}
+	default:
+		if hasPulseServer() {
+			return []string{"-f", "pulse", "-i", "default"}
+		}
+		return []string{"-f", "alsa", "-i", "default"}
+	}
+}
+
+// hasPulseServer reports whether a PipeWire or PulseAudio socket is available
+func hasPulseServer() bool {
+	if os.Getenv("PULSE_SERVER") != "" {
+		return true
+	}
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		return false
+	}
+	for _, socket := range []string{"pulse/native", "pipewire-0"} {
+		if _, err := os.Stat(filepath.Join(runtimeDir, socket)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultDshowAudioDevice returns the first DirectShow audio device name
+func defaultDshowAudioDevice() string {
+	// ffmpeg prints the device list on stderr and exits non-zero
+	out, _ := exec.Command("ffmpeg", "-hide_banner", "-list_devices", "true", "-f", "dshow", "-i", "dummy").CombinedOutput()
+	for _, line := range strings.Split(string(out), "\n") {
+		if !strings.Contains(line, "(audio)") {
+			continue
+		}
+		if start := strings.Index(line, "\""); start >= 0 {
+			if end := strings.Index(line[start+1:], "\""); end >= 0 {
+				return line[start+1 : start+1+end]
+			}
+		}
+	}
+	return "default"
+}
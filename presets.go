@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- PRESETS ---
+
+// Named presets in presets/<kind>/, shareable as .mspreset files.
+
+type PresetFile struct {
+	Format    string      `json:"format"` // Always "motion-studio-preset"
+	Version   int         `json:"version"`
+	Kind      string      `json:"kind"` // export, style, node-mappings, workflow
+	Name      string      `json:"name"`
+	CreatedAt string      `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+type workflowPreset struct {
+	Workflow json.RawMessage              `json:"workflow"`
+	Mappings map[string]map[string]string `json:"mappings"`
+}
+
+const (
+	presetFormat    = "motion-studio-preset"
+	presetVersion   = 1
+	presetExtension = ".mspreset"
+)
+
+// storedPresetKinds are plain JSON presets kept in the presets folder
+var storedPresetKinds = map[string]bool{"export": true, "style": true}
+
+func (a *App) getPresetDir(kind string) string {
+	dir := filepath.Join(a.getAppDir(), "presets", kind)
+	os.MkdirAll(dir, 0755)
+	return dir
+}
+
+func presetFileName(name string) (string, error) {
+	safe := sanitizeFileName(name)
+	if safe == "" || safe == "." || safe == ".." {
+		return "", fmt.Errorf("invalid preset name %q", name)
+	}
+	return safe + ".json", nil
+}
+
+// GetPresets lists the preset names of a stored kind
+func (a *App) GetPresets(kind string) []string {
+	names := []string{}
+	if !storedPresetKinds[kind] {
+		return names
+	}
+	entries, _ := os.ReadDir(a.getPresetDir(kind))
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetPreset returns a stored preset's data
+func (a *App) GetPreset(kind string, name string) (interface{}, error) {
+	if !storedPresetKinds[kind] {
+		return nil, fmt.Errorf("unknown preset kind %q", kind)
+	}
+	file, err := presetFileName(name)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(filepath.Join(a.getPresetDir(kind), file))
+	if err != nil {
+		return nil, err
+	}
+	var data interface{}
+	err = json.Unmarshal(raw, &data)
+	return data, err
+}
+
+// SavePreset stores (or replaces) a named preset
+func (a *App) SavePreset(kind string, name string, data interface{}) error {
+	if !storedPresetKinds[kind] {
+		return fmt.Errorf("unknown preset kind %q", kind)
+	}
+	file, err := presetFileName(name)
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(a.getPresetDir(kind), file), raw, 0644)
+}
+
+// DeletePreset removes a stored preset
+func (a *App) DeletePreset(kind string, name string) {
+	if file, err := presetFileName(name); err == nil && storedPresetKinds[kind] {
+		os.Remove(filepath.Join(a.getPresetDir(kind), file))
+	}
+}
+
+// buildPresetFile gathers the data for a portable preset
+func (a *App) buildPresetFile(kind string, name string) (PresetFile, error) {
+	preset := PresetFile{
+		Format:    presetFormat,
+		Version:   presetVersion,
+		Kind:      kind,
+		Name:      name,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+
+	switch {
+	case storedPresetKinds[kind]:
+		data, err := a.GetPreset(kind, name)
+		if err != nil {
+			return preset, err
+		}
+		preset.Data = data
+	case kind == "node-mappings":
+		a.mappingsMu.RLock()
+		preset.Data = maps.Clone(a.nodeMappings)
+		a.mappingsMu.RUnlock()
+	case kind == "workflow":
+		file, err := presetFileName(name)
+		if err != nil {
+			return preset, err
+		}
+		raw, err := os.ReadFile(filepath.Join(a.getWorkflowsDir(), file))
+		if err != nil {
+			return preset, err
+		}
+		preset.Data = workflowPreset{Workflow: raw, Mappings: a.workflowMappings(raw)}
+	default:
+		return preset, fmt.Errorf("unknown preset kind %q", kind)
+	}
+	return preset, nil
+}
+
+// workflowMappings picks the node mappings for the nodes a workflow uses
+func (a *App) workflowMappings(raw []byte) map[string]map[string]string {
+	var nodes map[string]struct {
+		ClassType string `json:"class_type"`
+	}
+	json.Unmarshal(raw, &nodes)
+	mappings := map[string]map[string]string{}
+	for _, node := range nodes {
+		if rules := a.nodeRules(node.ClassType); rules != nil {
+			mappings[node.ClassType] = rules
+		}
+	}
+	return mappings
+}
+
+// ExportPresetFile saves a preset as a portable .mspreset file chosen by the user
+func (a *App) ExportPresetFile(kind string, name string) string {
+	preset, err := a.buildPresetFile(kind, name)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+
+	outPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Preset",
+		DefaultFilename: sanitizeFileName(kind+" - "+name) + presetExtension,
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Motion Studio Preset", Pattern: "*" + presetExtension},
+		},
+	})
+	if err != nil || outPath == "" {
+		return "Cancelled"
+	}
+
+	data, _ := json.MarshalIndent(preset, "", "  ")
+	if err := os.WriteFile(outPath, data, 0644); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Success"
+}
+
+// ImportPresetFile lets the user pick a .mspreset file and installs it.
+// Returns the imported preset (without data) so the UI can refresh that kind.
+func (a *App) ImportPresetFile() (PresetFile, error) {
+	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Import Preset",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Motion Studio Preset", Pattern: "*" + presetExtension + ";*.json"},
+		},
+	})
+	if err != nil || selection == "" {
+		return PresetFile{}, fmt.Errorf("cancelled")
+	}
+	raw, err := os.ReadFile(selection)
+	if err != nil {
+		return PresetFile{}, err
+	}
+	return a.importPreset(raw)
+}
+
+func (a *App) importPreset(raw []byte) (PresetFile, error) {
+	var preset PresetFile
+	if err := json.Unmarshal(raw, &preset); err != nil {
+		return preset, fmt.Errorf("not a preset file: %v", err)
+	}
+	if preset.Format != presetFormat {
+		return preset, fmt.Errorf("not a Motion Studio preset")
+	}
+	if preset.Version > presetVersion {
+		return preset, fmt.Errorf("preset was made by a newer version (v%d)", preset.Version)
+	}
+
+	switch {
+	case storedPresetKinds[preset.Kind]:
+		if err := a.SavePreset(preset.Kind, preset.Name, preset.Data); err != nil {
+			return preset, err
+		}
+	case preset.Kind == "node-mappings":
+		var mappings map[string]map[string]string
+		if err := remarshal(preset.Data, &mappings); err != nil {
+			return preset, err
+		}
+		a.mergeNodeMappings(mappings)
+	case preset.Kind == "workflow":
+		var wf workflowPreset
+		if err := remarshal(preset.Data, &wf); err != nil {
+			return preset, err
+		}
+		// Checked and named like a workflow bundle, never over a local workflow
+		if err := validateAPIWorkflow(wf.Workflow); err != nil {
+			return preset, err
+		}
+		a.mergeNodeMappings(wf.Mappings)
+		name, err := a.installWorkflow(preset.Name, wf.Workflow)
+		if err != nil {
+			return preset, err
+		}
+		preset.Name = name
+	default:
+		return preset, fmt.Errorf("unknown preset kind %q", preset.Kind)
+	}
+
+	preset.Data = nil
+	return preset, nil
+}
+
+// mergeNodeMappings applies imported rules over the local ones
+func (a *App) mergeNodeMappings(mappings map[string]map[string]string) {
+	if len(mappings) == 0 {
+		return
+	}
+	a.mappingsMu.Lock()
+	for classType, rules := range mappings {
+		a.nodeMappings[classType] = rules
+	}
+	a.mappingsMu.Unlock()
+	a.saveNodeMappings()
+}
+
+// remarshal converts decoded JSON (interface{}) into a typed value
+func remarshal(in interface{}, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
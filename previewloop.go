@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- ANIMATED PROJECT THUMBNAILS ---
+
+// Short looping previews of each project for the home screen.
+
+const (
+	previewLoopShots    = 6
+	previewLoopDuration = 2.0
+	previewLoopFile     = "preview_loop.mp4"
+)
+
+var (
+	loopMu      sync.Mutex
+	loopPending = map[string]bool{}
+	loopTimer   *time.Timer
+)
+
+// schedulePreviewLoop queues a project's loop for a rebuild; renders in quick
+// succession are batched
+func (a *App) schedulePreviewLoop(projectId string) {
+	loopMu.Lock()
+	defer loopMu.Unlock()
+
+	loopPending[projectId] = true
+	if loopTimer != nil {
+		loopTimer.Stop()
+	}
+	loopTimer = time.AfterFunc(10*time.Second, a.buildPendingPreviewLoops)
+}
+
+func (a *App) buildPendingPreviewLoops() {
+	loopMu.Lock()
+	pending := loopPending
+	loopPending = map[string]bool{}
+	loopMu.Unlock()
+
+	for projectId := range pending {
+		for atomic.LoadInt32(&foregroundJobs) > 0 || isQueuePaused() {
+			time.Sleep(2 * time.Second)
+		}
+		if err := a.buildPreviewLoop(projectId); err != nil {
+			fmt.Println("Preview loop:", err)
+			continue
+		}
+		runtime.EventsEmit(a.ctx, "project:previewLoop", projectId)
+	}
+}
+
+// refreshPreviewLoops queues every project whose loop is missing or older than
+// its newest render
+func (a *App) refreshPreviewLoops() {
+	for _, p := range a.GetProjects() {
+		clips := a.recentRenderedShots(p.ID)
+		if len(clips) == 0 {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(a.getAppDir(), p.ID, previewLoopFile))
+		if err != nil || info.ModTime().Before(clips[0].modTime) {
+			a.schedulePreviewLoop(p.ID)
+		}
+	}
+}
+
+type renderedClip struct {
+	path    string
+	modTime time.Time
+}
+
+// recentRenderedShots returns the project's rendered shots, newest first
+func (a *App) recentRenderedShots(projectId string) []renderedClip {
+	var clips []renderedClip
+	for _, scene := range a.GetScenes(projectId) {
+		for _, shot := range a.GetShots(projectId, scene.ID) {
+			if shot.OutputVideo == "" {
+				continue
+			}
+			if info, err := os.Stat(shot.OutputVideo); err == nil {
+				clips = append(clips, renderedClip{path: shot.OutputVideo, modTime: info.ModTime()})
+			}
+		}
+	}
+	sort.Slice(clips, func(i, j int) bool { return clips[i].modTime.After(clips[j].modTime) })
+	return clips
+}
+
+// buildPreviewLoop cuts a slice from the middle of each recent shot and
+// concatenates them into a small looping montage
+func (a *App) buildPreviewLoop(projectId string) error {
+	clips := a.recentRenderedShots(projectId)
+	if len(clips) == 0 {
+		return nil
+	}
+	if len(clips) > previewLoopShots {
+		clips = clips[:previewLoopShots]
+	}
+	slice := previewLoopDuration / float64(len(clips))
+
+	args := []string{"-y"}
+	var filter strings.Builder
+	for i, c := range clips {
+		start := a.getVideoDuration(c.path)/2 - slice/2
+		if start < 0 {
+			start = 0
+		}
+		args = append(args, "-ss", fmt.Sprintf("%f", start), "-t", fmt.Sprintf("%f", slice), "-i", mediaPath(c.path))
+		fmt.Fprintf(&filter, "[%d:v]scale=320:180:force_original_aspect_ratio=decrease,pad=320:180:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=12,format=yuv420p[v%d];", i, i)
+	}
+	for i := range clips {
+		fmt.Fprintf(&filter, "[v%d]", i)
+	}
+	fmt.Fprintf(&filter, "concat=n=%d:v=1:a=0[out]", len(clips))
+
+	out := filepath.Join(a.getAppDir(), projectId, previewLoopFile)
+	tmp := out + ".partial.mp4"
+	args = append(args,
+		"-filter_complex", filter.String(),
+		"-map", "[out]", "-an",
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", "28",
+		"-movflags", "+faststart",
+		tmp)
+
+	cmd := exec.Command("ffmpeg", args...)
+	if output, err := combinedOutputTracked(cmd); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%v: %s", err, string(output))
+	}
+	return os.Rename(tmp, out)
+}
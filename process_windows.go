@@ -0,0 +1,39 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Windows has no SIGSTOP; ntdll's NtSuspendProcess freezes every thread
+var (
+	ntdll            = syscall.NewLazyDLL("ntdll.dll")
+	ntSuspendProcess = ntdll.NewProc("NtSuspendProcess")
+	ntResumeProcess  = ntdll.NewProc("NtResumeProcess")
+)
+
+const processSuspendResume = 0x0800
+
+func suspendProcess(p *os.Process) error {
+	return callProcessProc(ntSuspendProcess, p)
+}
+
+func resumeProcess(p *os.Process) error {
+	return callProcessProc(ntResumeProcess, p)
+}
+
+func callProcessProc(proc *syscall.LazyProc, p *os.Process) error {
+	handle, err := syscall.OpenProcess(processSuspendResume, false, uint32(p.Pid))
+	if err != nil {
+		return err
+	}
+	defer syscall.CloseHandle(handle)
+
+	if status, _, _ := proc.Call(uintptr(handle)); status != 0 {
+		return fmt.Errorf("%s failed: NTSTATUS 0x%x", proc.Name, status)
+	}
+	return nil
+}
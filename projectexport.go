@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- PROJECT EXPORT ---
+
+// Exports every scene of a project back to back.
+
+type ProjectExportOptions struct {
+	Export         ExportOptions `json:"export"`
+	SceneIDs       []string      `json:"sceneIds"`       // Order to export, empty = every scene
+	Slates         bool          `json:"slates"`         // Slate before each scene
+	SlateCountdown bool          `json:"slateCountdown"` // Add the 2-pop leader to each slate
+	GapSeconds     float64       `json:"gapSeconds"`     // Black between scenes
+}
+
+// appendSceneTracks adds a scene's tracks to the assembled timeline, shifted
+// to offset. Pair ids are namespaced so pairs from different scenes don't mix.
+func appendSceneTracks(assembled *TimelineData, scene TimelineData, offset float64, namespace string) {
+	for i, track := range scene.Tracks {
+		setting := TrackSetting{Visible: true}
+		if i < len(scene.TrackSettings) {
+			setting = scene.TrackSettings[i]
+		}
+		shifted := make([]map[string]interface{}, 0, len(track))
+		for _, raw := range track {
+			item := make(map[string]interface{}, len(raw))
+			for k, v := range raw {
+				item[k] = v
+			}
+			start, _ := item["startTime"].(float64)
+			item["startTime"] = start + offset
+			if pair, _ := item["pairId"].(string); pair != "" {
+				item["pairId"] = namespace + "/" + pair
+			}
+			shifted = append(shifted, item)
+		}
+		assembled.Tracks = append(assembled.Tracks, shifted)
+		assembled.TrackSettings = append(assembled.TrackSettings, setting)
+	}
+}
+
+// assembleProjectTimeline lays the selected scenes end to end, rendering
+// slates as processes of run
+func (a *App) assembleProjectTimeline(run *exportRun, projectId string, opts ProjectExportOptions) (TimelineData, error) {
+	sceneIds := opts.SceneIDs
+	if len(sceneIds) == 0 {
+		for _, scene := range a.GetScenes(projectId) {
+			sceneIds = append(sceneIds, scene.ID)
+		}
+	}
+
+	var assembled TimelineData
+	offset := 0.0
+	for i, sceneId := range sceneIds {
+		timeline := a.GetTimeline(projectId, sceneId)
+		length := timelineDuration(timeline)
+		if length <= 0 {
+			continue // Empty scenes are skipped rather than exported as black
+		}
+
+		if opts.Slates {
+			runtime.EventsEmit(a.ctx, "export:status", tr("project.slate", i+1, len(sceneIds)))
+			slate, err := a.generateSlate(run, projectId, sceneId, SlateOptions{Countdown: opts.SlateCountdown})
+			if err != nil {
+				return assembled, fmt.Errorf("slate for scene %s: %v", sceneId, err)
+			}
+			slateLength := a.getVideoDuration(slate)
+			item := func() map[string]interface{} {
+				return map[string]interface{}{"id": "slate-" + sceneId, "startTime": offset, "duration": slateLength, "trimStart": 0.0, "outputVideo": slate}
+			}
+			assembled.Tracks = append(assembled.Tracks, []map[string]interface{}{item()}, []map[string]interface{}{item()})
+			assembled.TrackSettings = append(assembled.TrackSettings,
+				TrackSetting{Visible: true, Type: "video", Name: "V-slate"},
+				TrackSetting{Visible: true, Type: "audio", Name: "A-slate"})
+			offset += slateLength
+		}
+
+		appendSceneTracks(&assembled, timeline, offset, fmt.Sprint(i))
+		offset += length
+		if i < len(sceneIds)-1 {
+			offset += max(opts.GapSeconds, 0)
+		}
+	}
+	if len(assembled.Tracks) == 0 {
+		return assembled, fmt.Errorf("%s", tr("export.error.emptyTimeline"))
+	}
+	return assembled, nil
+}
+
+// ExportProject asks for a destination and exports all scenes in one file
+func (a *App) ExportProject(projectId string, opts ProjectExportOptions) string {
+	options := opts.Export
+	outPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           tr("export.dialogTitle", strings.ToUpper(options.Format)),
+		DefaultFilename: "project." + options.Format,
+		Filters: []runtime.FileFilter{
+			{DisplayName: tr("export.fileFilter", strings.ToUpper(options.Format)), Pattern: "*." + options.Format},
+		},
+	})
+	if err != nil || outPath == "" {
+		return "Cancelled"
+	}
+	rememberExportDir(filepath.Dir(outPath))
+
+	result := a.exportProject(projectId, opts, outPath)
+	if result != "Success" && result != "Cancelled" {
+		recordEngineError("export", result)
+	}
+	return result
+}
+
+func (a *App) exportProject(projectId string, opts ProjectExportOptions, outPath string) string {
+	sceneIds := opts.SceneIDs
+	if len(sceneIds) == 0 {
+		for _, scene := range a.GetScenes(projectId) {
+			sceneIds = append(sceneIds, scene.ID)
+		}
+	}
+	// Per-scene sync warnings, since the assembled timeline has no scene
+	issues := []DurationIssue{}
+	for _, sceneId := range sceneIds {
+		issues = append(issues, a.ValidateSceneDurations(projectId, sceneId)...)
+	}
+	if len(issues) > 0 {
+		runtime.EventsEmit(a.ctx, "export:warnings", issues)
+	}
+
+	// One run covers the slates and the export, so it cancels as a whole
+	run := beginExportRun(nil)
+	defer endExportRun(run)
+	runtime.EventsEmit(a.ctx, "export:started", run.ID)
+
+	opts.SceneIDs = sceneIds
+	assembled, err := a.assembleProjectTimeline(run, projectId, opts)
+	if err != nil {
+		if run.isCanceled() {
+			runtime.EventsEmit(a.ctx, "export:cancelled", run.ID)
+			return "Cancelled"
+		}
+		return err.Error()
+	}
+	options := opts.Export
+	options.timeline = &assembled
+	options.run = run
+	result := a.exportTimeline(projectId, "", options, outPath)
+	if result == "Cancelled" {
+		runtime.EventsEmit(a.ctx, "export:cancelled", run.ID)
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// --- PROMPT FAVORITES ---
+
+// Starred prompts per project, applicable to any shot.
+
+type PromptFavorite struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Prompt    string `json:"prompt"`
+	ShotID    string `json:"shotId,omitempty"` // Shot it was starred from
+	CreatedAt string `json:"createdAt"`
+}
+
+var promptFavoritesMu sync.Mutex
+
+func (a *App) getPromptFavoritesPath(projectId string) string {
+	return filepath.Join(a.getAppDir(), projectId, "prompt_favorites.json")
+}
+
+// GetPromptFavorites returns a project's favorite prompts, oldest first
+func (a *App) GetPromptFavorites(projectId string) []PromptFavorite {
+	promptFavoritesMu.Lock()
+	defer promptFavoritesMu.Unlock()
+	return a.loadPromptFavorites(projectId)
+}
+
+func (a *App) loadPromptFavorites(projectId string) []PromptFavorite {
+	favorites := []PromptFavorite{}
+	if data, err := os.ReadFile(a.getPromptFavoritesPath(projectId)); err == nil {
+		json.Unmarshal(data, &favorites)
+	}
+	return favorites
+}
+
+func (a *App) savePromptFavorites(projectId string, favorites []PromptFavorite, message string) {
+	data, _ := json.MarshalIndent(favorites, "", "  ")
+	os.WriteFile(a.getPromptFavoritesPath(projectId), data, 0644)
+	a.recordHistory(projectId, message)
+}
+
+// AddPromptFavorite stars a prompt; name defaults to its first words. A
+// prompt that is already a favorite is returned as it is.
+func (a *App) AddPromptFavorite(projectId string, prompt string, name string, shotId string) (PromptFavorite, error) {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return PromptFavorite{}, fmt.Errorf("prompt is empty")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = promptTitle(prompt)
+	}
+
+	promptFavoritesMu.Lock()
+	defer promptFavoritesMu.Unlock()
+	favorites := a.loadPromptFavorites(projectId)
+	for _, f := range favorites {
+		if f.Prompt == prompt {
+			return f, nil
+		}
+	}
+	favorite := PromptFavorite{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Prompt:    prompt,
+		ShotID:    shotId,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+	a.savePromptFavorites(projectId, append(favorites, favorite), fmt.Sprintf("Add favorite prompt %q", name))
+	return favorite, nil
+}
+
+// FavoriteHistoryPrompt stars the prompt of a shot's history entry
+func (a *App) FavoriteHistoryPrompt(projectId string, sceneId string, shotId string, entryId string) (PromptFavorite, error) {
+	for _, e := range a.GetPromptHistory(projectId, sceneId, shotId) {
+		if e.ID == entryId {
+			return a.AddPromptFavorite(projectId, e.Settings.Prompt, "", shotId)
+		}
+	}
+	return PromptFavorite{}, fmt.Errorf("history entry not found")
+}
+
+// RenamePromptFavorite changes a favorite's name
+func (a *App) RenamePromptFavorite(projectId string, favoriteId string, name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "Error: name is empty"
+	}
+	promptFavoritesMu.Lock()
+	defer promptFavoritesMu.Unlock()
+	favorites := a.loadPromptFavorites(projectId)
+	for i := range favorites {
+		if favorites[i].ID == favoriteId {
+			favorites[i].Name = name
+			a.savePromptFavorites(projectId, favorites, fmt.Sprintf("Rename favorite prompt %q", name))
+			return "Success"
+		}
+	}
+	return "Error: favorite not found"
+}
+
+// RemovePromptFavorite unstars a prompt
+func (a *App) RemovePromptFavorite(projectId string, favoriteId string) string {
+	promptFavoritesMu.Lock()
+	defer promptFavoritesMu.Unlock()
+	favorites := a.loadPromptFavorites(projectId)
+	for i := range favorites {
+		if favorites[i].ID == favoriteId {
+			name := favorites[i].Name
+			a.savePromptFavorites(projectId, append(favorites[:i], favorites[i+1:]...), fmt.Sprintf("Remove favorite prompt %q", name))
+			return "Success"
+		}
+	}
+	return "Error: favorite not found"
+}
+
+// ApplyPromptFavorite sets a shot's prompt to a favorite
+func (a *App) ApplyPromptFavorite(projectId string, sceneId string, shotId string, favoriteId string) (Shot, error) {
+	var favorite *PromptFavorite
+	for _, f := range a.GetPromptFavorites(projectId) {
+		if f.ID == favoriteId {
+			f := f
+			favorite = &f
+			break
+		}
+	}
+	if favorite == nil {
+		return Shot{}, fmt.Errorf("favorite not found")
+	}
+
+	shotsMu.Lock()
+	defer shotsMu.Unlock()
+	shots := a.GetShots(projectId, sceneId)
+	for i := range shots {
+		if shots[i].ID == shotId {
+			shots[i].Prompt = favorite.Prompt
+			a.SaveShots(projectId, sceneId, shots) // Records the edit in the shot's history
+			return shots[i], nil
+		}
+	}
+	return Shot{}, fmt.Errorf("shot not found")
+}
+
+// promptTitle shortens a prompt to a few words for a default name
+func promptTitle(prompt string) string {
+	words := strings.Fields(prompt)
+	if len(words) > 6 {
+		return strings.Join(words[:6], " ") + "…"
+	}
+	return strings.Join(words, " ")
+}
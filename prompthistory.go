@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// --- PROMPT HISTORY ---
+
+// Per-scene history of shot generation settings and the takes they made.
+
+type ShotSettings struct {
+	Prompt         string            `json:"prompt"`
+	Seed           int64             `json:"seed"`
+	MotionStrength int               `json:"motionStrength"`
+	SourceImage    string            `json:"sourceImage"`
+	SourceImageEnd string            `json:"sourceImageEnd,omitempty"`
+	ControlImages  map[string]string `json:"controlImages,omitempty"`
+	AudioPath      string            `json:"audioPath"`
+	AudioStart     float64           `json:"audioStart"`
+	AudioDuration  float64           `json:"audioDuration"`
+}
+
+type PromptHistoryEntry struct {
+	ID       string       `json:"id"`
+	Time     string       `json:"time"`
+	Event    string       `json:"event"` // edit, render, revert
+	Settings ShotSettings `json:"settings"`
+	Take     string       `json:"take"` // Copy of the rendered video (render entries)
+}
+
+// Consecutive edits within this window collapse into one entry, so typing a
+// prompt doesn't produce one entry per save
+const promptEditWindow = 30 * time.Second
+
+var promptHistoryMu sync.Mutex
+
+func shotSettingsOf(s Shot) ShotSettings {
+	return ShotSettings{
+		Prompt:         s.Prompt,
+		Seed:           s.Seed,
+		MotionStrength: s.MotionStrength,
+		SourceImage:    s.SourceImage,
+		SourceImageEnd: s.SourceImageEnd,
+		ControlImages:  s.ControlImages,
+		AudioPath:      s.AudioPath,
+		AudioStart:     s.AudioStart,
+		AudioDuration:  s.AudioDuration,
+	}
+}
+
+func (a *App) getPromptHistoryPath(projectId string, sceneId string) string {
+	return filepath.Join(a.getAppDir(), projectId, "scenes", sceneId, "prompt_history.json")
+}
+
+func (a *App) loadPromptHistory(projectId string, sceneId string) map[string][]PromptHistoryEntry {
+	history := map[string][]PromptHistoryEntry{}
+	if data, err := os.ReadFile(a.getPromptHistoryPath(projectId, sceneId)); err == nil {
+		json.Unmarshal(data, &history)
+	}
+	return history
+}
+
+func (a *App) savePromptHistory(projectId string, sceneId string, history map[string][]PromptHistoryEntry) {
+	data, _ := json.MarshalIndent(history, "", "  ")
+	os.WriteFile(a.getPromptHistoryPath(projectId, sceneId), data, 0644)
+}
+
+// recordShotEdits compares the shots about to be saved with what's on disk and
+// appends an entry for every shot whose generation settings changed
+func (a *App) recordShotEdits(projectId string, sceneId string, shots []Shot) {
+	previous := map[string]ShotSettings{}
+	for _, s := range a.GetShots(projectId, sceneId) {
+		previous[s.ID] = shotSettingsOf(s)
+	}
+
+	promptHistoryMu.Lock()
+	defer promptHistoryMu.Unlock()
+
+	history := a.loadPromptHistory(projectId, sceneId)
+	changed := false
+	now := time.Now()
+	for _, s := range shots {
+		settings := shotSettingsOf(s)
+		old, existed := previous[s.ID]
+		if existed && reflect.DeepEqual(old, settings) {
+			continue
+		}
+		if !existed && settings.Prompt == "" {
+			continue // Fresh empty shot, nothing worth remembering yet
+		}
+
+		entries := history[s.ID]
+		if n := len(entries); n > 0 && entries[n-1].Event == "edit" {
+			if t, err := time.Parse(time.RFC3339, entries[n-1].Time); err == nil && now.Sub(t) < promptEditWindow {
+				entries[n-1].Settings = settings
+				entries[n-1].Time = now.Format(time.RFC3339)
+				changed = true
+				continue
+			}
+		}
+		history[s.ID] = append(entries, PromptHistoryEntry{
+			ID:       uuid.New().String(),
+			Time:     now.Format(time.RFC3339),
+			Event:    "edit",
+			Settings: settings,
+		})
+		changed = true
+	}
+	if changed {
+		a.savePromptHistory(projectId, sceneId, history)
+	}
+}
+
+// recordShotTake notes a freshly rendered take together with the settings
+// that produced it. Renders are versioned files now, so the take is the
+// output itself rather than a copy.
+func (a *App) recordShotTake(projectId string, sceneId string, shot Shot) {
+	if shot.OutputVideo == "" {
+		return
+	}
+	a.appendPromptEntry(projectId, sceneId, shot.ID, "render", shotSettingsOf(shot), shot.OutputVideo)
+}
+
+func (a *App) appendPromptEntry(projectId string, sceneId string, shotId string, event string, settings ShotSettings, take string) {
+	promptHistoryMu.Lock()
+	defer promptHistoryMu.Unlock()
+
+	history := a.loadPromptHistory(projectId, sceneId)
+	history[shotId] = append(history[shotId], PromptHistoryEntry{
+		ID:       uuid.New().String(),
+		Time:     time.Now().Format(time.RFC3339),
+		Event:    event,
+		Settings: settings,
+		Take:     take,
+	})
+	a.savePromptHistory(projectId, sceneId, history)
+}
+
+// GetPromptHistory returns a shot's history, newest first
+func (a *App) GetPromptHistory(projectId string, sceneId string, shotId string) []PromptHistoryEntry {
+	promptHistoryMu.Lock()
+	entries := a.loadPromptHistory(projectId, sceneId)[shotId]
+	promptHistoryMu.Unlock()
+
+	result := make([]PromptHistoryEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		result = append(result, entries[i])
+	}
+	return result
+}
+
+// RevertShotPrompt restores a shot's generation settings from a history
+// entry. With restoreTake, the entry's take also becomes the shot's output.
+func (a *App) RevertShotPrompt(projectId string, sceneId string, shotId string, entryId string, restoreTake bool) (Shot, error) {
+	promptHistoryMu.Lock()
+	var entry *PromptHistoryEntry
+	for _, e := range a.loadPromptHistory(projectId, sceneId)[shotId] {
+		if e.ID == entryId {
+			e := e
+			entry = &e
+			break
+		}
+	}
+	promptHistoryMu.Unlock()
+	if entry == nil {
+		return Shot{}, fmt.Errorf("history entry not found")
+	}
+
+	shots := a.GetShots(projectId, sceneId)
+	for i := range shots {
+		if shots[i].ID != shotId {
+			continue
+		}
+		s := &shots[i]
+		s.Prompt = entry.Settings.Prompt
+		s.Seed = entry.Settings.Seed
+		s.MotionStrength = entry.Settings.MotionStrength
+		s.SourceImage = entry.Settings.SourceImage
+		s.SourceImageEnd = entry.Settings.SourceImageEnd
+		s.ControlImages = entry.Settings.ControlImages
+		s.AudioPath = entry.Settings.AudioPath
+		s.AudioStart = entry.Settings.AudioStart
+		s.AudioDuration = entry.Settings.AudioDuration
+
+		if restoreTake && entry.Take != "" {
+			if _, err := os.Stat(entry.Take); err != nil {
+				return Shot{}, fmt.Errorf("take is no longer on disk")
+			}
+			s.OutputVideo = entry.Take
+			s.Status = "DONE"
+			s.Stale = false
+			// The settings now match the take again; the workflow is assumed unchanged
+			s.RenderFingerprint = a.shotFingerprint(*s, s.RenderWorkflow)
+			s.Duration = a.getVideoDuration(entry.Take)
+			a.generateShotThumbnail(projectId, sceneId, s)
+		}
+
+		take := ""
+		if restoreTake {
+			take = entry.Take
+		}
+		a.writeShots(projectId, sceneId, shots)
+		a.appendPromptEntry(projectId, sceneId, shotId, "revert", entry.Settings, take)
+		return *s, nil
+	}
+	return Shot{}, fmt.Errorf("shot not found")
+}
+
+// copyFile copies src to dst, replacing dst
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- QUEUE POSITION ---
+
+// Reports a pending prompt's place in the ComfyUI queue.
+
+type QueuePosition struct {
+	PromptID      string  `json:"promptId"`
+	ShotID        string  `json:"shotId"`
+	Status        string  `json:"status"`        // pending or running
+	Position      int     `json:"position"`      // 1-based among pending jobs, 0 when running
+	Ahead         int     `json:"ahead"`         // Jobs that run before it, running ones included
+	EstimatedWait float64 `json:"estimatedWait"` // Seconds until it starts, 0 if unknown
+}
+
+// queuePollTicks is how many 2s history polls pass between /queue checks
+const queuePollTicks = 2
+
+var (
+	queuePositionsMu sync.Mutex
+	queuePositions   = map[string]QueuePosition{} // Shot ID -> last reported place
+	serverRunTimes   = map[string]float64{}       // Server -> average render seconds
+)
+
+// GetQueuePosition returns where a shot's prompt stands on its server
+// (an empty Status when it isn't waiting on one)
+func (a *App) GetQueuePosition(shotId string) QueuePosition {
+	queuePositionsMu.Lock()
+	defer queuePositionsMu.Unlock()
+	return queuePositions[shotId]
+}
+
+// averageRunTime is the running average render time seen on a server
+func averageRunTime(server string) float64 {
+	queuePositionsMu.Lock()
+	defer queuePositionsMu.Unlock()
+	return serverRunTimes[server]
+}
+
+// recordRunTime folds one finished render into the server's average
+func recordRunTime(server string, seconds float64) {
+	queuePositionsMu.Lock()
+	defer queuePositionsMu.Unlock()
+	if avg := serverRunTimes[server]; avg > 0 {
+		serverRunTimes[server] = avg*0.7 + seconds*0.3
+	} else {
+		serverRunTimes[server] = seconds
+	}
+}
+
+// queueTracker follows one prompt through the server queue
+type queueTracker struct {
+	a        *App
+	server   string
+	shotId   string
+	promptID string
+	last     QueuePosition
+	started  time.Time // When it was first seen running
+}
+
+func (a *App) trackQueue(server string, shotId string, promptID string) *queueTracker {
+	return &queueTracker{a: a, server: server, shotId: shotId, promptID: promptID}
+}
+
+// poll checks the server queue and reports a changed place. Once the prompt
+// runs there is nothing left to report.
+func (t *queueTracker) poll() {
+	if !t.started.IsZero() {
+		return
+	}
+	var raw struct {
+		Running [][]interface{} `json:"queue_running"`
+		Pending [][]interface{} `json:"queue_pending"`
+	}
+	if err := t.a.comfyJSONAt(t.server, "GET", "/queue", nil, &raw); err != nil {
+		return
+	}
+
+	pos := QueuePosition{PromptID: t.promptID, ShotID: t.shotId}
+	for _, item := range raw.Running {
+		if len(item) > 1 && item[1] == t.promptID {
+			pos.Status = "running"
+		}
+	}
+	if pos.Status == "" {
+		pending := make([]ServerJob, 0, len(raw.Pending))
+		for _, item := range raw.Pending {
+			pending = append(pending, t.a.parseQueueItem(item, "pending"))
+		}
+		sort.Slice(pending, func(i, j int) bool { return pending[i].Number < pending[j].Number })
+		for i, job := range pending {
+			if job.PromptID == t.promptID {
+				pos.Status = "pending"
+				pos.Position = i + 1
+				pos.Ahead = len(raw.Running) + i
+			}
+		}
+	}
+	if pos.Status == "" {
+		return // Finished meanwhile, or lost (see renderretry.go)
+	}
+	if pos.Status == "running" {
+		t.started = time.Now()
+	} else if avg := averageRunTime(t.server); avg > 0 {
+		pos.EstimatedWait = float64(pos.Ahead) * avg
+	}
+
+	if pos.Status == t.last.Status && pos.Position == t.last.Position && pos.Ahead == t.last.Ahead {
+		return
+	}
+	t.last = pos
+	queuePositionsMu.Lock()
+	queuePositions[t.shotId] = pos
+	queuePositionsMu.Unlock()
+	if t.a.ctx != nil {
+		runtime.EventsEmit(t.a.ctx, "render:queue", pos)
+	}
+}
+
+// finish forgets the prompt's place; a successful render also updates the
+// server's average
+func (t *queueTracker) finish(ok bool) {
+	if ok && !t.started.IsZero() {
+		recordRunTime(t.server, time.Since(t.started).Seconds())
+	}
+	queuePositionsMu.Lock()
+	if queuePositions[t.shotId].PromptID == t.promptID {
+		delete(queuePositions, t.shotId)
+	}
+	queuePositionsMu.Unlock()
+}
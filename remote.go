@@ -0,0 +1,271 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- REMOTE CONTROL (HTTP / OSC) ---
+
+// Remote control commands over HTTP and OSC.
+
+type RemoteSettings struct {
+	OSCEnabled bool `json:"oscEnabled"`
+	OSCPort    int  `json:"oscPort"` // Default 9000
+}
+
+type RemoteCommand struct {
+	Command string            `json:"command"`
+	Args    map[string]string `json:"args"`
+}
+
+const defaultOSCPort = 9000
+
+// frontendCommands are handled by the UI
+var frontendCommands = map[string]bool{
+	"play": true, "pause": true, "toggle-play": true, "stop": true,
+	"next-shot": true, "prev-shot": true, "next-scene": true, "prev-scene": true,
+	"render-selected": true, "export": true, "seek": true,
+}
+
+var (
+	oscMu   sync.Mutex
+	oscConn net.PacketConn
+
+	// remoteToken authorizes HTTP control requests for this session, so a
+	// web page in the user's browser can't drive the engine port
+	remoteToken = uuid.New().String()
+)
+
+// GetRemoteToken returns the token HTTP controllers must send this session
+func (a *App) GetRemoteToken() string {
+	return remoteToken
+}
+
+// GetRemoteCommands lists every command a controller can send
+func (a *App) GetRemoteCommands() []string {
+	commands := []string{"render", "queue-pause", "queue-resume", "jobs-suspend", "jobs-resume", "jobs-toggle"}
+	for c := range frontendCommands {
+		commands = append(commands, c)
+	}
+	sort.Strings(commands)
+	return commands
+}
+
+// dispatchRemote runs one controller command
+func (a *App) dispatchRemote(cmd RemoteCommand) error {
+	switch cmd.Command {
+	case "queue-pause":
+		a.SetQueuePaused(true)
+	case "queue-resume":
+		a.SetQueuePaused(false)
+	case "jobs-suspend":
+		a.SuspendHeavyJobs()
+	case "jobs-resume":
+		a.ResumeHeavyJobs()
+	case "jobs-toggle":
+		a.ToggleHeavyJobs()
+	case "render":
+		// Explicit shot; without ids this is the UI's "render selected"
+		p, s, shot := cmd.Args["projectId"], cmd.Args["sceneId"], cmd.Args["shotId"]
+		if p == "" || s == "" || shot == "" {
+			cmd.Command = "render-selected"
+			return a.dispatchRemote(cmd)
+		}
+		// "" renders with the workflow the shot remembers
+		if _, err := a.QueueRender(p, s, shot, cmd.Args["workflow"]); err != nil {
+			return err
+		}
+	default:
+		if !frontendCommands[cmd.Command] {
+			return fmt.Errorf("unknown command %q", cmd.Command)
+		}
+		if a.ctx == nil {
+			return fmt.Errorf("app not ready")
+		}
+		runtime.EventsEmit(a.ctx, "remote:command", cmd)
+	}
+	return nil
+}
+
+// controlHandler serves POST /control/<command>?key=value
+func (a *App) controlHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "use POST"})
+		return
+	}
+	r.ParseForm()
+	token := r.Header.Get("X-Remote-Token")
+	if token == "" {
+		token = r.Form.Get("token")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(remoteToken)) != 1 {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "invalid token"})
+		return
+	}
+
+	cmd := RemoteCommand{
+		Command: strings.Trim(strings.TrimPrefix(r.URL.Path, "/control/"), "/"),
+		Args:    map[string]string{},
+	}
+	if cmd.Command == "" {
+		json.NewEncoder(w).Encode(a.GetRemoteCommands())
+		return
+	}
+	for key := range r.Form {
+		if key != "token" {
+			cmd.Args[key] = r.Form.Get(key)
+		}
+	}
+
+	if err := a.dispatchRemote(cmd); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+}
+
+// GetRemoteSettings returns the OSC listener configuration
+func (a *App) GetRemoteSettings() RemoteSettings {
+	settings := a.getConfig().Remote
+	if settings.OSCPort == 0 {
+		settings.OSCPort = defaultOSCPort
+	}
+	return settings
+}
+
+// SaveRemoteSettings persists and applies the OSC listener configuration
+func (a *App) SaveRemoteSettings(settings RemoteSettings) string {
+	a.updateConfig(func(c *Config) { c.Remote = settings })
+	if err := a.startOSC(); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Success"
+}
+
+// startOSC (re)starts the OSC listener on loopback according to the settings
+func (a *App) startOSC() error {
+	oscMu.Lock()
+	defer oscMu.Unlock()
+
+	if oscConn != nil {
+		oscConn.Close()
+		oscConn = nil
+	}
+	settings := a.GetRemoteSettings()
+	if !settings.OSCEnabled {
+		return nil
+	}
+
+	conn, err := net.ListenPacket("udp", fmt.Sprintf("127.0.0.1:%d", settings.OSCPort))
+	if err != nil {
+		recordEngineError("osc", err.Error())
+		return err
+	}
+	oscConn = conn
+	fmt.Println("🎛️ OSC control listening on", conn.LocalAddr())
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return // Closed
+			}
+			cmd, err := parseOSCCommand(buf[:n])
+			if err != nil {
+				fmt.Println("OSC:", err)
+				continue
+			}
+			if err := a.dispatchRemote(cmd); err != nil {
+				fmt.Println("OSC:", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// parseOSCCommand decodes an OSC message like /motionstudio/render with
+// string arguments "key=value" (or a single bare value stored as "value").
+// Bundles are not supported.
+func parseOSCCommand(packet []byte) (RemoteCommand, error) {
+	address, rest, err := readOSCString(packet)
+	if err != nil {
+		return RemoteCommand{}, err
+	}
+	name, ok := strings.CutPrefix(address, "/motionstudio/")
+	if !ok {
+		return RemoteCommand{}, fmt.Errorf("ignoring address %s", address)
+	}
+	cmd := RemoteCommand{Command: name, Args: map[string]string{}}
+
+	if len(rest) == 0 {
+		return cmd, nil
+	}
+	tags, rest, err := readOSCString(rest)
+	if err != nil || !strings.HasPrefix(tags, ",") {
+		return cmd, nil // Type tags are optional in old OSC senders
+	}
+	for _, tag := range tags[1:] {
+		var value string
+		switch tag {
+		case 's':
+			value, rest, err = readOSCString(rest)
+			if err != nil {
+				return cmd, err
+			}
+		case 'i':
+			if len(rest) < 4 {
+				return cmd, fmt.Errorf("truncated int argument")
+			}
+			value = fmt.Sprint(int32(binary.BigEndian.Uint32(rest)))
+			rest = rest[4:]
+		case 'f':
+			if len(rest) < 4 {
+				return cmd, fmt.Errorf("truncated float argument")
+			}
+			value = fmt.Sprint(math.Float32frombits(binary.BigEndian.Uint32(rest)))
+			rest = rest[4:]
+		default:
+			continue // T, F, N, I carry no data; others are unsupported
+		}
+		if key, val, found := strings.Cut(value, "="); found {
+			cmd.Args[key] = val
+		} else {
+			cmd.Args["value"] = value
+		}
+	}
+	return cmd, nil
+}
+
+// readOSCString reads a null-terminated string padded to 4 bytes
+func readOSCString(b []byte) (string, []byte, error) {
+	end := 0
+	for end < len(b) && b[end] != 0 {
+		end++
+	}
+	if end == len(b) {
+		return "", nil, fmt.Errorf("unterminated OSC string")
+	}
+	padded := (end + 4) &^ 3
+	if padded > len(b) {
+		padded = len(b)
+	}
+	return string(b[:end]), b[padded:], nil
+}
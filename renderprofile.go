@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// --- RENDER PROFILES ---
+
+// Draft renders scale the workflow down; final renders it as authored.
+
+const (
+	QualityDraft = "draft"
+	QualityFinal = "final"
+)
+
+// DraftProfile holds the scale factors of the draft profile (0 = default)
+type DraftProfile struct {
+	ResolutionScale float64 `json:"resolutionScale"` // Default 0.5
+	StepsScale      float64 `json:"stepsScale"`      // Default 0.5
+	FramesScale     float64 `json:"framesScale"`     // Default 1 (same length, keeps audio sync)
+}
+
+// withDefaults fills unset factors
+func (p DraftProfile) withDefaults() DraftProfile {
+	if p.ResolutionScale <= 0 {
+		p.ResolutionScale = 0.5
+	}
+	if p.StepsScale <= 0 {
+		p.StepsScale = 0.5
+	}
+	if p.FramesScale <= 0 {
+		p.FramesScale = 1
+	}
+	return p
+}
+
+// GetDraftProfile returns the draft scale factors
+func (a *App) GetDraftProfile() DraftProfile {
+	return a.getConfig().DraftProfile.withDefaults()
+}
+
+// SaveDraftProfile stores the draft scale factors (each between 0.1 and 1)
+func (a *App) SaveDraftProfile(p DraftProfile) string {
+	for _, f := range []float64{p.ResolutionScale, p.StepsScale, p.FramesScale} {
+		if f != 0 && (f < 0.1 || f > 1) {
+			return "Error: scale factors must be between 0.1 and 1"
+		}
+	}
+	a.updateConfig(func(c *Config) { c.DraftProfile = p })
+	return "Success"
+}
+
+func normalizeProfile(profile string) (string, error) {
+	switch profile {
+	case "", QualityFinal:
+		return QualityFinal, nil
+	case QualityDraft:
+		return QualityDraft, nil
+	}
+	return "", fmt.Errorf("unknown render profile %q", profile)
+}
+
+// frameInputs are frame-count inputs besides those mapped to MAX_FRAMES or WAN_LENGTH
+var frameInputs = map[string]bool{"length": true, "frame_count": true, "num_frames": true, "video_length": true}
+
+// applyRenderProfile scales a prepared workflow down for a draft
+func (a *App) applyRenderProfile(workflow map[string]interface{}, quality string, result *WorkflowPreview) {
+	if quality != QualityDraft {
+		return
+	}
+	p := a.GetDraftProfile()
+	for nodeId, node := range workflow {
+		nodeMap, _ := node.(map[string]interface{})
+		classType, _ := nodeMap["class_type"].(string)
+		inputs, _ := nodeMap["inputs"].(map[string]interface{})
+		rules := a.nodeRules(classType)
+		for input, value := range inputs {
+			n, ok := numberValue(value)
+			if !ok || n <= 0 {
+				continue
+			}
+			var scaled float64
+			switch {
+			case input == "width" || input == "height":
+				scaled = math.Max(64, math.Round(n*p.ResolutionScale/16)*16)
+			case input == "steps":
+				scaled = math.Max(1, math.Round(n*p.StepsScale))
+			case (frameInputs[input] || rules[input] == "MAX_FRAMES" || rules[input] == "WAN_LENGTH") && p.FramesScale < 1:
+				if int(n)%4 == 1 {
+					scaled = math.Max(1, math.Round((n-1)*p.FramesScale/4)*4) + 1 // Wan wants 4n+1
+				} else {
+					scaled = math.Max(1, math.Round(n*p.FramesScale))
+				}
+			default:
+				continue
+			}
+			if scaled == n {
+				continue
+			}
+			inputs[input] = int(scaled)
+			result.inject(nodeId, classType, input, "DRAFT", int(scaled))
+		}
+	}
+}
+
+// numberValue reads a JSON number whether it was decoded or injected as an int
+func numberValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// RenderShotProfile renders a shot with the draft or final profile
+func (a *App) RenderShotProfile(projectId string, sceneId string, shotId string, workflowName string, profile string) (Shot, error) {
+	profile, err := normalizeProfile(profile)
+	if err != nil {
+		return Shot{}, err
+	}
+	shot, err := a.renderShotOn(a.comfyURL, projectId, sceneId, shotId, workflowName, 0, 0, profile)
+	a.freeAfterRender(a.comfyURL)
+	return shot, err
+}
+
+// QueueRenderProfile queues a shot with the draft or final profile
+func (a *App) QueueRenderProfile(projectId string, sceneId string, shotId string, workflow string, profile string) (string, error) {
+	profile, err := normalizeProfile(profile)
+	if err != nil {
+		return "", err
+	}
+	return a.queueShot(projectId, sceneId, shotId, workflow, profile)
+}
+
+// RenderSceneProfile queues a scene's drafts with the draft or final profile
+func (a *App) RenderSceneProfile(projectId string, sceneId string, workflowName string, profile string) ([]string, error) {
+	profile, err := normalizeProfile(profile)
+	if err != nil {
+		return []string{}, err
+	}
+	return a.renderScene(projectId, sceneId, workflowName, profile)
+}
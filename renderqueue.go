@@ -0,0 +1,469 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- RENDER JOB QUEUE ---
+
+// Queued renders, dispatched to servers with free slots.
+
+const (
+	JobQueued   = "queued"
+	JobRunning  = "running"
+	JobDone     = "done"
+	JobFailed   = "failed"
+	JobCanceled = "canceled"
+
+	// maxFinishedJobs is how much history GetRenderQueue keeps
+	maxFinishedJobs = 50
+	// maxRenderWorkers caps parallel jobs; ComfyUI itself runs one prompt at a time
+	maxRenderWorkers = 4
+)
+
+type RenderJob struct {
+	ID         string  `json:"id"`
+	ProjectID  string  `json:"projectId"`
+	SceneID    string  `json:"sceneId"`
+	ShotID     string  `json:"shotId"`
+	ShotName   string  `json:"shotName"`
+	Workflow   string  `json:"workflow"`
+	Batch      string  `json:"batch,omitempty"`  // Set for jobs queued together by RenderScene or RenderChain
+	Server     string  `json:"server,omitempty"` // ComfyUI URL the job runs on
+	ServerName string  `json:"serverName,omitempty"`
+	Status     string  `json:"status"`
+	Progress   int     `json:"progress"`          // 0-100 for the running sampler
+	Attempt    int     `json:"attempt,omitempty"` // Retries after prompts lost to a ComfyUI restart
+	Seed       int64   `json:"seed,omitempty"`    // Seed of the lost prompt a retry resubmits
+	Seeds      []int64 `json:"seeds,omitempty"`   // Set for a RenderVariations job, one take each
+	Profile    string  `json:"profile,omitempty"` // draft or final, see renderprofile.go
+	Error      string  `json:"error,omitempty"`
+	Queued     string  `json:"queued"`
+	Started    string  `json:"started,omitempty"`
+	Finished   string  `json:"finished,omitempty"`
+}
+
+var (
+	renderQueueMu  sync.Mutex
+	renderJobs     []*RenderJob
+	renderCanceled = map[string]bool{} // Shot IDs whose running job was canceled
+	renderWake     = make(chan struct{}, 1)
+
+	// shotsMu serializes read-modify-write of shots.json by finished renders
+	shotsMu sync.Mutex
+)
+
+func wakeRenderQueue() {
+	select {
+	case renderWake <- struct{}{}:
+	default:
+	}
+}
+
+// emitJob sends a render:* event with a snapshot of the job
+func (a *App) emitJob(event string, job *RenderJob) {
+	renderQueueMu.Lock()
+	snapshot := *job
+	renderQueueMu.Unlock()
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "render:"+event, snapshot)
+	}
+}
+
+// renderWorkers is the configured number of parallel jobs
+func (a *App) renderWorkers() int {
+	return min(max(a.getConfig().RenderWorkers, 1), maxRenderWorkers)
+}
+
+// QueueRender adds a shot to the render queue and returns the job ID. A shot
+// that is already queued or rendering returns its existing job.
+func (a *App) QueueRender(projectId string, sceneId string, shotId string, workflow string) (string, error) {
+	return a.queueShot(projectId, sceneId, shotId, workflow, QualityFinal)
+}
+
+func (a *App) queueShot(projectId string, sceneId string, shotId string, workflow string, profile string) (string, error) {
+	var shot *Shot
+	shots := a.GetShots(projectId, sceneId)
+	for i := range shots {
+		if shots[i].ID == shotId {
+			shot = &shots[i]
+			break
+		}
+	}
+	if shot == nil {
+		return "", fmt.Errorf("shot not found")
+	}
+	if err := a.checkWorkflowBeforeQueue(shotWorkflow(*shot, workflow)); err != nil {
+		return "", err
+	}
+	return a.queueRender(projectId, sceneId, *shot, workflow, "", 0, profile), nil
+}
+
+func (a *App) queueRender(projectId string, sceneId string, shot Shot, workflow string, batch string, attempt int, profile string) string {
+	renderQueueMu.Lock()
+	for _, job := range renderJobs {
+		if job.ShotID == shot.ID && (job.Status == JobQueued || job.Status == JobRunning) {
+			renderQueueMu.Unlock()
+			return job.ID
+		}
+	}
+	job := &RenderJob{
+		ID:        uuid.New().String(),
+		ProjectID: projectId,
+		SceneID:   sceneId,
+		ShotID:    shot.ID,
+		ShotName:  shot.Name,
+		Workflow:  workflow,
+		Batch:     batch,
+		Attempt:   attempt,
+		Profile:   profile,
+		Status:    JobQueued,
+		Queued:    time.Now().Format(time.RFC3339),
+	}
+	if attempt > 0 {
+		job.Seed = shot.Seed // A retry keeps the seed it was first queued with
+	}
+	renderJobs = append(renderJobs, job)
+	renderQueueMu.Unlock()
+
+	a.emitJob("queued", job)
+	wakeRenderQueue()
+	return job.ID
+}
+
+// GetRenderQueue returns queued and running jobs plus recent finished ones
+func (a *App) GetRenderQueue() []RenderJob {
+	renderQueueMu.Lock()
+	defer renderQueueMu.Unlock()
+
+	jobs := make([]RenderJob, 0, len(renderJobs))
+	for _, job := range renderJobs {
+		jobs = append(jobs, *job)
+	}
+	return jobs
+}
+
+// CancelRenderJob drops a queued job, or interrupts a running one on the server
+func (a *App) CancelRenderJob(jobId string) string {
+	renderQueueMu.Lock()
+	var job *RenderJob
+	for _, j := range renderJobs {
+		if j.ID == jobId {
+			job = j
+			break
+		}
+	}
+	if job == nil {
+		renderQueueMu.Unlock()
+		return "Job not found"
+	}
+	status := job.Status
+	switch status {
+	case JobQueued:
+		job.Status = JobCanceled
+		job.Finished = time.Now().Format(time.RFC3339)
+	case JobRunning:
+		renderCanceled[job.ShotID] = true
+	}
+	renderQueueMu.Unlock()
+
+	switch status {
+	case JobQueued:
+		a.emitJob("canceled", job)
+		return "Success"
+	case JobRunning:
+		// renderShot notices the flag; interrupting frees the GPU right away
+		if promptID := promptForShot(job.ShotID); promptID != "" {
+			a.CancelServerJob(promptID)
+		}
+		return "Success"
+	}
+	return "Job already finished"
+}
+
+// ClearFinishedRenderJobs removes done, failed and canceled jobs from the list
+func (a *App) ClearFinishedRenderJobs() {
+	renderQueueMu.Lock()
+	defer renderQueueMu.Unlock()
+
+	active := renderJobs[:0]
+	for _, job := range renderJobs {
+		if job.Status == JobQueued || job.Status == JobRunning {
+			active = append(active, job)
+		}
+	}
+	renderJobs = active
+}
+
+// SetRenderWorkers sets how many queued jobs may render at the same time
+func (a *App) SetRenderWorkers(n int) int {
+	n = min(max(n, 1), maxRenderWorkers)
+	a.updateConfig(func(c *Config) { c.RenderWorkers = n })
+	wakeRenderQueue()
+	return n
+}
+
+// runRenderQueue is the dispatcher: it starts queued jobs whenever a worker
+// slot is free and the queue isn't paused
+func (a *App) runRenderQueue() {
+	for {
+		select {
+		case <-renderWake:
+		case <-time.After(2 * time.Second): // Notices SetQueuePaused(false)
+		}
+		if isQueuePaused() {
+			continue
+		}
+
+		slots := a.renderSlots()
+
+		renderQueueMu.Lock()
+		busy := map[string]int{}
+		for _, job := range renderJobs {
+			if job.Status == JobRunning {
+				busy[job.Server]++
+			}
+		}
+		var start []*RenderJob
+		for _, job := range renderJobs {
+			if job.Status != JobQueued {
+				continue
+			}
+			// Least loaded server with a free slot
+			var free *renderSlot
+			for i := range slots {
+				s := &slots[i]
+				if busy[s.URL] < s.Capacity && (free == nil || busy[s.URL] < busy[free.URL]) {
+					free = s
+				}
+			}
+			if free == nil {
+				break
+			}
+			busy[free.URL]++
+			job.Server, job.ServerName = free.URL, free.Name
+			job.Status = JobRunning
+			job.Started = time.Now().Format(time.RFC3339)
+			start = append(start, job)
+		}
+		renderQueueMu.Unlock()
+
+		for _, job := range start {
+			go a.runRenderJob(job)
+		}
+	}
+}
+
+func (a *App) runRenderJob(job *RenderJob) {
+	a.emitJob("started", job)
+	previous := ""
+	a.updateShot(job.ProjectID, job.SceneID, job.ShotID, func(s *Shot) {
+		previous = s.Status
+		s.Status = "RENDERING"
+	})
+	var err error
+	if len(job.Seeds) > 0 {
+		err = a.renderVariations(job)
+	} else {
+		_, err = a.renderShotOn(job.Server, job.ProjectID, job.SceneID, job.ShotID, job.Workflow, job.Attempt, job.Seed, job.Profile)
+	}
+	// Variations leave the shot's output alone, so it keeps its status too
+	if err != nil || len(job.Seeds) > 0 {
+		a.updateShot(job.ProjectID, job.SceneID, job.ShotID, func(s *Shot) { s.Status = previous })
+	}
+
+	renderQueueMu.Lock()
+	canceled := renderCanceled[job.ShotID]
+	delete(renderCanceled, job.ShotID)
+	job.Finished = time.Now().Format(time.RFC3339)
+	event := "done"
+	switch {
+	case canceled:
+		job.Status, event = JobCanceled, "canceled"
+	case err != nil:
+		job.Status, event = JobFailed, "failed"
+		job.Error = err.Error()
+	default:
+		job.Status = JobDone
+		job.Progress = 100
+	}
+	trimFinishedJobs()
+	renderQueueMu.Unlock()
+
+	a.emitJob(event, job)
+	if job.Batch != "" {
+		a.emitBatchProgress(job.Batch)
+	}
+	wakeRenderQueue()
+	a.freeAfterRender(job.Server)
+}
+
+// trimFinishedJobs drops the oldest finished jobs beyond maxFinishedJobs.
+// Callers hold renderQueueMu.
+func trimFinishedJobs() {
+	finished := 0
+	for _, job := range renderJobs {
+		if job.Status != JobQueued && job.Status != JobRunning {
+			finished++
+		}
+	}
+	kept := renderJobs[:0]
+	for _, job := range renderJobs {
+		if finished > maxFinishedJobs && job.Status != JobQueued && job.Status != JobRunning {
+			finished--
+			continue
+		}
+		kept = append(kept, job)
+	}
+	renderJobs = kept
+}
+
+// reportRenderProgress forwards sampler progress of a shot to its running job
+func (a *App) reportRenderProgress(shotId string, percent int) {
+	renderQueueMu.Lock()
+	var job *RenderJob
+	for _, j := range renderJobs {
+		if j.ShotID == shotId && j.Status == JobRunning {
+			j.Progress = percent
+			job = j
+			break
+		}
+	}
+	renderQueueMu.Unlock()
+	if job != nil {
+		a.emitJob("progress", job)
+	}
+}
+
+// rendersIdle reports whether no render runs or waits in the queue
+func rendersIdle() bool {
+	if atomic.LoadInt32(&activeRenders) > 0 {
+		return false
+	}
+	renderQueueMu.Lock()
+	defer renderQueueMu.Unlock()
+	for _, job := range renderJobs {
+		if job.Status == JobQueued || job.Status == JobRunning {
+			return false
+		}
+	}
+	return true
+}
+
+// shotRenderCanceled reports whether the running job of a shot was canceled
+func shotRenderCanceled(shotId string) bool {
+	renderQueueMu.Lock()
+	defer renderQueueMu.Unlock()
+	return renderCanceled[shotId]
+}
+
+// promptForShot returns the prompt id most recently submitted for a shot
+func promptForShot(shotId string) string {
+	promptsMu.Lock()
+	defer promptsMu.Unlock()
+	return latestPrompt[shotId]
+}
+
+// updateShot changes one shot in the scene's current shots.json, so parallel
+// renders in a scene don't overwrite each other's results
+func (a *App) updateShot(projectId string, sceneId string, shotId string, change func(s *Shot)) {
+	shotsMu.Lock()
+	defer shotsMu.Unlock()
+
+	shots := a.GetShots(projectId, sceneId)
+	for i := range shots {
+		if shots[i].ID == shotId {
+			change(&shots[i])
+			a.SaveShots(projectId, sceneId, shots)
+			return
+		}
+	}
+}
+
+// saveShotResult copies the outcome of a render into the stored shot, keeping
+// edits made to it while the render ran
+func (a *App) saveShotResult(projectId string, sceneId string, shot Shot) {
+	a.updateShot(projectId, sceneId, shot.ID, func(s *Shot) {
+		s.OutputVideo = shot.OutputVideo
+		s.RawVideo = shot.RawVideo
+		s.Status = shot.Status
+		s.Stale = shot.Stale
+		s.Duration = shot.Duration
+		s.Thumbnail = shot.Thumbnail
+		s.RenderWorkflow = shot.RenderWorkflow
+		s.RenderFingerprint = shot.RenderFingerprint
+		s.Seed = shot.Seed       // Seed the render actually ran with
+		s.Quality = shot.Quality // Profile of the new output
+	})
+}
+
+// --- SCENE BATCH RENDER ---
+
+// RenderScene queues every DRAFT shot of a scene, in order, as one batch and
+// returns the job IDs. Besides the per-job render:* events, "render:batch"
+// reports how far the batch is after each shot.
+func (a *App) RenderScene(projectId string, sceneId string, workflowName string) ([]string, error) {
+	return a.renderScene(projectId, sceneId, workflowName, QualityFinal)
+}
+
+func (a *App) renderScene(projectId string, sceneId string, workflowName string, profile string) ([]string, error) {
+	var drafts []Shot
+	checked := map[string]bool{}
+	for _, shot := range a.GetShots(projectId, sceneId) {
+		if shot.Status != "" && shot.Status != "DRAFT" {
+			continue
+		}
+		if a.checkSourceImage(shot, shotWorkflow(shot, workflowName)) != nil {
+			continue // renderShot would fail on it anyway
+		}
+		// Shots may bring their own workflows; check each once
+		if workflow := shotWorkflow(shot, workflowName); !checked[workflow] {
+			checked[workflow] = true
+			if err := a.checkWorkflowBeforeQueue(workflow); err != nil {
+				return []string{}, err
+			}
+		}
+		drafts = append(drafts, shot)
+	}
+	batch := uuid.New().String()
+	ids := []string{}
+	for _, shot := range drafts {
+		ids = append(ids, a.queueRender(projectId, sceneId, shot, workflowName, batch, 0, profile))
+	}
+	if len(ids) == 0 {
+		return ids, fmt.Errorf("no draft shots to render")
+	}
+	return ids, nil
+}
+
+// emitBatchProgress reports the state of a RenderScene batch
+func (a *App) emitBatchProgress(batch string) {
+	renderQueueMu.Lock()
+	progress := map[string]interface{}{"batch": batch}
+	total, done, failed := 0, 0, 0
+	for _, job := range renderJobs {
+		if job.Batch != batch {
+			continue
+		}
+		progress["sceneId"] = job.SceneID
+		total++
+		switch job.Status {
+		case JobDone:
+			done++
+		case JobFailed, JobCanceled:
+			failed++
+		}
+	}
+	renderQueueMu.Unlock()
+
+	progress["total"], progress["done"], progress["failed"] = total, done, failed
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "render:batch", progress)
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- RENDER RECOVERY ---
+
+// Tracks submitted prompts so renders survive ComfyUI and app restarts.
+
+const (
+	defaultRenderRetries = 2
+	maxRenderRetries     = 5
+	// promptLostCheckTicks is how many 2s history polls pass between queue checks
+	promptLostCheckTicks = 5
+	// recoverGiveUp is how long startup recovery waits for unreachable servers;
+	// what's left is tried again next launch
+	recoverGiveUp = 30 * time.Minute
+)
+
+var errPromptLost = errors.New("ComfyUI lost the prompt (server restarted?)")
+
+type inflightRender struct {
+	PromptID  string `json:"promptId"`
+	Server    string `json:"server"`
+	ProjectID string `json:"projectId"`
+	SceneID   string `json:"sceneId"`
+	ShotID    string `json:"shotId"`
+	Workflow  string `json:"workflow"`
+	Attempt   int    `json:"attempt"`
+	Seed      int64  `json:"seed,omitempty"` // Seed the prompt renders with, kept by retries
+	Submitted string `json:"submitted"`
+
+	// Set for a take of RenderVariations, which is only recorded as a version
+	Variation string `json:"variation,omitempty"`
+	Profile   string `json:"profile,omitempty"` // draft or final
+}
+
+var inflightMu sync.Mutex
+
+func (a *App) getInflightPath() string {
+	dir := filepath.Join(a.getAppDir(), "renders")
+	os.MkdirAll(dir, 0755)
+	return filepath.Join(dir, "inflight.json")
+}
+
+func (a *App) loadInflight() []inflightRender {
+	var list []inflightRender
+	if data, err := os.ReadFile(a.getInflightPath()); err == nil {
+		json.Unmarshal(data, &list)
+	}
+	return list
+}
+
+func (a *App) saveInflight(list []inflightRender) {
+	data, _ := json.MarshalIndent(list, "", "  ")
+	os.WriteFile(a.getInflightPath(), data, 0644)
+}
+
+func (a *App) trackInflight(entry inflightRender) {
+	inflightMu.Lock()
+	defer inflightMu.Unlock()
+	a.saveInflight(append(a.loadInflight(), entry))
+}
+
+func (a *App) untrackInflight(promptID string) {
+	inflightMu.Lock()
+	defer inflightMu.Unlock()
+	list := a.loadInflight()
+	kept := list[:0]
+	for _, e := range list {
+		if e.PromptID != promptID {
+			kept = append(kept, e)
+		}
+	}
+	a.saveInflight(kept)
+}
+
+// renderRetries is how often a lost prompt is resubmitted
+func (a *App) renderRetries() int {
+	n := a.getConfig().RenderRetries
+	switch {
+	case n < 0:
+		return 0
+	case n == 0:
+		return defaultRenderRetries
+	}
+	return min(n, maxRenderRetries)
+}
+
+// SetRenderRetries sets how often renders lost to a ComfyUI restart are
+// resubmitted (0 turns it off)
+func (a *App) SetRenderRetries(n int) int {
+	n = min(max(n, 0), maxRenderRetries)
+	stored := n
+	if n == 0 {
+		stored = -1 // 0 in the config means "default"
+	}
+	a.updateConfig(func(c *Config) { c.RenderRetries = stored })
+	return n
+}
+
+// GetRenderRetries returns the effective retry count
+func (a *App) GetRenderRetries() int {
+	return a.renderRetries()
+}
+
+func (a *App) emitRenderRetry(shotId string, attempt int) {
+	fmt.Printf("Render of %s lost on the server, retrying (%d/%d)\n", shotId, attempt, a.renderRetries())
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "render:retry", map[string]interface{}{"shotId": shotId, "attempt": attempt})
+	}
+}
+
+// promptLost reports whether a reachable server has the prompt neither
+// queued, running nor in its history. An unreachable server proves nothing.
+func (a *App) promptLost(server string, promptID string) bool {
+	var queue struct {
+		Running [][]interface{} `json:"queue_running"`
+		Pending [][]interface{} `json:"queue_pending"`
+	}
+	if err := a.comfyJSONAt(server, "GET", "/queue", nil, &queue); err != nil {
+		return false
+	}
+	for _, item := range append(queue.Running, queue.Pending...) {
+		if len(item) > 1 && item[1] == promptID {
+			return false
+		}
+	}
+	// It may have finished between the two requests
+	var history map[string]interface{}
+	if err := a.comfyJSONAt(server, "GET", "/history/"+promptID, nil, &history); err != nil {
+		return false
+	}
+	_, done := history[promptID]
+	return !done
+}
+
+// promptState is where a leftover prompt stands on its server
+type promptState int
+
+const (
+	promptUnknown promptState = iota // Server unreachable
+	promptFinished
+	promptPending
+	promptGone
+)
+
+func (a *App) checkPrompt(server string, promptID string) promptState {
+	var history map[string]interface{}
+	if err := a.comfyJSONAt(server, "GET", "/history/"+promptID, nil, &history); err != nil {
+		return promptUnknown
+	}
+	if _, ok := history[promptID]; ok {
+		return promptFinished
+	}
+	if a.promptLost(server, promptID) {
+		return promptGone
+	}
+	return promptPending
+}
+
+// recoverRenders resolves the prompts left over from the last session
+func (a *App) recoverRenders() {
+	inflightMu.Lock()
+	pending := a.loadInflight()
+	inflightMu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+	fmt.Printf("Recovering %d interrupted render(s)\n", len(pending))
+
+	deadline := time.Now().Add(recoverGiveUp)
+	for len(pending) > 0 && time.Now().Before(deadline) {
+		var unreachable []inflightRender
+		for _, entry := range pending {
+			switch a.checkPrompt(entry.Server, entry.PromptID) {
+			case promptUnknown:
+				unreachable = append(unreachable, entry)
+			case promptGone:
+				a.requeueLostRender(entry)
+			default:
+				go a.resumeRender(entry)
+			}
+		}
+		pending = unreachable
+		if len(pending) > 0 {
+			time.Sleep(30 * time.Second)
+		}
+	}
+}
+
+// resumeRender follows a leftover prompt to the end and finishes its shot
+func (a *App) resumeRender(entry inflightRender) {
+	var shot *Shot
+	shots := a.GetShots(entry.ProjectID, entry.SceneID)
+	for i := range shots {
+		if shots[i].ID == entry.ShotID {
+			shot = &shots[i]
+		}
+	}
+	if shot == nil {
+		a.untrackInflight(entry.PromptID) // Shot was deleted meanwhile
+		return
+	}
+
+	if entry.Profile != "" {
+		shot.Quality = entry.Profile
+	}
+	rememberPrompt(entry.PromptID, entry.Server, entry.ProjectID, entry.SceneID, *shot)
+	err := a.waitForPrompt(entry.Server, entry.ShotID, entry.PromptID)
+	if errors.Is(err, errPromptLost) {
+		a.requeueLostRender(entry)
+		return
+	}
+	var take *renderTake
+	if entry.Variation != "" {
+		take = &renderTake{Set: entry.Variation, Seed: entry.Seed}
+	}
+	if err == nil {
+		var result Shot
+		if result, err = a.collectShotOutput(entry.Server, entry.ProjectID, entry.SceneID, shot, entry.PromptID, entry.Workflow, take); err == nil {
+			if take == nil {
+				result = a.autoUpscale(entry.Server, entry.ProjectID, entry.SceneID, result)
+				a.recordShotTake(entry.ProjectID, entry.SceneID, result)
+				a.schedulePreviewLoop(entry.ProjectID)
+			} else {
+				a.updateShot(entry.ProjectID, entry.SceneID, entry.ShotID, resetRenderingStatus)
+			}
+			a.touchWorkflow(result.RenderWorkflow)
+			if a.ctx != nil {
+				runtime.EventsEmit(a.ctx, "render:recovered", result)
+			}
+		}
+	}
+	if err != nil {
+		recordEngineError("render", err.Error())
+		a.updateShot(entry.ProjectID, entry.SceneID, entry.ShotID, resetRenderingStatus)
+	}
+	a.untrackInflight(entry.PromptID)
+}
+
+// requeueLostRender puts a prompt the server forgot back into the render
+// queue, or gives up once its retries are used. Lost variation takes are
+// not requeued: the rest of their set is gone with the app's old queue.
+func (a *App) requeueLostRender(entry inflightRender) {
+	defer a.untrackInflight(entry.PromptID)
+
+	a.updateShot(entry.ProjectID, entry.SceneID, entry.ShotID, resetRenderingStatus)
+	if entry.Attempt >= a.renderRetries() || entry.Variation != "" {
+		recordEngineError("render", fmt.Sprintf("%s: %v", entry.ShotID, errPromptLost))
+		return
+	}
+	for _, shot := range a.GetShots(entry.ProjectID, entry.SceneID) {
+		if shot.ID == entry.ShotID {
+			a.emitRenderRetry(shot.ID, entry.Attempt+1)
+			shot.Seed = entry.Seed
+			a.queueRender(entry.ProjectID, entry.SceneID, shot, entry.Workflow, "", entry.Attempt+1, entry.Profile)
+			return
+		}
+	}
+}
+
+// resetRenderingStatus undoes the RENDERING status of an interrupted render
+func resetRenderingStatus(s *Shot) {
+	if s.Status != "RENDERING" {
+		return
+	}
+	if s.OutputVideo != "" {
+		s.Status = "DONE"
+	} else {
+		s.Status = "DRAFT"
+	}
+}
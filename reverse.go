@@ -0,0 +1,197 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// --- REVERSED CLIPS ---
+
+// Plays clips backwards, from a chunked reversed proxy for long ranges.
+
+const (
+	// reverseInlineSeconds is the longest video slice reversed in one go
+	reverseInlineSeconds = 5.0
+	// reverseAudioInlineSeconds is the same limit for audio, which is far cheaper
+	reverseAudioInlineSeconds = 120.0
+	// reverseChunkSeconds is the chunk length used to build proxies
+	reverseChunkSeconds = 5.0
+)
+
+// reverseMu serializes proxy builds so the background renderer and an export
+// never encode the same proxy twice
+var reverseMu sync.Mutex
+
+func (a *App) getReverseCacheDir() string {
+	dir := filepath.Join(a.getAppDir(), "cache", "reversed")
+	os.MkdirAll(dir, 0755)
+	return dir
+}
+
+// probeStreamTypes reports whether a file has video and audio streams
+func probeStreamTypes(path string) (bool, bool) {
+	out, err := exec.Command("ffprobe",
+		"-v", "error",
+		"-show_entries", "stream=codec_type",
+		"-of", "csv=p=0",
+		mediaPath(path)).Output()
+	if err != nil {
+		return false, false
+	}
+	hasVideo, hasAudio := false, false
+	for _, line := range strings.Split(string(out), "\n") {
+		switch strings.TrimSpace(line) {
+		case "video":
+			hasVideo = true
+		case "audio":
+			hasAudio = true
+		}
+	}
+	return hasVideo, hasAudio
+}
+
+// reversedProxyPath is the content-addressed cache location of source's proxy
+func (a *App) reversedProxyPath(source string, hasVideo bool) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s", source)
+	if info, err := os.Stat(source); err == nil {
+		fmt.Fprintf(h, "|%d|%d", info.Size(), info.ModTime().UnixNano())
+	}
+	ext := ".wav"
+	if hasVideo {
+		ext = ".mkv"
+	}
+	return filepath.Join(a.getReverseCacheDir(), hex.EncodeToString(h.Sum(nil))+ext)
+}
+
+// reversedProxy returns a cached copy of source played backwards (video and
+// audio), building it if needed, along with its duration
+func (a *App) reversedProxy(run *exportRun, source string) (string, float64, error) {
+	hasVideo, hasAudio := probeStreamTypes(source)
+	if !hasVideo && !hasAudio {
+		return "", 0, fmt.Errorf("no playable streams in %s", filepath.Base(source))
+	}
+	out := a.reversedProxyPath(source, hasVideo)
+
+	reverseMu.Lock()
+	defer reverseMu.Unlock()
+
+	if _, err := os.Stat(out); err != nil {
+		if err := buildReversedProxy(run, source, out, hasVideo, hasAudio); err != nil {
+			return "", 0, err
+		}
+	}
+	dur, err := probeDuration(out)
+	if err != nil {
+		return "", 0, err
+	}
+	return out, dur, nil
+}
+
+// buildReversedProxy reverses source chunk by chunk and joins the chunks last
+// to first into out
+func buildReversedProxy(run *exportRun, source string, out string, hasVideo bool, hasAudio bool) error {
+	total, err := probeDuration(source)
+	if err != nil || total <= 0 {
+		return fmt.Errorf("could not read duration of %s", filepath.Base(source))
+	}
+
+	work, err := os.MkdirTemp(filepath.Dir(out), "chunks_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(work)
+
+	ext := filepath.Ext(out)
+	n := int(math.Ceil(total / reverseChunkSeconds))
+	var list strings.Builder
+	list.WriteString("ffconcat version 1.0\n")
+	for i := n - 1; i >= 0; i-- {
+		chunk := filepath.Join(work, fmt.Sprintf("chunk_%05d%s", i, ext))
+		args := []string{"-y",
+			"-ss", fmt.Sprintf("%f", float64(i)*reverseChunkSeconds),
+			"-t", fmt.Sprintf("%f", reverseChunkSeconds),
+			"-i", mediaPath(source)}
+		if hasVideo {
+			args = append(args, "-map", "0:v:0", "-vf", "reverse,format=yuv420p",
+				"-c:v", "libx264", "-preset", "veryfast", "-crf", "14",
+				"-video_track_timescale", "90000")
+		}
+		if hasAudio {
+			args = append(args, "-map", "0:a:0", "-af", "areverse", "-c:a", "pcm_s16le", "-ar", "48000")
+		}
+		args = append(args, chunk)
+
+		cmd := exec.Command("ffmpeg", args...)
+		if output, err := run.combinedOutput(cmd); err != nil {
+			return fmt.Errorf("%v: %s", err, string(output))
+		}
+		list.WriteString(concatEntry(chunk))
+	}
+
+	listPath := filepath.Join(work, "chunks.txt")
+	if err := os.WriteFile(listPath, []byte(list.String()), 0644); err != nil {
+		return err
+	}
+	tmp := out + ".partial" + ext
+	cmd := exec.Command("ffmpeg", "-y", "-f", "concat", "-safe", "0", "-i", listPath, "-c", "copy", tmp)
+	if output, err := run.combinedOutput(cmd); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%v: %s", err, string(output))
+	}
+	return os.Rename(tmp, out)
+}
+
+// resolveReversedSegments turns segments flagged Reversed into something the
+// normal pipeline can render: a "reverse" clip filter for short slices, or the
+// matching forward range of the reversed proxy for long ones. run is the
+// export the proxies are built for (nil for previews).
+func (a *App) resolveReversedSegments(run *exportRun, segments []RenderSegment) error {
+	for i := range segments {
+		seg := &segments[i]
+		if !seg.Reversed {
+			continue
+		}
+		seg.Reversed = false
+		if seg.Duration <= reverseInlineSeconds {
+			seg.Filter = joinFilters("reverse", seg.Filter)
+			continue
+		}
+		proxy, length, err := a.reversedProxy(run, seg.SourcePath)
+		if err != nil {
+			return err
+		}
+		seg.SourcePath = proxy
+		seg.InPoint = max(0, length-seg.OutPoint)
+		seg.OutPoint = seg.InPoint + seg.Duration
+	}
+	return nil
+}
+
+// reversedSourceRange maps a slice [start, start+dur) of a reversed clip on the
+// timeline to the source range it shows (played backwards)
+func reversedSourceRange(item TimelineItem, start float64, dur float64) (float64, float64) {
+	out := item.TrimStart + item.Duration - (start - item.StartTime)
+	return out - dur, out
+}
+
+// PrepareReversedProxy builds (or reuses) the reversed proxy of a media file so
+// the player can scrub reversed clips, and returns its path
+func (a *App) PrepareReversedProxy(path string) (string, error) {
+	a.beginForeground()
+	defer a.endForeground()
+
+	proxy, _, err := a.reversedProxy(nil, path)
+	if err != nil {
+		recordEngineError("reverse", err.Error())
+		return "", err
+	}
+	return proxy, nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- REVIEW PACKAGE EXPORT ---
+
+// Self-contained review folders for clients.
+
+type ReviewShot struct {
+	Index     int     `json:"index"`
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Prompt    string  `json:"prompt"`
+	Duration  float64 `json:"duration"`
+	Status    string  `json:"status"`
+	Thumbnail string  `json:"thumbnail"` // Relative to the package folder
+}
+
+const reviewPageTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", sans-serif; background: #111; color: #ddd; margin: 0; padding: 32px; }
+  h1 { margin: 0 0 4px; font-size: 22px; }
+  .meta { color: #888; margin-bottom: 24px; }
+  video, .sheet { max-width: 100%; border-radius: 6px; background: #000; }
+  table { width: 100%; border-collapse: collapse; margin-top: 24px; }
+  td, th { border-bottom: 1px solid #333; padding: 10px; vertical-align: top; text-align: left; }
+  td img { width: 240px; border-radius: 4px; }
+  .prompt { color: #aaa; font-size: 13px; max-width: 420px; }
+  .comments { min-width: 260px; min-height: 80px; border: 1px dashed #555; border-radius: 4px; padding: 6px; color: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">Exported {{.Date}} &middot; {{len .Shots}} shots</div>
+{{if .HasVideo}}<video src="review.mp4" controls></video>{{end}}
+{{if .HasSheet}}<h2>Contact Sheet</h2><img class="sheet" src="contact_sheet.jpg" alt="Contact sheet">{{end}}
+<h2>Shots</h2>
+<table>
+<tr><th>#</th><th>Frame</th><th>Shot</th><th>Comments</th></tr>
+{{range .Shots}}<tr>
+  <td>{{.Index}}</td>
+  <td>{{if .Thumbnail}}<img src="{{.Thumbnail}}" alt="{{.Name}}">{{end}}</td>
+  <td><strong>{{.Name}}</strong><br>{{printf "%.1f" .Duration}}s &middot; {{.Status}}<div class="prompt">{{.Prompt}}</div></td>
+  <td><div class="comments" contenteditable="true">Comments…</div></td>
+</tr>{{end}}
+</table>
+</body>
+</html>
+`
+
+// reviewBitrateArgs caps the review cut's bitrate on top of the low quality CRF
+const reviewBitrateArgs = "-maxrate 2M -bufsize 4M"
+
+// ExportReviewPackage asks for a destination folder and writes a review bundle
+// for the scene into a new subfolder. watermark is burned into review.mp4.
+func (a *App) ExportReviewPackage(projectId string, sceneId string, watermark string) string {
+	parent, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: tr("review.dialogTitle"),
+	})
+	if err != nil || parent == "" {
+		return "Cancelled"
+	}
+
+	project, err := a.GetProject(projectId)
+	if err != nil {
+		return tr("review.error.project")
+	}
+	sceneName := strings.Trim(a.sceneLabel(projectId, sceneId), `"`)
+	title := fmt.Sprintf("%s - %s", project.Name, sceneName)
+
+	dir := filepath.Join(parent, sanitizeFileName(title)+" Review "+time.Now().Format("2006-01-02 1504"))
+	thumbsDir := filepath.Join(dir, "thumbs")
+	if err := os.MkdirAll(thumbsDir, 0755); err != nil {
+		return tr("review.error.folder", err.Error())
+	}
+
+	// 1. Thumbnails + shot list
+	runtime.EventsEmit(a.ctx, "export:status", tr("review.thumbnails"))
+	var reviewShots []ReviewShot
+	for i, shot := range a.GetShots(projectId, sceneId) {
+		rs := ReviewShot{
+			Index:    i + 1,
+			ID:       shot.ID,
+			Name:     shot.Name,
+			Prompt:   shot.Prompt,
+			Duration: shot.Duration,
+			Status:   shot.Status,
+		}
+		thumbName := fmt.Sprintf("%03d.jpg", i+1)
+		thumbPath := filepath.Join(thumbsDir, thumbName)
+		var thumbErr error
+		switch {
+		case shot.Thumbnail != "":
+			thumbErr = copyFile(shot.Thumbnail, thumbPath) // Render-time thumbnail
+		case shot.OutputVideo != "":
+			thumbErr = a.extractMiddleFrame(shot.OutputVideo, thumbPath)
+		case shot.SourceImage != "":
+			thumbErr = a.extractFrameAt(shot.SourceImage, 0, thumbPath)
+		default:
+			thumbErr = fmt.Errorf("no media")
+		}
+		if thumbErr == nil {
+			rs.Thumbnail = "thumbs/" + thumbName
+		}
+		reviewShots = append(reviewShots, rs)
+	}
+
+	shotData, _ := json.MarshalIndent(reviewShots, "", "  ")
+	os.WriteFile(filepath.Join(dir, "shots.json"), shotData, 0644)
+
+	// 2. Contact sheet (4 columns, shots without media are left out)
+	hasSheet := a.renderContactSheet(reviewShots, dir) == nil
+
+	// 3. Watermarked review cut, small enough to mail or stream
+	width, height := previewSize(project)
+	result := a.exportTimeline(projectId, sceneId, ExportOptions{
+		Format:       "mp4",
+		IncludeVideo: true,
+		IncludeAudio: true,
+		Quality:      "low",
+		Width:        width,
+		Height:       height,
+		Watermark:    watermark,
+		Advanced:     AdvancedExportArgs{EncodeArgs: reviewBitrateArgs},
+		unjournaled:  true,
+	}, filepath.Join(dir, "review.mp4"))
+	hasVideo := result == "Success"
+	if !hasVideo {
+		fmt.Println("Review package: video skipped:", result)
+	}
+
+	// 4. HTML page
+	tmpl := template.Must(template.New("review").Parse(reviewPageTemplate))
+	page, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return tr("review.error.page", err.Error())
+	}
+	defer page.Close()
+	err = tmpl.Execute(page, map[string]interface{}{
+		"Title":    title,
+		"Date":     time.Now().Format("2006-01-02 15:04"),
+		"Shots":    reviewShots,
+		"HasVideo": hasVideo,
+		"HasSheet": hasSheet,
+	})
+	if err != nil {
+		return tr("review.error.page", err.Error())
+	}
+
+	runtime.EventsEmit(a.ctx, "export:status", tr("review.ready"))
+	return "Success"
+}
+
+// renderContactSheet tiles the extracted thumbnails into contact_sheet.jpg
+func (a *App) renderContactSheet(shots []ReviewShot, dir string) error {
+	listPath := filepath.Join(dir, "thumbs", "sheet.txt")
+	var list strings.Builder
+	count := 0
+	for _, s := range shots {
+		if s.Thumbnail == "" {
+			continue
+		}
+		list.WriteString(fmt.Sprintf("file '%s'\n", filepath.Base(s.Thumbnail)))
+		count++
+	}
+	if count == 0 {
+		return fmt.Errorf("no thumbnails")
+	}
+	os.WriteFile(listPath, []byte(list.String()), 0644)
+	defer os.Remove(listPath)
+
+	cols := 4
+	rows := (count + cols - 1) / cols
+	filter := fmt.Sprintf("scale=320:180:force_original_aspect_ratio=decrease,pad=320:180:(ow-iw)/2:(oh-ih)/2:color=black,tile=%dx%d:padding=8:margin=8", cols, rows)
+	cmd := exec.Command("ffmpeg", "-y", "-f", "concat", "-safe", "0", "-i", listPath, "-vf", filter, "-frames:v", "1", filepath.Join(dir, "contact_sheet.jpg"))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, string(out))
+	}
+	return nil
+}
+
+// sanitizeFileName keeps a human-readable name while dropping characters
+// that are invalid in Windows/macOS file names
+func sanitizeFileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, r) || r < 32 {
+			return '_'
+		}
+		return r
+	}, name)
+	return strings.TrimSpace(safe)
+}
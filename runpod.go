@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- RUNPOD PROVISIONING ---
+
+// Starts and stops RunPod pods as render servers.
+
+type RunPodSettings struct {
+	PodID        string `json:"podId"`        // Pod to start; set when one is created
+	TemplateID   string `json:"templateId"`   // Creates a pod when PodID is empty
+	GPUType      string `json:"gpuType"`      // For new pods, e.g. "NVIDIA GeForce RTX 4090"
+	Port         int    `json:"port"`         // ComfyUI's port in the pod; 0 = 8188
+	StopWhenIdle bool   `json:"stopWhenIdle"` // Stop once the queue drains
+	IdleMinutes  int    `json:"idleMinutes"`  // 0 = defaultRunPodIdle
+}
+
+type RunPodStatus struct {
+	PodID   string `json:"podId"`
+	Status  string `json:"status"` // RUNNING, EXITED, ... as RunPod reports it
+	URL     string `json:"url"`    // ComfyUI through the RunPod proxy
+	Ready   bool   `json:"ready"`  // ComfyUI answers
+	InPool  bool   `json:"inPool"` // Registered as a render server
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	runpodAPI         = "https://rest.runpod.io/v1"
+	runpodServerName  = "RunPod"
+	defaultComfyPort  = 8188
+	defaultRunPodIdle = 10 // Minutes
+	runpodBootTimeout = 20 * time.Minute
+	runpodPollEvery   = 10 * time.Second
+)
+
+var (
+	runpodMu        sync.Mutex
+	runpodStarting  bool
+	runpodIdleSince time.Time
+)
+
+// GetRunPodSettings returns the RunPod pod settings
+func (a *App) GetRunPodSettings() RunPodSettings {
+	return a.getConfig().RunPod
+}
+
+// SaveRunPodSettings persists the RunPod pod settings
+func (a *App) SaveRunPodSettings(settings RunPodSettings) string {
+	settings.PodID = strings.TrimSpace(settings.PodID)
+	settings.TemplateID = strings.TrimSpace(settings.TemplateID)
+	settings.GPUType = strings.TrimSpace(settings.GPUType)
+	if settings.Port < 0 || settings.Port > 65535 {
+		return "Error: invalid port"
+	}
+	if settings.IdleMinutes < 0 {
+		return "Error: idle minutes must not be negative"
+	}
+	a.updateConfig(func(c *Config) { c.RunPod = settings })
+	return "Success"
+}
+
+func runpodURL(podId string, port int) string {
+	if port == 0 {
+		port = defaultComfyPort
+	}
+	return fmt.Sprintf("https://%s-%d.proxy.runpod.net", podId, port)
+}
+
+// GetRunPodStatus reports the pod's state and whether ComfyUI answers
+func (a *App) GetRunPodStatus() (RunPodStatus, error) {
+	settings := a.getConfig().RunPod
+	if settings.PodID == "" {
+		return RunPodStatus{}, fmt.Errorf("no pod is set up")
+	}
+	var pod struct {
+		DesiredStatus string `json:"desiredStatus"`
+	}
+	if err := a.runpodCall("GET", "/pods/"+settings.PodID, nil, &pod); err != nil {
+		return RunPodStatus{PodID: settings.PodID}, err
+	}
+	status := RunPodStatus{PodID: settings.PodID, Status: pod.DesiredStatus, URL: runpodURL(settings.PodID, settings.Port)}
+	status.InPool = a.findComfyServer(status.URL).Enabled
+	if pod.DesiredStatus == "RUNNING" {
+		status.Ready = a.comfyJSONAt(status.URL, "GET", "/system_stats", nil, nil) == nil
+	}
+	return status, nil
+}
+
+// StartRunPod starts (or creates) the pod, waits for ComfyUI and adds it to
+// the render pool. Progress comes as "runpod:status" events.
+func (a *App) StartRunPod() (RunPodStatus, error) {
+	runpodMu.Lock()
+	if runpodStarting {
+		runpodMu.Unlock()
+		return RunPodStatus{}, fmt.Errorf("the pod is already starting")
+	}
+	runpodStarting = true
+	runpodMu.Unlock()
+	defer func() {
+		runpodMu.Lock()
+		runpodStarting = false
+		runpodMu.Unlock()
+	}()
+
+	settings := a.getConfig().RunPod
+	if a.getCredential(CredRunPodKey) == "" {
+		return RunPodStatus{}, fmt.Errorf("no RunPod API key is stored")
+	}
+	if settings.PodID == "" {
+		podId, err := a.createRunPod(settings)
+		if err != nil {
+			return RunPodStatus{}, err
+		}
+		settings.PodID = podId
+		a.updateConfig(func(c *Config) { c.RunPod.PodID = podId })
+	} else {
+		a.emitRunPodStatus(RunPodStatus{PodID: settings.PodID, Status: "STARTING"})
+		if err := a.runpodCall("POST", "/pods/"+settings.PodID+"/start", nil, nil); err != nil {
+			return RunPodStatus{PodID: settings.PodID}, err
+		}
+	}
+
+	status := RunPodStatus{PodID: settings.PodID, Status: "STARTING", URL: runpodURL(settings.PodID, settings.Port)}
+	deadline := time.Now().Add(runpodBootTimeout)
+	for a.comfyJSONAt(status.URL, "GET", "/system_stats", nil, nil) != nil {
+		if time.Now().After(deadline) {
+			// Not in the pool, so the idle monitor would never stop it
+			status.Status, status.Message = "EXITED", "ComfyUI did not come up in time, pod stopped"
+			if err := a.runpodCall("POST", "/pods/"+settings.PodID+"/stop", nil, nil); err != nil {
+				status.Status, status.Message = "RUNNING", "ComfyUI did not come up in time and the pod could not be stopped"
+				recordEngineError("runpod", err.Error())
+			}
+			a.emitRunPodStatus(status)
+			return status, fmt.Errorf("ComfyUI on the pod did not answer within %v", runpodBootTimeout)
+		}
+		status.Message = "Waiting for ComfyUI"
+		a.emitRunPodStatus(status)
+		time.Sleep(runpodPollEvery)
+	}
+
+	a.registerRunPod(status.URL, true)
+	status.Status, status.Ready, status.InPool, status.Message = "RUNNING", true, true, ""
+	runpodMu.Lock()
+	runpodIdleSince = time.Time{}
+	runpodMu.Unlock()
+	a.emitRunPodStatus(status)
+	return status, nil
+}
+
+// StopRunPod takes the pod out of the render pool and stops it
+func (a *App) StopRunPod() string {
+	settings := a.getConfig().RunPod
+	if settings.PodID == "" {
+		return "Error: no pod is set up"
+	}
+	url := runpodURL(settings.PodID, settings.Port)
+	a.registerRunPod(url, false)
+	if err := a.runpodCall("POST", "/pods/"+settings.PodID+"/stop", nil, nil); err != nil {
+		return "Error: " + err.Error()
+	}
+	a.emitRunPodStatus(RunPodStatus{PodID: settings.PodID, Status: "EXITED", URL: url})
+	return "Success"
+}
+
+// createRunPod creates a pod from the configured template
+func (a *App) createRunPod(settings RunPodSettings) (string, error) {
+	if settings.TemplateID == "" {
+		return "", fmt.Errorf("set a pod ID or a template to create one from")
+	}
+	port := settings.Port
+	if port == 0 {
+		port = defaultComfyPort
+	}
+	body := map[string]interface{}{
+		"name":       "motion-studio-comfyui",
+		"templateId": settings.TemplateID,
+		"gpuCount":   1,
+		"ports":      []string{fmt.Sprintf("%d/http", port)},
+	}
+	if settings.GPUType != "" {
+		body["gpuTypeIds"] = []string{settings.GPUType}
+	}
+	a.emitRunPodStatus(RunPodStatus{Status: "CREATING"})
+	var pod struct {
+		ID string `json:"id"`
+	}
+	if err := a.runpodCall("POST", "/pods", body, &pod); err != nil {
+		return "", err
+	}
+	if pod.ID == "" {
+		return "", fmt.Errorf("RunPod did not return a pod id")
+	}
+	return pod.ID, nil
+}
+
+// registerRunPod adds the pod to the render pool, or disables it there
+func (a *App) registerRunPod(url string, enabled bool) {
+	a.updateConfig(func(c *Config) {
+		for i := range c.ComfyServers {
+			if c.ComfyServers[i].URL == url {
+				c.ComfyServers[i].Enabled = enabled
+				return
+			}
+		}
+		if enabled {
+			c.ComfyServers = append(c.ComfyServers, ComfyServer{Name: runpodServerName, URL: url, Enabled: true, Slots: 1})
+		}
+	})
+	wakeRenderQueue()
+}
+
+func (a *App) emitRunPodStatus(status RunPodStatus) {
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "runpod:status", status)
+	}
+}
+
+// runRunPodMonitor stops an idle pod when StopWhenIdle is set
+func (a *App) runRunPodMonitor() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		settings := a.getConfig().RunPod
+		if !settings.StopWhenIdle || settings.PodID == "" || !a.findComfyServer(runpodURL(settings.PodID, settings.Port)).Enabled {
+			continue
+		}
+		runpodMu.Lock()
+		switch {
+		case runpodStarting || !rendersIdle():
+			runpodIdleSince = time.Time{}
+		case runpodIdleSince.IsZero():
+			runpodIdleSince = time.Now()
+		}
+		minutes := settings.IdleMinutes
+		if minutes <= 0 {
+			minutes = defaultRunPodIdle
+		}
+		idle := !runpodIdleSince.IsZero() && time.Since(runpodIdleSince) >= time.Duration(minutes)*time.Minute
+		if idle {
+			runpodIdleSince = time.Time{}
+		}
+		runpodMu.Unlock()
+
+		if idle {
+			fmt.Println("RunPod: render queue idle, stopping pod", settings.PodID)
+			if result := a.StopRunPod(); result != "Success" {
+				recordEngineError("runpod", result)
+			}
+		}
+	}
+}
+
+// runpodCall sends a request to the RunPod REST API
+func (a *App) runpodCall(method string, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, runpodAPI+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.getCredential(CredRunPodKey))
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := (&http.Client{Timeout: 30 * time.Second}).Do(req)
+	if err != nil {
+		return fmt.Errorf("RunPod unreachable: %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("RunPod API Error (%d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out != nil && len(data) > 0 {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
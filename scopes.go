@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// --- VIDEO SCOPES ---
+
+// scopeFilters maps a scope name to the ffmpeg filter chain that draws it.
+// Inputs are converted to yuv444p first so chroma isn't subsampled in the scope.
+var scopeFilters = map[string]string{
+	"histogram":   "format=yuv444p,histogram=display_mode=stack:levels_mode=linear",
+	"waveform":    "format=yuv444p,waveform=filter=lowpass:scale=ire:graticule=green:flags=numbers+dots",
+	"parade":      "format=yuv444p,waveform=display=parade:components=7:scale=ire:graticule=green:flags=numbers+dots",
+	"vectorscope": "format=yuv444p,vectorscope=mode=color3:graticule=color:flags=name+white+black",
+}
+
+// GetScopeURL returns the engine URL that renders the requested scope
+// ("histogram", "waveform", "parade" or "vectorscope") for the frame at time t.
+func (a *App) GetScopeURL(path string, t float64, scope string) string {
+	if _, ok := scopeFilters[scope]; !ok {
+		return ""
+	}
+	query := url.Values{}
+	query.Set("path", path)
+	query.Set("t", strconv.FormatFloat(t, 'f', 3, 64))
+	query.Set("type", scope)
+	query.Set("v", strconv.FormatInt(time.Now().UnixMilli(), 10)) // Bust webview cache
+	return engineURL("/scopes?" + query.Encode())
+}
+
+// renderScope grabs a single frame at t and returns the scope as PNG bytes.
+func renderScope(path string, t float64, scope string) ([]byte, error) {
+	filter, ok := scopeFilters[scope]
+	if !ok {
+		return nil, fmt.Errorf("unknown scope %q", scope)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("ffmpeg",
+		"-v", "error",
+		"-ss", fmt.Sprintf("%f", t),
+		"-i", mediaPath(path),
+		"-frames:v", "1",
+		"-vf", filter,
+		"-f", "image2pipe",
+		"-vcodec", "png",
+		"-",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, stderr.String())
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no frame at %.3fs", t)
+	}
+	return out, nil
+}
+
+// ScopesHandler serves /scopes?path=<file>&t=<seconds>&type=<scope> as a PNG.
+func (s *StreamServer) ScopesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	q := r.URL.Query()
+	path := q.Get("path")
+	if path == "" {
+		http.Error(w, "missing path", http.StatusBadRequest)
+		return
+	}
+	t, _ := strconv.ParseFloat(q.Get("t"), 64)
+	scope := q.Get("type")
+	if scope == "" {
+		scope = "waveform"
+	}
+
+	png, err := renderScope(path, t, scope)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(png)
+}
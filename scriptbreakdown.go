@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- SCRIPT BREAKDOWN ---
+
+// Splits a script into shots through the prompt assistant.
+
+type ScriptShot struct {
+	Name     string  `json:"name"`
+	Prompt   string  `json:"prompt"`
+	Duration float64 `json:"duration"` // Seconds
+}
+
+const (
+	scriptShotMin = 1.0
+	scriptShotMax = 20.0 // Video models rarely go further in one render
+)
+
+const scriptBreakdownInstructions = `You are a storyboard artist breaking a script or treatment into shots for an AI video generator. Split it into consecutive shots in story order. For each shot give a short name (at most five words), a detailed video prompt (subject, action and motion, camera movement and framing, lighting, mood and style; no dialogue or on-screen text) and an estimated duration in seconds between 2 and 10. Reply with only a JSON array of objects with the keys "name", "prompt" and "duration".`
+
+// BreakdownScript asks the model to split a script into shots
+func (a *App) BreakdownScript(script string) ([]ScriptShot, error) {
+	script = strings.TrimSpace(script)
+	if script == "" {
+		return []ScriptShot{}, fmt.Errorf("the script is empty")
+	}
+	settings := a.getConfig().LLM
+	// Splitting wants a steadier model than prompt ideas do
+	if settings.Temperature == 0 {
+		settings.Temperature = 0.4
+	}
+	reply, err := a.llmChat(settings, scriptBreakdownInstructions, "Script:\n"+script)
+	if err != nil {
+		return []ScriptShot{}, err
+	}
+	shots := parseScriptShots(reply)
+	if len(shots) == 0 {
+		return []ScriptShot{}, fmt.Errorf("the assistant returned no shots")
+	}
+	return shots, nil
+}
+
+// CreateSceneFromScript breaks a script down and saves it as a new scene
+func (a *App) CreateSceneFromScript(projectId string, name string, script string) (Scene, error) {
+	shots, err := a.BreakdownScript(script)
+	if err != nil {
+		return Scene{}, err
+	}
+	return a.CreateSceneFromShots(projectId, name, shots)
+}
+
+// CreateSceneFromShots creates a scene with a DRAFT shot per entry
+func (a *App) CreateSceneFromShots(projectId string, name string, list []ScriptShot) (Scene, error) {
+	if _, err := a.GetProject(projectId); err != nil {
+		return Scene{}, fmt.Errorf("project not found")
+	}
+	if len(list) == 0 {
+		return Scene{}, fmt.Errorf("no shots to create")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Script Breakdown"
+	}
+
+	scene := a.CreateScene(projectId, name)
+	shots := make([]Shot, 0, len(list))
+	for i, entry := range list {
+		shot := a.CreateShot(scene.ID)
+		// Shot IDs come from the clock; keep them apart in a tight loop
+		for len(shots) > 0 && shot.ID == shots[len(shots)-1].ID {
+			time.Sleep(time.Microsecond)
+			shot = a.CreateShot(scene.ID)
+		}
+		shot.Name = strings.TrimSpace(entry.Name)
+		if shot.Name == "" {
+			shot.Name = fmt.Sprintf("Shot %d", i+1)
+		}
+		shot.Prompt = strings.TrimSpace(entry.Prompt)
+		if entry.Duration > 0 {
+			shot.Duration = math.Min(math.Max(entry.Duration, scriptShotMin), scriptShotMax)
+		}
+		shots = append(shots, shot)
+	}
+	a.SaveShots(projectId, scene.ID, shots)
+	scene.ShotCount = len(shots)
+	return scene, nil
+}
+
+// parseScriptShots reads the reply's JSON array of shots; models sometimes
+// wrap it in prose or give durations as "4s"
+func parseScriptShots(reply string) []ScriptShot {
+	shots := []ScriptShot{}
+	start, end := strings.Index(reply, "["), strings.LastIndex(reply, "]")
+	if start < 0 || end <= start {
+		return shots
+	}
+	var raw []map[string]interface{}
+	if json.Unmarshal([]byte(reply[start:end+1]), &raw) != nil {
+		return shots
+	}
+	for _, entry := range raw {
+		shot := ScriptShot{}
+		shot.Name, _ = entry["name"].(string)
+		shot.Prompt, _ = entry["prompt"].(string)
+		switch d := entry["duration"].(type) {
+		case float64:
+			shot.Duration = d
+		case string:
+			d = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(strings.ToLower(d)), "s"))
+			shot.Duration, _ = strconv.ParseFloat(d, 64)
+		}
+		if strings.TrimSpace(shot.Prompt) == "" {
+			continue
+		}
+		shots = append(shots, shot)
+	}
+	return shots
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// --- SEED MODES ---
+
+// Seeds for new renders: locked, random or increment.
+
+const (
+	SeedLocked    = "locked"
+	SeedRandom    = "random"
+	SeedIncrement = "increment"
+)
+
+// maxRandomSeed keeps drawn seeds exact in JavaScript numbers
+const maxRandomSeed = 1 << 53
+
+// renderSeed picks the seed for a new render of a shot. Retries of a lost
+// prompt (attempt > 0) render with chosen, the seed of the first attempt.
+func renderSeed(shot Shot, attempt int, chosen int64) int64 {
+	if attempt > 0 {
+		return chosen
+	}
+	switch shot.SeedMode {
+	case SeedRandom:
+		return rand.Int63n(maxRandomSeed)
+	case SeedIncrement:
+		if shot.OutputVideo == "" {
+			return shot.Seed // First render uses the seed as entered
+		}
+		return shot.Seed + 1
+	}
+	return shot.Seed
+}
+
+// SetShotSeedMode sets how a shot's seed changes between renders
+func (a *App) SetShotSeedMode(projectId string, sceneId string, shotId string, mode string) error {
+	switch mode {
+	case "", SeedLocked:
+		mode = "" // Stored empty, the default
+	case SeedRandom, SeedIncrement:
+	default:
+		return fmt.Errorf("unknown seed mode %q", mode)
+	}
+	for _, s := range a.GetShots(projectId, sceneId) {
+		if s.ID == shotId {
+			a.updateShot(projectId, sceneId, shotId, func(s *Shot) { s.SeedMode = mode })
+			return nil
+		}
+	}
+	return fmt.Errorf("shot not found")
+}
+
+// executedSeed finds the seed in the prompt a history entry ran (the
+// "prompt" field: [number, id, graph, extra, outputs]). Inputs mapped as
+// SEED are read; a seed wired from another node is followed one step to
+// that node's value. The history must be decoded with UseNumber.
+func (a *App) executedSeed(entry map[string]interface{}) (int64, bool) {
+	prompt, _ := entry["prompt"].([]interface{})
+	if len(prompt) < 3 {
+		return 0, false
+	}
+	graph, _ := prompt[2].(map[string]interface{})
+
+	number := func(v interface{}) (int64, bool) {
+		n, ok := v.(json.Number)
+		if !ok {
+			return 0, false
+		}
+		seed, err := strconv.ParseInt(n.String(), 10, 64)
+		return seed, err == nil // Seeds above 2^63 don't fit a shot and are skipped
+	}
+
+	ids := make([]string, 0, len(graph))
+	for id := range graph {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids) // Same answer every time when several nodes take a seed
+	for _, id := range ids {
+		nodeMap, _ := graph[id].(map[string]interface{})
+		classType, _ := nodeMap["class_type"].(string)
+		inputs, _ := nodeMap["inputs"].(map[string]interface{})
+		for input, role := range a.nodeRules(classType) {
+			if role != "SEED" {
+				continue
+			}
+			value, exists := inputs[input]
+			if !exists {
+				continue
+			}
+			if link, isLink := value.([]interface{}); isLink && len(link) == 2 {
+				source, _ := graph[fmt.Sprint(link[0])].(map[string]interface{})
+				sourceInputs, _ := source["inputs"].(map[string]interface{})
+				for _, key := range []string{"seed", "noise_seed", "value", "int"} {
+					if seed, ok := number(sourceInputs[key]); ok {
+						return seed, true
+					}
+				}
+				continue
+			}
+			if seed, ok := number(value); ok {
+				return seed, true
+			}
+		}
+	}
+	return 0, false
+}
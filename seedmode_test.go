@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestRenderSeed(t *testing.T) {
+	rendered := "shot_v1.mp4"
+	tests := []struct {
+		name    string
+		shot    Shot
+		attempt int
+		chosen  int64
+		want    int64
+	}{
+		{name: "locked", shot: Shot{Seed: 42, OutputVideo: rendered}, want: 42},
+		{name: "locked explicit", shot: Shot{Seed: 42, SeedMode: SeedLocked, OutputVideo: rendered}, want: 42},
+		{name: "increment first render", shot: Shot{Seed: 42, SeedMode: SeedIncrement}, want: 42},
+		{name: "increment", shot: Shot{Seed: 42, SeedMode: SeedIncrement, OutputVideo: rendered}, want: 43},
+		{name: "retry keeps chosen seed", shot: Shot{Seed: 42, SeedMode: SeedIncrement, OutputVideo: rendered}, attempt: 1, chosen: 43, want: 43},
+		{name: "retry of random", shot: Shot{Seed: 42, SeedMode: SeedRandom}, attempt: 2, chosen: 7, want: 7},
+		{name: "retry of zero seed", shot: Shot{Seed: 42}, attempt: 1, chosen: 0, want: 0},
+	}
+	for _, tt := range tests {
+		if got := renderSeed(tt.shot, tt.attempt, tt.chosen); got != tt.want {
+			t.Errorf("%s: renderSeed = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		if got := renderSeed(Shot{Seed: 42, SeedMode: SeedRandom}, 0, 0); got < 0 || got >= maxRandomSeed {
+			t.Fatalf("random: renderSeed = %d, want 0 <= seed < 2^53", got)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- BACKGROUND SEGMENT RENDERING ---
+
+// Renders timeline segments into a content-addressed cache in the background.
+
+type sceneRef struct {
+	ProjectID string
+	SceneID   string
+}
+
+var (
+	// foregroundJobs counts exports/renders in flight; the worker idles while > 0
+	foregroundJobs int32
+
+	// queuePaused holds background work (tray "Pause Queue")
+	queuePaused atomic.Bool
+
+	bgMu         sync.Mutex
+	bgPending    *sceneRef
+	bgTimer      *time.Timer
+	bgGeneration int64 // Bumped on every timeline change to abandon stale work
+	bgWake       = make(chan struct{}, 1)
+
+	segmentLocks sync.Map // Cache key -> *sync.Mutex, see renderCachedSegment
+)
+
+// beginForeground marks the start of user-facing heavy work
+func (a *App) beginForeground() { atomic.AddInt32(&foregroundJobs, 1) }
+
+// endForeground marks the end of user-facing heavy work
+func (a *App) endForeground() { atomic.AddInt32(&foregroundJobs, -1) }
+
+func isQueuePaused() bool { return queuePaused.Load() }
+
+// SetQueuePaused holds or resumes background rendering
+func (a *App) SetQueuePaused(paused bool) {
+	queuePaused.Store(paused)
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "queue:paused", paused)
+	}
+}
+
+// IsQueuePaused reports whether background rendering is on hold
+func (a *App) IsQueuePaused() bool {
+	return isQueuePaused()
+}
+
+func (a *App) getSegmentCacheDir() string {
+	dir := filepath.Join(a.getAppDir(), "cache", "segments")
+	os.MkdirAll(dir, 0755)
+	return dir
+}
+
+// previewSize maps the project aspect ("16:9 (Cinematic)", "9:16 (Social)",
+// "4:3 (Classic)") to the resolution used for cached preview segments
+func previewSize(p Project) (int, int) {
+	switch {
+	case strings.HasPrefix(p.Type, "9:16"):
+		return 720, 1280
+	case strings.HasPrefix(p.Type, "4:3"):
+		return 960, 720
+	case strings.HasPrefix(p.Type, "1:1"):
+		return 1080, 1080
+	default:
+		return 1280, 720
+	}
+}
+
+// segmentCacheKey identifies a rendered segment by everything that affects
+// its pixels, including the source file's size and mtime so re-renders of a
+// shot invalidate old entries.
+func segmentCacheKey(seg RenderSegment, params string) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s|%f|%f|%f|%t|%s|%s", seg.SourcePath, seg.InPoint, seg.OutPoint, seg.Duration, seg.IsImage, seg.Filter, params)
+	if info, err := os.Stat(seg.SourcePath); err == nil {
+		fmt.Fprintf(h, "|%d|%d", info.Size(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// renderCachedSegment returns the cached intermediate for seg, rendering it if
+// needed. vf is appended after the clip filter; params must describe vf and
+// the encode settings so they are part of the key.
+func (a *App) renderCachedSegment(run *exportRun, seg RenderSegment, vf string, params string) (string, error) {
+	key := segmentCacheKey(seg, params)
+	// One render per key: a concurrent export of the same segment waits and
+	// reuses it instead of writing the same partial file
+	lock, _ := segmentLocks.LoadOrStore(key, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	out := filepath.Join(a.getSegmentCacheDir(), key+".mp4")
+	if _, err := os.Stat(out); err == nil {
+		return out, nil
+	}
+
+	filters := []string{}
+	if seg.Filter != "" {
+		if err := validateClipFilter(seg.Filter, "video"); err != nil {
+			return "", err
+		}
+		filters = append(filters, seg.Filter)
+	}
+	if vf != "" {
+		filters = append(filters, vf)
+	}
+	filters = append(filters, "format=yuv420p")
+
+	args := []string{"-y"}
+	if seg.IsImage {
+		args = append(args, "-loop", "1", "-t", fmt.Sprintf("%f", seg.Duration), "-i", mediaPath(seg.SourcePath))
+	} else {
+		args = append(args, "-ss", fmt.Sprintf("%f", seg.InPoint), "-t", fmt.Sprintf("%f", seg.Duration), "-i", mediaPath(seg.SourcePath))
+	}
+	tmp := out + ".partial.mp4"
+	args = append(args,
+		"-vf", strings.Join(filters, ","),
+		"-an",
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", "14",
+		"-video_track_timescale", "90000",
+		tmp)
+
+	cmd := exec.Command("ffmpeg", args...)
+	if output, err := run.combinedOutput(cmd); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("%v: %s", err, string(output))
+	}
+	if err := os.Rename(tmp, out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// previewSegmentParams returns the normalization filter and cache params for
+// a project's preview segments
+func (a *App) previewSegmentParams(projectId string) (string, string) {
+	p, _ := a.GetProject(projectId)
+	w, h := previewSize(p)
+	fps := p.FrameRate
+	if fps <= 0 {
+		fps = 25
+	}
+	vf := fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,setsar=1,%s",
+		w, h, w, h, conformFilter(fps, p.ConformPolicy))
+	return vf, "preview|" + vf
+}
+
+// scheduleBackgroundRender queues a scene for background segment rendering.
+// Rapid saves are debounced and only the latest scene is kept.
+func (a *App) scheduleBackgroundRender(projectId string, sceneId string) {
+	bgMu.Lock()
+	defer bgMu.Unlock()
+
+	atomic.AddInt64(&bgGeneration, 1)
+	bgPending = &sceneRef{ProjectID: projectId, SceneID: sceneId}
+	if bgTimer != nil {
+		bgTimer.Stop()
+	}
+	bgTimer = time.AfterFunc(2*time.Second, func() {
+		select {
+		case bgWake <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// runBackgroundRenderer is the lowest-priority worker: it renders one missing
+// segment at a time and backs off whenever foreground work is running.
+func (a *App) runBackgroundRenderer() {
+	for range bgWake {
+		bgMu.Lock()
+		ref := bgPending
+		bgPending = nil
+		generation := atomic.LoadInt64(&bgGeneration)
+		bgMu.Unlock()
+		if ref == nil {
+			continue
+		}
+
+		timeline := a.GetTimeline(ref.ProjectID, ref.SceneID)
+		blackPath, silencePath := a.prepareGapMedia()
+		segments, _ := analyzeVisualSegments(timeline, blackPath, silencePath)
+		if err := a.resolveReversedSegments(nil, segments); err != nil {
+			fmt.Println("Background render:", err)
+			recordEngineError("background", err.Error())
+			continue
+		}
+		vf, params := a.previewSegmentParams(ref.ProjectID)
+
+		done := 0
+		for _, seg := range segments {
+			for atomic.LoadInt32(&foregroundJobs) > 0 || isQueuePaused() {
+				time.Sleep(2 * time.Second)
+			}
+			if atomic.LoadInt64(&bgGeneration) != generation {
+				break // Timeline changed again; the newer request will pick up
+			}
+			if _, err := a.renderCachedSegment(nil, seg, vf, params); err != nil {
+				fmt.Println("Background render:", err)
+				recordEngineError("background", err.Error())
+				continue
+			}
+			// Export intermediates for custom clip filters are cached too
+			if seg.Filter != "" {
+				if _, err := a.renderFilteredSegment(nil, seg); err != nil {
+					fmt.Println("Background render:", err)
+				}
+			}
+			done++
+			runtime.EventsEmit(a.ctx, "preview:cached", map[string]interface{}{
+				"sceneId": ref.SceneID,
+				"done":    done,
+				"total":   len(segments),
+			})
+		}
+	}
+}
+
+// RenderScenePreview assembles a flattened preview of a scene from cached
+// segments (rendering any that are missing) plus the audio mix, and returns the
+// URL to stream it from.
+func (a *App) RenderScenePreview(projectId string, sceneId string) string {
+	if server == nil {
+		return "error: server_not_ready"
+	}
+	a.beginForeground()
+	defer a.endForeground()
+
+	timeline := a.GetTimeline(projectId, sceneId)
+	if len(timeline.Tracks) == 0 {
+		return "error: empty timeline"
+	}
+	blackPath, silencePath := a.prepareGapMedia()
+	segments, _ := analyzeVisualSegments(timeline, blackPath, silencePath)
+	if err := a.resolveReversedSegments(nil, segments); err != nil {
+		return "error: " + err.Error()
+	}
+	vf, params := a.previewSegmentParams(projectId)
+
+	var list strings.Builder
+	list.WriteString("ffconcat version 1.0\n")
+	for i, seg := range segments {
+		runtime.EventsEmit(a.ctx, "preview:status", tr("preview.preparing", i+1, len(segments)))
+		cached, err := a.renderCachedSegment(nil, seg, vf, params)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		list.WriteString(concatEntry(cached))
+	}
+
+	listPath := filepath.Join(server.currentDir, "scene_preview.txt")
+	os.WriteFile(listPath, []byte(list.String()), 0644)
+
+	// Audio mix through the export engine (silently)
+	mixPath := filepath.Join(server.currentDir, "scene_preview_audio.wav")
+	hasAudio := a.exportTimeline(projectId, sceneId, ExportOptions{
+		Format:       "wav",
+		IncludeAudio: true,
+		silent:       true,
+	}, mixPath) == "Success"
+
+	args := []string{"-y", "-f", "concat", "-safe", "0", "-i", listPath}
+	if hasAudio {
+		args = append(args, "-i", mixPath, "-map", "0:v", "-map", "1:a", "-c:a", "aac", "-b:a", "192k", "-shortest")
+	}
+	args = append(args, "-c:v", "copy", "-movflags", "+faststart", filepath.Join(server.currentDir, "preview.mp4"))
+
+	cmd := exec.Command("ffmpeg", args...)
+	if out, err := combinedOutputTracked(cmd); err != nil {
+		return "error: " + string(out)
+	}
+	return engineURL(fmt.Sprintf("/preview.mp4?t=%d", time.Now().UnixMilli()))
+}
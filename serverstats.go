@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- SERVER STATS ---
+
+// Device and memory stats of the render servers.
+
+type GPUDevice struct {
+	Key            string  `json:"key"` // "cuda:0", see gpuselect.go
+	Name           string  `json:"name"`
+	Type           string  `json:"type"` // cuda, mps, cpu, ...
+	Index          int     `json:"index"`
+	VRAMTotal      int64   `json:"vramTotal"` // Bytes
+	VRAMFree       int64   `json:"vramFree"`
+	TorchVRAMTotal int64   `json:"torchVramTotal"` // Reserved by PyTorch
+	TorchVRAMFree  int64   `json:"torchVramFree"`
+	UsedPercent    float64 `json:"usedPercent"`
+	LowVRAM        bool    `json:"lowVram"`
+}
+
+type ServerStats struct {
+	Server         string      `json:"server"`
+	Online         bool        `json:"online"`
+	Error          string      `json:"error,omitempty"`
+	ComfyVersion   string      `json:"comfyVersion"`
+	PythonVersion  string      `json:"pythonVersion"`
+	PytorchVersion string      `json:"pytorchVersion"`
+	OS             string      `json:"os"`
+	RAMTotal       int64       `json:"ramTotal"` // Bytes
+	RAMFree        int64       `json:"ramFree"`
+	Devices        []GPUDevice `json:"devices"`
+	LowVRAM        bool        `json:"lowVram"` // Any device
+	Time           string      `json:"time"`
+}
+
+const (
+	statsInterval = 5 * time.Second
+	// A device is low once less than this share, or this many bytes, is free
+	lowVRAMShare = 0.1
+	lowVRAMBytes = 1 << 30
+)
+
+var (
+	serverStatsMu sync.Mutex
+	serverStats   = map[string]ServerStats{} // Last reading per server URL
+)
+
+// GetServerStats reads the primary ComfyUI server's system stats
+func (a *App) GetServerStats() (ServerStats, error) {
+	stats := a.readServerStats(a.comfyURL)
+	if !stats.Online {
+		return stats, errors.New(stats.Error)
+	}
+	return stats, nil
+}
+
+// GetAllServerStats returns the last reading of every render server
+func (a *App) GetAllServerStats() []ServerStats {
+	serverStatsMu.Lock()
+	defer serverStatsMu.Unlock()
+	all := []ServerStats{}
+	for _, slot := range a.renderSlots() {
+		if stats, ok := serverStats[slot.URL]; ok {
+			all = append(all, stats)
+		}
+	}
+	return all
+}
+
+func (a *App) readServerStats(server string) ServerStats {
+	stats := ServerStats{Server: server, Devices: []GPUDevice{}, Time: time.Now().Format(time.RFC3339)}
+	var raw struct {
+		System struct {
+			OS             string `json:"os"`
+			RAMTotal       int64  `json:"ram_total"`
+			RAMFree        int64  `json:"ram_free"`
+			ComfyVersion   string `json:"comfyui_version"`
+			PythonVersion  string `json:"python_version"`
+			PytorchVersion string `json:"pytorch_version"`
+		} `json:"system"`
+		Devices []struct {
+			Name           string `json:"name"`
+			Type           string `json:"type"`
+			Index          int    `json:"index"`
+			VRAMTotal      int64  `json:"vram_total"`
+			VRAMFree       int64  `json:"vram_free"`
+			TorchVRAMTotal int64  `json:"torch_vram_total"`
+			TorchVRAMFree  int64  `json:"torch_vram_free"`
+		} `json:"devices"`
+	}
+	if err := a.comfyJSONAt(server, "GET", "/system_stats", nil, &raw); err != nil {
+		stats.Error = err.Error()
+		return stats
+	}
+
+	stats.Online = true
+	stats.OS = raw.System.OS
+	stats.RAMTotal, stats.RAMFree = raw.System.RAMTotal, raw.System.RAMFree
+	stats.ComfyVersion = raw.System.ComfyVersion
+	stats.PythonVersion = strings.Fields(raw.System.PythonVersion + " ")[0] // Drop the build info
+	stats.PytorchVersion = raw.System.PytorchVersion
+	for _, d := range raw.Devices {
+		device := GPUDevice{
+			Name: d.Name, Type: d.Type, Index: d.Index,
+			VRAMTotal: d.VRAMTotal, VRAMFree: d.VRAMFree,
+			TorchVRAMTotal: d.TorchVRAMTotal, TorchVRAMFree: d.TorchVRAMFree,
+		}
+		device.Key = deviceKey(device)
+		if d.VRAMTotal > 0 && d.Type != "cpu" {
+			device.UsedPercent = float64(d.VRAMTotal-d.VRAMFree) / float64(d.VRAMTotal) * 100
+			device.LowVRAM = float64(d.VRAMFree) < float64(d.VRAMTotal)*lowVRAMShare || d.VRAMFree < lowVRAMBytes
+		}
+		stats.LowVRAM = stats.LowVRAM || device.LowVRAM
+		stats.Devices = append(stats.Devices, device)
+	}
+	return stats
+}
+
+// runStatsMonitor emits "comfy:stats" for every render server
+func (a *App) runStatsMonitor() {
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, slot := range a.renderSlots() {
+			if a.serverBackend(slot.URL) != nil {
+				continue // /system_stats is ComfyUI's
+			}
+			stats := a.readServerStats(slot.URL)
+			serverStatsMu.Lock()
+			serverStats[slot.URL] = stats
+			serverStatsMu.Unlock()
+			if a.ctx != nil {
+				runtime.EventsEmit(a.ctx, "comfy:stats", stats)
+			}
+		}
+	}
+}
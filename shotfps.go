@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// --- RENDER FRAME RATE ---
+
+// Render frame rate per workflow and per shot.
+
+const defaultRenderFPS = 25.0
+
+// configuredFPS returns the shot's rate, else the workflow's, else 0
+func (a *App) configuredFPS(shot Shot, workflowName string) float64 {
+	if shot.FPS > 0 {
+		return shot.FPS
+	}
+	return a.loadWorkflowMeta(workflowName).FPS
+}
+
+// renderFPS is the rate a shot renders at
+func (a *App) renderFPS(shot Shot, workflowName string) float64 {
+	if fps := a.configuredFPS(shot, workflowName); fps > 0 {
+		return fps
+	}
+	return defaultRenderFPS
+}
+
+func validFPS(fps float64) error {
+	if fps < 0 || fps > 240 {
+		return fmt.Errorf("frame rate must be between 1 and 240 (0 clears it)")
+	}
+	return nil
+}
+
+// SetWorkflowFPS sets the frame rate a workflow renders at (0 = default)
+func (a *App) SetWorkflowFPS(name string, fps float64) string {
+	if err := validFPS(fps); err != nil {
+		return "Error: " + err.Error()
+	}
+	if _, err := os.Stat(filepath.Join(a.getWorkflowsDir(), name+".json")); err != nil {
+		return "Workflow not found"
+	}
+	meta := a.loadWorkflowMeta(name)
+	meta.FPS = fps
+	if err := a.saveWorkflowMeta(name, meta); err != nil {
+		return "Error saving workflow info"
+	}
+	return "Success"
+}
+
+// SetShotFPS overrides the frame rate of one shot (0 = use the workflow's)
+func (a *App) SetShotFPS(projectId string, sceneId string, shotId string, fps float64) error {
+	if err := validFPS(fps); err != nil {
+		return err
+	}
+	for _, s := range a.GetShots(projectId, sceneId) {
+		if s.ID == shotId {
+			a.updateShot(projectId, sceneId, shotId, func(s *Shot) { s.FPS = fps })
+			return nil
+		}
+	}
+	return fmt.Errorf("shot not found")
+}
+
+// countVideoFrames decodes a file's first video stream and counts its frames
+func countVideoFrames(path string) int {
+	out, err := exec.Command("ffprobe",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-count_frames",
+		"-show_entries", "stream=nb_read_frames",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		mediaPath(path)).Output()
+	if err != nil {
+		return 0
+	}
+	frames, _ := strconv.Atoi(strings.TrimSpace(string(out)))
+	return frames
+}
+
+// outputDuration is the length of a rendered output. Files without a frame
+// rate of their own (image sequences, some animated images) are timed at the
+// render rate instead of ffprobe's guess.
+func (a *App) outputDuration(path string, fps float64) float64 {
+	if a.getVideoFrameRate(path) <= 0 {
+		if frames := countVideoFrames(path); frames > 0 && fps > 0 {
+			return float64(frames) / fps
+		}
+	}
+	return a.getVideoDuration(path)
+}
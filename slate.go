@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- SLATE & COUNTDOWN LEADER ---
+
+// Slate cards and countdown leaders.
+
+type SlateOptions struct {
+	Title     string  `json:"title"`     // Defaults to the project name
+	Scene     string  `json:"scene"`     // Defaults to the scene name
+	Client    string  `json:"client"`    // Optional
+	Notes     string  `json:"notes"`     // Optional extra line
+	Date      string  `json:"date"`      // Defaults to today
+	Seconds   float64 `json:"seconds"`   // Slate card length, default 10
+	Countdown bool    `json:"countdown"` // Append the 8-second leader with 2-pop
+	Width     int     `json:"width"`     // 0 = match the first timeline clip
+	Height    int     `json:"height"`
+}
+
+const (
+	defaultSlateSeconds = 10.0
+	countdownSeconds    = 8.0
+)
+
+// drawtextSafe drops the characters that need multi-level filtergraph
+// escaping in drawtext
+func drawtextSafe(text string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`\':,;[]%"`, r) {
+			return ' '
+		}
+		return r
+	}, text)
+}
+
+// formatTRT formats a running time as HH:MM:SS:FF
+func formatTRT(seconds float64, fps float64) string {
+	frames := int(math.Round(seconds * fps))
+	perSecond := int(math.Round(fps))
+	if perSecond <= 0 {
+		perSecond = 24
+	}
+	ff := frames % perSecond
+	total := frames / perSecond
+	return fmt.Sprintf("%02d:%02d:%02d:%02d", total/3600, total/60%60, total%60, ff)
+}
+
+// timelineDuration returns where the last clip on any track ends
+func timelineDuration(timeline TimelineData) float64 {
+	end := 0.0
+	for _, track := range timeline.Tracks {
+		for _, raw := range track {
+			item := parseTimelineItem(raw)
+			end = math.Max(end, item.StartTime+item.Duration)
+		}
+	}
+	return end
+}
+
+// GenerateSlate renders the slate for a scene and returns the clip's path
+func (a *App) GenerateSlate(projectId string, sceneId string, options SlateOptions) (string, error) {
+	return a.generateSlate(nil, projectId, sceneId, options)
+}
+
+// generateSlate is GenerateSlate as a process of run (nil outside exports)
+func (a *App) generateSlate(run *exportRun, projectId string, sceneId string, options SlateOptions) (string, error) {
+	project, err := a.GetProject(projectId)
+	if err != nil {
+		return "", err
+	}
+	timeline := a.GetTimeline(projectId, sceneId)
+
+	fps := project.FrameRate
+	if fps <= 0 {
+		fps = 24
+	}
+	w, h := options.Width, options.Height
+	if w <= 0 || h <= 0 {
+		w, h = 1920, 1080
+		blackPath, silencePath := a.prepareGapMedia()
+		segments, _ := analyzeVisualSegments(timeline, blackPath, silencePath)
+		for _, seg := range segments {
+			if seg.SourcePath == blackPath {
+				continue
+			}
+			if sw, sh, err := probeVideoSize(seg.SourcePath); err == nil {
+				w, h = sw, sh
+				break
+			}
+		}
+	}
+	w, h = w&^1, h&^1
+
+	if options.Title == "" {
+		options.Title = project.Name
+	}
+	if options.Scene == "" {
+		options.Scene = strings.Trim(a.sceneLabel(projectId, sceneId), `"`)
+	}
+	if options.Date == "" {
+		options.Date = time.Now().Format("2006-01-02")
+	}
+	slateSeconds := options.Seconds
+	if slateSeconds <= 0 {
+		slateSeconds = defaultSlateSeconds
+	}
+	total := slateSeconds
+	if options.Countdown {
+		total += countdownSeconds
+	}
+
+	// Slate card
+	lines := []string{options.Title, "Scene  " + options.Scene}
+	if options.Client != "" {
+		lines = append(lines, "Client  "+options.Client)
+	}
+	lines = append(lines,
+		"Date  "+options.Date,
+		"TRT  "+formatTRT(timelineDuration(timeline), fps),
+		fmt.Sprintf("Format  %dx%d  %.3g fps", w, h, fps))
+	if options.Notes != "" {
+		lines = append(lines, options.Notes)
+	}
+
+	filters := []string{}
+	lineHeight := h / 14
+	top := (h - lineHeight*len(lines)) / 2
+	for i, line := range lines {
+		size := h / 22
+		if i == 0 {
+			size = h / 14
+		}
+		filters = append(filters, fmt.Sprintf("drawtext=text='%s':fontcolor=white:fontsize=%d:x=(w-text_w)/2:y=%d:enable='lt(t,%f)'",
+			drawtextSafe(line), size, top+i*lineHeight, slateSeconds))
+	}
+
+	// Countdown: 8..3 on screen, a single frame of "2" with the pop, then black
+	if options.Countdown {
+		start := slateSeconds
+		popAt := start + countdownSeconds - 2
+		frame := 1 / fps
+		filters = append(filters,
+			fmt.Sprintf("drawbox=x=0:y=ih/2:w=iw:h=2:color=gray:t=fill:enable='between(t,%f,%f)'", start, popAt+frame),
+			fmt.Sprintf("drawbox=x=iw/2:y=0:w=2:h=ih:color=gray:t=fill:enable='between(t,%f,%f)'", start, popAt+frame),
+			fmt.Sprintf("drawtext=text='%%{eif\\:%d-floor(t-%f)\\:d}':fontcolor=white:fontsize=h/3:x=(w-text_w)/2:y=(h-text_h)/2:enable='between(t,%f,%f)'",
+				int(countdownSeconds), start, start, popAt-0.0001),
+			fmt.Sprintf("drawtext=text='2':fontcolor=white:fontsize=h/3:x=(w-text_w)/2:y=(h-text_h)/2:enable='between(t,%f,%f)'", popAt, popAt+frame-0.0001),
+		)
+	}
+
+	args := []string{"-y",
+		"-f", "lavfi", "-i", fmt.Sprintf("color=c=black:s=%dx%d:r=%s:d=%f", w, h, strconv.FormatFloat(fps, 'f', -1, 64), total),
+	}
+	if options.Countdown {
+		popAt := slateSeconds + countdownSeconds - 2
+		args = append(args,
+			"-f", "lavfi", "-i", fmt.Sprintf("sine=frequency=1000:sample_rate=48000:duration=%f", 1/fps),
+			"-af", fmt.Sprintf("adelay=%d:all=1,apad", int(math.Round(popAt*1000))))
+	} else {
+		args = append(args, "-f", "lavfi", "-i", "anullsrc=r=48000:cl=stereo")
+	}
+
+	assetsDir := filepath.Join(a.getAppDir(), projectId, "assets")
+	os.MkdirAll(assetsDir, 0755)
+	outPath := filepath.Join(assetsDir, fmt.Sprintf("slate_%s_%d.mp4", sanitizeFileName(sceneId), time.Now().UnixNano()))
+
+	args = append(args,
+		"-vf", strings.Join(filters, ","),
+		"-t", fmt.Sprintf("%f", total),
+		"-c:v", "libx264", "-preset", "fast", "-crf", "18", "-pix_fmt", "yuv420p",
+		"-c:a", "aac", "-b:a", "192k", "-ac", "2",
+		"-movflags", "+faststart",
+		outPath)
+
+	cmd := exec.Command("ffmpeg", args...)
+	if out, err := run.combinedOutput(cmd); err != nil {
+		os.Remove(outPath)
+		return "", fmt.Errorf("%v: %s", err, string(out))
+	}
+	return outPath, nil
+}
+
+// withSlate returns a copy of timeline with every clip pushed back by the
+// slate's duration and the slate placed at zero on the first video track and
+// on its own audio track
+func withSlate(timeline TimelineData, slatePath string, duration float64) TimelineData {
+	out := TimelineData{TrackSettings: append([]TrackSetting{}, timeline.TrackSettings...)}
+	for len(out.TrackSettings) < len(timeline.Tracks) {
+		out.TrackSettings = append(out.TrackSettings, TrackSetting{Visible: true})
+	}
+
+	videoTrack := -1
+	for i, track := range timeline.Tracks {
+		shifted := make([]map[string]interface{}, 0, len(track)+1)
+		for _, raw := range track {
+			item := make(map[string]interface{}, len(raw))
+			for k, v := range raw {
+				item[k] = v
+			}
+			start, _ := item["startTime"].(float64)
+			item["startTime"] = start + duration
+			shifted = append(shifted, item)
+		}
+		out.Tracks = append(out.Tracks, shifted)
+		if videoTrack < 0 && !isAudioTrack(out, i) && !isAdjustmentTrack(out, i) {
+			videoTrack = i
+		}
+	}
+
+	slate := func() map[string]interface{} {
+		return map[string]interface{}{
+			"id":          "slate",
+			"startTime":   0.0,
+			"duration":    duration,
+			"trimStart":   0.0,
+			"outputVideo": slatePath,
+		}
+	}
+	if videoTrack < 0 {
+		out.Tracks = append(out.Tracks, nil)
+		out.TrackSettings = append(out.TrackSettings, TrackSetting{Visible: true, Type: "video", Name: "V-slate"})
+		videoTrack = len(out.Tracks) - 1
+	}
+	out.Tracks[videoTrack] = append([]map[string]interface{}{slate()}, out.Tracks[videoTrack]...)
+	out.Tracks = append(out.Tracks, []map[string]interface{}{slate()})
+	out.TrackSettings = append(out.TrackSettings, TrackSetting{Visible: true, Type: "audio", Name: "A-slate"})
+	return out
+}
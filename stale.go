@@ -0,0 +1,202 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// --- STALE SHOT DETECTION ---
+
+// Finds shots whose inputs changed since their last render.
+
+// fingerprintWorkflow is the fallback when a shot doesn't know its workflow
+const fingerprintWorkflow = "default"
+
+type StaleShot struct {
+	SceneID   string   `json:"sceneId"`
+	SceneName string   `json:"sceneName"`
+	ShotID    string   `json:"shotId"`
+	Name      string   `json:"name"`
+	Workflow  string   `json:"workflow"`
+	Reasons   []string `json:"reasons"` // prompt, image, audio, workflow, params, flagged
+}
+
+type StaleRerenderResult struct {
+	Jobs   []string          `json:"jobs"`   // Render queue job IDs
+	Failed map[string]string `json:"failed"` // Shot ID -> why it wasn't queued
+}
+
+// staleRerenderBatch is the render queue batch of the last RerenderStaleShots
+var staleRerenderBatch atomic.Value
+
+// fileStamp hashes a path together with its size and mtime, so replacing
+// an image or audio file in place still counts as a change
+func fileStamp(path string) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s", path)
+	if info, err := os.Stat(path); err == nil {
+		fmt.Fprintf(h, "|%d|%d", info.Size(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashOf(format string, args ...interface{}) string {
+	h := sha1.New()
+	fmt.Fprintf(h, format, args...)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// shotFingerprint hashes the render inputs of a shot by group
+func (a *App) shotFingerprint(shot Shot, workflowName string) map[string]string {
+	if workflowName == "" {
+		workflowName = fingerprintWorkflow
+	}
+	workflow := ""
+	if data, err := os.ReadFile(filepath.Join(a.getWorkflowsDir(), workflowName+".json")); err == nil {
+		workflow = hashOf("%s|%s", workflowName, data)
+	}
+	params := hashOf("%d|%d", shot.Seed, shot.MotionStrength)
+	if len(shot.Params) > 0 {
+		params = hashOf("%d|%d|%s", shot.Seed, shot.MotionStrength, paramsHash(shot.Params))
+	}
+	if fps := a.configuredFPS(shot, workflowName); fps > 0 {
+		params = hashOf("%s|%g", params, fps)
+	}
+	audio := ""
+	if shot.AudioPath != "" {
+		audio = hashOf("%s|%f|%f", fileStamp(shot.AudioPath), shot.AudioStart, shot.AudioDuration)
+	}
+	image := fileStamp(shot.SourceImage)
+	if shot.SourceImageEnd != "" {
+		image = hashOf("%s|%s", image, fileStamp(shot.SourceImageEnd))
+	}
+	for _, kind := range controlKindList(shot.ControlImages) {
+		image = hashOf("%s|%s=%s", image, kind, fileStamp(shot.ControlImages[kind]))
+	}
+	return map[string]string{
+		"prompt":   hashOf("%s", shot.Prompt),
+		"image":    image,
+		"audio":    audio,
+		"workflow": workflow,
+		"params":   params,
+		"models":   modelsHash(shot.Models),
+	}
+}
+
+// staleReasons lists what changed since the shot's last render (nil = fresh)
+func (a *App) staleReasons(shot Shot) []string {
+	if shot.OutputVideo == "" {
+		return nil // Never rendered: a draft, not stale
+	}
+	var reasons []string
+	if len(shot.RenderFingerprint) > 0 {
+		for group, hash := range a.shotFingerprint(shot, shot.RenderWorkflow) {
+			if shot.RenderFingerprint[group] != hash {
+				reasons = append(reasons, group)
+			}
+		}
+		sort.Strings(reasons)
+	}
+	if shot.Stale {
+		reasons = append(reasons, "flagged")
+	}
+	return reasons
+}
+
+// GetStaleShots lists shots whose output no longer matches their settings.
+// An empty sceneId checks every scene of the project.
+func (a *App) GetStaleShots(projectId string, sceneId string) []StaleShot {
+	var sceneIds []string
+	if sceneId != "" {
+		sceneIds = []string{sceneId}
+	} else {
+		for _, s := range a.GetScenes(projectId) {
+			sceneIds = append(sceneIds, s.ID)
+		}
+	}
+
+	stale := []StaleShot{}
+	for _, sid := range sceneIds {
+		for _, shot := range a.GetShots(projectId, sid) {
+			reasons := a.staleReasons(shot)
+			if len(reasons) == 0 {
+				continue
+			}
+			workflow := shot.RenderWorkflow
+			if workflow == "" {
+				workflow = fingerprintWorkflow
+			}
+			stale = append(stale, StaleShot{
+				SceneID:   sid,
+				SceneName: a.sceneLabel(projectId, sid),
+				ShotID:    shot.ID,
+				Name:      shot.Name,
+				Workflow:  workflow,
+				Reasons:   reasons,
+			})
+		}
+	}
+	return stale
+}
+
+// RerenderStaleShots queues every stale shot (of one scene, or the whole
+// project when sceneId is empty) as one render queue batch, whose progress
+// is reported by the "render:batch" events. workflowName overrides the
+// workflow each shot was last rendered with.
+func (a *App) RerenderStaleShots(projectId string, sceneId string, workflowName string) StaleRerenderResult {
+	result := StaleRerenderResult{Jobs: []string{}, Failed: map[string]string{}}
+	batch := uuid.New().String()
+	staleRerenderBatch.Store(batch)
+
+	checked := map[string]error{}
+	for _, s := range a.GetStaleShots(projectId, sceneId) {
+		workflow := workflowName
+		if workflow == "" {
+			workflow = s.Workflow
+		}
+		var shot *Shot
+		shots := a.GetShots(projectId, s.SceneID)
+		for i := range shots {
+			if shots[i].ID == s.ShotID {
+				shot = &shots[i]
+				break
+			}
+		}
+		if shot == nil {
+			result.Failed[s.ShotID] = "shot not found"
+			continue
+		}
+		// Each workflow is checked once, like RenderScene does
+		name := shotWorkflow(*shot, workflow)
+		if _, done := checked[name]; !done {
+			checked[name] = a.checkWorkflowBeforeQueue(name)
+		}
+		if err := checked[name]; err != nil {
+			result.Failed[s.ShotID] = err.Error()
+			continue
+		}
+		result.Jobs = append(result.Jobs, a.queueRender(projectId, s.SceneID, *shot, workflow, batch, 0, QualityFinal))
+	}
+	return result
+}
+
+// CancelStaleRerender drops the shots of the last stale batch that haven't
+// started yet; the ones rendering finish
+func (a *App) CancelStaleRerender() {
+	batch, _ := staleRerenderBatch.Load().(string)
+	if batch == "" {
+		return
+	}
+	for _, job := range a.GetRenderQueue() {
+		if job.Batch == batch && job.Status == JobQueued {
+			a.CancelRenderJob(job.ID)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// --- SCENE STATISTICS ---
+
+type SceneStats struct {
+	SceneID          string         `json:"sceneId"`
+	ShotCount        int            `json:"shotCount"`
+	ByStatus         map[string]int `json:"byStatus"` // DRAFT, RENDERING, DONE (+ any other status seen)
+	RenderedDuration float64        `json:"renderedDuration"`
+	PlannedDuration  float64        `json:"plannedDuration"`
+	Progress         float64        `json:"progress"`     // Rendered / planned, 0..1
+	LastActivity     string         `json:"lastActivity"` // RFC3339, empty if nothing on disk
+}
+
+// GetSceneStats summarizes a scene's production progress from the shots on disk
+func (a *App) GetSceneStats(projectId string, sceneId string) SceneStats {
+	stats := SceneStats{
+		SceneID:  sceneId,
+		ByStatus: map[string]int{"DRAFT": 0, "RENDERING": 0, "DONE": 0},
+	}
+
+	for _, shot := range a.GetShots(projectId, sceneId) {
+		stats.ShotCount++
+		status := strings.ToUpper(shot.Status)
+		if status == "" {
+			status = "DRAFT"
+		}
+		stats.ByStatus[status]++
+
+		// Drafts carry their planned length; rendered shots their real one
+		planned := shot.Duration
+		if planned <= 0 {
+			planned = shot.AudioDuration
+		}
+		stats.PlannedDuration += planned
+		if status == "DONE" && shot.OutputVideo != "" {
+			stats.RenderedDuration += shot.Duration
+		}
+	}
+	if stats.PlannedDuration > 0 {
+		stats.Progress = min(stats.RenderedDuration/stats.PlannedDuration, 1)
+	}
+
+	if latest := latestModTime(filepath.Join(a.getAppDir(), projectId, "scenes", sceneId)); !latest.IsZero() {
+		stats.LastActivity = latest.Format(time.RFC3339)
+	}
+	return stats
+}
+
+// GetProjectSceneStats returns GetSceneStats for every scene of a project
+func (a *App) GetProjectSceneStats(projectId string) []SceneStats {
+	all := []SceneStats{}
+	for _, scene := range a.GetScenes(projectId) {
+		all = append(all, a.GetSceneStats(projectId, scene.ID))
+	}
+	return all
+}
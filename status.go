@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// --- ENGINE STATUS ---
+
+type EngineError struct {
+	Source  string `json:"source"` // export, render, preview, engine...
+	Message string `json:"message"`
+	Time    string `json:"time"`
+}
+
+type EngineStatus struct {
+	Engine           EngineHealth     `json:"engine"`
+	FFmpeg           bool             `json:"ffmpeg"`
+	FFprobe          bool             `json:"ffprobe"`
+	FFmpegVersion    string           `json:"ffmpegVersion"`
+	ActiveJobs       int              `json:"activeJobs"`
+	ActiveRenders    int              `json:"activeRenders"`
+	ActiveExports    int              `json:"activeExports"`
+	QueuePaused      bool             `json:"queuePaused"`
+	BackgroundQueued bool             `json:"backgroundQueued"`
+	CacheBytes       map[string]int64 `json:"cacheBytes"`
+	RecentErrors     []EngineError    `json:"recentErrors"`
+}
+
+const maxEngineErrors = 20
+
+var (
+	engineErrorsMu sync.Mutex
+	engineErrors   []EngineError
+
+	ffmpegVersionOnce sync.Once
+	ffmpegVersion     string
+)
+
+// recordEngineError keeps the last few failures so the UI can explain why
+// something silently didn't happen
+func recordEngineError(source string, message string) {
+	engineErrorsMu.Lock()
+	defer engineErrorsMu.Unlock()
+
+	engineErrors = append(engineErrors, EngineError{
+		Source:  source,
+		Message: message,
+		Time:    time.Now().Format(time.RFC3339),
+	})
+	if len(engineErrors) > maxEngineErrors {
+		engineErrors = engineErrors[len(engineErrors)-maxEngineErrors:]
+	}
+}
+
+func getFFmpegVersion() string {
+	ffmpegVersionOnce.Do(func() {
+		out, err := exec.Command("ffmpeg", "-version").Output()
+		if err != nil {
+			return
+		}
+		line, _, _ := strings.Cut(string(out), "\n")
+		ffmpegVersion = strings.TrimSpace(line)
+	})
+	return ffmpegVersion
+}
+
+// dirSize sums file sizes under dir (0 if missing)
+func dirSize(dir string) int64 {
+	var total int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// GetEngineStatus reports everything the frontend needs to show a meaningful
+// "engine offline" state instead of failing silently
+func (a *App) GetEngineStatus() EngineStatus {
+	_, ffmpegErr := exec.LookPath("ffmpeg")
+	_, ffprobeErr := exec.LookPath("ffprobe")
+
+	bgMu.Lock()
+	queued := bgPending != nil
+	bgMu.Unlock()
+
+	engineErrorsMu.Lock()
+	recent := append([]EngineError{}, engineErrors...)
+	engineErrorsMu.Unlock()
+
+	status := EngineStatus{
+		Engine:           a.GetEngineHealth(),
+		FFmpeg:           ffmpegErr == nil,
+		FFprobe:          ffprobeErr == nil,
+		ActiveJobs:       int(atomic.LoadInt32(&foregroundJobs)),
+		ActiveRenders:    int(atomic.LoadInt32(&activeRenders)),
+		ActiveExports:    int(atomic.LoadInt32(&activeExports)),
+		QueuePaused:      isQueuePaused(),
+		BackgroundQueued: queued,
+		CacheBytes: map[string]int64{
+			"segments": dirSize(filepath.Join(a.getAppDir(), "cache", "segments")),
+			"reversed": dirSize(filepath.Join(a.getAppDir(), "cache", "reversed")),
+			"stream":   dirSize(filepath.Join(os.TempDir(), "motion_studio_stream")),
+		},
+		RecentErrors: recent,
+	}
+	if status.FFmpeg {
+		status.FFmpegVersion = getFFmpegVersion()
+	}
+	return status
+}
+
+// healthzHandler is a cheap liveness probe: 200 when ffmpeg is usable, 503 otherwise
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Cache-Control", "no-cache")
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		http.Error(w, "ffmpeg not found", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok"))
+}
+
+// statusHandler serves GetEngineStatus as JSON
+func (a *App) statusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a.GetEngineStatus())
+}
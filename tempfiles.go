@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// --- TEMP FILE CLEANUP ---
+
+// Registers temp files so they are removed when their job ends.
+
+// tempPrefixes are the names the app uses for files directly in os.TempDir()
+var tempPrefixes = []string{
+	"trim_", "export_list_", "export_audio_list_", "temp_video_", "temp_audio_", "loudness_", "whisper_",
+}
+
+// gapMediaNames are shared, regenerated on demand by prepareGapMedia
+var gapMediaNames = []string{"black.png", "silence.wav"}
+
+// staleTempAge protects files of another running instance from the startup sweep
+const staleTempAge = time.Hour
+
+var (
+	tempMu    sync.Mutex
+	tempFiles = map[string]bool{}
+)
+
+type CleanupReport struct {
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+}
+
+func (r *CleanupReport) remove(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	size := info.Size()
+	if info.IsDir() {
+		size = dirSize(path)
+	}
+	if os.RemoveAll(path) == nil {
+		r.Files++
+		r.Bytes += size
+	}
+}
+
+// trackTemp registers a temp file for cleanup and returns its path
+func trackTemp(path string) string {
+	tempMu.Lock()
+	tempFiles[path] = true
+	tempMu.Unlock()
+	return path
+}
+
+// releaseTemp deletes temp files that are no longer needed
+func releaseTemp(paths ...string) {
+	tempMu.Lock()
+	defer tempMu.Unlock()
+	for _, p := range paths {
+		os.Remove(p)
+		delete(tempFiles, p)
+	}
+}
+
+// tempSet collects one job's temp files so they go away together
+type tempSet struct{ paths []string }
+
+func (t *tempSet) add(path string) string {
+	t.paths = append(t.paths, trackTemp(path))
+	return path
+}
+
+func (t *tempSet) release() { releaseTemp(t.paths...) }
+
+// purgeTrackedTemps removes every temp file still registered (shutdown)
+func purgeTrackedTemps() CleanupReport {
+	var report CleanupReport
+	tempMu.Lock()
+	defer tempMu.Unlock()
+	for p := range tempFiles {
+		report.remove(p)
+		delete(tempFiles, p)
+	}
+	return report
+}
+
+// sweepTempDir removes app temp files older than minAge from os.TempDir()
+func sweepTempDir(minAge time.Duration, includeShared bool) CleanupReport {
+	var report CleanupReport
+	dir := os.TempDir()
+	entries, _ := os.ReadDir(dir)
+	cutoff := time.Now().Add(-minAge)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		match := false
+		for _, prefix := range tempPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				match = true
+				break
+			}
+		}
+		if includeShared {
+			for _, shared := range gapMediaNames {
+				match = match || name == shared
+			}
+		}
+		if !match {
+			continue
+		}
+		if info, err := e.Info(); err == nil && info.ModTime().Before(cutoff) {
+			report.remove(filepath.Join(dir, name))
+		}
+	}
+	return report
+}
+
+// orphanedLastFrames finds *_lastframe.png files nothing in the project
+// refers to: shots, timeline clips, prompt history or any other project JSON
+func (a *App) orphanedLastFrames(minAge time.Duration) []string {
+	orphans := []string{}
+	cutoff := time.Now().Add(-minAge)
+	for _, project := range a.GetProjects() {
+		projectDir := filepath.Join(a.getAppDir(), project.ID)
+		used := lastFrameRefs(projectDir)
+		filepath.WalkDir(projectDir, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), "_lastframe.png") || used[d.Name()] {
+				return nil
+			}
+			if info, err := d.Info(); err == nil && info.ModTime().Before(cutoff) {
+				orphans = append(orphans, path)
+			}
+			return nil
+		})
+	}
+	return orphans
+}
+
+// lastFrameRefs collects the file names of last frames any JSON file under
+// projectDir mentions. Names, not paths: clips may hold engine URLs.
+func lastFrameRefs(projectDir string) map[string]bool {
+	used := map[string]bool{}
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch v := v.(type) {
+		case string:
+			if strings.HasSuffix(v, "_lastframe.png") {
+				used[v[strings.LastIndexAny(v, `/\`)+1:]] = true
+			}
+		case []interface{}:
+			for _, item := range v {
+				collect(item)
+			}
+		case map[string]interface{}:
+			for _, item := range v {
+				collect(item)
+			}
+		}
+	}
+	filepath.WalkDir(projectDir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		var doc interface{}
+		if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &doc) == nil {
+			collect(doc)
+		}
+		return nil
+	})
+	return used
+}
+
+// cleanupOnStartup clears what a previous session left behind
+func (a *App) cleanupOnStartup() {
+	report := sweepTempDir(staleTempAge, false)
+	if report.Files > 0 {
+		fmt.Printf("🧹 Removed %d stale temp files (%d bytes)\n", report.Files, report.Bytes)
+	}
+}
+
+// cleanupOnShutdown removes this session's temp files
+func (a *App) cleanupOnShutdown() {
+	purgeTrackedTemps()
+}
+
+// CleanCaches removes temp files, the preview segment and reversed proxy
+// caches and orphaned last-frame images. Refuses while exports or renders are running.
+func (a *App) CleanCaches() (CleanupReport, error) {
+	// Renders don't take a foreground slot but use trim_ and whisper_ temps
+	if atomic.LoadInt32(&foregroundJobs) > 0 || atomic.LoadInt32(&activeRenders) > 0 {
+		return CleanupReport{}, fmt.Errorf("wait for running exports and renders to finish")
+	}
+
+	report := purgeTrackedTemps()
+	add := func(r CleanupReport) {
+		report.Files += r.Files
+		report.Bytes += r.Bytes
+	}
+	add(sweepTempDir(0, true))
+
+	segments := a.getSegmentCacheDir()
+	entries, _ := os.ReadDir(segments)
+	for _, e := range entries {
+		report.remove(filepath.Join(segments, e.Name()))
+	}
+	reversed := a.getReverseCacheDir()
+	entries, _ = os.ReadDir(reversed)
+	for _, e := range entries {
+		report.remove(filepath.Join(reversed, e.Name()))
+	}
+
+	// Recently extracted frames may not be saved into a shot yet
+	for _, path := range a.orphanedLastFrames(10 * time.Minute) {
+		report.remove(path)
+	}
+	return report, nil
+}
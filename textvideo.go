@@ -0,0 +1,38 @@
+package main
+
+import "fmt"
+
+// --- TEXT-TO-VIDEO ---
+
+// Lets workflows without an image input render without a source image.
+
+// workflowNeedsImage reports whether a workflow takes the shot's source
+// image. Workflows that can't be read count as needing one, so the usual
+// error still comes first.
+func (a *App) workflowNeedsImage(workflowName string) bool {
+	workflow, err := a.loadWorkflow(workflowName)
+	if err != nil {
+		return true
+	}
+	for _, node := range workflow {
+		nodeMap, _ := node.(map[string]interface{})
+		classType, _ := nodeMap["class_type"].(string)
+		inputs, _ := nodeMap["inputs"].(map[string]interface{})
+		for input, role := range a.nodeRules(classType) {
+			value, exists := inputs[input]
+			if _, isLink := value.([]interface{}); exists && !isLink && role == "IMAGE" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkSourceImage fails for a shot without a source image when its
+// workflow needs one
+func (a *App) checkSourceImage(shot Shot, workflowName string) error {
+	if shot.SourceImage == "" && a.workflowNeedsImage(workflowName) {
+		return fmt.Errorf("source image is missing")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// --- SHOT THUMBNAILS ---
+
+// Middle-frame thumbnails of rendered shots.
+
+func (a *App) getThumbnailsDir(projectId string, sceneId string) string {
+	dir := filepath.Join(a.getAppDir(), projectId, "scenes", sceneId, "thumbnails")
+	os.MkdirAll(dir, 0755)
+	return dir
+}
+
+// generateShotThumbnail extracts the thumbnail for a shot's current output and
+// stores its path on the shot. The caller saves the shot.
+func (a *App) generateShotThumbnail(projectId string, sceneId string, shot *Shot) error {
+	if shot.OutputVideo == "" {
+		return fmt.Errorf("shot has no output")
+	}
+	thumbPath := filepath.Join(a.getThumbnailsDir(projectId, sceneId), shot.ID+".jpg")
+	if err := a.extractMiddleFrame(shot.OutputVideo, thumbPath); err != nil {
+		return err
+	}
+	shot.Thumbnail = thumbPath
+	return nil
+}
+
+// GenerateShotThumbnails backfills thumbnails for rendered shots that don't
+// have one (projects created before thumbnails existed). Returns how many
+// were created.
+func (a *App) GenerateShotThumbnails(projectId string, sceneId string) int {
+	shots := a.GetShots(projectId, sceneId)
+	created := 0
+	for i := range shots {
+		if shots[i].OutputVideo == "" {
+			continue
+		}
+		if shots[i].Thumbnail != "" {
+			if _, err := os.Stat(shots[i].Thumbnail); err == nil {
+				continue
+			}
+		}
+		if err := a.generateShotThumbnail(projectId, sceneId, &shots[i]); err != nil {
+			fmt.Println("Thumbnail:", err)
+			continue
+		}
+		created++
+	}
+	if created > 0 {
+		a.writeShots(projectId, sceneId, shots)
+	}
+	return created
+}
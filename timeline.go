@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/base64"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// --- TIMELINE ANALYSIS ---
+
+// TimelineItem is the typed view of a clip stored in timeline.json.
+// The frontend owns the clip shape, so unknown keys are simply ignored.
+type TimelineItem struct {
+	ID          string
+	StartTime   float64
+	Duration    float64
+	TrimStart   float64
+	OutputVideo string
+	AudioPath   string
+	SourceImage string
+	PairID      string
+	VideoFilter string // Raw ffmpeg filter chain (power users)
+	AudioFilter string
+	Reversed    bool   // Play the trimmed range backwards
+	LoopMode    string // "", "repeat" or "boomerang" (see expandLoop)
+	LoopCount   int
+}
+
+func parseTimelineItem(rawItem map[string]interface{}) TimelineItem {
+	item := TimelineItem{}
+	if v, ok := rawItem["id"].(string); ok {
+		item.ID = v
+	}
+	if v, ok := rawItem["startTime"].(float64); ok {
+		item.StartTime = v
+	}
+	if v, ok := rawItem["duration"].(float64); ok {
+		item.Duration = v
+	}
+	if v, ok := rawItem["trimStart"].(float64); ok {
+		item.TrimStart = v
+	}
+	if v, ok := rawItem["outputVideo"].(string); ok {
+		item.OutputVideo = v
+	}
+	if v, ok := rawItem["audioPath"].(string); ok {
+		item.AudioPath = v
+	}
+	if v, ok := rawItem["sourceImage"].(string); ok {
+		item.SourceImage = v
+	}
+	if v, ok := rawItem["pairId"].(string); ok {
+		item.PairID = v
+	}
+	if v, ok := rawItem["videoFilter"].(string); ok {
+		item.VideoFilter = v
+	}
+	if v, ok := rawItem["audioFilter"].(string); ok {
+		item.AudioFilter = v
+	}
+	if v, ok := rawItem["reversed"].(bool); ok {
+		item.Reversed = v
+	}
+	if v, ok := rawItem["loopMode"].(string); ok {
+		item.LoopMode = v
+	}
+	if v, ok := rawItem["loopCount"].(float64); ok {
+		item.LoopCount = int(v)
+	}
+	return item
+}
+
+// prepareGapMedia makes sure the black frame and silence used to fill
+// timeline gaps exist in the temp dir
+func (a *App) prepareGapMedia() (string, string) {
+	tempDir := os.TempDir()
+
+	// 0. Prepare Black Frame for Gaps
+	blackPath := filepath.Join(tempDir, "black.png")
+	if _, err := os.Stat(blackPath); os.IsNotExist(err) {
+		data, _ := base64.StdEncoding.DecodeString("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNk+A8AAQUBAScY42YAAAAASUVORK5CYII=")
+		os.WriteFile(blackPath, data, 0644)
+	}
+
+	// 0.5 Prepare Silence for Audio Gaps (1 hour buffer)
+	silencePath := filepath.Join(tempDir, "silence.wav")
+	if _, err := os.Stat(silencePath); os.IsNotExist(err) {
+		exec.Command("ffmpeg", "-y", "-f", "lavfi", "-i", "anullsrc=r=48000:cl=stereo", "-t", "3600", "-c:a", "pcm_s16le", silencePath).Run()
+	}
+
+	return blackPath, silencePath
+}
+
+// isAudioTrack reports whether the track at index holds audio-only clips
+func isAudioTrack(timeline TimelineData, idx int) bool {
+	if idx >= len(timeline.TrackSettings) {
+		return false
+	}
+	ts := timeline.TrackSettings[idx]
+	return ts.Type == "audio" || (ts.Type != TrackTypeAdjustment && strings.HasPrefix(ts.Name, "A"))
+}
+
+// analyzeVisualSegments flattens the video tracks into a gapless list of
+// segments, picking the top-most visible clip for every slice of time.
+// It also returns the PairIDs of clips that ended up visible, so paired audio
+// of covered clips can be muted.
+func analyzeVisualSegments(timeline TimelineData, blackPath string, silencePath string) ([]RenderSegment, map[string]bool) {
+	// Map to track which PairIDs are currently visible on screen
+	// If a video is covered up, its PairID won't be in this map.
+	visiblePairIDs := make(map[string]bool)
+
+	// 1. Collect all time points
+	timePoints := []float64{0.0}
+
+	var tracks [][]TimelineItem
+	for _, rawTrack := range timeline.Tracks {
+		var track []TimelineItem
+		for _, rawItem := range rawTrack {
+			// Looping clips become one clip per pass
+			for _, item := range expandLoop(parseTimelineItem(rawItem)) {
+				track = append(track, item)
+				timePoints = append(timePoints, item.StartTime)
+				timePoints = append(timePoints, item.StartTime+item.Duration)
+			}
+		}
+		tracks = append(tracks, track)
+	}
+
+	// 2. Sort and Unique
+	sort.Float64s(timePoints)
+	uniquePoints := []float64{}
+	if len(timePoints) > 0 {
+		uniquePoints = append(uniquePoints, timePoints[0])
+		for i := 1; i < len(timePoints); i++ {
+			if timePoints[i] > timePoints[i-1]+0.001 {
+				uniquePoints = append(uniquePoints, timePoints[i])
+			}
+		}
+	}
+
+	var segments []RenderSegment
+
+	// 3. Iterate Time Slices
+	for i := 0; i < len(uniquePoints)-1; i++ {
+		start := uniquePoints[i]
+		end := uniquePoints[i+1]
+		mid := (start + end) / 2
+		dur := end - start
+
+		var activeItem *TimelineItem
+		activeLayer := 0
+
+		// 4. Find Top-Most Visible Video
+		for tIdx, track := range tracks {
+			if tIdx < len(timeline.TrackSettings) {
+				if !timeline.TrackSettings[tIdx].Visible {
+					continue
+				}
+				if isAudioTrack(timeline, tIdx) || isAdjustmentTrack(timeline, tIdx) {
+					continue
+				}
+			}
+
+			foundClip := false
+			for _, item := range track {
+				if mid >= item.StartTime && mid < item.StartTime+item.Duration {
+					itemCopy := item
+					activeItem = &itemCopy
+					activeLayer = tIdx
+					foundClip = true
+					break
+				}
+			}
+			if foundClip {
+				break
+			}
+		}
+
+		if activeItem != nil {
+			// Register this clip as "Visible"
+			if activeItem.PairID != "" {
+				visiblePairIDs[activeItem.PairID] = true
+			}
+
+			offset := start - activeItem.StartTime + activeItem.TrimStart
+			source := activeItem.OutputVideo
+			if source == "" {
+				source = activeItem.SourceImage
+			}
+
+			isImage := strings.HasSuffix(source, ".png") || strings.HasSuffix(source, ".jpg")
+
+			// ECHO FIX: Force AudioSource to Silence.
+			// We will rely entirely on Pass 3 (Audio Tracks) to render the audio.
+			// This prevents the "Video File" and "Audio File" from playing at the same time.
+			seg := RenderSegment{
+				SourcePath:  source,
+				InPoint:     offset,
+				OutPoint:    offset + dur,
+				Duration:    dur,
+				IsImage:     isImage,
+				AudioSource: silencePath, // <--- Key Change
+				Filter:      joinFilters(activeItem.VideoFilter, adjustmentsAt(timeline, activeLayer, mid)),
+			}
+			// Reversed clips keep the source range here; resolveReversedSegments
+			// decides how to play it backwards
+			if activeItem.Reversed && !isImage {
+				seg.InPoint, seg.OutPoint = reversedSourceRange(*activeItem, start, dur)
+				seg.Reversed = true
+			}
+			segments = append(segments, seg)
+		} else {
+			segments = append(segments, RenderSegment{
+				SourcePath:  blackPath,
+				AudioSource: silencePath,
+				Duration:    dur,
+				IsImage:     true,
+				InPoint:     0,
+				OutPoint:    dur,
+			})
+		}
+	}
+
+	return segments, visiblePairIDs
+}
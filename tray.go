@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"os/exec"
+	goruntime "runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"fyne.io/systray"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- SYSTEM TRAY ---
+
+// System tray icon with render and export status.
+
+//go:embed build/appicon.png
+var appIconPNG []byte
+
+var (
+	// activeRenders and activeExports count user-visible jobs for the tray
+	activeRenders int32
+	activeExports int32
+
+	lastExportMu  sync.Mutex
+	lastExportDir string
+)
+
+// startTray shows the tray icon and returns the function that removes it.
+// On macOS the tray shares the wails NSApplication, so this must run on the
+// main thread before wails.Run; elsewhere it runs its own message loop.
+func startTray(a *App) func() {
+	if goruntime.GOOS == "darwin" {
+		start, end := systray.RunWithExternalLoop(a.onTrayReady, nil)
+		start()
+		return end
+	}
+	go func() {
+		// Windows delivers tray messages to the thread that created the icon
+		goruntime.LockOSThread()
+		systray.Run(a.onTrayReady, nil)
+	}()
+	return systray.Quit
+}
+
+func (a *App) onTrayReady() {
+	systray.SetIcon(trayIcon())
+	systray.SetTooltip("Motion Studio")
+
+	mOpen := systray.AddMenuItem("Open Motion Studio", "Show the main window")
+	mStatus := systray.AddMenuItem("Idle", "")
+	mStatus.Disable()
+	systray.AddSeparator()
+	mPause := systray.AddMenuItemCheckbox("Pause Queue", "Hold background rendering", isQueuePaused())
+	mOutput := systray.AddMenuItem("Open Output Folder", "Open the last export location")
+	systray.AddSeparator()
+	mQuit := systray.AddMenuItem("Quit", "Quit Motion Studio")
+
+	go a.runTrayStatus(mStatus)
+
+	for {
+		select {
+		case <-mOpen.ClickedCh:
+			if a.ctx != nil {
+				runtime.WindowUnminimise(a.ctx)
+				runtime.WindowShow(a.ctx)
+			}
+		case <-mPause.ClickedCh:
+			paused := !isQueuePaused()
+			a.SetQueuePaused(paused)
+			if paused {
+				mPause.Check()
+			} else {
+				mPause.Uncheck()
+			}
+		case <-mOutput.ClickedCh:
+			openFolder(a.getOutputDir())
+		case <-mQuit.ClickedCh:
+			if a.ctx != nil {
+				runtime.Quit(a.ctx)
+			}
+			return
+		}
+	}
+}
+
+// runTrayStatus refreshes the job counts every couple of seconds
+func (a *App) runTrayStatus(status *systray.MenuItem) {
+	last := ""
+	for range time.Tick(2 * time.Second) {
+		text := trayStatusText()
+		if text == last {
+			continue
+		}
+		last = text
+		status.SetTitle(text)
+		systray.SetTooltip("Motion Studio - " + text)
+		if goruntime.GOOS == "darwin" {
+			// Menu-bar title stays empty when idle to keep the bar tidy
+			if text == "Idle" {
+				systray.SetTitle("")
+			} else {
+				systray.SetTitle(text)
+			}
+		}
+	}
+}
+
+func trayStatusText() string {
+	renders := atomic.LoadInt32(&activeRenders)
+	exports := atomic.LoadInt32(&activeExports)
+	if renders == 0 && exports == 0 {
+		if isQueuePaused() {
+			return "Paused"
+		}
+		return "Idle"
+	}
+	text := fmt.Sprintf("%d rendering, %d exporting", renders, exports)
+	if isQueuePaused() {
+		text += " (paused)"
+	}
+	return text
+}
+
+// rememberExportDir records where the user last exported to
+func rememberExportDir(dir string) {
+	lastExportMu.Lock()
+	lastExportDir = dir
+	lastExportMu.Unlock()
+}
+
+// getOutputDir returns the last export location, or the app directory
+func (a *App) getOutputDir() string {
+	lastExportMu.Lock()
+	defer lastExportMu.Unlock()
+	if lastExportDir != "" {
+		return lastExportDir
+	}
+	return a.getAppDir()
+}
+
+// openFolder reveals dir in the platform file manager
+func openFolder(dir string) {
+	var cmd *exec.Cmd
+	switch goruntime.GOOS {
+	case "windows":
+		cmd = exec.Command("explorer", dir)
+	case "darwin":
+		cmd = exec.Command("open", dir)
+	default:
+		cmd = exec.Command("xdg-open", dir)
+	}
+	if err := cmd.Start(); err != nil {
+		fmt.Println("Open folder:", err)
+	}
+}
+
+// trayIcon scales the 1024px app icon down to tray size. Windows needs the
+// PNG wrapped in an .ico container.
+func trayIcon() []byte {
+	src, err := png.Decode(bytes.NewReader(appIconPNG))
+	if err != nil {
+		return appIconPNG
+	}
+
+	const size = 64
+	bounds := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dst.Set(x, y, src.At(bounds.Min.X+x*bounds.Dx()/size, bounds.Min.Y+y*bounds.Dy()/size))
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, dst)
+	if goruntime.GOOS != "windows" {
+		return buf.Bytes()
+	}
+
+	// ICONDIR + one ICONDIRENTRY pointing at the embedded PNG
+	var ico bytes.Buffer
+	binary.Write(&ico, binary.LittleEndian, []uint16{0, 1, 1})
+	ico.Write([]byte{size, size, 0, 0})
+	binary.Write(&ico, binary.LittleEndian, []uint16{1, 32})
+	binary.Write(&ico, binary.LittleEndian, []uint32{uint32(buf.Len()), 22})
+	ico.Write(buf.Bytes())
+	return ico.Bytes()
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- UPSCALE PASS ---
+
+// Upscales final renders through ComfyUI or ffmpeg.
+
+type UpscaleSettings struct {
+	Enabled  bool    `json:"enabled"`  // Upscale after every final render
+	Workflow string  `json:"workflow"` // ComfyUI workflow; "" = ffmpeg
+	Scale    float64 `json:"scale"`    // ffmpeg factor, 0 = 2
+}
+
+// GetUpscaleSettings returns the upscale pass settings
+func (a *App) GetUpscaleSettings() UpscaleSettings {
+	return a.getConfig().Upscale
+}
+
+// SaveUpscaleSettings stores the upscale pass settings
+func (a *App) SaveUpscaleSettings(s UpscaleSettings) string {
+	if s.Scale != 0 && (s.Scale < 1 || s.Scale > 4) {
+		return tr("upscale.error.scale")
+	}
+	if s.Workflow != "" {
+		if _, err := os.Stat(filepath.Join(a.getWorkflowsDir(), s.Workflow+".json")); err != nil {
+			return tr("upscale.error.workflow")
+		}
+	}
+	a.updateConfig(func(c *Config) { c.Upscale = s })
+	return "Success"
+}
+
+// upscaledPath is where the upscaled copy of a raw output goes
+func upscaledPath(raw string) string {
+	return strings.TrimSuffix(raw, filepath.Ext(raw)) + "_up.mp4"
+}
+
+// UpscaleShot runs the upscale pass on a shot's current output now
+func (a *App) UpscaleShot(projectId string, sceneId string, shotId string) (Shot, error) {
+	for _, s := range a.GetShots(projectId, sceneId) {
+		if s.ID == shotId {
+			if s.OutputVideo == "" {
+				return s, fmt.Errorf("shot has no output")
+			}
+			return a.upscaleShot(a.comfyURL, projectId, sceneId, s)
+		}
+	}
+	return Shot{}, fmt.Errorf("shot not found")
+}
+
+// autoUpscale runs the pass after a render when it is enabled. Failures are
+// reported but keep the raw render.
+func (a *App) autoUpscale(server string, projectId string, sceneId string, shot Shot) Shot {
+	if !a.getConfig().Upscale.Enabled || shot.Quality == QualityDraft || shot.OutputVideo == "" {
+		return shot
+	}
+	upscaled, err := a.upscaleShot(server, projectId, sceneId, shot)
+	if err != nil {
+		return shot
+	}
+	return upscaled
+}
+
+func (a *App) upscaleShot(server string, projectId string, sceneId string, shot Shot) (Shot, error) {
+	// Upscale the raw file again rather than the upscaled one
+	raw := shot.OutputVideo
+	if shot.RawVideo != "" && upscaledPath(shot.RawVideo) == shot.OutputVideo {
+		raw = shot.RawVideo
+	}
+	emit := func(status string, err error) {
+		event := map[string]interface{}{"shotId": shot.ID, "status": status}
+		if err != nil {
+			event["error"] = err.Error()
+		}
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "render:upscale", event)
+		}
+	}
+
+	emit("started", nil)
+	up := upscaledPath(raw)
+	var err error
+	if workflow := a.getConfig().Upscale.Workflow; workflow != "" {
+		err = a.runVideoWorkflow(server, shot.ID, workflow, raw, up, nil)
+	} else {
+		err = a.upscaleWithFFmpeg(raw, up)
+	}
+	if err == nil {
+		if check := verifyMedia(up, MediaExpectation{Video: true}); !check.OK {
+			err = fmt.Errorf("upscaled output is corrupt: %s", check.summary())
+		}
+	}
+	if err != nil {
+		os.Remove(up)
+		recordEngineError("upscale", err.Error())
+		emit("failed", err)
+		return shot, err
+	}
+
+	previous := shot.OutputVideo
+	a.updateShot(projectId, sceneId, shot.ID, func(s *Shot) {
+		if s.OutputVideo != previous {
+			return // Changed meanwhile; leave it
+		}
+		s.RawVideo = raw
+		s.OutputVideo = up
+		if err := a.generateShotThumbnail(projectId, sceneId, s); err != nil {
+			fmt.Println("Thumbnail:", err)
+		}
+		shot = *s
+	})
+	a.markVersionUpscaled(projectId, sceneId, shot.ID, raw, up)
+	a.repointTimelineMedia(projectId, sceneId, previous, up)
+	emit("done", nil)
+	return shot, nil
+}
+
+func (a *App) upscaleWithFFmpeg(raw string, up string) error {
+	scale := a.getConfig().Upscale.Scale
+	if scale <= 0 {
+		scale = 2
+	}
+	// Even dimensions for yuv420p
+	filter := fmt.Sprintf("scale=trunc(iw*%g/2)*2:trunc(ih*%g/2)*2:flags=lanczos,format=yuv420p", scale, scale)
+	cmd := exec.Command("ffmpeg", "-y", "-i", mediaPath(raw),
+		"-vf", filter,
+		"-c:v", "libx264", "-preset", "slow", "-crf", "16",
+		"-c:a", "copy", "-movflags", "+faststart",
+		up)
+	if out, err := combinedOutputTracked(cmd); err != nil {
+		return fmt.Errorf("%v: %s", err, string(out))
+	}
+	return nil
+}
+
+// runVideoWorkflow runs a post-processing workflow on a video: in goes into
+// its VIDEO inputs, values (by role) into the others, and its first output
+// is saved as out
+func (a *App) runVideoWorkflow(server string, shotId string, workflowName string, in string, out string, values map[string]interface{}) error {
+	workflow, err := a.loadWorkflow(workflowName)
+	if err != nil {
+		return err
+	}
+	uploaded, err := a.uploadAsset(server, in)
+	if err != nil {
+		return fmt.Errorf("video upload failed: %v", err)
+	}
+	injected := false
+	for _, node := range workflow {
+		nodeMap, _ := node.(map[string]interface{})
+		classType, _ := nodeMap["class_type"].(string)
+		inputs, _ := nodeMap["inputs"].(map[string]interface{})
+		for input, role := range a.nodeRules(classType) {
+			value, exists := inputs[input]
+			if _, isLink := value.([]interface{}); !exists || isLink {
+				continue
+			}
+			if role == "VIDEO" {
+				inputs[input] = uploaded
+				injected = true
+			} else if v, ok := values[role]; ok {
+				inputs[input] = v
+			}
+		}
+	}
+	if !injected {
+		return fmt.Errorf("workflow %q has no video loader (VIDEO input)", workflowName)
+	}
+
+	promptID, err := a.queuePrompt(server, workflow)
+	if err != nil {
+		return err
+	}
+	if err := a.waitForPrompt(server, shotId, promptID); err != nil {
+		return err
+	}
+	// Frame sequences keep the input's rate unless the workflow sets a new one
+	fps := a.getVideoFrameRate(in)
+	if rate, ok := values["FRAME_RATE"].(float64); ok {
+		fps = rate
+	}
+	return a.downloadPromptOutput(server, promptID, fps, out)
+}
+
+// downloadPromptOutput saves the video a finished prompt produced (see
+// comfyoutputs.go), timing frame sequences at fps
+func (a *App) downloadPromptOutput(server string, promptID string, fps float64, dest string) error {
+	var history map[string]struct {
+		Outputs map[string]interface{} `json:"outputs"`
+	}
+	if err := a.comfyJSONAt(server, "GET", "/history/"+promptID, nil, &history); err != nil {
+		return err
+	}
+	plan, err := planShotOutput(comfyOutputs(history[promptID].Outputs))
+	if err != nil {
+		return err
+	}
+	return a.fetchShotOutput(server, plan, dest, fps)
+}
+
+// markVersionUpscaled records the upscaled copy on the version of a raw output
+func (a *App) markVersionUpscaled(projectId string, sceneId string, shotId string, raw string, up string) {
+	versionsMu.Lock()
+	defer versionsMu.Unlock()
+
+	versions := a.loadVersions(projectId, sceneId)
+	for i, v := range versions[shotId] {
+		if v.Output == raw {
+			versions[shotId][i].Upscaled = up
+			a.saveVersions(projectId, sceneId, versions)
+			return
+		}
+	}
+}
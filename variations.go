@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- SEED VARIATIONS ---
+
+// Renders a shot with several seeds as one job.
+
+const maxVariations = 16
+
+// renderTake is one render of a variation set
+type renderTake struct {
+	Set  string
+	Seed int64
+}
+
+type VariationSet struct {
+	ID     string  `json:"id"` // Also the render job ID
+	ShotID string  `json:"shotId"`
+	Seeds  []int64 `json:"seeds"`
+}
+
+// RenderVariations queues count renders of a shot with fresh random seeds
+func (a *App) RenderVariations(projectId string, sceneId string, shotId string, workflowName string, count int) (VariationSet, error) {
+	if count < 1 || count > maxVariations {
+		return VariationSet{}, fmt.Errorf("count must be between 1 and %d", maxVariations)
+	}
+	var shot *Shot
+	shots := a.GetShots(projectId, sceneId)
+	for i := range shots {
+		if shots[i].ID == shotId {
+			shot = &shots[i]
+			break
+		}
+	}
+	if shot == nil {
+		return VariationSet{}, fmt.Errorf("shot not found")
+	}
+	if err := a.checkSourceImage(*shot, shotWorkflow(*shot, workflowName)); err != nil {
+		return VariationSet{}, err
+	}
+	if err := a.checkWorkflowBeforeQueue(shotWorkflow(*shot, workflowName)); err != nil {
+		return VariationSet{}, err
+	}
+
+	seen := map[int64]bool{}
+	seeds := make([]int64, 0, count)
+	for len(seeds) < count {
+		if seed := rand.Int63n(maxRandomSeed); !seen[seed] {
+			seen[seed] = true
+			seeds = append(seeds, seed)
+		}
+	}
+
+	renderQueueMu.Lock()
+	for _, job := range renderJobs {
+		if job.ShotID == shotId && (job.Status == JobQueued || job.Status == JobRunning) {
+			renderQueueMu.Unlock()
+			return VariationSet{}, fmt.Errorf("shot is already queued or rendering")
+		}
+	}
+	job := &RenderJob{
+		ID:        uuid.New().String(),
+		ProjectID: projectId,
+		SceneID:   sceneId,
+		ShotID:    shotId,
+		ShotName:  shot.Name,
+		Workflow:  workflowName,
+		Seeds:     seeds,
+		Status:    JobQueued,
+		Queued:    time.Now().Format(time.RFC3339),
+	}
+	renderJobs = append(renderJobs, job)
+	renderQueueMu.Unlock()
+
+	a.emitJob("queued", job)
+	wakeRenderQueue()
+	return VariationSet{ID: job.ID, ShotID: shotId, Seeds: seeds}, nil
+}
+
+// renderVariations runs the takes of a variation job. It fails only when no
+// take could be rendered or the job was canceled.
+func (a *App) renderVariations(job *RenderJob) error {
+	atomic.AddInt32(&activeRenders, 1)
+	defer atomic.AddInt32(&activeRenders, -1)
+
+	rendered := 0
+	var lastErr error
+	for i, seed := range job.Seeds {
+		if shotRenderCanceled(job.ShotID) {
+			return fmt.Errorf("render canceled")
+		}
+		take := &renderTake{Set: job.ID, Seed: seed}
+		attempt := 0
+		shot, err := a.renderShot(job.Server, job.ProjectID, job.SceneID, job.ShotID, job.Workflow, attempt, seed, take, job.Profile)
+		for errors.Is(err, errPromptLost) && attempt < a.renderRetries() {
+			attempt++
+			a.emitRenderRetry(job.ShotID, attempt)
+			shot, err = a.renderShot(job.Server, job.ProjectID, job.SceneID, job.ShotID, job.Workflow, attempt, seed, take, job.Profile)
+		}
+
+		event := map[string]interface{}{
+			"set":    job.ID,
+			"shotId": job.ShotID,
+			"index":  i,
+			"total":  len(job.Seeds),
+			"seed":   seed,
+		}
+		if err != nil {
+			recordEngineError("render", err.Error())
+			lastErr = err
+			event["error"] = err.Error()
+		} else {
+			rendered++
+			a.touchWorkflow(shot.RenderWorkflow)
+			event["seed"] = shot.Seed // As executed
+			event["output"] = shot.OutputVideo
+		}
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "render:variation", event)
+		}
+	}
+	if rendered == 0 && lastErr != nil {
+		return lastErr
+	}
+	return nil
+}
+
+// GetVariations returns the finished takes of a variation set, newest first
+func (a *App) GetVariations(projectId string, sceneId string, shotId string, setId string) []ShotVersion {
+	takes := []ShotVersion{}
+	for _, v := range a.GetShotVersions(projectId, sceneId, shotId) {
+		if v.Variation == setId {
+			takes = append(takes, v)
+		}
+	}
+	return takes
+}
+
+// PromoteVariation makes a take the shot's output (and its seed the shot's)
+func (a *App) PromoteVariation(projectId string, sceneId string, shotId string, versionId string) (Shot, error) {
+	shot, err := a.SetActiveVersion(projectId, sceneId, shotId, versionId)
+	if err == nil {
+		a.recordShotTake(projectId, sceneId, shot)
+	}
+	return shot, err
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// --- SHOT VERSIONS ---
+
+// Every render is kept as a numbered version of its shot.
+
+type ShotVersion struct {
+	ID          string            `json:"id"`
+	Number      int               `json:"number"`
+	PromptID    string            `json:"promptId"`
+	Seed        int64             `json:"seed"`
+	Prompt      string            `json:"prompt"`
+	Workflow    string            `json:"workflow"`
+	Time        string            `json:"time"`
+	Output      string            `json:"output"`
+	Duration    float64           `json:"duration"`
+	Fingerprint map[string]string `json:"fingerprint,omitempty"`
+	Variation   string            `json:"variation,omitempty"` // Set of RenderVariations it came from
+	Quality     string            `json:"quality,omitempty"`   // draft or final, see renderprofile.go
+	Upscaled    string            `json:"upscaled,omitempty"`  // Upscaled copy of Output, see upscale.go
+	Active      bool              `json:"active"`              // Derived: is the shot's current output
+}
+
+var versionsMu sync.Mutex
+
+func (a *App) getVersionsPath(projectId string, sceneId string) string {
+	return filepath.Join(a.getAppDir(), projectId, "scenes", sceneId, "versions.json")
+}
+
+func (a *App) loadVersions(projectId string, sceneId string) map[string][]ShotVersion {
+	versions := map[string][]ShotVersion{}
+	if data, err := os.ReadFile(a.getVersionsPath(projectId, sceneId)); err == nil {
+		json.Unmarshal(data, &versions)
+	}
+	return versions
+}
+
+func (a *App) saveVersions(projectId string, sceneId string, versions map[string][]ShotVersion) {
+	data, _ := json.MarshalIndent(versions, "", "  ")
+	os.WriteFile(a.getVersionsPath(projectId, sceneId), data, 0644)
+}
+
+// nextVersionPath returns where the next render of a shot should be written.
+// The file is created empty to reserve the number for this render; callers
+// remove it when the render fails.
+func (a *App) nextVersionPath(projectId string, sceneId string, shotId string) string {
+	versionsMu.Lock()
+	defer versionsMu.Unlock()
+
+	n := 1
+	for _, v := range a.loadVersions(projectId, sceneId)[shotId] {
+		n = max(n, v.Number+1)
+	}
+	dir := filepath.Join(a.getAppDir(), projectId, "scenes", sceneId)
+	// Skip numbers whose file exists without a record (interrupted renders,
+	// or a concurrent render that reserved it)
+	for {
+		path := filepath.Join(dir, fmt.Sprintf("%s_v%03d.mp4", shotId, n))
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			f.Close()
+			return path
+		}
+		if !os.IsExist(err) {
+			return path
+		}
+		n++
+	}
+}
+
+// versionNumber reads NNN from <shotId>_vNNN.mp4, 0 for other names
+func versionNumber(shotId string, path string) int {
+	rest, ok := strings.CutPrefix(filepath.Base(path), shotId+"_v")
+	if !ok {
+		return 0
+	}
+	end := 0
+	for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(rest[:end])
+	return n
+}
+
+// addShotVersion records a finished render of shot (whose OutputVideo is the
+// new take); variation is the variation set it belongs to, if any
+func (a *App) addShotVersion(projectId string, sceneId string, shot Shot, promptID string, workflow string, variation string) {
+	versionsMu.Lock()
+	defer versionsMu.Unlock()
+
+	versions := a.loadVersions(projectId, sceneId)
+	// The number nextVersionPath reserved; concurrent renders may finish out of order
+	n := versionNumber(shot.ID, shot.OutputVideo)
+	if n == 0 {
+		n = 1
+		for _, v := range versions[shot.ID] {
+			n = max(n, v.Number+1)
+		}
+	}
+	versions[shot.ID] = append(versions[shot.ID], ShotVersion{
+		ID:          uuid.New().String(),
+		Number:      n,
+		PromptID:    promptID,
+		Seed:        shot.Seed,
+		Prompt:      shot.Prompt,
+		Workflow:    workflow,
+		Time:        time.Now().Format(time.RFC3339),
+		Output:      shot.OutputVideo,
+		Duration:    shot.Duration,
+		Fingerprint: shot.RenderFingerprint,
+		Variation:   variation,
+		Quality:     shot.Quality,
+	})
+	a.saveVersions(projectId, sceneId, versions)
+}
+
+// GetShotVersions lists a shot's renders, newest first
+func (a *App) GetShotVersions(projectId string, sceneId string, shotId string) []ShotVersion {
+	current := ""
+	for _, s := range a.GetShots(projectId, sceneId) {
+		if s.ID == shotId {
+			current = s.OutputVideo
+		}
+	}
+
+	versionsMu.Lock()
+	list := a.loadVersions(projectId, sceneId)[shotId]
+	versionsMu.Unlock()
+
+	result := make([]ShotVersion, 0, len(list))
+	for i := len(list) - 1; i >= 0; i-- {
+		v := list[i]
+		v.Active = v.Output == current || (v.Upscaled != "" && v.Upscaled == current)
+		result = append(result, v)
+	}
+	return result
+}
+
+// SetActiveVersion makes an earlier render the shot's output again
+func (a *App) SetActiveVersion(projectId string, sceneId string, shotId string, versionId string) (Shot, error) {
+	var version *ShotVersion
+	for _, v := range a.GetShotVersions(projectId, sceneId, shotId) {
+		if v.ID == versionId {
+			v := v
+			version = &v
+			break
+		}
+	}
+	if version == nil {
+		return Shot{}, fmt.Errorf("version not found")
+	}
+	if _, err := os.Stat(version.Output); err != nil {
+		return Shot{}, fmt.Errorf("version %d is no longer on disk", version.Number)
+	}
+
+	// Prefer the upscaled copy while it exists
+	output, raw := version.Output, ""
+	if version.Upscaled != "" {
+		if _, err := os.Stat(version.Upscaled); err == nil {
+			output, raw = version.Upscaled, version.Output
+		}
+	}
+
+	var updated *Shot
+	oldOutput := ""
+	a.updateShot(projectId, sceneId, shotId, func(s *Shot) {
+		oldOutput = s.OutputVideo
+		s.OutputVideo = output
+		s.RawVideo = raw
+		s.Duration = version.Duration
+		s.Seed = version.Seed // Part of the fingerprint; keeps the take reproducible
+		s.Quality = version.Quality
+		s.Status = "DONE"
+		s.RenderWorkflow = version.Workflow
+		s.RenderFingerprint = version.Fingerprint
+		s.Stale = false
+		if err := a.generateShotThumbnail(projectId, sceneId, s); err != nil {
+			fmt.Println("Thumbnail:", err)
+		}
+		copied := *s
+		updated = &copied
+	})
+	if updated == nil {
+		return Shot{}, fmt.Errorf("shot not found")
+	}
+	a.repointTimelineMedia(projectId, sceneId, oldOutput, output)
+	return *updated, nil
+}
+
+// deleteShotVersions removes every recorded render of a shot from disk
+func (a *App) deleteShotVersions(projectId string, sceneId string, shotId string) {
+	versionsMu.Lock()
+	defer versionsMu.Unlock()
+
+	versions := a.loadVersions(projectId, sceneId)
+	for _, v := range versions[shotId] {
+		os.Remove(v.Output)
+		if v.Upscaled != "" {
+			os.Remove(v.Upscaled)
+		}
+	}
+	delete(versions, shotId)
+	a.saveVersions(projectId, sceneId, versions)
+}
+
+// repointTimelineMedia swaps a clip source in the scene's timeline, so clips
+// follow a shot to its new output
+func (a *App) repointTimelineMedia(projectId string, sceneId string, oldPath string, newPath string) {
+	if oldPath == "" || oldPath == newPath {
+		return
+	}
+	timeline := a.GetTimeline(projectId, sceneId)
+	changed := false
+	for _, track := range timeline.Tracks {
+		for _, raw := range track {
+			if v, _ := raw["outputVideo"].(string); v == oldPath {
+				raw["outputVideo"] = newPath
+				changed = true
+			}
+		}
+	}
+	if changed {
+		a.SaveTimeline(projectId, sceneId, timeline)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestVersionNumber(t *testing.T) {
+	tests := []struct {
+		shot, path string
+		want       int
+	}{
+		{"s1", "/p/scenes/a/s1_v001.mp4", 1},
+		{"s1", "/p/scenes/a/s1_v012.mp4", 12},
+		{"s1", "/p/scenes/a/s1_v1000.mp4", 1000},
+		{"s1", "/p/scenes/a/s1_v003_up.mp4", 3},
+		{"s1", "/p/scenes/a/s1.mp4", 0},
+		{"s1", "/p/scenes/a/s10_v002.mp4", 0},
+		{"s1", "", 0},
+	}
+	for _, tt := range tests {
+		if got := versionNumber(tt.shot, tt.path); got != tt.want {
+			t.Errorf("versionNumber(%q, %q) = %d, want %d", tt.shot, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- VOICEOVER RECORDING ---
+
+// Records the microphone into the project's assets.
+
+type VoiceRecording struct {
+	ProjectID string    `json:"projectId"`
+	Path      string    `json:"path"`
+	StartedAt string    `json:"startedAt"`
+	Duration  float64   `json:"duration"` // Seconds, set once stopped
+	Peaks     []float64 `json:"peaks"`    // At voicePeaksPerSec, set once stopped
+}
+
+const (
+	voicePeaksPerSec = 20
+	// voiceStartCheck is how long a new recording must survive to count as started
+	voiceStartCheck = 700 * time.Millisecond
+	voiceStopWait   = 5 * time.Second
+)
+
+type voiceSession struct {
+	info   VoiceRecording
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	done   chan error
+	stderr *safeBuffer
+}
+
+var (
+	voiceMu      sync.Mutex
+	voiceCurrent *voiceSession
+)
+
+// StartVoiceRecording starts recording the microphone into the project's assets
+func (a *App) StartVoiceRecording(projectId string) (VoiceRecording, error) {
+	voiceMu.Lock()
+	defer voiceMu.Unlock()
+	if voiceCurrent != nil {
+		return VoiceRecording{}, fmt.Errorf("a recording is already running")
+	}
+	if _, err := a.GetProject(projectId); err != nil {
+		return VoiceRecording{}, fmt.Errorf("project not found")
+	}
+	assetsDir := filepath.Join(a.getAppDir(), projectId, "assets")
+	os.MkdirAll(assetsDir, 0755)
+	path := filepath.Join(assetsDir, fmt.Sprintf("voiceover_%d.wav", time.Now().UnixNano()))
+
+	args := append([]string{"-hide_banner", "-v", "error", "-y"}, audioCaptureInput()...)
+	args = append(args, "-ac", "1", "-ar", "48000", "-c:a", "pcm_s16le", path)
+	cmd := exec.Command("ffmpeg", args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return VoiceRecording{}, err
+	}
+	stderr := &safeBuffer{}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return VoiceRecording{}, fmt.Errorf("could not start recording: %v", err)
+	}
+	session := &voiceSession{
+		info:   VoiceRecording{ProjectID: projectId, Path: path, StartedAt: time.Now().Format(time.RFC3339), Peaks: []float64{}},
+		cmd:    cmd,
+		stdin:  stdin,
+		done:   make(chan error, 1),
+		stderr: stderr,
+	}
+	go func() { session.done <- cmd.Wait() }()
+
+	// A missing or busy device makes ffmpeg quit right away
+	select {
+	case err := <-session.done:
+		os.Remove(path)
+		message := strings.TrimSpace(string(stderr.Bytes()))
+		if message == "" && err != nil {
+			message = err.Error()
+		}
+		recordEngineError("voiceover", message)
+		return VoiceRecording{}, fmt.Errorf("could not open the microphone: %s", message)
+	case <-time.After(voiceStartCheck):
+	}
+
+	voiceCurrent = session
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "voiceover:status", map[string]interface{}{"recording": true, "path": path})
+	}
+	return session.info, nil
+}
+
+// StopVoiceRecording finishes the running recording and returns its file
+func (a *App) StopVoiceRecording() (VoiceRecording, error) {
+	voiceMu.Lock()
+	session := voiceCurrent
+	voiceCurrent = nil
+	voiceMu.Unlock()
+	if session == nil {
+		return VoiceRecording{}, fmt.Errorf("no recording is running")
+	}
+
+	session.finish()
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "voiceover:status", map[string]interface{}{"recording": false, "path": session.info.Path})
+	}
+	info := session.info
+	if stat, err := os.Stat(info.Path); err != nil || stat.Size() == 0 {
+		os.Remove(info.Path)
+		return VoiceRecording{}, fmt.Errorf("nothing was recorded: %s", strings.TrimSpace(string(session.stderr.Bytes())))
+	}
+	info.Duration = a.getVideoDuration(info.Path)
+	if peaks, err := a.ExtractAudioPeaks(info.Path, voicePeaksPerSec); err == nil && peaks != nil {
+		info.Peaks = peaks
+	}
+	return info, nil
+}
+
+// CancelVoiceRecording stops the running recording and deletes its file
+func (a *App) CancelVoiceRecording() string {
+	voiceMu.Lock()
+	session := voiceCurrent
+	voiceCurrent = nil
+	voiceMu.Unlock()
+	if session == nil {
+		return "Error: no recording is running"
+	}
+	session.cmd.Process.Kill()
+	<-session.done
+	os.Remove(session.info.Path)
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "voiceover:status", map[string]interface{}{"recording": false})
+	}
+	return "Success"
+}
+
+// GetVoiceRecording returns the running recording, if any
+func (a *App) GetVoiceRecording() (VoiceRecording, error) {
+	voiceMu.Lock()
+	defer voiceMu.Unlock()
+	if voiceCurrent == nil {
+		return VoiceRecording{}, fmt.Errorf("no recording is running")
+	}
+	return voiceCurrent.info, nil
+}
+
+// finish asks ffmpeg to stop cleanly, killing it if it doesn't
+func (s *voiceSession) finish() {
+	io.WriteString(s.stdin, "q")
+	s.stdin.Close()
+	select {
+	case <-s.done:
+	case <-time.After(voiceStopWait):
+		s.cmd.Process.Kill()
+		<-s.done
+	}
+}
+
+// stopVoiceRecording keeps what was recorded when the app quits mid-take
+func stopVoiceRecording() {
+	voiceMu.Lock()
+	session := voiceCurrent
+	voiceCurrent = nil
+	voiceMu.Unlock()
+	if session != nil {
+		session.finish()
+	}
+}
@@ -0,0 +1,446 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- DIALOG TRANSCRIPTION (WHISPER) ---
+
+// Transcribes shot audio into word and phrase timings.
+
+type WhisperSettings struct {
+	Backend  string `json:"backend"`  // local (whisper.cpp, default) or api
+	Binary   string `json:"binary"`   // whisper.cpp executable; "" = whisper-cli from PATH
+	Model    string `json:"model"`    // ggml model file (local) or model name (api, "" = whisper-1)
+	Endpoint string `json:"endpoint"` // OpenAI-compatible base URL; "" = OpenAI
+	Language string `json:"language"` // ISO code; "" = detect
+}
+
+type TranscriptWord struct {
+	Text  string  `json:"text"`
+	Start float64 `json:"start"` // Seconds from the shot's start
+	End   float64 `json:"end"`
+}
+
+type TranscriptPhrase struct {
+	Text       string  `json:"text"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	StartFrame int     `json:"startFrame"` // At the shot's render rate
+	Frames     int     `json:"frames"`
+}
+
+type ShotTranscript struct {
+	ShotID    string             `json:"shotId"`
+	Text      string             `json:"text"`
+	Language  string             `json:"language,omitempty"`
+	FPS       float64            `json:"fps"`
+	Words     []TranscriptWord   `json:"words"`
+	Phrases   []TranscriptPhrase `json:"phrases"`
+	CreatedAt string             `json:"createdAt"`
+	// The audio it was made from; Stale once the shot's audio changes
+	AudioPath     string  `json:"audioPath"`
+	AudioStart    float64 `json:"audioStart"`
+	AudioDuration float64 `json:"audioDuration"`
+	Stale         bool    `json:"stale"`
+}
+
+const (
+	whisperLocal = "local"
+	whisperAPI   = "api"
+
+	defaultWhisperModel = "whisper-1"
+	// phrasePause splits phrases at silences at least this long
+	phrasePause = 0.4
+)
+
+// whisperBinaries are the names whisper.cpp's CLI goes by
+var whisperBinaries = []string{"whisper-cli", "whisper-cpp", "whisper.cpp", "main"}
+
+var transcriptsMu sync.Mutex
+
+// GetWhisperSettings returns the transcription backend settings
+func (a *App) GetWhisperSettings() WhisperSettings {
+	return a.getConfig().Whisper
+}
+
+// SaveWhisperSettings persists the transcription backend settings
+func (a *App) SaveWhisperSettings(settings WhisperSettings) string {
+	settings.Backend = strings.ToLower(strings.TrimSpace(settings.Backend))
+	switch settings.Backend {
+	case "":
+		settings.Backend = whisperLocal
+	case whisperLocal, whisperAPI:
+	default:
+		return "Error: backend must be local or api"
+	}
+	settings.Binary = strings.TrimSpace(settings.Binary)
+	settings.Model = strings.TrimSpace(settings.Model)
+	settings.Endpoint = strings.TrimRight(strings.TrimSpace(settings.Endpoint), "/")
+	settings.Language = strings.ToLower(strings.TrimSpace(settings.Language))
+	if settings.Backend == whisperLocal && settings.Model != "" {
+		if _, err := os.Stat(settings.Model); err != nil {
+			return "Error: model file not found"
+		}
+	}
+	a.updateConfig(func(c *Config) { c.Whisper = settings })
+	return "Success"
+}
+
+func (a *App) getTranscriptsPath(projectId string, sceneId string) string {
+	return filepath.Join(a.getAppDir(), projectId, "scenes", sceneId, "transcripts.json")
+}
+
+func (a *App) loadTranscripts(projectId string, sceneId string) map[string]ShotTranscript {
+	transcripts := map[string]ShotTranscript{}
+	if data, err := os.ReadFile(a.getTranscriptsPath(projectId, sceneId)); err == nil {
+		json.Unmarshal(data, &transcripts)
+	}
+	return transcripts
+}
+
+// GetShotTranscript returns a shot's stored transcript
+func (a *App) GetShotTranscript(projectId string, sceneId string, shotId string) (ShotTranscript, error) {
+	transcriptsMu.Lock()
+	t, ok := a.loadTranscripts(projectId, sceneId)[shotId]
+	transcriptsMu.Unlock()
+	if !ok {
+		return ShotTranscript{}, fmt.Errorf("shot has no transcript")
+	}
+	for _, s := range a.GetShots(projectId, sceneId) {
+		if s.ID == shotId {
+			t.Stale = s.AudioPath != t.AudioPath || s.AudioStart != t.AudioStart || s.AudioDuration != t.AudioDuration
+		}
+	}
+	return t, nil
+}
+
+// TranscribeShot transcribes a shot's audio and stores the result
+func (a *App) TranscribeShot(projectId string, sceneId string, shotId string) (ShotTranscript, error) {
+	var shot Shot
+	found := false
+	for _, s := range a.GetShots(projectId, sceneId) {
+		if s.ID == shotId {
+			shot, found = s, true
+			break
+		}
+	}
+	if !found {
+		return ShotTranscript{}, fmt.Errorf("shot not found")
+	}
+	if shot.AudioPath == "" {
+		return ShotTranscript{}, fmt.Errorf("shot has no audio")
+	}
+
+	// Keeps CleanCaches off the temp files below
+	a.beginForeground()
+	defer a.endForeground()
+
+	// whisper.cpp only reads 16 kHz WAV; the API gets the same small file
+	wav := trackTemp(filepath.Join(os.TempDir(), fmt.Sprintf("whisper_%s_%d.wav", shot.ID, time.Now().UnixNano())))
+	defer releaseTemp(wav)
+	args := []string{"-y", "-i", mediaPath(shot.AudioPath)}
+	if shot.AudioDuration > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%f", shot.AudioStart), "-t", fmt.Sprintf("%f", shot.AudioDuration))
+	}
+	args = append(args, "-vn", "-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", wav)
+	if out, err := combinedOutputTracked(exec.Command("ffmpeg", args...)); err != nil {
+		return ShotTranscript{}, fmt.Errorf("could not read the shot's audio: %v: %s", err, string(out))
+	}
+
+	settings := a.getConfig().Whisper
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "transcribe:status", map[string]string{"shotId": shotId, "status": "running"})
+	}
+	var words []TranscriptWord
+	var language string
+	var err error
+	if settings.Backend == whisperAPI {
+		words, language, err = a.transcribeWithAPI(settings, wav)
+	} else {
+		words, err = transcribeLocal(settings, wav)
+		language = settings.Language
+	}
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "transcribe:status", map[string]string{"shotId": shotId, "status": "done"})
+	}
+	if err != nil {
+		recordEngineError("whisper", err.Error())
+		return ShotTranscript{}, err
+	}
+
+	fps := a.renderFPS(shot, shot.Workflow)
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.Text
+	}
+	transcript := ShotTranscript{
+		ShotID:        shotId,
+		Text:          strings.Join(texts, " "),
+		Language:      language,
+		FPS:           fps,
+		Words:         words,
+		Phrases:       groupPhrases(words, fps),
+		CreatedAt:     time.Now().Format(time.RFC3339),
+		AudioPath:     shot.AudioPath,
+		AudioStart:    shot.AudioStart,
+		AudioDuration: shot.AudioDuration,
+	}
+
+	transcriptsMu.Lock()
+	transcripts := a.loadTranscripts(projectId, sceneId)
+	transcripts[shotId] = transcript
+	data, _ := json.MarshalIndent(transcripts, "", "  ")
+	err = os.WriteFile(a.getTranscriptsPath(projectId, sceneId), data, 0644)
+	transcriptsMu.Unlock()
+	if err != nil {
+		return transcript, fmt.Errorf("failed to save the transcript: %v", err)
+	}
+	a.recordHistory(projectId, "Transcribe shots in scene "+a.sceneLabel(projectId, sceneId))
+	return transcript, nil
+}
+
+// transcribeLocal runs whisper.cpp with one word per segment
+func transcribeLocal(settings WhisperSettings, wav string) ([]TranscriptWord, error) {
+	if settings.Model == "" {
+		return nil, fmt.Errorf("no whisper.cpp model is set")
+	}
+	binary := settings.Binary
+	if binary == "" {
+		for _, name := range whisperBinaries {
+			if path, err := exec.LookPath(name); err == nil {
+				binary = path
+				break
+			}
+		}
+		if binary == "" {
+			return nil, fmt.Errorf("whisper.cpp was not found; set its path in the settings")
+		}
+	}
+	language := settings.Language
+	if language == "" {
+		language = "auto"
+	}
+	base := strings.TrimSuffix(wav, filepath.Ext(wav))
+	defer releaseTemp(trackTemp(base + ".json"))
+
+	cmd := exec.Command(binary, "-m", settings.Model, "-f", wav, "-l", language,
+		"-ml", "1", "-sow", "-oj", "-of", base, "-np")
+	if out, err := combinedOutputTracked(cmd); err != nil {
+		return nil, fmt.Errorf("whisper.cpp failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	data, err := os.ReadFile(base + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("whisper.cpp wrote no transcript")
+	}
+	var result struct {
+		Transcription []struct {
+			Offsets struct {
+				From int `json:"from"` // Milliseconds
+				To   int `json:"to"`
+			} `json:"offsets"`
+			Text string `json:"text"`
+		} `json:"transcription"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("unexpected whisper.cpp output: %v", err)
+	}
+	words := []TranscriptWord{}
+	for _, s := range result.Transcription {
+		text := strings.TrimSpace(s.Text)
+		if text == "" {
+			continue
+		}
+		// Punctuation can come as its own segment; it belongs to the word before
+		if len(words) > 0 && strings.Trim(text, ".,!?;:…") == "" {
+			words[len(words)-1].Text += text
+			continue
+		}
+		words = append(words, TranscriptWord{Text: text, Start: float64(s.Offsets.From) / 1000, End: float64(s.Offsets.To) / 1000})
+	}
+	return words, nil
+}
+
+// transcribeWithAPI posts the audio to an OpenAI-compatible endpoint
+func (a *App) transcribeWithAPI(settings WhisperSettings, wav string) ([]TranscriptWord, string, error) {
+	endpoint := settings.Endpoint
+	if endpoint == "" {
+		endpoint = defaultOpenAIURL
+	}
+	model := settings.Model
+	if model == "" {
+		model = defaultWhisperModel
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", filepath.Base(wav))
+	file, err := os.Open(wav)
+	if err != nil {
+		return nil, "", err
+	}
+	io.Copy(part, file)
+	file.Close()
+	writer.WriteField("model", model)
+	writer.WriteField("response_format", "verbose_json")
+	writer.WriteField("timestamp_granularities[]", "word")
+	writer.WriteField("timestamp_granularities[]", "segment")
+	if settings.Language != "" {
+		writer.WriteField("language", settings.Language)
+	}
+	writer.Close()
+
+	req, err := http.NewRequest("POST", endpoint+"/audio/transcriptions", body)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if key := a.getCredential(CredWhisperKey); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := (&http.Client{Timeout: 10 * time.Minute}).Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("transcription service unreachable: %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("transcription failed (Status %d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var result struct {
+		Language string `json:"language"`
+		Words    []struct {
+			Word  string  `json:"word"`
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+		} `json:"words"`
+		Segments []struct {
+			Text  string  `json:"text"`
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+		} `json:"segments"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, "", fmt.Errorf("unexpected transcription reply: %v", err)
+	}
+	words := []TranscriptWord{}
+	for _, w := range result.Words {
+		if text := strings.TrimSpace(w.Word); text != "" {
+			words = append(words, TranscriptWord{Text: text, Start: w.Start, End: w.End})
+		}
+	}
+	// Servers without word timestamps: segments are the finest there is
+	if len(words) == 0 {
+		for _, s := range result.Segments {
+			if text := strings.TrimSpace(s.Text); text != "" {
+				words = append(words, TranscriptWord{Text: text, Start: s.Start, End: s.End})
+			}
+		}
+	}
+	return words, result.Language, nil
+}
+
+// groupPhrases joins words into phrases at pauses and sentence ends
+func groupPhrases(words []TranscriptWord, fps float64) []TranscriptPhrase {
+	phrases := []TranscriptPhrase{}
+	var current []string
+	var start, end float64
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		first := int(start*fps + 0.5)
+		phrases = append(phrases, TranscriptPhrase{
+			Text:       strings.Join(current, " "),
+			Start:      start,
+			End:        end,
+			StartFrame: first,
+			Frames:     int(end*fps+0.5) - first,
+		})
+		current = nil
+	}
+	for _, w := range words {
+		if len(current) > 0 && w.Start-end >= phrasePause {
+			flush()
+		}
+		if len(current) == 0 {
+			start = w.Start
+		}
+		current = append(current, w.Text)
+		end = w.End
+		if strings.ContainsAny(w.Text[len(w.Text)-1:], ".!?") {
+			flush()
+		}
+	}
+	flush()
+	return phrases
+}
+
+// ExportShotCaptions saves a shot's transcript as srt or vtt captions
+func (a *App) ExportShotCaptions(projectId string, sceneId string, shotId string, format string) string {
+	format = strings.ToLower(format)
+	if format != "srt" && format != "vtt" {
+		return "Unsupported caption format: " + format
+	}
+	transcript, err := a.GetShotTranscript(projectId, sceneId, shotId)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	name := "captions"
+	for _, s := range a.GetShots(projectId, sceneId) {
+		if s.ID == shotId {
+			name = sanitizeFileName(s.Name)
+		}
+	}
+
+	outPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Captions",
+		DefaultFilename: name + "." + format,
+		Filters: []runtime.FileFilter{
+			{DisplayName: strings.ToUpper(format) + " Captions", Pattern: "*." + format},
+		},
+	})
+	if err != nil || outPath == "" {
+		return "Cancelled"
+	}
+	rememberExportDir(filepath.Dir(outPath))
+	if err := os.WriteFile(outPath, []byte(formatCaptions(transcript.Phrases, format)), 0644); err != nil {
+		return "Error: " + err.Error()
+	}
+	return "Success"
+}
+
+// formatCaptions renders phrases as SRT or WebVTT
+func formatCaptions(phrases []TranscriptPhrase, format string) string {
+	var b strings.Builder
+	separator := ","
+	if format == "vtt" {
+		b.WriteString("WEBVTT\n\n")
+		separator = "."
+	}
+	stamp := func(seconds float64) string {
+		ms := int(seconds*1000 + 0.5)
+		return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, separator, ms%1000)
+	}
+	for i, p := range phrases {
+		if format == "srt" {
+			fmt.Fprintf(&b, "%d\n", i+1)
+		}
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", stamp(p.Start), stamp(p.End), p.Text)
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupPhrases(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []TranscriptWord
+		fps   float64
+		want  []TranscriptPhrase
+	}{
+		{name: "no words", words: nil, fps: 24, want: []TranscriptPhrase{}},
+		{
+			name:  "one word",
+			words: []TranscriptWord{{Text: "Hello", Start: 0.5, End: 1}},
+			fps:   24,
+			want:  []TranscriptPhrase{{Text: "Hello", Start: 0.5, End: 1, StartFrame: 12, Frames: 12}},
+		},
+		{
+			name: "split at a pause",
+			words: []TranscriptWord{
+				{Text: "one", Start: 0, End: 0.3},
+				{Text: "two", Start: 0.35, End: 0.6},
+				{Text: "three", Start: 1.0, End: 1.4}, // 0.4s after "two"
+			},
+			fps: 10,
+			want: []TranscriptPhrase{
+				{Text: "one two", Start: 0, End: 0.6, StartFrame: 0, Frames: 6},
+				{Text: "three", Start: 1.0, End: 1.4, StartFrame: 10, Frames: 4},
+			},
+		},
+		{
+			name: "split at sentence ends",
+			words: []TranscriptWord{
+				{Text: "Stop.", Start: 0, End: 0.5},
+				{Text: "Why?", Start: 0.5, End: 1},
+				{Text: "Go!", Start: 1, End: 1.5},
+				{Text: "now", Start: 1.5, End: 2},
+			},
+			fps: 2,
+			want: []TranscriptPhrase{
+				{Text: "Stop.", Start: 0, End: 0.5, StartFrame: 0, Frames: 1},
+				{Text: "Why?", Start: 0.5, End: 1, StartFrame: 1, Frames: 1},
+				{Text: "Go!", Start: 1, End: 1.5, StartFrame: 2, Frames: 1},
+				{Text: "now", Start: 1.5, End: 2, StartFrame: 3, Frames: 1},
+			},
+		},
+		{
+			name: "short gaps and commas keep one phrase",
+			words: []TranscriptWord{
+				{Text: "well,", Start: 0, End: 0.2},
+				{Text: "maybe", Start: 0.5, End: 0.9},
+			},
+			fps: 25,
+			want: []TranscriptPhrase{
+				{Text: "well, maybe", Start: 0, End: 0.9, StartFrame: 0, Frames: 23},
+			},
+		},
+		{
+			name:  "frames round to nearest",
+			words: []TranscriptWord{{Text: "hi", Start: 0.02, End: 0.1}},
+			fps:   16,
+			want:  []TranscriptPhrase{{Text: "hi", Start: 0.02, End: 0.1, StartFrame: 0, Frames: 2}},
+		},
+	}
+	for _, tt := range tests {
+		if got := groupPhrases(tt.words, tt.fps); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: groupPhrases = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- WORKFLOW BUNDLES ---
+
+// Workflows shared as .msworkflow zips with their mappings, params and metadata.
+
+type WorkflowBundleManifest struct {
+	Format    string `json:"format"` // Always "motion-studio-workflow"
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// WorkflowBundleImport describes what ImportWorkflowBundle installed
+type WorkflowBundleImport struct {
+	Name     string   `json:"name"`     // Local workflow name (may differ from the bundle's)
+	Mappings int      `json:"mappings"` // Node classes whose mappings were merged
+	Params   int      `json:"params"`   // Default parameters applied
+	Warnings []string `json:"warnings"`
+}
+
+const (
+	bundleFormat    = "motion-studio-workflow"
+	bundleVersion   = 1
+	bundleExtension = ".msworkflow"
+	bundleMaxEntry  = 64 << 20 // Per file; workflows are kilobytes
+)
+
+// safeWorkflowName applies the same rules as ImportWorkflow
+func safeWorkflowName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// workflowDefaults returns the tunable values of a workflow keyed like shot params
+func (a *App) workflowDefaults(name string) map[string]interface{} {
+	params, _ := a.GetWorkflowParameters(name)
+	defaults := map[string]interface{}{}
+	for _, p := range params {
+		if !p.Managed {
+			defaults[p.Key] = p.Value
+		}
+	}
+	return defaults
+}
+
+// ExportWorkflowBundle saves a workflow with its mappings, defaults and
+// metadata as a .msworkflow file chosen by the user
+func (a *App) ExportWorkflowBundle(name string) string {
+	raw, err := os.ReadFile(filepath.Join(a.getWorkflowsDir(), name+".json"))
+	if err != nil {
+		return "Error: workflow not found"
+	}
+	meta := a.loadWorkflowMeta(name)
+	meta.LastUsed = "" // Local history, not part of the setup
+
+	outPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Workflow Bundle",
+		DefaultFilename: name + bundleExtension,
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Motion Studio Workflow", Pattern: "*" + bundleExtension},
+		},
+	})
+	if err != nil || outPath == "" {
+		return "Cancelled"
+	}
+
+	manifest := WorkflowBundleManifest{
+		Format:    bundleFormat,
+		Version:   bundleVersion,
+		Name:      name,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+	entries := []struct {
+		name string
+		data interface{}
+	}{
+		{"manifest.json", manifest},
+		{"workflow.json", json.RawMessage(raw)},
+		{"mappings.json", a.workflowMappings(raw)},
+		{"params.json", a.workflowDefaults(name)},
+		{"meta.json", meta},
+	}
+
+	tmpPath := outPath + ".partial"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	zw := zip.NewWriter(out)
+	for _, e := range entries {
+		var w io.Writer
+		if w, err = zw.Create(e.name); err != nil {
+			break
+		}
+		data, _ := json.MarshalIndent(e.data, "", "  ")
+		if _, err = w.Write(data); err != nil {
+			break
+		}
+	}
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, outPath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "Error: " + err.Error()
+	}
+	return "Success"
+}
+
+// readBundleEntry returns a file from a bundle, or nil if it isn't there
+func readBundleEntry(zr *zip.ReadCloser, name string) ([]byte, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		if f.UncompressedSize64 > bundleMaxEntry {
+			return nil, fmt.Errorf("%s is too large", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(io.LimitReader(rc, bundleMaxEntry))
+	}
+	return nil, nil
+}
+
+// ImportWorkflowBundle installs a .msworkflow file. An empty path asks the
+// user to pick one. The workflow keeps the bundle's name unless a workflow
+// of that name exists, in which case a number is appended.
+func (a *App) ImportWorkflowBundle(path string) (WorkflowBundleImport, error) {
+	result := WorkflowBundleImport{Warnings: []string{}}
+	if path == "" {
+		selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+			Title: "Import Workflow Bundle",
+			Filters: []runtime.FileFilter{
+				{DisplayName: "Motion Studio Workflow", Pattern: "*" + bundleExtension},
+			},
+		})
+		if err != nil || selection == "" {
+			return result, fmt.Errorf("cancelled")
+		}
+		path = selection
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return result, fmt.Errorf("not a workflow bundle: %v", err)
+	}
+	defer zr.Close()
+
+	var manifest WorkflowBundleManifest
+	raw, err := readBundleEntry(zr, "manifest.json")
+	if err != nil {
+		return result, err
+	}
+	if raw == nil || json.Unmarshal(raw, &manifest) != nil || manifest.Format != bundleFormat {
+		return result, fmt.Errorf("not a Motion Studio workflow bundle")
+	}
+	if manifest.Version > bundleVersion {
+		return result, fmt.Errorf("bundle was made by a newer version (v%d)", manifest.Version)
+	}
+
+	workflow, err := readBundleEntry(zr, "workflow.json")
+	if err != nil {
+		return result, err
+	}
+	if workflow == nil {
+		return result, fmt.Errorf("bundle has no workflow.json")
+	}
+	if err := validateAPIWorkflow(workflow); err != nil {
+		return result, err
+	}
+
+	var mappings map[string]map[string]string
+	var defaults map[string]interface{}
+	var meta WorkflowMeta
+	for _, part := range []struct {
+		name string
+		out  interface{}
+	}{
+		{"mappings.json", &mappings},
+		{"params.json", &defaults},
+		{"meta.json", &meta},
+	} {
+		data, err := readBundleEntry(zr, part.name)
+		if err != nil {
+			return result, err
+		}
+		if data != nil {
+			if err := json.Unmarshal(data, part.out); err != nil {
+				return result, fmt.Errorf("%s is invalid: %v", part.name, err)
+			}
+		}
+	}
+
+	// Mappings go in first so the defaults below skip the inputs they manage
+	a.mergeNodeMappings(mappings)
+	result.Mappings = len(mappings)
+
+	if len(defaults) > 0 {
+		var nodes map[string]interface{}
+		json.Unmarshal(workflow, &nodes)
+		problems := a.applyParamOverrides(nodes, defaults)
+		result.Warnings = append(result.Warnings, problems...)
+		result.Params = len(defaults) - len(problems)
+		workflow, _ = json.MarshalIndent(nodes, "", "  ")
+	}
+
+	name, err := a.installWorkflow(manifest.Name, workflow)
+	if err != nil {
+		return result, err
+	}
+	meta.LastUsed = ""
+	if meta.Tags == nil {
+		meta.Tags = []string{}
+	}
+	a.saveWorkflowMeta(name, meta)
+
+	result.Name = name
+	return result, nil
+}
+
+// installWorkflow saves an imported workflow under a free name derived from
+// name, so an import never replaces a local workflow. Returns the name used.
+func (a *App) installWorkflow(name string, workflow []byte) (string, error) {
+	base := safeWorkflowName(name)
+	if base == "" {
+		base = "workflow_" + fmt.Sprintf("%d", time.Now().Unix())
+	}
+	name = base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(a.getWorkflowsDir(), name+".json")); os.IsNotExist(err) {
+			break
+		}
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	if err := os.WriteFile(filepath.Join(a.getWorkflowsDir(), name+".json"), workflow, 0644); err != nil {
+		return "", fmt.Errorf("error saving workflow: %v", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// --- UI-FORMAT WORKFLOW CONVERSION ---
+
+// Converts ComfyUI editor graphs to the API prompt format.
+
+// uiWorkflow is the part of the editor format the conversion reads
+type uiWorkflow struct {
+	Nodes       []uiNode          `json:"nodes"`
+	Links       []json.RawMessage `json:"links"`
+	Definitions *struct {
+		Subgraphs []json.RawMessage `json:"subgraphs"`
+	} `json:"definitions"`
+}
+
+type uiNode struct {
+	ID      json.Number `json:"id"`
+	Type    string      `json:"type"`
+	Title   string      `json:"title"`
+	Mode    int         `json:"mode"` // 0 always, 2 muted, 4 bypassed
+	Inputs  []uiSlot    `json:"inputs"`
+	Outputs []uiSlot    `json:"outputs"`
+	Widgets interface{} `json:"widgets_values"`
+}
+
+type uiSlot struct {
+	Name   string      `json:"name"`
+	Type   interface{} `json:"type"`
+	Link   *int64      `json:"link"`
+	Widget *struct {
+		Name string `json:"name"`
+	} `json:"widget"`
+}
+
+type uiLink struct {
+	Origin     string
+	OriginSlot int
+}
+
+const (
+	uiModeMuted    = 2
+	uiModeBypassed = 4
+)
+
+// uiOnlyNodes exist only in the editor and are never sent to the server
+var uiOnlyNodes = map[string]bool{
+	"Note":          true,
+	"MarkdownNote":  true,
+	"PrimitiveNode": true,
+	"Reroute":       true,
+}
+
+// seedControlValues are the "control after generate" widget the editor adds
+// after seed inputs; it is saved with the values but is not a node input
+var seedControlValues = map[string]bool{"fixed": true, "increment": true, "decrement": true, "randomize": true}
+
+// isUIWorkflow reports whether data is an editor graph rather than an API prompt
+func isUIWorkflow(data []byte) bool {
+	var probe struct {
+		Nodes []json.RawMessage `json:"nodes"`
+		Links []json.RawMessage `json:"links"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Nodes != nil && probe.Links != nil
+}
+
+// validateAPIWorkflow checks that data is an API prompt ComfyUI will accept
+func validateAPIWorkflow(data []byte) error {
+	var workflow map[string]json.RawMessage
+	if err := json.Unmarshal(data, &workflow); err != nil {
+		return fmt.Errorf("not valid JSON: %v", err)
+	}
+	if len(workflow) == 0 {
+		return fmt.Errorf("the workflow has no nodes")
+	}
+	for id, raw := range workflow {
+		var node struct {
+			ClassType string                 `json:"class_type"`
+			Inputs    map[string]interface{} `json:"inputs"`
+		}
+		if json.Unmarshal(raw, &node) != nil || node.ClassType == "" {
+			return fmt.Errorf("node %q has no class_type; this is not an API-format workflow", id)
+		}
+	}
+	return nil
+}
+
+// objectInfo is the subset of /object_info needed to name widget values
+type objectInfo map[string]struct {
+	Input struct {
+		Required json.RawMessage `json:"required"`
+		Optional json.RawMessage `json:"optional"`
+	} `json:"input"`
+	Output []interface{} `json:"output"`
+}
+
+// orderedInputs decodes an input group keeping the server's key order,
+// which is the order the editor lays widgets out in
+func orderedInputs(raw json.RawMessage) ([]string, map[string]json.RawMessage) {
+	specs := map[string]json.RawMessage{}
+	if len(raw) == 0 || json.Unmarshal(raw, &specs) != nil {
+		return nil, specs
+	}
+	var order []string
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.Token() // {
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			break
+		}
+		order = append(order, fmt.Sprint(key))
+		var skip json.RawMessage
+		if dec.Decode(&skip) != nil {
+			break
+		}
+	}
+	return order, specs
+}
+
+// widgetNames lists a node class's widget inputs in editor order. The bool
+// per name says whether a seed control value follows it.
+func (info objectInfo) widgetNames(class string) ([]string, []bool, bool) {
+	def, ok := info[class]
+	if !ok {
+		return nil, nil, false
+	}
+	var names []string
+	var controls []bool
+	add := func(raw json.RawMessage) {
+		order, specs := orderedInputs(raw)
+		for _, name := range order {
+			spec, ok := specs[name]
+			if !ok {
+				continue
+			}
+			isWidget, control := widgetSpec(name, spec)
+			if isWidget {
+				names = append(names, name)
+				controls = append(controls, control)
+			}
+		}
+	}
+	add(def.Input.Required)
+	add(def.Input.Optional)
+	return names, controls, true
+}
+
+// widgetSpec tells from an input spec ([type, options]) whether it is shown
+// as a widget and whether the editor adds a seed control after it
+func widgetSpec(name string, spec json.RawMessage) (bool, bool) {
+	var parts []json.RawMessage
+	if json.Unmarshal(spec, &parts) != nil || len(parts) == 0 {
+		return false, false
+	}
+	var options struct {
+		ForceInput           bool `json:"forceInput"`
+		ControlAfterGenerate bool `json:"control_after_generate"`
+	}
+	if len(parts) > 1 {
+		json.Unmarshal(parts[1], &options)
+	}
+	if options.ForceInput {
+		return false, false
+	}
+	var combo []interface{}
+	if json.Unmarshal(parts[0], &combo) == nil {
+		return true, false
+	}
+	var kind string
+	json.Unmarshal(parts[0], &kind)
+	switch kind {
+	case "INT":
+		return true, options.ControlAfterGenerate || name == "seed" || name == "noise_seed"
+	case "FLOAT", "STRING", "BOOLEAN", "COMBO":
+		return true, false
+	}
+	return false, false
+}
+
+// convertUIWorkflow turns an editor graph into an API prompt. info may be
+// nil when the server can't be reached.
+func convertUIWorkflow(data []byte, info objectInfo) ([]byte, error) {
+	var graph uiWorkflow
+	if err := json.Unmarshal(data, &graph); err != nil {
+		return nil, err
+	}
+	if graph.Definitions != nil && len(graph.Definitions.Subgraphs) > 0 {
+		return nil, fmt.Errorf("workflows with subgraphs can't be converted")
+	}
+
+	nodes := map[string]*uiNode{}
+	for i := range graph.Nodes {
+		nodes[graph.Nodes[i].ID.String()] = &graph.Nodes[i]
+	}
+	links := map[int64]uiLink{}
+	for _, raw := range graph.Links {
+		// [id, origin_id, origin_slot, target_id, target_slot, type], or an
+		// object with the same fields in newer editors
+		var l []json.Number
+		var obj struct {
+			ID         json.Number `json:"id"`
+			Origin     json.Number `json:"origin_id"`
+			OriginSlot int         `json:"origin_slot"`
+		}
+		if json.Unmarshal(raw, &obj) == nil && obj.ID != "" {
+			id, _ := obj.ID.Int64()
+			links[id] = uiLink{Origin: obj.Origin.String(), OriginSlot: obj.OriginSlot}
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var arr []interface{}
+		if dec.Decode(&arr) != nil || len(arr) < 3 {
+			continue
+		}
+		for _, v := range arr[:3] {
+			n, _ := v.(json.Number)
+			l = append(l, n)
+		}
+		id, _ := l[0].Int64()
+		slot, _ := l[2].Int64()
+		links[id] = uiLink{Origin: l[1].String(), OriginSlot: int(slot)}
+	}
+
+	// resolve follows a link through reroutes and bypassed nodes to the
+	// node that really produces the value; ok is false for dropped sources
+	var resolve func(link uiLink, depth int) (uiLink, bool)
+	resolve = func(link uiLink, depth int) (uiLink, bool) {
+		src, found := nodes[link.Origin]
+		if !found || depth > len(nodes) {
+			return link, false
+		}
+		switch {
+		case src.Type == "Reroute" || src.Mode == uiModeBypassed:
+			// Pass through the first input carrying the output's type
+			outType := ""
+			if link.OriginSlot < len(src.Outputs) {
+				outType = fmt.Sprint(src.Outputs[link.OriginSlot].Type)
+			}
+			for _, in := range src.Inputs {
+				if in.Link == nil {
+					continue
+				}
+				if src.Type == "Reroute" || fmt.Sprint(in.Type) == outType {
+					if next, ok := links[*in.Link]; ok {
+						return resolve(next, depth+1)
+					}
+				}
+			}
+			return link, false
+		case src.Mode == uiModeMuted || uiOnlyNodes[src.Type]:
+			return link, false
+		}
+		return link, true
+	}
+
+	prompt := map[string]interface{}{}
+	var missing []string
+	for _, node := range graph.Nodes {
+		if node.Mode == uiModeMuted || node.Mode == uiModeBypassed || uiOnlyNodes[node.Type] {
+			continue
+		}
+		if strings.HasPrefix(node.Type, "SetNode") || strings.HasPrefix(node.Type, "GetNode") {
+			return nil, fmt.Errorf("Get/Set nodes (node %s) can't be converted", node.ID)
+		}
+		inputs := map[string]interface{}{}
+
+		// Widget values, by name
+		switch values := node.Widgets.(type) {
+		case map[string]interface{}:
+			for k, v := range values {
+				inputs[k] = v
+			}
+		case []interface{}:
+			names, controls, known := info.widgetNames(node.Type)
+			if !known {
+				if info != nil {
+					missing = append(missing, node.Type)
+					continue
+				}
+				// No server: newer editors name every widget input on the node
+				for _, in := range node.Inputs {
+					if in.Widget != nil {
+						names = append(names, in.Widget.Name)
+						controls = append(controls, false)
+					}
+				}
+				if len(names) == 0 && len(values) > 0 {
+					return nil, fmt.Errorf("can't name the settings of %s (node %s) without a running ComfyUI", node.Type, node.ID)
+				}
+			}
+			v := 0
+			for i, name := range names {
+				if v >= len(values) {
+					break
+				}
+				inputs[name] = values[v]
+				v++
+				// Skip the editor-only "control after generate" value
+				if v < len(values) {
+					if s, ok := values[v].(string); ok && seedControlValues[s] {
+						if controls[i] || (!known && isNumber(values[v-1])) {
+							v++
+						}
+					}
+				}
+			}
+		default:
+			if info != nil {
+				if _, _, known := info.widgetNames(node.Type); !known {
+					missing = append(missing, node.Type)
+					continue
+				}
+			}
+		}
+
+		// Connections override widget values (converted widgets keep both)
+		for _, in := range node.Inputs {
+			if in.Link == nil {
+				continue
+			}
+			link, ok := links[*in.Link]
+			if !ok {
+				continue
+			}
+			src, ok := resolve(link, 0)
+			if !ok {
+				if source := nodes[link.Origin]; source != nil && source.Type == "PrimitiveNode" {
+					continue // The widget already holds the primitive's value
+				}
+				delete(inputs, in.Name)
+				continue
+			}
+			inputs[in.Name] = []interface{}{src.Origin, src.OriginSlot}
+		}
+
+		title := node.Title
+		if title == "" {
+			title = node.Type
+		}
+		prompt[node.ID.String()] = map[string]interface{}{
+			"class_type": node.Type,
+			"inputs":     inputs,
+			"_meta":      map[string]interface{}{"title": title},
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("ComfyUI doesn't know these nodes (install them first): %s", strings.Join(uniqueStrings(missing), ", "))
+	}
+	if len(prompt) == 0 {
+		return nil, fmt.Errorf("the workflow has no nodes to run")
+	}
+	return json.MarshalIndent(prompt, "", "  ")
+}
+
+func isNumber(v interface{}) bool {
+	switch n := v.(type) {
+	case float64:
+		return true
+	case string:
+		_, err := strconv.ParseFloat(n, 64)
+		return err == nil
+	}
+	return false
+}
+
+func uniqueStrings(sorted []string) []string {
+	out := sorted[:0]
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// prepareWorkflowImport returns API-format JSON for an imported file,
+// converting editor graphs with the primary server's node definitions
+func (a *App) prepareWorkflowImport(data []byte) ([]byte, error) {
+	if isUIWorkflow(data) {
+		info, err := a.fetchObjectInfo(a.comfyURL)
+		if err != nil {
+			fmt.Println("Workflow import: /object_info unavailable, converting from the file alone:", err)
+			info = nil
+		}
+		converted, err := convertUIWorkflow(data, info)
+		if err != nil {
+			return nil, fmt.Errorf("%v. Export it from ComfyUI with Workflow > Export (API) instead", err)
+		}
+		data = converted
+	}
+	if err := validateAPIWorkflow(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// --- WORKFLOW ORGANIZATION ---
+
+// Tags, category, favorite flag and notes stored next to each workflow.
+
+type WorkflowMeta struct {
+	Tags        []string `json:"tags"`
+	Category    string   `json:"category"`
+	Favorite    bool     `json:"favorite"`
+	Description string   `json:"description"`
+	LastUsed    string   `json:"lastUsed,omitempty"` // RFC3339, set by renders
+	FPS         float64  `json:"fps,omitempty"`      // Render frame rate, see shotfps.go
+
+	Interpolate InterpolateSettings `json:"interpolate"` // Post-download pass, see interpolate.go
+}
+
+// WorkflowFilter narrows and orders FindWorkflows; empty fields match everything
+type WorkflowFilter struct {
+	Query     string `json:"query"` // Substring of name, description or tag
+	Tag       string `json:"tag"`
+	Category  string `json:"category"`
+	Favorites bool   `json:"favorites"`
+	SortBy    string `json:"sortBy"` // name (default), lastUsed, category
+}
+
+const workflowMetaSuffix = ".meta.json"
+
+// isWorkflowFile tells workflow JSON apart from the metadata next to it
+func isWorkflowFile(name string) bool {
+	return strings.HasSuffix(name, ".json") && !strings.HasSuffix(name, workflowMetaSuffix)
+}
+
+func (a *App) workflowMetaPath(name string) string {
+	return filepath.Join(a.getWorkflowsDir(), name+workflowMetaSuffix)
+}
+
+func (a *App) loadWorkflowMeta(name string) WorkflowMeta {
+	meta := WorkflowMeta{Tags: []string{}}
+	if data, err := os.ReadFile(a.workflowMetaPath(name)); err == nil {
+		json.Unmarshal(data, &meta)
+	}
+	if meta.Tags == nil {
+		meta.Tags = []string{}
+	}
+	return meta
+}
+
+func (a *App) saveWorkflowMeta(name string, meta WorkflowMeta) error {
+	data, _ := json.MarshalIndent(meta, "", "  ")
+	return os.WriteFile(a.workflowMetaPath(name), data, 0644)
+}
+
+// SetWorkflowMeta replaces a workflow's tags, category, favorite flag and
+// description (the last-used time, frame rate and interpolation are kept)
+func (a *App) SetWorkflowMeta(name string, meta WorkflowMeta) string {
+	if _, err := os.Stat(filepath.Join(a.getWorkflowsDir(), name+".json")); err != nil {
+		return "Workflow not found"
+	}
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, t := range meta.Tags {
+		t = strings.TrimSpace(t)
+		if t != "" && !seen[strings.ToLower(t)] {
+			seen[strings.ToLower(t)] = true
+			tags = append(tags, t)
+		}
+	}
+	meta.Tags = tags
+	meta.Category = strings.Trim(strings.TrimSpace(meta.Category), "/")
+	current := a.loadWorkflowMeta(name)
+	meta.LastUsed = current.LastUsed
+	meta.FPS = current.FPS
+	meta.Interpolate = current.Interpolate
+	if err := a.saveWorkflowMeta(name, meta); err != nil {
+		return "Error saving workflow info"
+	}
+	return "Success"
+}
+
+// SetWorkflowFavorite toggles the favorite flag alone
+func (a *App) SetWorkflowFavorite(name string, favorite bool) string {
+	meta := a.loadWorkflowMeta(name)
+	meta.Favorite = favorite
+	return a.SetWorkflowMeta(name, meta)
+}
+
+// touchWorkflow records that a workflow just rendered
+func (a *App) touchWorkflow(name string) {
+	if name == "" {
+		return
+	}
+	meta := a.loadWorkflowMeta(name)
+	meta.LastUsed = time.Now().Format(time.RFC3339)
+	a.saveWorkflowMeta(name, meta)
+}
+
+// moveWorkflowMeta keeps metadata with a renamed or deleted workflow
+// (newName "" deletes it)
+func (a *App) moveWorkflowMeta(oldName string, newName string) {
+	if newName == "" {
+		os.Remove(a.workflowMetaPath(oldName))
+		return
+	}
+	os.Rename(a.workflowMetaPath(oldName), a.workflowMetaPath(newName))
+}
+
+// FindWorkflows filters and sorts the workflow list
+func (a *App) FindWorkflows(filter WorkflowFilter) []Workflow {
+	query := strings.ToLower(strings.TrimSpace(filter.Query))
+	result := []Workflow{}
+	for _, w := range a.GetWorkflows() {
+		if filter.Favorites && !w.Meta.Favorite {
+			continue
+		}
+		if filter.Category != "" && w.Meta.Category != filter.Category && !strings.HasPrefix(w.Meta.Category, filter.Category+"/") {
+			continue
+		}
+		if filter.Tag != "" && !containsFold(w.Meta.Tags, filter.Tag) {
+			continue
+		}
+		if query != "" {
+			text := strings.ToLower(w.Name + "\n" + w.Meta.Description + "\n" + strings.Join(w.Meta.Tags, "\n"))
+			if !strings.Contains(text, query) {
+				continue
+			}
+		}
+		result = append(result, w)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		x, y := result[i], result[j]
+		switch filter.SortBy {
+		case "lastUsed":
+			if x.Meta.LastUsed != y.Meta.LastUsed {
+				return x.Meta.LastUsed > y.Meta.LastUsed // Most recent first, never used last
+			}
+		case "category":
+			if x.Meta.Category != y.Meta.Category {
+				return x.Meta.Category < y.Meta.Category
+			}
+		}
+		return strings.ToLower(x.Name) < strings.ToLower(y.Name)
+	})
+	return result
+}
+
+// GetWorkflowTags returns every tag in use, for filter menus
+func (a *App) GetWorkflowTags() []string {
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, w := range a.GetWorkflows() {
+		for _, t := range w.Meta.Tags {
+			if !seen[strings.ToLower(t)] {
+				seen[strings.ToLower(t)] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return strings.ToLower(tags[i]) < strings.ToLower(tags[j]) })
+	return tags
+}
+
+// GetWorkflowCategories returns every category in use ("a/b" nests b under a)
+func (a *App) GetWorkflowCategories() []string {
+	seen := map[string]bool{}
+	categories := []string{}
+	for _, w := range a.GetWorkflows() {
+		if w.Meta.Category != "" && !seen[w.Meta.Category] {
+			seen[w.Meta.Category] = true
+			categories = append(categories, w.Meta.Category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
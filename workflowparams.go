@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// --- WORKFLOW PARAMETERS ---
+
+// Lists a workflow's plain value inputs and applies shot overrides.
+
+type WorkflowParameter struct {
+	Key       string      `json:"key"` // "<node id>.<input>", the override key
+	NodeID    string      `json:"nodeId"`
+	ClassType string      `json:"classType"`
+	Title     string      `json:"title"`
+	Input     string      `json:"input"`
+	Type      string      `json:"type"` // number, boolean or string
+	Value     interface{} `json:"value"`
+	Managed   bool        `json:"managed"` // Set by the app at render time
+}
+
+func paramKey(nodeId string, input string) string {
+	return nodeId + "." + input
+}
+
+func paramType(v interface{}) string {
+	switch v.(type) {
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	}
+	return ""
+}
+
+// managedInput reports whether renderShot overwrites an input of a class
+func (a *App) managedInput(classType string, input string) bool {
+	if _, mapped := a.nodeRules(classType)[input]; mapped {
+		return true
+	}
+	return classType == "WanImageToVideo" && input == "length"
+}
+
+// loadWorkflow reads a saved API-format workflow
+func (a *App) loadWorkflow(name string) (map[string]interface{}, error) {
+	if name == "" {
+		name = "default"
+	}
+	data, err := os.ReadFile(filepath.Join(a.getWorkflowsDir(), name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("workflow %q not found", name)
+	}
+	var workflow map[string]interface{}
+	if err := json.Unmarshal(data, &workflow); err != nil {
+		return nil, fmt.Errorf("workflow %q is not valid JSON: %v", name, err)
+	}
+	return workflow, nil
+}
+
+// GetWorkflowParameters lists the tunable inputs of a workflow, in node order
+func (a *App) GetWorkflowParameters(name string) ([]WorkflowParameter, error) {
+	workflow, err := a.loadWorkflow(name)
+	if err != nil {
+		return []WorkflowParameter{}, err
+	}
+
+	params := []WorkflowParameter{}
+	for id, node := range workflow {
+		nodeMap, _ := node.(map[string]interface{})
+		classType, _ := nodeMap["class_type"].(string)
+		inputs, _ := nodeMap["inputs"].(map[string]interface{})
+		title := classType
+		if meta, ok := nodeMap["_meta"].(map[string]interface{}); ok {
+			if t, _ := meta["title"].(string); t != "" {
+				title = t
+			}
+		}
+		for input, value := range inputs {
+			kind := paramType(value)
+			if kind == "" {
+				continue // Links and lists
+			}
+			params = append(params, WorkflowParameter{
+				Key:       paramKey(id, input),
+				NodeID:    id,
+				ClassType: classType,
+				Title:     title,
+				Input:     input,
+				Type:      kind,
+				Value:     value,
+				Managed:   a.managedInput(classType, input),
+			})
+		}
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].NodeID != params[j].NodeID {
+			ni, errI := strconv.Atoi(params[i].NodeID)
+			nj, errJ := strconv.Atoi(params[j].NodeID)
+			if errI == nil && errJ == nil {
+				return ni < nj
+			}
+			return params[i].NodeID < params[j].NodeID
+		}
+		return params[i].Input < params[j].Input
+	})
+	return params, nil
+}
+
+// coerceParam converts an override to the type of the value it replaces
+// (the frontend may send numbers from text fields as strings)
+func coerceParam(current interface{}, value interface{}) (interface{}, error) {
+	switch current.(type) {
+	case float64:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a number", v)
+			}
+			return f, nil
+		}
+		return nil, fmt.Errorf("expected a number")
+	case bool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not true or false", v)
+			}
+			return b, nil
+		}
+		return nil, fmt.Errorf("expected true or false")
+	case string:
+		return fmt.Sprint(value), nil
+	}
+	return nil, fmt.Errorf("input can't be overridden")
+}
+
+// applyParamOverrides writes overrides into a workflow, skipping managed
+// inputs. It returns one problem per override that couldn't be applied.
+func (a *App) applyParamOverrides(workflow map[string]interface{}, params map[string]interface{}) []string {
+	var problems []string
+	for key, value := range params {
+		nodeId, input, ok := strings.Cut(key, ".")
+		nodeMap, _ := workflow[nodeId].(map[string]interface{})
+		inputs, _ := nodeMap["inputs"].(map[string]interface{})
+		current, exists := inputs[input]
+		if !ok || !exists {
+			problems = append(problems, fmt.Sprintf("%s: no such input", key))
+			continue
+		}
+		classType, _ := nodeMap["class_type"].(string)
+		if a.managedInput(classType, input) {
+			problems = append(problems, fmt.Sprintf("%s: set by the app", key))
+			continue
+		}
+		coerced, err := coerceParam(current, value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		inputs[input] = coerced
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// shotWorkflow picks the workflow a render uses: the requested one, else the
+// shot's own, else the default
+func shotWorkflow(shot Shot, requested string) string {
+	if requested != "" {
+		return requested
+	}
+	if shot.Workflow != "" {
+		return shot.Workflow
+	}
+	return "default"
+}
+
+// SetShotParams stores a shot's workflow overrides after checking they fit
+// the workflow; nil or empty clears them
+func (a *App) SetShotParams(projectId string, sceneId string, shotId string, workflowName string, params map[string]interface{}) error {
+	var shot *Shot
+	shots := a.GetShots(projectId, sceneId)
+	for i := range shots {
+		if shots[i].ID == shotId {
+			shot = &shots[i]
+			break
+		}
+	}
+	if shot == nil {
+		return fmt.Errorf("shot not found")
+	}
+	if len(params) > 0 {
+		workflow, err := a.loadWorkflow(shotWorkflow(*shot, workflowName))
+		if err != nil {
+			return err
+		}
+		if problems := a.applyParamOverrides(workflow, params); len(problems) > 0 {
+			return fmt.Errorf("invalid parameters: %s", strings.Join(problems, "; "))
+		}
+	}
+	a.updateShot(projectId, sceneId, shotId, func(s *Shot) { s.Params = params })
+	return nil
+}
+
+// RenderShotWithParams stores overrides on the shot and queues its render.
+// Returns the render queue job ID.
+func (a *App) RenderShotWithParams(projectId string, sceneId string, shotId string, workflowName string, params map[string]interface{}) (string, error) {
+	if err := a.SetShotParams(projectId, sceneId, shotId, workflowName, params); err != nil {
+		return "", err
+	}
+	return a.QueueRender(projectId, sceneId, shotId, workflowName)
+}
+
+// paramsHash is a stable digest of overrides for the shot fingerprint
+func paramsHash(params map[string]interface{}) string {
+	if len(params) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(params) // Map keys are sorted
+	return string(data)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// --- INJECTED WORKFLOW PREVIEW ---
+
+// Shows the prompt a render would queue, with every injected value.
+
+type WorkflowInjection struct {
+	NodeID    string      `json:"nodeId"`
+	ClassType string      `json:"classType"`
+	Input     string      `json:"input"`
+	Role      string      `json:"role"` // IMAGE, PROMPT, SEED, AUDIO, ...
+	Value     interface{} `json:"value"`
+}
+
+type WorkflowPreview struct {
+	WorkflowName string              `json:"workflowName"`
+	JSON         string              `json:"json"` // The prompt as it would be sent
+	Injections   []WorkflowInjection `json:"injections"`
+	Warnings     []string            `json:"warnings"`
+
+	Workflow map[string]interface{} `json:"-"`
+}
+
+func (p *WorkflowPreview) inject(nodeId string, classType string, input string, role string, value interface{}) {
+	p.Injections = append(p.Injections, WorkflowInjection{NodeID: nodeId, ClassType: classType, Input: input, Role: role, Value: value})
+}
+
+func (p *WorkflowPreview) warn(message string) {
+	p.Warnings = append(p.Warnings, message)
+}
+
+// PreviewInjectedWorkflow returns the workflow RenderShot would queue for a shot
+func (a *App) PreviewInjectedWorkflow(projectId string, sceneId string, shotId string, workflowName string) (WorkflowPreview, error) {
+	var shot *Shot
+	shots := a.GetShots(projectId, sceneId)
+	for i := range shots {
+		if shots[i].ID == shotId {
+			shot = &shots[i]
+			break
+		}
+	}
+	if shot == nil {
+		return WorkflowPreview{}, fmt.Errorf("shot not found")
+	}
+
+	workflowName = shotWorkflow(*shot, workflowName)
+	if err := a.checkSourceImage(*shot, workflowName); err != nil {
+		return WorkflowPreview{}, err
+	}
+	shot.Seed = renderSeed(*shot, 0, 0)
+	shot.Quality = QualityFinal // What RenderShot queues
+	preview, err := a.buildShotWorkflow(shot, workflowName, true, func(path string) (string, error) {
+		return filepath.Base(path), nil
+	})
+	if err != nil {
+		return WorkflowPreview{}, err
+	}
+	preview.WorkflowName = workflowName
+	a.applyDevice(a.comfyURL, *shot, preview.Workflow, preview)
+	if shot.SeedMode == SeedRandom {
+		preview.warn("seed mode is random; the render will draw its own seed")
+	}
+	sort.Slice(preview.Injections, func(i, j int) bool {
+		if preview.Injections[i].NodeID != preview.Injections[j].NodeID {
+			return preview.Injections[i].NodeID < preview.Injections[j].NodeID
+		}
+		return preview.Injections[i].Input < preview.Injections[j].Input
+	})
+	data, _ := json.MarshalIndent(preview.Workflow, "", "  ")
+	preview.JSON = string(data)
+	return *preview, nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- WORKFLOW VALIDATION ---
+
+// Checks a workflow against the server's /object_info before it is queued.
+
+type WorkflowProblem struct {
+	NodeID    string `json:"nodeId"`
+	ClassType string `json:"classType"`
+	Title     string `json:"title,omitempty"`
+	Input     string `json:"input,omitempty"`
+	Message   string `json:"message"`
+}
+
+type WorkflowValidation struct {
+	Workflow     string            `json:"workflow"`
+	Server       string            `json:"server"`
+	OK           bool              `json:"ok"`
+	MissingNodes []string          `json:"missingNodes"` // Class types the server doesn't have
+	Problems     []WorkflowProblem `json:"problems"`
+}
+
+// objectInfoTTL is how long a server's node definitions are reused
+const objectInfoTTL = 2 * time.Minute
+
+type cachedObjectInfo struct {
+	info    objectInfo
+	fetched time.Time
+}
+
+var (
+	objectInfoMu    sync.Mutex
+	objectInfoCache = map[string]cachedObjectInfo{}
+)
+
+// fetchObjectInfo returns the node definitions of a server, cached briefly
+// because the response runs to megabytes with many custom nodes
+func (a *App) fetchObjectInfo(server string) (objectInfo, error) {
+	objectInfoMu.Lock()
+	cached, ok := objectInfoCache[server]
+	objectInfoMu.Unlock()
+	if ok && time.Since(cached.fetched) < objectInfoTTL {
+		return cached.info, nil
+	}
+
+	var info objectInfo
+	if err := a.comfyJSONAt(server, "GET", "/object_info", nil, &info); err != nil {
+		return nil, err
+	}
+	objectInfoMu.Lock()
+	objectInfoCache[server] = cachedObjectInfo{info: info, fetched: time.Now()}
+	objectInfoMu.Unlock()
+	return info, nil
+}
+
+// comboOptions returns the allowed values of a list input, or nil for other types
+func comboOptions(spec json.RawMessage) []interface{} {
+	var parts []json.RawMessage
+	if json.Unmarshal(spec, &parts) != nil || len(parts) == 0 {
+		return nil
+	}
+	var options []interface{}
+	if json.Unmarshal(parts[0], &options) == nil {
+		return options
+	}
+	var kind string
+	if json.Unmarshal(parts[0], &kind) == nil && kind == "COMBO" && len(parts) > 1 {
+		var extra struct {
+			Options []interface{} `json:"options"`
+		}
+		json.Unmarshal(parts[1], &extra)
+		return extra.Options
+	}
+	return nil
+}
+
+// ValidateWorkflow checks a saved workflow against the primary ComfyUI server
+func (a *App) ValidateWorkflow(name string) (WorkflowValidation, error) {
+	return a.validateWorkflowOn(a.comfyURL, name)
+}
+
+func (a *App) validateWorkflowOn(server string, name string) (WorkflowValidation, error) {
+	if name == "" {
+		name = "default"
+	}
+	result := WorkflowValidation{Workflow: name, Server: server, MissingNodes: []string{}, Problems: []WorkflowProblem{}}
+
+	data, err := os.ReadFile(filepath.Join(a.getWorkflowsDir(), name+".json"))
+	if err != nil {
+		return result, fmt.Errorf("workflow %q not found", name)
+	}
+	var workflow map[string]struct {
+		ClassType string                 `json:"class_type"`
+		Inputs    map[string]interface{} `json:"inputs"`
+		Meta      struct {
+			Title string `json:"title"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(data, &workflow); err != nil {
+		return result, fmt.Errorf("workflow %q is not valid JSON: %v", name, err)
+	}
+
+	info, err := a.fetchObjectInfo(server)
+	if err != nil {
+		return result, fmt.Errorf("could not read node definitions from ComfyUI: %v", err)
+	}
+
+	ids := make([]string, 0, len(workflow))
+	for id := range workflow {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	missing := map[string]bool{}
+	for _, id := range ids {
+		node := workflow[id]
+		problem := func(input string, format string, args ...interface{}) {
+			result.Problems = append(result.Problems, WorkflowProblem{
+				NodeID:    id,
+				ClassType: node.ClassType,
+				Title:     node.Meta.Title,
+				Input:     input,
+				Message:   fmt.Sprintf(format, args...),
+			})
+		}
+
+		def, known := info[node.ClassType]
+		if !known {
+			if !missing[node.ClassType] {
+				missing[node.ClassType] = true
+				result.MissingNodes = append(result.MissingNodes, node.ClassType)
+			}
+			problem("", "node type %s is not installed on the server", node.ClassType)
+			continue
+		}
+		injected := a.nodeRules(node.ClassType)
+
+		requiredOrder, required := orderedInputs(def.Input.Required)
+		_, optional := orderedInputs(def.Input.Optional)
+		for _, input := range requiredOrder {
+			if _, set := node.Inputs[input]; !set {
+				problem(input, "required input %s is not set", input)
+			}
+		}
+
+		for input, value := range node.Inputs {
+			spec, isRequired := required[input]
+			if !isRequired {
+				spec = optional[input]
+			}
+
+			// Links: [node id, output slot]
+			if link, ok := value.([]interface{}); ok && len(link) == 2 {
+				source, _ := link[0].(string)
+				slot, _ := link[1].(float64)
+				src, exists := workflow[source]
+				if !exists {
+					problem(input, "connected to node %s, which is not in the workflow", source)
+					continue
+				}
+				if srcDef, ok := info[src.ClassType]; ok && int(slot) >= len(srcDef.Output) {
+					problem(input, "connected to output %d of %s, which has only %d", int(slot), src.ClassType, len(srcDef.Output))
+				}
+				continue
+			}
+
+			if injected[input] == "IMAGE" || injected[input] == "AUDIO" {
+				continue // Replaced with the uploaded file at render time
+			}
+			options := comboOptions(spec)
+			if options == nil {
+				continue
+			}
+			allowed := false
+			for _, o := range options {
+				if o == value {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				problem(input, "%v is not available on the server (missing model or file?)", value)
+			}
+		}
+	}
+
+	result.OK = len(result.Problems) == 0
+	return result, nil
+}
+
+// summary lists the problems in one line for errors
+func (v WorkflowValidation) summary() string {
+	if len(v.MissingNodes) > 0 {
+		return "missing nodes: " + strings.Join(v.MissingNodes, ", ")
+	}
+	parts := make([]string, 0, len(v.Problems))
+	for _, p := range v.Problems {
+		label := p.Title
+		if label == "" {
+			label = p.ClassType
+		}
+		parts = append(parts, fmt.Sprintf("%s (node %s): %s", label, p.NodeID, p.Message))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// checkWorkflowBeforeQueue blocks queueing a workflow that can't run. A
+// server that can't be asked doesn't block; renders report that themselves.
+func (a *App) checkWorkflowBeforeQueue(name string) error {
+	validation, err := a.validateWorkflowOn(a.comfyURL, name)
+	if err != nil || validation.OK {
+		return nil
+	}
+	return fmt.Errorf("workflow %q can't run: %s", validation.Workflow, validation.summary())
+}